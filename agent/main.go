@@ -4,14 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/shellhub-io/shellhub/pkg/agent"
 	"github.com/shellhub-io/shellhub/pkg/agent/pkg/selfupdater"
-	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/loglevel"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -26,12 +27,14 @@ import (
 var AgentVersion string
 
 func main() {
+	// configFile is the path to the optional YAML or TOML configuration file, whose settings are overridden by the
+	// environment variables.
+	var configFile string
+
 	// Default command.
 	rootCmd := &cobra.Command{ // nolint: exhaustruct
 		Use: "agent",
 		Run: func(cmd *cobra.Command, args []string) {
-			loglevel.SetLogLevel()
-
 			// NOTE(r): When T, the generic parameter, is a structure with required tag, the fallback for an
 			// "unprefixed" parameter is used.
 			//
@@ -49,15 +52,18 @@ func main() {
 			//    TenantID string `envconfig:"tenant_id" required:"true"`
 			//  }
 			//
-			//  This behavior is driven by the [envconfig] package. Check it out for more information.
+			//  This behavior follows the one of the [envconfig] package. Check it out for more information.
 			//
 			// [envconfig]: https://github.com/kelseyhightower/envconfig
-			cfg, err := envs.ParseWithPrefix[agent.Config]("shellhub")
+			cfg, err := agent.LoadConfig(configFile)
 			if err != nil {
 				envconfig.Usage("shellhub", &cfg) // nolint:errcheck
 				log.Fatal(err)
 			}
 
+			// The log level is set after loading the configuration, since it can be set by the file.
+			setLogLevel(cfg)
+
 			if os.Geteuid() == 0 && cfg.SingleUserPassword != "" {
 				log.Error("ShellHub agent cannot run as root when single-user mode is enabled.")
 				log.Error("To disable single-user mode unset SHELLHUB_SINGLE_USER_PASSWORD env.")
@@ -72,14 +78,14 @@ func main() {
 				os.Exit(1)
 			}
 
-			updater, err := selfupdater.NewUpdater(AgentVersion)
+			updater, err := selfupdater.NewUpdater(AgentVersion, AgentPlatform)
 			if err != nil {
 				log.Panic(err)
 			}
 
+			// A failure to complete the update keeps the agent running, so the device stays reachable.
 			if err := updater.CompleteUpdate(); err != nil {
-				log.Warning(err)
-				os.Exit(0)
+				log.WithError(err).Error("Failed to complete the update")
 			}
 
 			currentVersion := new(semver.Version)
@@ -108,20 +114,59 @@ func main() {
 			if err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"version":       AgentVersion,
-					"configuration": cfg,
+					"configuration": cfg.Redacted(),
 				}).Fatal("Failed to create agent")
 			}
 
 			if err := ag.Initialize(); err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"version":       AgentVersion,
-					"configuration": cfg,
+					"configuration": cfg.Redacted(),
 				}).Fatal("Failed to initialize agent")
 			}
 
+			if cfg.ControlSocket != "" {
+				go func() {
+					if err := ag.ServeControl(cfg.ControlSocket); err != nil {
+						log.WithError(err).WithFields(log.Fields{
+							"socket": cfg.ControlSocket,
+						}).Warn("Failed to serve the control interface")
+					}
+				}()
+			}
+
+			// SIGHUP reloads the configuration, applying the settings that do not identify the device.
+			go func() {
+				hup := make(chan os.Signal, 1)
+				signal.Notify(hup, syscall.SIGHUP)
+
+				for range hup {
+					reloaded, err := agent.LoadConfig(configFile)
+					if err != nil {
+						log.WithError(err).Error("Failed to reload the configuration")
+
+						continue
+					}
+
+					setLogLevel(reloaded)
+					ag.Reload(reloaded)
+
+					log.WithFields(log.Fields{
+						"version":            AgentVersion,
+						"keepalive_interval": reloaded.KeepAliveInterval,
+					}).Info("Configuration reloaded")
+				}
+			}()
+
 			listing := make(chan bool)
 			go func() {
 				<-listing
+
+				// The agent connected to the server, so an update pending is kept.
+				if err := updater.ConfirmUpdate(); err != nil {
+					log.WithError(err).Warn("Failed to confirm the update")
+				}
+
 				// NOTICE: We only start to ping the server when the agent is ready to accept connections.
 				// It will make the agent ping to server after the ticker time set on ping function, what is 10 minutes by
 				// default.
@@ -161,6 +206,8 @@ func main() {
 						if nextVersion.GreaterThan(currentVersion) {
 							if err := updater.ApplyUpdate(nextVersion); err != nil {
 								log.Error(err)
+
+								goto sleep
 							}
 
 							log.WithFields(log.Fields{
@@ -186,24 +233,20 @@ func main() {
 		Use:   "info",
 		Short: "Show information about the agent",
 		Run: func(cmd *cobra.Command, args []string) {
-			loglevel.SetLogLevel()
-
-			cfg := agent.Config{}
-
-			// Process unprefixed env vars for backward compatibility
-			envconfig.Process("", &cfg) // nolint:errcheck
-
-			if err := envconfig.Process("shellhub", &cfg); err != nil {
+			cfg, err := agent.LoadConfig(configFile)
+			if err != nil {
 				// show envconfig usage help users to run agent
-				envconfig.Usage("shellhub", &cfg) // nolint:errcheck
+				envconfig.Usage("shellhub", &agent.Config{}) // nolint:errcheck
 				log.Fatal(err)
 			}
 
-			ag, err := agent.NewAgentWithConfig(&cfg)
+			setLogLevel(cfg)
+
+			ag, err := agent.NewAgentWithConfig(cfg)
 			if err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"version":       AgentVersion,
-					"configuration": cfg,
+					"configuration": cfg.Redacted(),
 				}).Fatal("Failed to create agent")
 			}
 
@@ -211,7 +254,7 @@ func main() {
 			if err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"version":       AgentVersion,
-					"configuration": cfg,
+					"configuration": cfg.Redacted(),
 				}).Fatal("Failed to get agent information")
 			}
 
@@ -225,7 +268,7 @@ func main() {
 			if err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"version":       AgentVersion,
-					"configuration": cfg,
+					"configuration": cfg.Redacted(),
 				}).Fatal("Failed to marshal agent information")
 			}
 
@@ -236,6 +279,187 @@ func main() {
 		},
 	})
 
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "rotate-key",
+		Short: "Replace the device's private key",
+		Long: `Replace the device's private key by a new one, keeping the device's identity, sessions and tags on the
+server. The current key cannot authenticate the device anymore after the rotation.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := agent.LoadConfig(configFile)
+			if err != nil {
+				envconfig.Usage("shellhub", &cfg) // nolint:errcheck
+				log.Fatal(err)
+			}
+
+			setLogLevel(cfg)
+
+			ag, err := agent.NewAgentWithConfig(cfg)
+			if err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": AgentVersion,
+				}).Fatal("Failed to create agent")
+			}
+
+			if err := ag.Initialize(); err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": AgentVersion,
+				}).Fatal("Failed to initialize agent")
+			}
+
+			if err := ag.RotateKey(); err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": AgentVersion,
+				}).Fatal("Failed to rotate the device key")
+			}
+
+			log.WithFields(log.Fields{
+				"version": AgentVersion,
+			}).Info("Device key rotated")
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "rekey <token>",
+		Short: "Replace the device's private key with a re-key token",
+		Long: `Replace the device's private key by a new one, authorized by the one-time re-key token issued by an
+administrator, keeping the device's identity, sessions and tags on the server. Unlike rotate-key, it recovers a device
+whose key was revoked.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := agent.LoadConfig(configFile)
+			if err != nil {
+				envconfig.Usage("shellhub", &cfg) // nolint:errcheck
+				log.Fatal(err)
+			}
+
+			setLogLevel(cfg)
+
+			ag, err := agent.NewAgentWithConfig(cfg)
+			if err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": AgentVersion,
+				}).Fatal("Failed to create agent")
+			}
+
+			if err := ag.Rekey(args[0]); err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": AgentVersion,
+				}).Fatal("Failed to re-key the device")
+			}
+
+			log.WithFields(log.Fields{
+				"version": AgentVersion,
+			}).Info("Device key replaced")
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "status",
+		Short: "Show the status of the running agent",
+		Long:  `Show the connection state, the device and the active sessions of the agent running on this device.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			status, err := agent.GetStatus(controlSocket(configFile))
+			if err != nil {
+				cmd.PrintErrln("Failed to get the agent status:", err)
+				os.Exit(1)
+			}
+
+			data, err := json.MarshalIndent(status, "", "  ")
+			if err != nil {
+				cmd.PrintErrln("Failed to encode the agent status:", err)
+				os.Exit(1)
+			}
+
+			cmd.Println(string(data))
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "reconnect",
+		Short: "Force the running agent to reconnect",
+		Long:  `Force the agent running on this device to close its tunnel to the server and open a fresh one.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := agent.RequestReconnect(controlSocket(configFile)); err != nil {
+				cmd.PrintErrln("Failed to reconnect the agent:", err)
+				os.Exit(1)
+			}
+
+			cmd.Println("Reconnection requested")
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "doctor",
+		Short: "Diagnose the connection to the server",
+		Long: `Check, in turn, each stage of the connection to the server: resolving the server address, reaching its
+information endpoint, authenticating the device, opening the tunnel's websocket and pinging the server through it.
+
+The stage that fails is reported with a suggested fix, and the following ones are skipped. The tunnel's stages are
+refused while an agent is running on the device, since its tunnel would be replaced by the diagnostic's one.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := agent.LoadConfig(configFile)
+			if err != nil {
+				envconfig.Usage("shellhub", &cfg) // nolint:errcheck
+				log.Fatal(err)
+			}
+
+			ag, err := agent.NewAgentWithConfig(cfg)
+			if err != nil {
+				cmd.PrintErrln("Invalid configuration:", err)
+				os.Exit(1)
+			}
+
+			failed := false
+			for _, diagnostic := range ag.Diagnose(controlSocket(configFile)) {
+				switch {
+				case diagnostic.Skipped:
+					cmd.Printf("[SKIP] %s: %s\n", diagnostic.Stage, diagnostic.Description)
+				case diagnostic.Err != nil:
+					failed = true
+
+					cmd.Printf("[FAIL] %s: %s\n", diagnostic.Stage, diagnostic.Description)
+					cmd.Printf("       error: %s\n", diagnostic.Err)
+					if diagnostic.Hint != "" {
+						cmd.Printf("       hint: %s\n", diagnostic.Hint)
+					}
+				default:
+					cmd.Printf("[PASS] %s: %s\n", diagnostic.Stage, diagnostic.Description)
+				}
+			}
+
+			if failed {
+				os.Exit(1)
+			}
+		},
+	})
+
+	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
+		Use:   "validate [file]",
+		Short: "Validate the agent configuration",
+		Long: `Validate the agent configuration from the file, given as argument or by the --config flag, and the
+environment variables, which take precedence over the file.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			path := configFile
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			cfg, err := agent.LoadConfig(path)
+			if err != nil {
+				cmd.PrintErrln("Invalid configuration:", err)
+				os.Exit(1)
+			}
+
+			if _, err := agent.NewAgentWithConfig(cfg); err != nil {
+				cmd.PrintErrln("Invalid configuration:", err)
+				os.Exit(1)
+			}
+
+			cmd.Println("Configuration is valid")
+		},
+	})
+
 	rootCmd.AddCommand(&cobra.Command{ // nolint: exhaustruct
 		Use:   "sftp",
 		Short: "Starts the SFTP server",
@@ -246,6 +470,8 @@ It is initialized by the agent when a new SFTP session is created.`,
 		},
 	})
 
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "path to the YAML or TOML configuration file")
+
 	rootCmd.Version = AgentVersion
 
 	rootCmd.SetVersionTemplate(fmt.Sprintf("{{ .Name }} version: {{ .Version }}\ngo: %s\n",
@@ -257,3 +483,36 @@ It is initialized by the agent when a new SFTP session is created.`,
 
 	rootCmd.Execute() // nolint: errcheck
 }
+
+// controlSocket gets the path of the running agent's control socket from the configuration file, when given, and the
+// environment variables, without requiring the other settings.
+func controlSocket(configFile string) string {
+	if path := os.Getenv("SHELLHUB_CONTROL_SOCKET"); path != "" {
+		return path
+	}
+
+	if configFile != "" {
+		values, err := agent.LoadConfigFile(configFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if path, ok := values["control_socket"].(string); ok && path != "" {
+			return path
+		}
+	}
+
+	return agent.DefaultControlSocket
+}
+
+// setLogLevel sets the log level from the environment variables and, when the configuration sets it, from the loaded
+// configuration, where the environment variables take precedence over the file.
+func setLogLevel(cfg *agent.Config) {
+	loglevel.SetLogLevel()
+
+	if cfg.LogLevel != "" {
+		if level, err := log.ParseLevel(cfg.LogLevel); err == nil {
+			log.SetLevel(level)
+		}
+	}
+}
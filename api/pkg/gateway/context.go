@@ -56,6 +56,12 @@ func (c *Context) ID() *models.ID {
 	return nil
 }
 
+// SessionID returns the ID of the user's session got from JWT through gateway.
+// Notice: it is empty to the tokens that are not bound to a session.
+func (c *Context) SessionID() string {
+	return c.Request().Header.Get("X-Session-ID")
+}
+
 func (c *Context) Ctx() context.Context {
 	return c.Request().Context()
 }
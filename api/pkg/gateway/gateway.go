@@ -54,3 +54,12 @@ func IDFromContext(ctx context.Context) *models.ID {
 
 	return nil
 }
+
+// ClientFromContext returns the IP address, set by the gateway, and the user agent of the request's client.
+func ClientFromContext(ctx context.Context) (string, string) {
+	if c, ok := ctx.Value("ctx").(*Context); ok {
+		return c.Request().Header.Get("X-Real-IP"), c.Request().UserAgent()
+	}
+
+	return "", ""
+}
@@ -4,20 +4,22 @@ type Action int
 
 // AllActions is a struct to act like an Enum and facilitate to indicate the action used in the service.
 type AllActions struct {
-	Device    DeviceActions
-	Session   SessionActions
-	Firewall  FirewallActions
-	PublicKey PublicKeyActions
-	Namespace NamespaceActions
-	Billing   BillingActions
+	Device          DeviceActions
+	Session         SessionActions
+	Firewall        FirewallActions
+	PublicKey       PublicKeyActions
+	Namespace       NamespaceActions
+	Billing         BillingActions
+	AccessRequest   AccessRequestActions
+	EnrollmentToken EnrollmentTokenActions
 }
 
 type DeviceActions struct {
-	Accept, Reject, Update, Remove, Connect, Rename, CreateTag, UpdateTag, RemoveTag, RenameTag, DeleteTag int
+	Accept, Reject, Update, Remove, Connect, Rename, CreateTag, UpdateTag, RemoveTag, RenameTag, DeleteTag, RevokeKey, Rekey int
 }
 
 type SessionActions struct {
-	Play, Close, Remove, Details int
+	Play, Close, Remove, Details, Shadow int
 }
 
 type FirewallActions struct {
@@ -29,13 +31,21 @@ type PublicKeyActions struct {
 }
 
 type NamespaceActions struct {
-	Rename, AddMember, RemoveMember, EditMember, EnableSessionRecord, Delete int
+	Rename, AddMember, RemoveMember, EditMember, EnableSessionRecord, EditSessionPolicy, EditAgentTags, RequireMFA, Delete, TransferOwnership int
 }
 
 type BillingActions struct {
 	CreateCustomer, ChooseDevices, AddPaymentMethod, UpdatePaymentMethod, RemovePaymentMethod, CancelSubscription, CreateSubscription, GetSubscription int
 }
 
+type AccessRequestActions struct {
+	Review, Configure int
+}
+
+type EnrollmentTokenActions struct {
+	List, Create, Remove, Configure int
+}
+
 // Actions has all available and allowed actions.
 // You should use it to get the code's action.
 var Actions = AllActions{
@@ -51,12 +61,15 @@ var Actions = AllActions{
 		RemoveTag: DeviceRemoveTag,
 		RenameTag: DeviceRenameTag,
 		DeleteTag: DeviceDeleteTag,
+		RevokeKey: DeviceRevokeKey,
+		Rekey:     DeviceRekey,
 	},
 	Session: SessionActions{
 		Play:    SessionPlay,
 		Close:   SessionClose,
 		Remove:  SessionRemove,
 		Details: SessionDetails,
+		Shadow:  SessionShadow,
 	},
 	Firewall: FirewallActions{
 		Create: FirewallCreate,
@@ -77,7 +90,11 @@ var Actions = AllActions{
 		RemoveMember:        NamespaceRemoveMember,
 		EditMember:          NamespaceEditMember,
 		EnableSessionRecord: NamespaceEnableSessionRecord,
+		EditSessionPolicy:   NamespaceEditSessionPolicy,
+		EditAgentTags:       NamespaceEditAgentTags,
+		RequireMFA:          NamespaceRequireMFA,
 		Delete:              NamespaceDelete,
+		TransferOwnership:   NamespaceTransferOwnership,
 	},
 	Billing: BillingActions{
 		CreateCustomer:      BillingCreateCustomer,
@@ -89,4 +106,14 @@ var Actions = AllActions{
 		CreateSubscription:  BillingCreateSubscription,
 		GetSubscription:     BillingGetSubscription,
 	},
+	AccessRequest: AccessRequestActions{
+		Review:    AccessRequestReview,
+		Configure: AccessRequestConfigure,
+	},
+	EnrollmentToken: EnrollmentTokenActions{
+		List:      EnrollmentTokenList,
+		Create:    EnrollmentTokenCreate,
+		Remove:    EnrollmentTokenRemove,
+		Configure: EnrollmentTokenConfigure,
+	},
 }
@@ -362,10 +362,14 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Device.RenameTag,
 				Actions.Device.DeleteTag,
 
+				Actions.Device.RevokeKey,
+				Actions.Device.Rekey,
+
 				Actions.Session.Play,
 				Actions.Session.Close,
 				Actions.Session.Remove,
 				Actions.Session.Details,
+				Actions.Session.Shadow,
 
 				Actions.Firewall.Create,
 				Actions.Firewall.Edit,
@@ -380,6 +384,16 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Namespace.RemoveMember,
 				Actions.Namespace.EditMember,
 				Actions.Namespace.EnableSessionRecord,
+				Actions.Namespace.EditSessionPolicy,
+				Actions.Namespace.EditAgentTags,
+
+				Actions.AccessRequest.Review,
+				Actions.AccessRequest.Configure,
+
+				Actions.EnrollmentToken.List,
+				Actions.EnrollmentToken.Create,
+				Actions.EnrollmentToken.Remove,
+				Actions.EnrollmentToken.Configure,
 			},
 			requiredMocks: func() {
 			},
@@ -402,10 +416,14 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Device.RenameTag,
 				Actions.Device.DeleteTag,
 
+				Actions.Device.RevokeKey,
+				Actions.Device.Rekey,
+
 				Actions.Session.Play,
 				Actions.Session.Close,
 				Actions.Session.Remove,
 				Actions.Session.Details,
+				Actions.Session.Shadow,
 
 				Actions.Firewall.Create,
 				Actions.Firewall.Edit,
@@ -420,6 +438,9 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Namespace.RemoveMember,
 				Actions.Namespace.EditMember,
 				Actions.Namespace.EnableSessionRecord,
+				Actions.Namespace.EditSessionPolicy,
+				Actions.Namespace.EditAgentTags,
+				Actions.Namespace.RequireMFA,
 				Actions.Namespace.Delete,
 
 				Actions.Billing.AddPaymentMethod,
@@ -429,6 +450,14 @@ func TestCheckPermission(t *testing.T) {
 				Actions.Billing.CancelSubscription,
 				Actions.Billing.CreateSubscription,
 				Actions.Billing.GetSubscription,
+
+				Actions.AccessRequest.Review,
+				Actions.AccessRequest.Configure,
+
+				Actions.EnrollmentToken.List,
+				Actions.EnrollmentToken.Create,
+				Actions.EnrollmentToken.Remove,
+				Actions.EnrollmentToken.Configure,
 			},
 			requiredMocks: func() {
 			},
@@ -17,10 +17,14 @@ const (
 	DeviceRenameTag
 	DeviceDeleteTag
 
+	DeviceRevokeKey
+	DeviceRekey
+
 	SessionPlay
 	SessionClose
 	SessionRemove
 	SessionDetails
+	SessionShadow
 
 	FirewallCreate
 	FirewallEdit
@@ -43,7 +47,11 @@ const (
 	NamespaceRemoveMember
 	NamespaceEditMember
 	NamespaceEnableSessionRecord
+	NamespaceEditSessionPolicy
+	NamespaceEditAgentTags
+	NamespaceRequireMFA
 	NamespaceDelete
+	NamespaceTransferOwnership
 
 	BillingCreateCustomer
 	BillingChooseDevices
@@ -54,6 +62,14 @@ const (
 	BillingCreateSubscription
 	BillingGetPaymentMethod
 	BillingGetSubscription
+
+	AccessRequestReview
+	AccessRequestConfigure
+
+	EnrollmentTokenList
+	EnrollmentTokenCreate
+	EnrollmentTokenRemove
+	EnrollmentTokenConfigure
 )
 
 var observerPermissions = Permissions{
@@ -94,12 +110,16 @@ var adminPermissions = Permissions{
 	DeviceRenameTag,
 	DeviceDeleteTag,
 
+	DeviceRevokeKey,
+	DeviceRekey,
+
 	DeviceUpdate,
 
 	SessionPlay,
 	SessionClose,
 	SessionRemove,
 	SessionDetails,
+	SessionShadow,
 
 	FirewallCreate,
 	FirewallEdit,
@@ -120,6 +140,16 @@ var adminPermissions = Permissions{
 	NamespaceRemoveMember,
 	NamespaceEditMember,
 	NamespaceEnableSessionRecord,
+	NamespaceEditSessionPolicy,
+	NamespaceEditAgentTags,
+
+	AccessRequestReview,
+	AccessRequestConfigure,
+
+	EnrollmentTokenList,
+	EnrollmentTokenCreate,
+	EnrollmentTokenRemove,
+	EnrollmentTokenConfigure,
 }
 
 var ownerPermissions = Permissions{
@@ -137,12 +167,16 @@ var ownerPermissions = Permissions{
 	DeviceRenameTag,
 	DeviceDeleteTag,
 
+	DeviceRevokeKey,
+	DeviceRekey,
+
 	DeviceUpdate,
 
 	SessionPlay,
 	SessionClose,
 	SessionRemove,
 	SessionDetails,
+	SessionShadow,
 
 	FirewallCreate,
 	FirewallEdit,
@@ -163,7 +197,11 @@ var ownerPermissions = Permissions{
 	NamespaceRemoveMember,
 	NamespaceEditMember,
 	NamespaceEnableSessionRecord,
+	NamespaceEditSessionPolicy,
+	NamespaceEditAgentTags,
+	NamespaceRequireMFA,
 	NamespaceDelete,
+	NamespaceTransferOwnership,
 
 	BillingCreateCustomer,
 	BillingChooseDevices,
@@ -173,4 +211,12 @@ var ownerPermissions = Permissions{
 	BillingCancelSubscription,
 	BillingCreateSubscription,
 	BillingGetSubscription,
+
+	AccessRequestReview,
+	AccessRequestConfigure,
+
+	EnrollmentTokenList,
+	EnrollmentTokenCreate,
+	EnrollmentTokenRemove,
+	EnrollmentTokenConfigure,
 }
@@ -0,0 +1,38 @@
+package keyring
+
+import (
+	"math/big"
+)
+
+// JWK is a RSA public key in the JSON Web Key format, as defined by RFC 7517.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+// JWKS is a set of JSON Web Keys, served to let other services verify the tokens.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the verification keys as a JSON Web Key Set.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+
+	for _, key := range k.keys {
+		set.Keys = append(set.Keys, JWK{
+			KeyType:   "RSA",
+			Use:       "sig",
+			Algorithm: "RS256",
+			KeyID:     key.ID,
+			Modulus:   encode(key.PublicKey.N.Bytes()),
+			Exponent:  encode(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+		})
+	}
+
+	return set
+}
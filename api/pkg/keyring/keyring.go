@@ -0,0 +1,154 @@
+// Package keyring holds the RSA keys used to sign and verify the tokens issued by the API, identifying each one by a
+// key ID, the "kid" header of the tokens, so the signing key can be rotated while the tokens signed by the previous keys
+// are still verified.
+package keyring
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrUnexpectedMethod = errors.New("unexpected signing method")
+)
+
+// Key is a public key able to verify the tokens.
+type Key struct {
+	ID        string
+	PublicKey *rsa.PublicKey
+}
+
+// Keyring signs the tokens with its signing key and verifies them with any of its keys.
+type Keyring struct {
+	signing *rsa.PrivateKey
+	// keys are the verification keys, the signing key's public key being the first one.
+	keys []Key
+}
+
+// New creates a Keyring that signs with the private key and verifies with its public key and with the verification
+// keys, like the public keys of the previous signing keys.
+func New(signing *rsa.PrivateKey, verification ...*rsa.PublicKey) *Keyring {
+	keyring := &Keyring{signing: signing}
+
+	for _, key := range append([]*rsa.PublicKey{&signing.PublicKey}, verification...) {
+		if key == nil {
+			continue
+		}
+
+		id := KeyID(key)
+		if _, ok := keyring.lookup(id); ok {
+			continue
+		}
+
+		keyring.keys = append(keyring.keys, Key{ID: id, PublicKey: key})
+	}
+
+	return keyring
+}
+
+// With returns a Keyring with the same signing key that also verifies with the verification keys.
+func (k *Keyring) With(verification ...*rsa.PublicKey) *Keyring {
+	keys := make([]*rsa.PublicKey, 0, len(k.keys)+len(verification))
+	for _, key := range k.keys {
+		keys = append(keys, key.PublicKey)
+	}
+
+	return New(k.signing, append(keys, verification...)...)
+}
+
+// KeyID identifies the public key by its JWK thumbprint, as defined by RFC 7638, so the same key has always the same ID.
+func KeyID(key *rsa.PublicKey) string {
+	// The members are in lexicographic order and without whitespaces, as required by the RFC.
+	thumbprint := fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, encode(big.NewInt(int64(key.E)).Bytes()), encode(key.N.Bytes()))
+	sum := sha256.Sum256([]byte(thumbprint))
+
+	return encode(sum[:])
+}
+
+// SigningKeyID returns the ID of the key that signs the tokens.
+func (k *Keyring) SigningKeyID() string {
+	return k.keys[0].ID
+}
+
+// Keys returns the verification keys, the signing key's public key being the first one.
+func (k *Keyring) Keys() []Key {
+	return append([]Key(nil), k.keys...)
+}
+
+// Sign signs the claims with RS256 and the signing key, setting the key's ID in the token's header.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = k.SigningKeyID()
+
+	return token.SignedString(k.signing)
+}
+
+// PublicKey returns the key that verifies the tokens with the key ID. The tokens without a key ID were issued before the
+// keys were identified, so they are verified by the signing key.
+func (k *Keyring) PublicKey(id string) (*rsa.PublicKey, error) {
+	if id == "" {
+		return k.keys[0].PublicKey, nil
+	}
+
+	key, ok := k.lookup(id)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key.PublicKey, nil
+}
+
+// Keyfunc is a [jwt.Keyfunc] that returns the key that verifies the token, checking its signing method.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedMethod, token.Header["alg"])
+	}
+
+	id, _ := token.Header["kid"].(string)
+
+	return k.PublicKey(id)
+}
+
+func (k *Keyring) lookup(id string) (Key, bool) {
+	for _, key := range k.keys {
+		if key.ID == id {
+			return key, true
+		}
+	}
+
+	return Key{}, false
+}
+
+// ParsePublicKeys parses the RSA public keys from PEM blocks, like a file with the previous signing keys' public keys
+// concatenated.
+func ParsePublicKeys(data []byte) ([]*rsa.PublicKey, error) {
+	var keys []*rsa.PublicKey
+
+	for {
+		var block *pem.Block
+		if block, data = pem.Decode(data); block == nil {
+			break
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
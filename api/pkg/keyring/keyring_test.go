@@ -0,0 +1,119 @@
+package keyring
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	return key
+}
+
+func TestKeyID(t *testing.T) {
+	// The example of the RFC 7638, section 3.1.
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", KeyID(&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}))
+}
+
+func TestKeyring(t *testing.T) {
+	previous := generateKey(t)
+	current := generateKey(t)
+
+	keyring := New(current, &previous.PublicKey, &current.PublicKey)
+	assert.Len(t, keyring.Keys(), 2)
+	assert.Equal(t, KeyID(&current.PublicKey), keyring.SigningKeyID())
+
+	t.Run("verifies the tokens signed by the current key", func(t *testing.T) {
+		signed, err := keyring.Sign(jwt.MapClaims{"id": "id"})
+		assert.NoError(t, err)
+
+		token, err := jwt.Parse(signed, keyring.Keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, keyring.SigningKeyID(), token.Header["kid"])
+	})
+
+	t.Run("verifies the tokens signed by a previous key", func(t *testing.T) {
+		signed, err := New(previous).Sign(jwt.MapClaims{"id": "id"})
+		assert.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keyring.Keyfunc)
+		assert.NoError(t, err)
+	})
+
+	t.Run("verifies the tokens without a key ID with the current key", func(t *testing.T) {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"id": "id"}).SignedString(current)
+		assert.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keyring.Keyfunc)
+		assert.NoError(t, err)
+	})
+
+	t.Run("fails when the key is unknown", func(t *testing.T) {
+		signed, err := New(generateKey(t)).Sign(jwt.MapClaims{"id": "id"})
+		assert.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keyring.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("fails when the signing method is not RSA", func(t *testing.T) {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": "id"}).SignedString([]byte("secret"))
+		assert.NoError(t, err)
+
+		_, err = jwt.Parse(signed, keyring.Keyfunc)
+		assert.ErrorIs(t, err, ErrUnexpectedMethod)
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	key := generateKey(t)
+
+	set := New(key).JWKS()
+	assert.Len(t, set.Keys, 1)
+	assert.Equal(t, "RSA", set.Keys[0].KeyType)
+	assert.Equal(t, "RS256", set.Keys[0].Algorithm)
+	assert.Equal(t, KeyID(&key.PublicKey), set.Keys[0].KeyID)
+	assert.Equal(t, "AQAB", set.Keys[0].Exponent)
+
+	n, err := base64.RawURLEncoding.DecodeString(set.Keys[0].Modulus)
+	assert.NoError(t, err)
+	assert.Equal(t, key.N, new(big.Int).SetBytes(n))
+}
+
+func TestParsePublicKeys(t *testing.T) {
+	encode := func(key *rsa.PrivateKey) []byte {
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		assert.NoError(t, err)
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	first := generateKey(t)
+	second := generateKey(t)
+
+	keys, err := ParsePublicKeys(append(encode(first), encode(second)...))
+	assert.NoError(t, err)
+	assert.Equal(t, []*rsa.PublicKey{&first.PublicKey, &second.PublicKey}, keys)
+
+	keys, err = ParsePublicKeys(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParsePublicKeys(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("invalid")}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,271 @@
+// Package ldap implements the authentication of users against a LDAP directory, like OpenLDAP or Active Directory,
+// through the go-ldap's client.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/shellhub-io/shellhub/api/pkg/membership"
+)
+
+// UsernamePlaceholder is replaced by the escaped username in the user filter.
+const UsernamePlaceholder = "{username}"
+
+var (
+	ErrUserNotFound       = errors.New("LDAP user not found")
+	ErrInvalidCredentials = errors.New("invalid LDAP credentials")
+)
+
+// Config is the configuration of the LDAP's authentication. It is loaded from the environment variables prefixed by
+// LDAP_.
+type Config struct {
+	// URL is the directory's URL, with the ldap or ldaps scheme, like ldaps://ldap.example.com.
+	URL string `envconfig:"ldap_url"`
+	// StartTLS upgrades a connection with the ldap scheme to TLS.
+	StartTLS bool `envconfig:"ldap_start_tls" default:"false"`
+	// CAFile is the path of the PEM file with the certificates that sign the directory's certificate. When empty, the
+	// system's certificates are used.
+	CAFile string `envconfig:"ldap_ca_file"`
+	// InsecureSkipVerify skips the verification of the directory's certificate.
+	InsecureSkipVerify bool `envconfig:"ldap_insecure_skip_verify" default:"false"`
+	// BindDN and BindPassword are the credentials used to search the user. When empty, the search is anonymous.
+	BindDN       string `envconfig:"ldap_bind_dn"`
+	BindPassword string `envconfig:"ldap_bind_password"`
+	// BaseDN is where the users are searched.
+	BaseDN string `envconfig:"ldap_base_dn"`
+	// UserFilter is the filter that finds the user, where {username} is replaced by the escaped username.
+	UserFilter string `envconfig:"ldap_user_filter" default:"(&(objectClass=person)(uid={username}))"`
+	// UsernameAttribute, NameAttribute and EmailAttribute are the attributes mapped to the user's data.
+	UsernameAttribute string `envconfig:"ldap_username_attribute" default:"uid"`
+	NameAttribute     string `envconfig:"ldap_name_attribute" default:"cn"`
+	EmailAttribute    string `envconfig:"ldap_email_attribute" default:"mail"`
+	// GroupsAttribute is the user's attribute with the DNs of its groups.
+	GroupsAttribute string `envconfig:"ldap_groups_attribute" default:"memberOf"`
+	// Groups maps the directory's groups to namespaces' memberships. A group is identified by its DN or its CN.
+	Groups membership.Mappings `envconfig:"ldap_groups"`
+	// Timeout limits each operation with the directory.
+	Timeout time.Duration `envconfig:"ldap_timeout" default:"10s"`
+}
+
+// Enabled checks if the LDAP's authentication is configured.
+func (c *Config) Enabled() bool {
+	return c.URL != "" && c.BaseDN != ""
+}
+
+// User is a user authenticated by the directory.
+type User struct {
+	DN       string
+	Username string
+	Name     string
+	Email    string
+	// Groups has the DN and the CN of each user's group.
+	Groups []string
+}
+
+// Authenticator authenticates the users against a directory. It is implemented by Directory.
+type Authenticator interface {
+	// Authenticate finds the user by its username and checks its password.
+	Authenticate(ctx context.Context, username, password string) (*User, error)
+	// Config returns the directory's configuration.
+	Config() Config
+}
+
+// Directory authenticates the users against a LDAP directory.
+type Directory struct {
+	config Config
+	tls    *tls.Config
+	// dial connects to the directory. It is replaced by the tests, which do not have a directory.
+	dial func() (goldap.Client, error)
+}
+
+var _ Authenticator = (*Directory)(nil)
+
+// NewDirectory creates a Directory from its configuration, loading the certificates of CAFile.
+func NewDirectory(config Config) (*Directory, error) {
+	if !strings.Contains(config.UserFilter, UsernamePlaceholder) {
+		return nil, fmt.Errorf("the LDAP user filter must have the %s placeholder", UsernamePlaceholder)
+	}
+
+	parsed, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsed.Scheme != "ldap" && parsed.Scheme != "ldaps" {
+		return nil, fmt.Errorf("unsupported LDAP scheme %q", parsed.Scheme)
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec
+	}
+
+	if config.CAFile != "" {
+		data, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found at %s", config.CAFile)
+		}
+	}
+
+	directory := &Directory{config: config, tls: tlsConfig}
+	directory.dial = directory.connect
+
+	return directory, nil
+}
+
+// connect connects to the directory's URL, upgrading the connection with StartTLS when asked. Each operation is limited
+// by the configuration's timeout.
+func (d *Directory) connect() (goldap.Client, error) {
+	conn, err := goldap.DialURL(d.config.URL, goldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}), goldap.DialWithTLSConfig(d.tls))
+	if err != nil {
+		return nil, err
+	}
+
+	conn.SetTimeout(d.config.Timeout)
+
+	if d.config.StartTLS && strings.HasPrefix(d.config.URL, "ldap://") {
+		if err := conn.StartTLS(d.tls); err != nil {
+			conn.Close()
+
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// Config returns the directory's configuration.
+func (d *Directory) Config() Config {
+	return d.config
+}
+
+// Authenticate finds the user by its username and checks its password, binding to the directory as the user.
+//
+// It returns ErrUserNotFound when the directory does not have exactly one user with the username, and
+// ErrInvalidCredentials when the password is wrong.
+func (d *Directory) Authenticate(ctx context.Context, username, password string) (*User, error) {
+	// A bind with an empty password is an unauthenticated bind, which most directories accept for any DN.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+
+	// The go-ldap's client does not receive a context, so the connection is closed when the context is done, failing the
+	// operation in progress.
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if d.config.BindDN != "" {
+		if err := bind(conn, d.config.BindDN, d.config.BindPassword); err != nil {
+			return nil, fmt.Errorf("failed to bind as the search user: %w", err)
+		}
+	}
+
+	filter := strings.ReplaceAll(d.config.UserFilter, UsernamePlaceholder, goldap.EscapeFilter(username))
+
+	attributes := []string{d.config.UsernameAttribute, d.config.NameAttribute, d.config.EmailAttribute, d.config.GroupsAttribute}
+
+	// Two entries are enough to know that the username is ambiguous.
+	result, err := conn.Search(goldap.NewSearchRequest(
+		d.config.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, int(d.config.Timeout/time.Second), false,
+		filter, attributes, nil,
+	))
+	// When more entries than the limit match, the server returns the limit's entries with a size limit exceeded result.
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return nil, err
+	}
+
+	if result == nil || len(result.Entries) != 1 {
+		return nil, ErrUserNotFound
+	}
+
+	entry := result.Entries[0]
+
+	if err := bind(conn, entry.DN, password); err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		DN:       entry.DN,
+		Username: entry.GetEqualFoldAttributeValue(d.config.UsernameAttribute),
+		Name:     entry.GetEqualFoldAttributeValue(d.config.NameAttribute),
+		Email:    entry.GetEqualFoldAttributeValue(d.config.EmailAttribute),
+	}
+
+	if user.Username == "" {
+		user.Username = username
+	}
+
+	for _, group := range entry.GetEqualFoldAttributeValues(d.config.GroupsAttribute) {
+		user.Groups = append(user.Groups, group)
+
+		if cn := commonName(group); cn != "" {
+			user.Groups = append(user.Groups, cn)
+		}
+	}
+
+	return user, nil
+}
+
+// bind authenticates the connection with the DN and password, returning ErrInvalidCredentials when the directory
+// refuses them.
+func bind(conn goldap.Client, dn, password string) error {
+	if err := conn.Bind(dn, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return ErrInvalidCredentials
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// commonName returns the value of the DN's first RDN when it is a CN, like devs for cn=devs,ou=groups,dc=example,dc=com.
+func commonName(dn string) string {
+	parsed, err := goldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+
+	attribute := parsed.RDNs[0].Attributes[0]
+	if !strings.EqualFold(attribute.Type, "cn") {
+		return ""
+	}
+
+	return attribute.Value
+}
@@ -0,0 +1,208 @@
+package ldap
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+// client is a go-ldap's client of a directory with fixed entries. Only the operations used by the Directory are
+// implemented.
+type client struct {
+	goldap.Client
+	// passwords are the passwords of the DNs that can bind.
+	passwords map[string]string
+	// results are the entries found by each filter.
+	results map[string][]*goldap.Entry
+	closed  bool
+}
+
+func (c *client) Bind(dn, password string) error {
+	if expected, ok := c.passwords[dn]; !ok || expected != password {
+		return goldap.NewError(goldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+
+	return nil
+}
+
+func (c *client) Search(req *goldap.SearchRequest) (*goldap.SearchResult, error) {
+	entries := c.results[req.Filter]
+	if len(entries) > req.SizeLimit {
+		return &goldap.SearchResult{Entries: entries[:req.SizeLimit]}, goldap.NewError(goldap.LDAPResultSizeLimitExceeded, errors.New("size limit exceeded"))
+	}
+
+	return &goldap.SearchResult{Entries: entries}, nil
+}
+
+func (c *client) Close() {
+	c.closed = true
+}
+
+func TestNewDirectory(t *testing.T) {
+	t.Run("fails when the user filter does not have the placeholder", func(t *testing.T) {
+		_, err := NewDirectory(Config{URL: "ldap://localhost", BaseDN: "dc=example,dc=com", UserFilter: "(uid=john)"})
+		assert.Error(t, err)
+	})
+
+	t.Run("fails when the URL's scheme is not supported", func(t *testing.T) {
+		_, err := NewDirectory(Config{URL: "ldapi:///var/run/slapd/ldapi", BaseDN: "dc=example,dc=com", UserFilter: "(uid={username})"})
+		assert.Error(t, err)
+	})
+
+	t.Run("fails when the CA file does not exist", func(t *testing.T) {
+		_, err := NewDirectory(Config{URL: "ldap://localhost", BaseDN: "dc=example,dc=com", UserFilter: "(uid={username})", CAFile: "/nonexistent"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	john := goldap.NewEntry("uid=john,ou=users,dc=example,dc=com", map[string][]string{
+		"uid":      {"john"},
+		"cn":       {"John Doe"},
+		"mail":     {"john@example.com"},
+		"memberOf": {"cn=devs,ou=groups,dc=example,dc=com", "ou=ops,dc=example,dc=com"},
+	})
+
+	newClient := func() *client {
+		return &client{
+			passwords: map[string]string{
+				"cn=search,dc=example,dc=com":         "search",
+				"uid=john,ou=users,dc=example,dc=com": "secret",
+			},
+			results: map[string][]*goldap.Entry{
+				"(&(objectClass=person)(uid=john))": {john},
+				"(&(objectClass=person)(uid=jane))": {
+					goldap.NewEntry("uid=jane,ou=users,dc=example,dc=com", nil),
+					goldap.NewEntry("uid=jane,ou=others,dc=example,dc=com", nil),
+					goldap.NewEntry("uid=jane,ou=archive,dc=example,dc=com", nil),
+				},
+				// The filter that would be used if the username was not escaped.
+				"(&(objectClass=person)(uid=*))": {john},
+			},
+		}
+	}
+
+	config := Config{
+		URL:               "ldap://localhost",
+		BindDN:            "cn=search,dc=example,dc=com",
+		BindPassword:      "search",
+		BaseDN:            "dc=example,dc=com",
+		UserFilter:        "(&(objectClass=person)(uid={username}))",
+		UsernameAttribute: "uid",
+		NameAttribute:     "cn",
+		EmailAttribute:    "mail",
+		GroupsAttribute:   "memberOf",
+	}
+
+	cases := []struct {
+		description string
+		config      func(config Config) Config
+		username    string
+		password    string
+		expected    *User
+		err         error
+	}{
+		{
+			description: "fails when the password is empty",
+			username:    "john",
+			password:    "",
+			err:         ErrInvalidCredentials,
+		},
+		{
+			description: "fails when the search user's credentials are invalid",
+			config: func(config Config) Config {
+				config.BindPassword = "wrong"
+
+				return config
+			},
+			username: "john",
+			password: "secret",
+			err:      ErrInvalidCredentials,
+		},
+		{
+			description: "fails when the user does not exist",
+			username:    "jack",
+			password:    "secret",
+			err:         ErrUserNotFound,
+		},
+		{
+			description: "fails when the username matches more than one user",
+			username:    "jane",
+			password:    "secret",
+			err:         ErrUserNotFound,
+		},
+		{
+			description: "fails when the username tries to inject a filter",
+			username:    "*",
+			password:    "secret",
+			err:         ErrUserNotFound,
+		},
+		{
+			description: "fails when the password is wrong",
+			username:    "john",
+			password:    "wrong",
+			err:         ErrInvalidCredentials,
+		},
+		{
+			description: "succeeds when the credentials are valid",
+			username:    "john",
+			password:    "secret",
+			expected: &User{
+				DN:       "uid=john,ou=users,dc=example,dc=com",
+				Username: "john",
+				Name:     "John Doe",
+				Email:    "john@example.com",
+				Groups:   []string{"cn=devs,ou=groups,dc=example,dc=com", "devs", "ou=ops,dc=example,dc=com"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			c := config
+			if tc.config != nil {
+				c = tc.config(c)
+			}
+
+			directory, err := NewDirectory(c)
+			assert.NoError(t, err)
+
+			conn := newClient()
+			directory.dial = func() (goldap.Client, error) {
+				return conn, nil
+			}
+
+			user, err := directory.Authenticate(context.TODO(), tc.username, tc.password)
+			assert.ErrorIs(t, err, tc.err)
+			assert.Equal(t, tc.expected, user)
+
+			if tc.password != "" {
+				assert.True(t, conn.closed)
+			}
+		})
+	}
+}
+
+func TestAuthenticateUnreachable(t *testing.T) {
+	// A listener closed right away gives an address that refuses the connections.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	listener.Close()
+
+	directory, err := NewDirectory(Config{URL: "ldap://" + listener.Addr().String(), BaseDN: "dc=example,dc=com", UserFilter: "(uid={username})"})
+	assert.NoError(t, err)
+
+	_, err = directory.Authenticate(context.TODO(), "john", "secret")
+	assert.Error(t, err)
+}
+
+func TestCommonName(t *testing.T) {
+	assert.Equal(t, "devs", commonName("cn=devs,ou=groups,dc=example,dc=com"))
+	assert.Equal(t, "devs, ops", commonName(`CN=devs\, ops,OU=groups,DC=example,DC=com`))
+	assert.Equal(t, "", commonName("ou=ops,dc=example,dc=com"))
+	assert.Equal(t, "", commonName("invalid"))
+}
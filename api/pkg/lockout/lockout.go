@@ -0,0 +1,186 @@
+// Package lockout protects the authentication from brute-force attacks, tracking the failed attempts of a key, like an
+// account or a source IP, in the cache.
+//
+// After a number of free failures, each new failure blocks the key for an exponentially longer delay; after the maximum
+// number of failures, the key is locked until the lockout expires or it is reset, like by an administrator.
+package lockout
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+)
+
+// Policy defines when a key is blocked by its failures.
+type Policy struct {
+	// FreeFailures is the number of failures that do not block the key.
+	FreeFailures int
+	// BaseDelay is the delay after the first failure beyond the free ones, doubled on each new failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxFailures is the number of failures that locks the key for LockoutDuration.
+	MaxFailures     int
+	LockoutDuration time.Duration
+	// Window is the time, since the last failure, after which the failures are forgotten.
+	Window time.Duration
+	// History is the number of the most recent attempts kept on the record.
+	History int
+}
+
+var (
+	// AccountPolicy is the policy of the accounts, where a few failures are enough to block an attacker.
+	AccountPolicy = Policy{
+		FreeFailures:    3,
+		BaseDelay:       2 * time.Second,
+		MaxDelay:        5 * time.Minute,
+		MaxFailures:     10,
+		LockoutDuration: 30 * time.Minute,
+		Window:          time.Hour,
+		History:         10,
+	}
+
+	// SourcePolicy is the policy of the source IPs, which is more tolerant as many users may share an IP.
+	SourcePolicy = Policy{
+		FreeFailures:    10,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		MaxFailures:     50,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+		History:         10,
+	}
+)
+
+// Attempt is a failed attempt.
+type Attempt struct {
+	IP   string    `json:"ip"`
+	Time time.Time `json:"time"`
+}
+
+// Record is the failed attempts of a key.
+type Record struct {
+	Failures int       `json:"failures"`
+	Attempts []Attempt `json:"attempts"`
+	// BlockedUntil is when the key can try again.
+	BlockedUntil time.Time `json:"blocked_until"`
+	// Locked reports whether the key reached the maximum number of failures.
+	Locked bool `json:"locked"`
+}
+
+// Limiter tracks the failed attempts of the keys in a namespace of the cache, like the accounts or the source IPs.
+//
+// The failures are counted by an atomic counter of the cache, so concurrent failures are all counted. The record, which
+// keeps the recent attempts, is the last one written.
+type Limiter struct {
+	cache  cache.Cache
+	prefix string
+	policy Policy
+}
+
+// NewLimiter creates a Limiter that keeps the records at the cache's keys with the prefix.
+func NewLimiter(cache cache.Cache, prefix string, policy Policy) *Limiter {
+	return &Limiter{cache: cache, prefix: prefix, policy: policy}
+}
+
+func (l *Limiter) key(key string) string {
+	return "lockout/" + l.prefix + "/" + key
+}
+
+// counter is the cache's key of the key's failures counter.
+func (l *Limiter) counter(key string) string {
+	return l.key(key) + "/failures"
+}
+
+// Get returns the key's record. A key without failures has an empty record.
+func (l *Limiter) Get(ctx context.Context, key string) (*Record, error) {
+	record := new(Record)
+	if err := l.cache.Get(ctx, l.key(key), record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Blocked returns how long the key must wait before a new attempt, being zero when it is not blocked.
+func (l *Limiter) Blocked(ctx context.Context, key string) (time.Duration, error) {
+	record, err := l.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if record.BlockedUntil.IsZero() {
+		return 0, nil
+	}
+
+	if wait := record.BlockedUntil.Sub(clock.Now()); wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+// Fail records a failed attempt from the IP, blocking the key according to the policy.
+func (l *Limiter) Fail(ctx context.Context, key, ip string) (*Record, error) {
+	// The counter expires with the window, like the record, since the last failure.
+	failures, err := l.cache.Incr(ctx, l.counter(key), l.policy.Window)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := l.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+
+	record.Failures = int(failures)
+	record.Attempts = append(record.Attempts, Attempt{IP: ip, Time: now})
+	if len(record.Attempts) > l.policy.History {
+		record.Attempts = record.Attempts[len(record.Attempts)-l.policy.History:]
+	}
+
+	switch {
+	case record.Failures >= l.policy.MaxFailures:
+		record.Locked = true
+		record.BlockedUntil = now.Add(l.policy.LockoutDuration)
+	case record.Failures > l.policy.FreeFailures:
+		record.BlockedUntil = now.Add(l.delay(record.Failures - l.policy.FreeFailures))
+	}
+
+	ttl := l.policy.Window
+	if wait := record.BlockedUntil.Sub(now); wait > ttl {
+		ttl = wait
+	}
+
+	if err := l.cache.Set(ctx, l.key(key), record, ttl); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// delay returns the delay after the nth failure beyond the free ones.
+func (l *Limiter) delay(n int) time.Duration {
+	delay := l.policy.BaseDelay
+	for i := 1; i < n && delay < l.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > l.policy.MaxDelay {
+		return l.policy.MaxDelay
+	}
+
+	return delay
+}
+
+// Reset forgets the key's failures, unlocking it.
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	if err := l.cache.Delete(ctx, l.counter(key)); err != nil {
+		return err
+	}
+
+	return l.cache.Delete(ctx, l.key(key))
+}
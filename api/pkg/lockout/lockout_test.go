@@ -0,0 +1,181 @@
+package lockout
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryCache is a cache.Cache that keeps the values in memory, ignoring their expiration.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(_ context.Context, key string, value interface{}) error {
+	if data, ok := c[key]; ok {
+		return json.Unmarshal(data, value)
+	}
+
+	return nil
+}
+
+func (c memoryCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	c[key] = data
+
+	return err
+}
+
+func (c memoryCache) Delete(_ context.Context, key string) error {
+	delete(c, key)
+
+	return nil
+}
+
+func (c memoryCache) Incr(_ context.Context, key string, _ time.Duration) (int64, error) {
+	var value int64
+	if data, ok := c[key]; ok {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return 0, err
+		}
+	}
+
+	value++
+
+	data, err := json.Marshal(value)
+	c[key] = data
+
+	return value, err
+}
+
+func TestLimiter(t *testing.T) {
+	policy := Policy{
+		FreeFailures:    2,
+		BaseDelay:       time.Second,
+		MaxDelay:        4 * time.Second,
+		MaxFailures:     6,
+		LockoutDuration: time.Hour,
+		Window:          time.Hour,
+		History:         3,
+	}
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	clockMock := new(clockmocks.Clock)
+	clockMock.On("Now").Return(func() time.Time { return now })
+
+	backend := clock.DefaultBackend
+	clock.DefaultBackend = clockMock
+	defer func() { clock.DefaultBackend = backend }()
+
+	limiter := NewLimiter(memoryCache{}, "account", policy)
+
+	ctx := context.TODO()
+
+	// The free failures do not block, then the delay doubles up to the maximum, until the account is locked.
+	for i, expected := range []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Hour} {
+		record, err := limiter.Fail(ctx, "john", "192.168.1.1")
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, record.Failures)
+		assert.Equal(t, i+1 == policy.MaxFailures, record.Locked)
+
+		wait, err := limiter.Blocked(ctx, "john")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, wait)
+	}
+
+	record, err := limiter.Get(ctx, "john")
+	assert.NoError(t, err)
+	assert.Len(t, record.Attempts, policy.History)
+	assert.Equal(t, Attempt{IP: "192.168.1.1", Time: now}, record.Attempts[0])
+
+	// The other keys are not affected.
+	wait, err := limiter.Blocked(ctx, "jane")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// The block expires with the time.
+	now = now.Add(2 * time.Hour)
+
+	wait, err = limiter.Blocked(ctx, "john")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	// A reset unlocks the account.
+	now = now.Add(-2 * time.Hour)
+
+	assert.NoError(t, limiter.Reset(ctx, "john"))
+
+	wait, err = limiter.Blocked(ctx, "john")
+	assert.NoError(t, err)
+	assert.Zero(t, wait)
+
+	record, err = limiter.Get(ctx, "john")
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{}, record)
+}
+
+func TestLimiterConcurrentFailures(t *testing.T) {
+	cache := &lockedCache{cache: memoryCache{}}
+	limiter := NewLimiter(cache, "account", AccountPolicy)
+
+	ctx := context.TODO()
+
+	// The failures sent at once are all counted, instead of overwriting each other.
+	var wg sync.WaitGroup
+	for i := 0; i < AccountPolicy.MaxFailures; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := limiter.Fail(ctx, "john", "192.168.1.1")
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	record, err := limiter.Fail(ctx, "john", "192.168.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, AccountPolicy.MaxFailures+1, record.Failures)
+	assert.True(t, record.Locked)
+}
+
+// lockedCache serializes the calls to a cache, like a remote cache does, but does not make a sequence of them atomic.
+type lockedCache struct {
+	mu    sync.Mutex
+	cache memoryCache
+}
+
+func (c *lockedCache) Get(ctx context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Get(ctx, key, value)
+}
+
+func (c *lockedCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Set(ctx, key, value, ttl)
+}
+
+func (c *lockedCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Delete(ctx, key)
+}
+
+func (c *lockedCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.cache.Incr(ctx, key, ttl)
+}
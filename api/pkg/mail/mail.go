@@ -0,0 +1,222 @@
+// Package mail sends e-mails through a SMTP server, like the ones with the links to reset a password or to confirm an
+// e-mail address.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// TLSNone sends the e-mails in plain text, like to a local SMTP sink.
+	TLSNone = "none"
+	// TLSStartTLS upgrades the connection to TLS, failing when the server does not support it.
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects to the server through TLS, usually on the port 465.
+	TLSImplicit = "tls"
+)
+
+var ErrStartTLSNotSupported = errors.New("the SMTP server does not support STARTTLS")
+
+// Config is the configuration of the SMTP server. It is loaded from the environment variables prefixed by SMTP_.
+type Config struct {
+	Host string `envconfig:"smtp_host"`
+	Port int    `envconfig:"smtp_port" default:"587"`
+	// Username and Password are the credentials to the server. When empty, the e-mails are sent without
+	// authentication.
+	Username string `envconfig:"smtp_username"`
+	Password string `envconfig:"smtp_password"`
+	// From is the sender's address, like "ShellHub <noreply@example.com>".
+	From string `envconfig:"smtp_from"`
+	// TLS is how the connection is secured: TLSStartTLS, TLSImplicit or TLSNone.
+	TLS string `envconfig:"smtp_tls" default:"starttls"`
+	// InsecureSkipVerify skips the verification of the server's certificate.
+	InsecureSkipVerify bool `envconfig:"smtp_insecure_skip_verify" default:"false"`
+	// BaseURL is the ShellHub's public URL, like https://shellhub.example.com, used to build the links sent by e-mail.
+	BaseURL string `envconfig:"smtp_base_url"`
+	// Timeout limits the delivery of each e-mail.
+	Timeout time.Duration `envconfig:"smtp_timeout" default:"10s"`
+}
+
+// Enabled checks if the SMTP server is configured.
+func (c *Config) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// Message is an e-mail in plain text.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends e-mails through the configured SMTP server.
+type Mailer struct {
+	config  Config
+	from    *mail.Address
+	baseURL *url.URL
+}
+
+// NewMailer creates a Mailer from its configuration, validating the sender's address and the base URL.
+func NewMailer(config Config) (*Mailer, error) {
+	switch config.TLS {
+	case TLSNone, TLSStartTLS, TLSImplicit:
+	default:
+		return nil, fmt.Errorf("invalid SMTP TLS mode %q", config.TLS)
+	}
+
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP sender address: %w", err)
+	}
+
+	baseURL, err := url.Parse(config.BaseURL)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid SMTP base URL %q", config.BaseURL)
+	}
+
+	return &Mailer{config: config, from: from, baseURL: baseURL}, nil
+}
+
+// URL builds a link to the path of the ShellHub's public URL with the query.
+func (m *Mailer) URL(path string, query url.Values) string {
+	link := m.baseURL.JoinPath(path)
+	link.RawQuery = query.Encode()
+
+	return link.String()
+}
+
+// Send delivers the message to the SMTP server.
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	data, err := m.encode(to, msg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline) //nolint:errcheck
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if m.config.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return ErrStartTLSNotSupported
+		}
+
+		if err := client.StartTLS(m.tlsConfig()); err != nil {
+			return err
+		}
+	}
+
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return err
+	}
+
+	if err := client.Rcpt(to.Address); err != nil {
+		return err
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (m *Mailer) dial(ctx context.Context) (net.Conn, error) {
+	address := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	if m.config.TLS == TLSImplicit {
+		dialer := &tls.Dialer{Config: m.tlsConfig()}
+
+		return dialer.DialContext(ctx, "tcp", address)
+	}
+
+	dialer := &net.Dialer{}
+
+	return dialer.DialContext(ctx, "tcp", address)
+}
+
+func (m *Mailer) tlsConfig() *tls.Config {
+	return &tls.Config{
+		ServerName:         m.config.Host,
+		InsecureSkipVerify: m.config.InsecureSkipVerify, //nolint:gosec
+	}
+}
+
+// encode formats the message with its headers, encoding the subject and the body to be safe to any server.
+func (m *Mailer) encode(to *mail.Address, msg Message) ([]byte, error) {
+	var buffer bytes.Buffer
+
+	headers := [][2]string{
+		{"From", m.from.String()},
+		{"To", to.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", msg.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "text/plain; charset=utf-8"},
+		{"Content-Transfer-Encoding", "quoted-printable"},
+	}
+
+	for _, header := range headers {
+		fmt.Fprintf(&buffer, "%s: %s\r\n", header[0], header[1])
+	}
+
+	buffer.WriteString("\r\n")
+
+	writer := quotedprintable.NewWriter(&buffer)
+	if _, err := writer.Write([]byte(msg.Body)); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
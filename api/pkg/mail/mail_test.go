@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/mail/mailtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMailer(t *testing.T) {
+	valid := Config{Host: "smtp.example.com", From: "ShellHub <noreply@example.com>", TLS: TLSStartTLS, BaseURL: "https://shellhub.example.com"}
+
+	_, err := NewMailer(valid)
+	assert.NoError(t, err)
+
+	invalid := valid
+	invalid.TLS = "ssl"
+	_, err = NewMailer(invalid)
+	assert.Error(t, err)
+
+	invalid = valid
+	invalid.From = "noreply"
+	_, err = NewMailer(invalid)
+	assert.Error(t, err)
+
+	invalid = valid
+	invalid.BaseURL = "shellhub.example.com"
+	_, err = NewMailer(invalid)
+	assert.Error(t, err)
+}
+
+func TestMailerURL(t *testing.T) {
+	mailer, err := NewMailer(Config{From: "noreply@example.com", TLS: TLSNone, BaseURL: "https://shellhub.example.com/ui/"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://shellhub.example.com/ui/reset-password?id=id&token=a%2Bb", mailer.URL("/reset-password", url.Values{"id": {"id"}, "token": {"a+b"}}))
+}
+
+func TestMailerSend(t *testing.T) {
+	server := mailtest.NewServer()
+	defer server.Close()
+
+	server.Username = "user"
+	server.Password = "secret"
+
+	config := Config{
+		Host:     server.Host,
+		Port:     server.Port,
+		Username: "user",
+		Password: "secret",
+		From:     "ShellHub <noreply@example.com>",
+		TLS:      TLSNone,
+		BaseURL:  "https://shellhub.example.com",
+		Timeout:  5 * time.Second,
+	}
+
+	mailer, err := NewMailer(config)
+	assert.NoError(t, err)
+
+	body := "Olá John,\n\nUse the link below:\nhttps://shellhub.example.com/reset-password?id=id&token=" + strings.Repeat("a", 80) + "\n"
+
+	assert.NoError(t, mailer.Send(context.TODO(), Message{To: "John <john@example.com>", Subject: "Reset your password", Body: body}))
+
+	messages := server.Messages()
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "noreply@example.com", messages[0].From)
+	assert.Equal(t, []string{"john@example.com"}, messages[0].To)
+	assert.Equal(t, "Reset your password", messages[0].Header("Subject"))
+	assert.Equal(t, `"John" <john@example.com>`, messages[0].Header("To"))
+
+	_, encoded, _ := strings.Cut(messages[0].Data, "\r\n\r\n")
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(encoded)))
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(body, "\n", "\r\n"), string(decoded))
+
+	t.Run("fails when the credentials are wrong", func(t *testing.T) {
+		config := config
+		config.Password = "wrong"
+
+		mailer, err := NewMailer(config)
+		assert.NoError(t, err)
+
+		assert.Error(t, mailer.Send(context.TODO(), Message{To: "john@example.com", Subject: "subject", Body: "body"}))
+	})
+
+	t.Run("fails when the server does not support STARTTLS", func(t *testing.T) {
+		config := config
+		config.TLS = TLSStartTLS
+
+		mailer, err := NewMailer(config)
+		assert.NoError(t, err)
+
+		assert.ErrorIs(t, mailer.Send(context.TODO(), Message{To: "john@example.com", Subject: "subject", Body: "body"}), ErrStartTLSNotSupported)
+	})
+}
@@ -0,0 +1,197 @@
+// Package mailtest implements a SMTP sink that keeps the received e-mails in memory, to test the e-mails sent through
+// the mail package.
+package mailtest
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Message is an e-mail received by the server.
+type Message struct {
+	From string
+	To   []string
+	// Data is the e-mail with its headers, as sent by the client.
+	Data string
+}
+
+// Header returns the value of the e-mail's header.
+func (m Message) Header(key string) string {
+	msg, err := mail.ReadMessage(strings.NewReader(m.Data))
+	if err != nil {
+		return ""
+	}
+
+	return msg.Header.Get(key)
+}
+
+// Server is a SMTP sink listening on a local address.
+type Server struct {
+	// Host and Port are the server's address.
+	Host string
+	Port int
+	// Username and Password, when set, are the credentials required by the server.
+	Username string
+	Password string
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Message
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port.
+func NewServer() *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+
+	addr := listener.Addr().(*net.TCPAddr) //nolint:forcetypeassert
+
+	server := &Server{Host: addr.IP.String(), Port: addr.Port, listener: listener}
+
+	server.wg.Add(1)
+	go server.serve()
+
+	return server
+}
+
+// Messages returns the e-mails received by the server.
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	reply := func(code int, text string) {
+		conn.Write([]byte(strconv.Itoa(code) + " " + text + "\r\n")) //nolint:errcheck
+	}
+
+	reply(220, "mailtest ready")
+
+	var msg Message
+	authenticated := s.Username == ""
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		command, arg, _ := strings.Cut(line, " ")
+
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			conn.Write([]byte("250-mailtest\r\n250 AUTH PLAIN\r\n")) //nolint:errcheck
+		case "AUTH":
+			mechanism, response, _ := strings.Cut(arg, " ")
+			credentials, err := base64.StdEncoding.DecodeString(response)
+			if err != nil || !strings.EqualFold(mechanism, "PLAIN") {
+				reply(501, "invalid authentication")
+
+				continue
+			}
+
+			fields := strings.Split(string(credentials), "\x00")
+			if len(fields) != 3 || fields[1] != s.Username || fields[2] != s.Password {
+				reply(535, "authentication failed")
+
+				continue
+			}
+
+			authenticated = true
+			reply(235, "authenticated")
+		case "MAIL":
+			if !authenticated {
+				reply(530, "authentication required")
+
+				continue
+			}
+
+			msg = Message{From: address(arg)}
+			reply(250, "ok")
+		case "RCPT":
+			msg.To = append(msg.To, address(arg))
+			reply(250, "ok")
+		case "DATA":
+			reply(354, "end data with <CR><LF>.<CR><LF>")
+
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+
+			msg.Data = data.String()
+
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+
+			reply(250, "ok")
+		case "RSET", "NOOP":
+			reply(250, "ok")
+		case "QUIT":
+			reply(221, "bye")
+
+			return
+		default:
+			reply(502, "command not implemented")
+		}
+	}
+}
+
+// address extracts the address from the argument of the MAIL and RCPT commands, like FROM:<john@example.com>.
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value = strings.TrimSpace(value)
+
+	if end := strings.Index(value, ">"); end >= 0 {
+		value = value[:end+1]
+	}
+
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,81 @@
+// Package membership maps the groups of external identity providers to namespaces' memberships.
+package membership
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+)
+
+// Mapping maps the members of an identity provider's group to a namespace, with a role.
+type Mapping struct {
+	Name     string
+	TenantID string
+	Role     string
+}
+
+// Mappings is a list of Mapping decoded from a comma-separated list of group=tenant:role.
+type Mappings []Mapping
+
+var ErrMappingInvalid = errors.New("invalid group mapping")
+
+// Decode decodes a comma-separated list of group=tenant:role, like "devs=00000000-0000-4000-0000-000000000000:operator".
+//
+// The owner role cannot be mapped, since each namespace has a single owner.
+func (m *Mappings) Decode(value string) error {
+	mappings := Mappings{}
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, assignment, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrMappingInvalid, item)
+		}
+
+		tenant, role, ok := strings.Cut(assignment, ":")
+		if !ok || name == "" || tenant == "" {
+			return fmt.Errorf("%w: %q", ErrMappingInvalid, item)
+		}
+
+		if guard.GetRoleCode(role) == guard.RoleInvalidCode || role == guard.RoleOwner {
+			return fmt.Errorf("%w: %q has an invalid role", ErrMappingInvalid, item)
+		}
+
+		mappings = append(mappings, Mapping{Name: name, TenantID: tenant, Role: role})
+	}
+
+	*m = mappings
+
+	return nil
+}
+
+// Memberships returns the role of the user on each mapped namespace. When more than one of the user's groups is mapped
+// to the same namespace, the role with more permissions is used. The namespaces that are mapped, but not to any of the
+// user's groups, are returned with an empty role.
+func (m Mappings) Memberships(groups []string) map[string]string {
+	memberships := make(map[string]string)
+
+	for _, mapping := range m {
+		if _, ok := memberships[mapping.TenantID]; !ok {
+			memberships[mapping.TenantID] = ""
+		}
+
+		for _, group := range groups {
+			if group != mapping.Name {
+				continue
+			}
+
+			if current := memberships[mapping.TenantID]; current == "" || guard.CheckRole(mapping.Role, current) {
+				memberships[mapping.TenantID] = mapping.Role
+			}
+		}
+	}
+
+	return memberships
+}
@@ -0,0 +1,73 @@
+package membership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMappingsDecode(t *testing.T) {
+	cases := []struct {
+		description string
+		value       string
+		expected    Mappings
+		err         bool
+	}{
+		{
+			description: "decodes an empty value",
+			value:       "",
+			expected:    Mappings{},
+		},
+		{
+			description: "fails when the tenant is missing",
+			value:       "devs=operator",
+			err:         true,
+		},
+		{
+			description: "fails when the role is invalid",
+			value:       "devs=tenant:root",
+			err:         true,
+		},
+		{
+			description: "fails when the role is owner",
+			value:       "devs=tenant:owner",
+			err:         true,
+		},
+		{
+			description: "decodes a list of groups",
+			value:       "devs=tenant:operator, admins=tenant:administrator",
+			expected: Mappings{
+				{Name: "devs", TenantID: "tenant", Role: "operator"},
+				{Name: "admins", TenantID: "tenant", Role: "administrator"},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var mappings Mappings
+
+			err := mappings.Decode(tc.value)
+			if tc.err {
+				assert.ErrorIs(t, err, ErrMappingInvalid)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, mappings)
+		})
+	}
+}
+
+func TestMappingsMemberships(t *testing.T) {
+	mappings := Mappings{
+		{Name: "devs", TenantID: "tenant", Role: "operator"},
+		{Name: "admins", TenantID: "tenant", Role: "administrator"},
+		{Name: "observers", TenantID: "other", Role: "observer"},
+	}
+
+	assert.Equal(t, map[string]string{"tenant": "administrator", "other": ""}, mappings.Memberships([]string{"admins", "devs"}))
+	assert.Equal(t, map[string]string{"tenant": "operator", "other": "observer"}, mappings.Memberships([]string{"devs", "observers"}))
+	assert.Equal(t, map[string]string{"tenant": "", "other": ""}, mappings.Memberships(nil))
+}
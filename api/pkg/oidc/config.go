@@ -0,0 +1,33 @@
+package oidc
+
+import (
+	"github.com/shellhub-io/shellhub/api/pkg/membership"
+)
+
+// Config is the configuration of the OpenID Connect's login. It is loaded from the environment variables prefixed by
+// OIDC_.
+type Config struct {
+	// Issuer is the identity provider's URL. The provider's endpoints are discovered from it.
+	Issuer string `envconfig:"oidc_issuer"`
+	// ClientID is the identifier of ShellHub at the identity provider.
+	ClientID string `envconfig:"oidc_client_id"`
+	// ClientSecret is the secret of ShellHub at the identity provider. It can be empty to public clients, as the
+	// authorization code is also protected by PKCE.
+	ClientSecret string `envconfig:"oidc_client_secret"`
+	// RedirectURL is where the identity provider sends the user back with the authorization code.
+	RedirectURL string `envconfig:"oidc_redirect_url"`
+	// Scopes are the scopes requested to the identity provider.
+	Scopes []string `envconfig:"oidc_scopes" default:"openid,profile,email"`
+	// GroupsClaim is the ID token's claim with the user's groups.
+	GroupsClaim string `envconfig:"oidc_groups_claim" default:"groups"`
+	// Groups maps the identity provider's groups to namespaces' memberships.
+	Groups membership.Mappings `envconfig:"oidc_groups"`
+	// LinkByEmail links the identity provider's account to the local user with the same verified e-mail on its first
+	// login. When disabled, that login fails, so an account at the identity provider cannot take over a local user.
+	LinkByEmail bool `envconfig:"oidc_link_by_email" default:"false"`
+}
+
+// Enabled checks if the OpenID Connect's login is configured.
+func (c *Config) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != "" && c.RedirectURL != ""
+}
@@ -0,0 +1,91 @@
+package oidc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"math/big"
+)
+
+var ErrJWKS = errors.New("invalid JSON web key set")
+
+// jwk is a JSON web key. Only the RSA and EC signing keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwks is the JSON web key set with the identity provider's keys.
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+// parse converts the key set to a map of public keys indexed by their kid. Keys that are not used to sign, or whose
+// type is not supported, are ignored.
+func (s *jwks) parse() (map[string]interface{}, error) {
+	keys := make(map[string]interface{})
+
+	for _, key := range s.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch key.Kty {
+		case "RSA":
+			n, err := decodeBigInt(key.N)
+			if err != nil {
+				return nil, errors.Join(ErrJWKS, err)
+			}
+
+			e, err := decodeBigInt(key.E)
+			if err != nil {
+				return nil, errors.Join(ErrJWKS, err)
+			}
+
+			keys[key.Kid] = &rsa.PublicKey{N: n, E: int(e.Int64())}
+		case "EC":
+			var curve elliptic.Curve
+			switch key.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+
+			x, err := decodeBigInt(key.X)
+			if err != nil {
+				return nil, errors.Join(ErrJWKS, err)
+			}
+
+			y, err := decodeBigInt(key.Y)
+			if err != nil {
+				return nil, errors.Join(ErrJWKS, err)
+			}
+
+			keys[key.Kid] = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		}
+	}
+
+	return keys, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(data), nil
+}
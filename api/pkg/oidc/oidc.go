@@ -0,0 +1,324 @@
+// Package oidc implements the OpenID Connect's authorization code flow, protected by PKCE, used to log users in
+// through an external identity provider.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrDiscovery = errors.New("failed to discover the identity provider's configuration")
+	ErrExchange  = errors.New("failed to exchange the authorization code")
+	ErrIDToken   = errors.New("invalid ID token")
+)
+
+// Claims are the user's claims read from the ID token.
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Groups            []string
+}
+
+// discovery is the part of the identity provider's configuration used by the authorization code flow.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// KeysRefreshInterval is the minimum time between two fetches of the identity provider's keys, so tokens signed by
+// unknown keys cannot make each login fetch them again.
+const KeysRefreshInterval = time.Minute
+
+// Provider is an OpenID Connect's identity provider. Its configuration and keys are fetched on the first use, and the
+// keys are fetched again when a token is signed by an unknown key, at most once each KeysRefreshInterval.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discovery
+	keys      map[string]interface{}
+	fetched   time.Time
+}
+
+// NewProvider creates a Provider from its configuration.
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Config returns the provider's configuration.
+func (p *Provider) Config() Config {
+	return p.config
+}
+
+// GenerateRandom generates a random URL-safe string, used as the state, nonce and PKCE's code verifier.
+func GenerateRandom() (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Challenge derives the PKCE's S256 code challenge from the code verifier.
+func Challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeURL returns the URL where the user authenticates at the identity provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", Challenge(verifier))
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return d.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange redeems the authorization code at the identity provider, returning the claims of the verified ID token.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("code_verifier", verifier)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("client_id", p.config.ClientID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Join(ErrExchange, err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrExchange, res.StatusCode)
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil || token.IDToken == "" {
+		return nil, fmt.Errorf("%w: response without ID token", ErrExchange)
+	}
+
+	return p.Verify(ctx, token.IDToken, nonce)
+}
+
+// Verify checks the ID token's signature, issuer, audience, expiration and nonce, returning its claims.
+func (p *Provider) Verify(ctx context.Context, raw, nonce string) (*Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+
+	claims := jwt.MapClaims{}
+	if _, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		return p.key(ctx, d, kid)
+	}); err != nil {
+		return nil, errors.Join(ErrIDToken, err)
+	}
+
+	if !claims.VerifyIssuer(d.Issuer, true) {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrIDToken)
+	}
+
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrIDToken)
+	}
+
+	if _, ok := claims["exp"]; !ok {
+		return nil, fmt.Errorf("%w: missing expiration", ErrIDToken)
+	}
+
+	if value, _ := claims["nonce"].(string); value != nonce {
+		return nil, fmt.Errorf("%w: unexpected nonce", ErrIDToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrIDToken)
+	}
+
+	result := &Claims{Subject: subject}
+	result.Email, _ = claims["email"].(string)
+	result.EmailVerified, _ = claims["email_verified"].(bool)
+	result.Name, _ = claims["name"].(string)
+	result.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	switch groups := claims[p.config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				result.Groups = append(result.Groups, name)
+			}
+		}
+	case string:
+		result.Groups = []string{groups}
+	}
+
+	return result, nil
+}
+
+// discover fetches the identity provider's configuration, caching it when it is valid.
+func (p *Provider) discover(ctx context.Context) (*discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	d := new(discovery)
+	if err := p.get(ctx, strings.TrimSuffix(p.config.Issuer, "/")+"/.well-known/openid-configuration", d); err != nil {
+		return nil, errors.Join(ErrDiscovery, err)
+	}
+
+	// The issuer must be the one configured, so tokens of another provider cannot be accepted.
+	if strings.TrimSuffix(d.Issuer, "/") != strings.TrimSuffix(p.config.Issuer, "/") {
+		return nil, fmt.Errorf("%w: issuer %q does not match the configured one", ErrDiscovery, d.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: missing endpoints", ErrDiscovery)
+	}
+
+	p.discovery = d
+
+	return d, nil
+}
+
+// key returns the identity provider's public key identified by kid. The keys are fetched again when kid is unknown, as
+// the provider may have rotated them. The lock is not held while the keys are fetched, so a slow provider does not
+// block the logins whose keys are known.
+func (p *Provider) key(ctx context.Context, d *discovery, kid string) (interface{}, error) {
+	p.mu.Lock()
+
+	if key, ok := lookup(p.keys, kid); ok {
+		p.mu.Unlock()
+
+		return key, nil
+	}
+
+	if !p.fetched.IsZero() && time.Since(p.fetched) < KeysRefreshInterval {
+		p.mu.Unlock()
+
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	p.fetched = time.Now()
+	p.mu.Unlock()
+
+	set := new(jwks)
+	if err := p.get(ctx, d.JWKSURI, set); err != nil {
+		return nil, err
+	}
+
+	keys, err := set.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok := lookup(keys, kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return key, nil
+}
+
+// lookup finds the key identified by kid. A token without kid is accepted when the provider has a single key.
+func lookup(keys map[string]interface{}, kid string) (interface{}, bool) {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+
+	key, ok := keys[kid]
+
+	return key, ok
+}
+
+func (p *Provider) get(ctx context.Context, url string, value interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, url)
+	}
+
+	return json.NewDecoder(res.Body).Decode(value)
+}
@@ -0,0 +1,143 @@
+package oidc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc/oidctest"
+	"github.com/stretchr/testify/assert"
+)
+
+const redirectURL = "http://localhost/login/oidc"
+
+func login(t *testing.T, provider *oidc.Provider, server *oidctest.Server, nonce string) (string, string) {
+	t.Helper()
+
+	verifier, err := oidc.GenerateRandom()
+	assert.NoError(t, err)
+
+	url, err := provider.AuthCodeURL(context.TODO(), "state", nonce, verifier)
+	assert.NoError(t, err)
+
+	code, state, err := server.Login(url)
+	assert.NoError(t, err)
+	assert.Equal(t, "state", state)
+
+	return code, verifier
+}
+
+func TestExchange(t *testing.T) {
+	server := oidctest.NewServer("shellhub", "secret")
+	defer server.Close()
+
+	server.SetUser(map[string]interface{}{
+		"sub":                "subject",
+		"email":              "john@example.com",
+		"email_verified":     true,
+		"name":               "John Doe",
+		"preferred_username": "john",
+		"groups":             []string{"devs", "ops"},
+	})
+
+	t.Run("fails when the code verifier does not match the challenge", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config(redirectURL))
+
+		code, _ := login(t, provider, server, "nonce")
+
+		_, err := provider.Exchange(context.TODO(), code, "other", "nonce")
+		assert.ErrorIs(t, err, oidc.ErrExchange)
+	})
+
+	t.Run("fails when the nonce does not match", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config(redirectURL))
+
+		code, verifier := login(t, provider, server, "nonce")
+
+		_, err := provider.Exchange(context.TODO(), code, verifier, "other")
+		assert.ErrorIs(t, err, oidc.ErrIDToken)
+	})
+
+	t.Run("fails when the client secret is wrong", func(t *testing.T) {
+		config := server.Config(redirectURL)
+		config.ClientSecret = "wrong"
+		provider := oidc.NewProvider(config)
+
+		code, verifier := login(t, provider, server, "nonce")
+
+		_, err := provider.Exchange(context.TODO(), code, verifier, "nonce")
+		assert.ErrorIs(t, err, oidc.ErrExchange)
+	})
+
+	t.Run("fails when the code is used twice", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config(redirectURL))
+
+		code, verifier := login(t, provider, server, "nonce")
+
+		_, err := provider.Exchange(context.TODO(), code, verifier, "nonce")
+		assert.NoError(t, err)
+
+		_, err = provider.Exchange(context.TODO(), code, verifier, "nonce")
+		assert.ErrorIs(t, err, oidc.ErrExchange)
+	})
+
+	t.Run("fails when the token is issued to another client", func(t *testing.T) {
+		other := oidc.NewProvider(server.Config(redirectURL))
+		code, verifier := login(t, other, server, "nonce")
+
+		config := server.Config(redirectURL)
+		config.ClientID = "other"
+		provider := oidc.NewProvider(config)
+
+		_, err := provider.Exchange(context.TODO(), code, verifier, "nonce")
+		assert.Error(t, err)
+	})
+
+	t.Run("fails without fetching the keys again when the key is unknown", func(t *testing.T) {
+		server := oidctest.NewServer("shellhub", "secret")
+		defer server.Close()
+
+		server.SetKeyID("unknown")
+		provider := oidc.NewProvider(server.Config(redirectURL))
+
+		for i := 0; i < 3; i++ {
+			code, verifier := login(t, provider, server, "nonce")
+
+			_, err := provider.Exchange(context.TODO(), code, verifier, "nonce")
+			assert.ErrorIs(t, err, oidc.ErrIDToken)
+		}
+
+		// The keys are fetched once for the first unknown key, and not again before the refresh interval.
+		assert.Equal(t, 1, server.JWKSRequests())
+	})
+
+	t.Run("success when the code is valid", func(t *testing.T) {
+		provider := oidc.NewProvider(server.Config(redirectURL))
+
+		code, verifier := login(t, provider, server, "nonce")
+
+		claims, err := provider.Exchange(context.TODO(), code, verifier, "nonce")
+		assert.NoError(t, err)
+		assert.Equal(t, &oidc.Claims{
+			Subject:           "subject",
+			Email:             "john@example.com",
+			EmailVerified:     true,
+			Name:              "John Doe",
+			PreferredUsername: "john",
+			Groups:            []string{"devs", "ops"},
+		}, claims)
+	})
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	t.Run("fails when the issuer does not match the discovered one", func(t *testing.T) {
+		server := oidctest.NewServer("shellhub", "")
+		defer server.Close()
+
+		config := server.Config(redirectURL)
+		config.Issuer = server.URL + "/other"
+
+		_, err := oidc.NewProvider(config).AuthCodeURL(context.TODO(), "state", "nonce", "verifier")
+		assert.ErrorIs(t, err, oidc.ErrDiscovery)
+	})
+}
@@ -0,0 +1,288 @@
+// Package oidctest provides a local OpenID Connect's identity provider to test the login flow without an external one.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
+)
+
+// KeyID is the kid of the key that signs the ID tokens.
+const KeyID = "oidctest"
+
+// authorization is an authorization code waiting to be exchanged.
+type authorization struct {
+	clientID    string
+	redirectURI string
+	challenge   string
+	nonce       string
+	claims      map[string]interface{}
+}
+
+// Server is an identity provider that authenticates, without interaction, the user whose claims were set by SetUser.
+type Server struct {
+	*httptest.Server
+
+	ClientID     string
+	ClientSecret string
+
+	key *rsa.PrivateKey
+
+	mu     sync.Mutex
+	claims map[string]interface{}
+	codes  map[string]authorization
+	kid    string
+	// jwksRequests is the number of times the keys were fetched.
+	jwksRequests int
+}
+
+// NewServer starts an identity provider that accepts the client identified by clientID and clientSecret. An empty
+// clientSecret accepts the client as a public one.
+func NewServer(clientID, clientSecret string) *Server {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		key:          key,
+		claims:       map[string]interface{}{},
+		codes:        map[string]authorization{},
+		kid:          KeyID,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// Config returns an oidc.Config to use the server as the identity provider.
+func (s *Server) Config(redirectURL string) oidc.Config {
+	return oidc.Config{
+		Issuer:       s.URL,
+		ClientID:     s.ClientID,
+		ClientSecret: s.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "profile", "email"},
+		GroupsClaim:  "groups",
+	}
+}
+
+// SetUser sets the claims of the user authenticated by the next authorizations, like sub, email and groups.
+func (s *Server) SetUser(claims map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.claims = claims
+}
+
+// SetKeyID sets the kid of the ID tokens, where a kid other than KeyID is unknown to the published keys.
+func (s *Server) SetKeyID(kid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.kid = kid
+}
+
+// JWKSRequests returns the number of times the keys were fetched.
+func (s *Server) JWKSRequests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.jwksRequests
+}
+
+// Login follows the authorization URL as the user's browser, returning the code and state sent to the redirect URL.
+func (s *Server) Login(authURL string) (string, string, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		return "", "", errors.New("authorization was not granted")
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return "", "", err
+	}
+
+	return location.Query().Get("code"), location.Query().Get("state"), nil
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.URL,
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("response_type") != "code" || query.Get("client_id") != s.ClientID {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+
+		return
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+
+		return
+	}
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || redirect.String() == "" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+
+		return
+	}
+
+	code := random()
+
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		clientID:    query.Get("client_id"),
+		redirectURI: query.Get("redirect_uri"),
+		challenge:   query.Get("code_challenge"),
+		nonce:       query.Get("nonce"),
+		claims:      s.claims,
+	}
+	s.mu.Unlock()
+
+	values := redirect.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirect.RawQuery = values.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+
+		return
+	}
+
+	if s.ClientSecret != "" {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != s.ClientID || secret != s.ClientSecret {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+
+			return
+		}
+	}
+
+	code := r.PostForm.Get("code")
+
+	s.mu.Lock()
+	auth, ok := s.codes[code]
+	delete(s.codes, code)
+	kid := s.kid
+	s.mu.Unlock()
+
+	if !ok || auth.clientID != r.PostForm.Get("client_id") || auth.redirectURI != r.PostForm.Get("redirect_uri") ||
+		auth.challenge != oidc.Challenge(r.PostForm.Get("code_verifier")) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{
+		"iss":   s.URL,
+		"aud":   s.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": auth.nonce,
+	}
+
+	for key, value := range auth.claims {
+		claims[key] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": random(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     signed,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	s.jwksRequests++
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": KeyID,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes()),
+			},
+		},
+	})
+}
+
+func random() string {
+	data := make([]byte, 16)
+	if _, err := rand.Read(data); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(value) //nolint:errcheck
+}
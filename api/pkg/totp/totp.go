@@ -0,0 +1,122 @@
+// Package totp implements the time-based one-time passwords described on RFC 6238, with the parameters understood by
+// the common authenticator apps: HMAC-SHA1, six digits and a period of thirty seconds.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// Digits is the number of digits of a code.
+	Digits = 6
+	// Period is the time that a code is valid for.
+	Period = 30 * time.Second
+	// Skew is the number of periods, before and after the current one, whose codes are also accepted to tolerate clock
+	// drift between the server and the authenticator.
+	Skew = 1
+)
+
+// secretSize is the size, in bytes, of a generated secret.
+const secretSize = 20
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret generates a random secret encoded in base32, as expected by the authenticator apps.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth URI, usually shown as a QR code, used to register the secret on an authenticator app.
+func URI(issuer, account, secret string) string {
+	values := url.Values{}
+	values.Set("secret", secret)
+	values.Set("issuer", issuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(Digits))
+	values.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	return (&url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: values.Encode(),
+	}).String()
+}
+
+// Code generates the code of secret at the time t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	return code(key, Step(t)), nil
+}
+
+// Validate checks if code is a valid code of secret at the time t.
+func Validate(secret, code string, t time.Time) bool {
+	_, ok := ValidateStep(secret, code, t)
+
+	return ok
+}
+
+// ValidateStep checks if code is a valid code of secret at the time t, returning the time step that generated it. The
+// step allows the caller to accept each code only once, as a code is valid during the whole skew.
+func ValidateStep(secret, code string, t time.Time) (uint64, bool) {
+	if len(code) != Digits {
+		return 0, false
+	}
+
+	for i := -Skew; i <= Skew; i++ {
+		at := t.Add(time.Duration(i) * Period)
+
+		expected, err := Code(secret, at)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return Step(at), true
+		}
+	}
+
+	return 0, false
+}
+
+// Step returns the time step, the number of periods since the Unix epoch, of the time t.
+func Step(t time.Time) uint64 {
+	return uint64(t.Unix() / int64(Period.Seconds()))
+}
+
+func code(key []byte, counter uint64) string {
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, counter)
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
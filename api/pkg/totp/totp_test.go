@@ -0,0 +1,125 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// secret is the RFC 6238's SHA1 test secret, "12345678901234567890", encoded in base32.
+const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateSecret(t *testing.T) {
+	generated, err := GenerateSecret()
+	assert.NoError(t, err)
+
+	key, err := encoding.DecodeString(generated)
+	assert.NoError(t, err)
+	assert.Len(t, key, secretSize)
+
+	other, err := GenerateSecret()
+	assert.NoError(t, err)
+	assert.NotEqual(t, generated, other)
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("ShellHub", "john@example.com", secret))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/ShellHub:john@example.com", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "ShellHub", uri.Query().Get("issuer"))
+	assert.Equal(t, "6", uri.Query().Get("digits"))
+	assert.Equal(t, "30", uri.Query().Get("period"))
+}
+
+func TestCode(t *testing.T) {
+	// The expected values are the last six digits of the RFC 6238's SHA1 test vectors.
+	cases := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range cases {
+		code, err := Code(secret, time.Unix(tc.unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, code)
+	}
+
+	_, err := Code("not base32!", time.Unix(59, 0))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	cases := []struct {
+		description string
+		code        string
+		at          time.Time
+		expected    bool
+	}{
+		{
+			description: "accepts the current code",
+			code:        "050471",
+			at:          now,
+			expected:    true,
+		},
+		{
+			description: "accepts the code of the previous period",
+			code:        "050471",
+			at:          now.Add(Period),
+			expected:    true,
+		},
+		{
+			description: "rejects the code of an older period",
+			code:        "050471",
+			at:          now.Add(3 * Period),
+			expected:    false,
+		},
+		{
+			description: "rejects a wrong code",
+			code:        "000000",
+			at:          now,
+			expected:    false,
+		},
+		{
+			description: "rejects a code with the wrong size",
+			code:        "50471",
+			at:          now,
+			expected:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Validate(secret, tc.code, tc.at))
+		})
+	}
+}
+
+func TestValidateStep(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	step, ok := ValidateStep(secret, "050471", now)
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	// The code of the previous period is matched to its own step, not to the current one.
+	step, ok = ValidateStep(secret, "050471", now.Add(Period))
+	assert.True(t, ok)
+	assert.Equal(t, Step(now), step)
+
+	_, ok = ValidateStep(secret, "000000", now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,195 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/paginator"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/api/webhook"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ListAccessRequestsURL     = "/access-requests"
+	CreateAccessRequestURL    = "/access-requests"
+	ReviewAccessRequestURL    = "/access-requests/:id/:action"
+	EvaluateAccessRequestURL  = "/access-requests/evaluate"
+	EditAccessApprovalTagsURL = "/namespaces/:tenant/access-approval"
+)
+
+type accessRequestQuery struct {
+	requests.AccessRequestList
+	paginator.Query
+}
+
+func (h *Handler) GetAccessRequestList(c gateway.Context) error {
+	query := accessRequestQuery{Query: *paginator.NewQuery()}
+	if err := c.Bind(&query); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&query.AccessRequestList); err != nil {
+		return err
+	}
+
+	query.Normalize()
+
+	var tenant string
+	if v := c.Tenant(); v != nil {
+		tenant = v.ID
+	}
+
+	// Members who cannot review access requests only see their own ones.
+	var userID string
+	if err := guard.EvaluatePermission(c.Role(), guard.Actions.AccessRequest.Review, func() error { return nil }); err != nil {
+		if v := c.ID(); v != nil {
+			userID = v.ID
+		}
+
+		if userID == "" {
+			return err
+		}
+	}
+
+	list, count, err := h.service.ListAccessRequests(c.Ctx(), tenant, userID, query.Status, query.Query)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("X-Total-Count", strconv.Itoa(count))
+
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) CreateAccessRequest(c gateway.Context) error {
+	var req requests.AccessRequestCreate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant, userID string
+	if v := c.Tenant(); v != nil {
+		tenant = v.ID
+	}
+
+	if v := c.ID(); v != nil {
+		userID = v.ID
+	}
+
+	request, err := h.service.CreateAccessRequest(c.Ctx(), tenant, userID, req)
+	if err != nil {
+		return err
+	}
+
+	notifyAccessRequest(webhook.WebhookAccessRequestCreatedEvent, request)
+
+	return c.JSON(http.StatusOK, request)
+}
+
+func (h *Handler) ReviewAccessRequest(c gateway.Context) error {
+	var req requests.AccessRequestReview
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant, userID string
+	if v := c.Tenant(); v != nil {
+		tenant = v.ID
+	}
+
+	if v := c.ID(); v != nil {
+		userID = v.ID
+	}
+
+	var request *models.AccessRequest
+	err := guard.EvaluatePermission(c.Role(), guard.Actions.AccessRequest.Review, func() error {
+		var err error
+		request, err = h.service.ReviewAccessRequest(c.Ctx(), tenant, userID, req.ID, req.Action == "approve")
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	notifyAccessRequest(webhook.WebhookAccessRequestReviewedEvent, request)
+
+	return c.JSON(http.StatusOK, request)
+}
+
+func (h *Handler) EvaluateAccessRequest(c gateway.Context) error {
+	var req requests.AccessRequestEvaluate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	allowed, err := h.service.EvaluateAccessRequest(c.Ctx(), req.TenantID, req.DeviceUID, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, allowed)
+}
+
+func (h *Handler) EditAccessApprovalTags(c gateway.Context) error {
+	var req requests.NamespaceEditAccessApproval
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.AccessRequest.Configure, func() error {
+		return h.service.EditAccessApprovalTags(c.Ctx(), ns.TenantID, req.Tags)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// notifyAccessRequest sends an access request's event to the webhook without holding the response.
+func notifyAccessRequest(event string, request *models.AccessRequest) {
+	go func() {
+		wh := webhook.NewClient()
+		if wh == nil {
+			return
+		}
+
+		if err := wh.Notify(event, request); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"event":   event,
+				"request": request.ID,
+			}).Warn("failed to notify the access request event")
+		}
+	}()
+}
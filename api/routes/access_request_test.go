@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestReviewAccessRequest(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		role           string
+		action         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when action is invalid",
+			role:           guard.RoleAdministrator,
+			action:         "ignore",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:          "fails when role cannot review access requests",
+			role:           guard.RoleOperator,
+			action:         "approve",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "success when administrator approves the access request",
+			role:   guard.RoleAdministrator,
+			action: "approve",
+			requiredMocks: func() {
+				mock.On("ReviewAccessRequest", gomock.Anything, "tenant", "adminID", "id", true).
+					Return(&models.AccessRequest{ID: "id", Status: models.AccessRequestApproved}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title:  "success when administrator denies the access request",
+			role:   guard.RoleAdministrator,
+			action: "deny",
+			requiredMocks: func() {
+				mock.On("ReviewAccessRequest", gomock.Anything, "tenant", "adminID", "id", false).
+					Return(&models.AccessRequest{ID: "id", Status: models.AccessRequestDenied}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/access-requests/id/"+tc.action, nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", "tenant")
+			req.Header.Set("X-ID", "adminID")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -1,29 +1,40 @@
 package routes
 
 import (
-	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	jwt "github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/mitchellh/mapstructure"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
 	errs "github.com/shellhub-io/shellhub/api/routes/errors"
 	svc "github.com/shellhub-io/shellhub/api/services"
 	client "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 )
 
 const (
-	AuthRequestURL   = "/auth"
-	AuthDeviceURL    = "/devices/auth"
-	AuthDeviceURLV2  = "/auth/device"
-	AuthUserURL      = "/login"
-	AuthUserURLV2    = "/auth/user"
-	AuthUserTokenURL = "/auth/token/:tenant" //nolint:gosec
-	AuthPublicKeyURL = "/auth/ssh"
+	AuthRequestURL  = "/auth"
+	AuthDeviceURL   = "/devices/auth"
+	AuthDeviceURLV2 = "/auth/device"
+	// AuthDeviceChallengeURL issues the nonce signed by the device to authenticate.
+	AuthDeviceChallengeURL = "/devices/auth/challenge"
+	// AuthDeviceRotateKeyURL replaces the device's key, authenticated by the signatures of its current and new keys.
+	AuthDeviceRotateKeyURL = "/devices/auth/rotate"
+	AuthUserURL            = "/login"
+	AuthUserURLV2          = "/auth/user"
+	AuthUserTokenURL       = "/auth/token/:tenant" //nolint:gosec
+	AuthPublicKeyURL       = "/auth/ssh"
+	// AuthDeviceRekeyURL replaces the device's key, authenticated by the re-key token issued by an administrator.
+	AuthDeviceRekeyURL = "/devices/auth/rekey"
 )
 
 const (
@@ -90,10 +101,18 @@ func (h *Handler) AuthRequest(c gateway.Context) error {
 			}
 		}
 
+		// The tokens of a revoked session are rejected before they expire.
+		if claims.SessionID != "" {
+			if ok, err := h.service.AuthIsUserSessionActive(c.Ctx(), claims.SessionID); err != nil || !ok {
+				return svc.NewErrAuthUnathorized(err)
+			}
+		}
+
 		setHeader(c, "X-Tenant-ID", claims.Tenant)
 		setHeader(c, "X-Username", claims.Username)
 		setHeader(c, "X-ID", claims.ID)
 		setHeader(c, "X-Role", claims.Role)
+		setHeader(c, "X-Session-ID", claims.SessionID)
 
 		return c.NoContent(http.StatusOK)
 	case AuthRequestDeviceToken:
@@ -103,6 +122,17 @@ func (h *Handler) AuthRequest(c gateway.Context) error {
 			return err
 		}
 
+		// The registered claims are not decoded into the struct, so the issue time is read from the raw claims.
+		var issuedAt time.Time
+		if iat, ok := (*rawClaims)["iat"].(float64); ok {
+			issuedAt = time.Unix(int64(iat), 0)
+		}
+
+		// The tokens issued before the device's key was revoked are rejected before they expire.
+		if ok, err := h.service.AuthIsDeviceTokenActive(c.Ctx(), claims.UID, issuedAt); err != nil || !ok {
+			return svc.NewErrAuthUnathorized(err)
+		}
+
 		// Extract device UID from JWT and set it into the header.
 		setHeader(c, client.DeviceUIDHeader, claims.UID)
 
@@ -112,6 +142,49 @@ func (h *Handler) AuthRequest(c gateway.Context) error {
 	}
 }
 
+func (h *Handler) AuthDeviceChallenge(c gateway.Context) error {
+	challenge, err := h.service.AuthDeviceChallenge(c.Ctx())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, challenge)
+}
+
+func (h *Handler) AuthDeviceRotateKey(c gateway.Context) error {
+	var req requests.DeviceRotateKey
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.RotateDeviceKey(c.Ctx(), req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) AuthDeviceRekey(c gateway.Context) error {
+	var req requests.DeviceRekey
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.RekeyDevice(c.Ctx(), req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
 func (h *Handler) AuthDevice(c gateway.Context) error {
 	var req requests.DeviceAuth
 	if err := c.Bind(&req); err != nil {
@@ -146,8 +219,10 @@ func (h *Handler) AuthUser(c gateway.Context) error {
 		return err
 	}
 
-	res, err := h.service.AuthUser(c.Ctx(), req)
+	res, err := h.service.AuthUser(c.Ctx(), req, c.Request().Header.Get("X-Real-IP"))
 	if err != nil {
+		setRetryAfter(c, err)
+
 		if errors.Is(err, svc.ErrUserNotFound) {
 			return errs.NewErrUnauthorized(err)
 		}
@@ -204,7 +279,7 @@ func (h *Handler) AuthSwapToken(c gateway.Context) error {
 		id = v.ID
 	}
 
-	res, err := h.service.AuthSwapToken(c.Ctx(), id, req.Tenant)
+	res, err := h.service.AuthSwapToken(c.Ctx(), id, c.SessionID(), req.Tenant)
 	if err != nil {
 		return err
 	}
@@ -222,14 +297,28 @@ func (h *Handler) AuthPublicKey(c gateway.Context) error {
 		return err
 	}
 
-	res, err := h.service.AuthPublicKey(c.Ctx(), req)
+	res, err := h.service.AuthPublicKey(c.Ctx(), req, c.Request().Header.Get("X-Real-IP"))
 	if err != nil {
+		setRetryAfter(c, err)
+
 		return err
 	}
 
 	return c.JSON(http.StatusOK, res)
 }
 
+// setRetryAfter sets the Retry-After header, in seconds, when the request was refused because too many were made.
+func setRetryAfter(c gateway.Context, err error) {
+	var e errors.Error
+	if !errors.As(err, &e) {
+		return
+	}
+
+	if data, ok := e.Data.(svc.ErrDataTooManyRequests); ok {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(data.RetryAfter.Seconds()))))
+	}
+}
+
 func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		ctx, ok := c.Get("ctx").(*gateway.Context)
@@ -237,10 +326,21 @@ func AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 			return svc.ErrTypeAssertion
 		}
 
+		keys := ctx.Service().(svc.Service).Keyring()
+
 		jwt := middleware.JWTWithConfig(middleware.JWTConfig{ //nolint:staticcheck
-			Claims:        &jwt.MapClaims{},
-			SigningKey:    ctx.Service().(svc.Service).PublicKey(),
-			SigningMethod: "RS256",
+			Claims: &jwt.MapClaims{},
+			// The token is verified by the key identified in its header, so the tokens signed by the previous keys are
+			// still valid after a rotation.
+			KeyFunc: func(token *jwt.Token) (interface{}, error) {
+				if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+					return nil, fmt.Errorf("%w: %s", keyring.ErrUnexpectedMethod, token.Header["alg"])
+				}
+
+				id, _ := token.Header["kid"].(string)
+
+				return keys.PublicKey(id)
+			},
 		})
 
 		return jwt(next)(c)
@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestAuthUser(t *testing.T) {
+	mock := new(mocks.Service)
+
+	req := requests.UserAuth{Username: "john", Password: "secret"}
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		status        int
+		retryAfter    string
+	}{
+		{
+			title: "fails when the credentials are invalid",
+			requiredMocks: func() {
+				mock.On("AuthUser", gomock.Anything, req, "192.168.1.1").Return(nil, svc.NewErrAuthUnathorized(nil)).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			title: "fails when the account is blocked",
+			requiredMocks: func() {
+				mock.On("AuthUser", gomock.Anything, req, "192.168.1.1").Return(nil, svc.NewErrAuthBlocked(2500*time.Millisecond, nil)).Once()
+			},
+			status:     http.StatusTooManyRequests,
+			retryAfter: "3",
+		},
+		{
+			title: "success when the credentials are valid",
+			requiredMocks: func() {
+				mock.On("AuthUser", gomock.Anything, req, "192.168.1.1").Return(&models.UserAuthResponse{Token: "jwt", ID: "id"}, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(`{"username": "john", "password": "secret"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Real-IP", "192.168.1.1")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+			assert.Equal(t, tc.retryAfter, rec.Result().Header.Get("Retry-After"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthRequestUserSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserAuthClaims{
+		ID:         "id",
+		SessionID:  "session",
+		AuthClaims: models.AuthClaims{Claims: "user"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString(privateKey)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		status        int
+		sessionID     string
+	}{
+		{
+			title: "fails when the session was revoked",
+			requiredMocks: func() {
+				mock.On("AuthIsUserSessionActive", gomock.Anything, "session").Return(false, nil).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			title: "success when the session is active",
+			requiredMocks: func() {
+				mock.On("AuthIsUserSessionActive", gomock.Anything, "session").Return(true, nil).Once()
+			},
+			status:    http.StatusOK,
+			sessionID: "session",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			mock.On("Keyring").Return(keyring.New(privateKey)).Once()
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/auth", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+			assert.Equal(t, tc.sessionID, rec.Result().Header.Get("X-Session-ID"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthDeviceChallenge(t *testing.T) {
+	mock := new(mocks.Service)
+
+	mock.On("AuthDeviceChallenge", gomock.Anything).Return(&models.DeviceChallenge{Nonce: "nonce"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/devices/auth/challenge", nil)
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+	assert.JSONEq(t, `{"nonce": "nonce"}`, rec.Body.String())
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthDeviceRequiresChallenge(t *testing.T) {
+	mock := new(mocks.Service)
+
+	// The challenge may only be missing along with the signature, sent by the agents older than the proof of key
+	// possession.
+	req := httptest.NewRequest(http.MethodPost, "/api/devices/auth", strings.NewReader(`{"info": {"id": "linux"}, "tenant_id": "tenant", "hostname": "device", "public_key": "key", "signature": "signature"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Result().StatusCode)
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthRequestDeviceToken(t *testing.T) {
+	mock := new(mocks.Service)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	issuedAt := time.Now().Truncate(time.Second)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, models.DeviceAuthClaims{
+		UID:        "uid",
+		AuthClaims: models.AuthClaims{Claims: "device"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(time.Hour)),
+		},
+	}).SignedString(privateKey)
+	assert.NoError(t, err)
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		status        int
+		uid           string
+	}{
+		{
+			title: "fails when the device's key was revoked after the token was issued",
+			requiredMocks: func() {
+				mock.On("AuthIsDeviceTokenActive", gomock.Anything, "uid", issuedAt).Return(false, nil).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			title: "success when the token is active",
+			requiredMocks: func() {
+				mock.On("AuthIsDeviceTokenActive", gomock.Anything, "uid", issuedAt).Return(true, nil).Once()
+			},
+			status: http.StatusOK,
+			uid:    "uid",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			mock.On("Keyring").Return(keyring.New(privateKey)).Once()
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/auth", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+			assert.Equal(t, tc.uid, rec.Result().Header.Get("X-Device-UID"))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
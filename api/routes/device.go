@@ -27,6 +27,8 @@ const (
 	UpdateTagURL                = "/devices/:uid/tags"      // Update device's tags with a new set.
 	RemoveTagURL                = "/devices/:uid/tags/:tag" // Delete a tag from a device.
 	UpdateDevice                = "/devices/:uid"
+	RevokeDeviceKeyURL          = "/devices/:uid/key"
+	CreateDeviceRekeyTokenURL   = "/devices/:uid/key/rekey" // Issue the one-time token to replace the device's key.
 )
 
 const (
@@ -139,6 +141,60 @@ func (h *Handler) DeleteDevice(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+func (h *Handler) RevokeDeviceKey(c gateway.Context) error {
+	var req requests.DeviceRevokeKey
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.RevokeKey, func() error {
+		return h.service.RevokeDeviceKey(c.Ctx(), tenant, models.UID(req.UID))
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) CreateDeviceRekeyToken(c gateway.Context) error {
+	var req requests.DeviceCreateRekeyToken
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var tenant string
+	if c.Tenant() != nil {
+		tenant = c.Tenant().ID
+	}
+
+	var token *models.DeviceRekeyToken
+	err := guard.EvaluatePermission(c.Role(), guard.Actions.Device.Rekey, func() error {
+		var err error
+		token, err = h.service.CreateDeviceRekeyToken(c.Ctx(), tenant, models.UID(req.UID))
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
+
 func (h *Handler) RenameDevice(c gateway.Context) error {
 	var req requests.DeviceRename
 	if err := c.Bind(&req); err != nil {
@@ -0,0 +1,143 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+const (
+	ListEnrollmentTokensURL                = "/namespaces/:tenant/enrollment-tokens"
+	CreateEnrollmentTokenURL               = "/namespaces/:tenant/enrollment-tokens"
+	DeleteEnrollmentTokenURL               = "/namespaces/:tenant/enrollment-tokens/:id"
+	EditNamespaceRequireEnrollmentTokenURL = "/namespaces/:tenant/enrollment"
+)
+
+func (h *Handler) ListEnrollmentTokens(c gateway.Context) error {
+	var req requests.EnrollmentTokenList
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var tokens []models.EnrollmentToken
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.EnrollmentToken.List, func() error {
+		tokens, err = h.service.ListEnrollmentTokens(c.Ctx(), ns.TenantID)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+func (h *Handler) CreateEnrollmentToken(c gateway.Context) error {
+	var req requests.EnrollmentTokenCreate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	var token *models.EnrollmentToken
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.EnrollmentToken.Create, func() error {
+		token, err = h.service.CreateEnrollmentToken(c.Ctx(), ns.TenantID, uid, req)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
+
+func (h *Handler) DeleteEnrollmentToken(c gateway.Context) error {
+	var req requests.EnrollmentTokenDelete
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.EnrollmentToken.Remove, func() error {
+		return h.service.DeleteEnrollmentToken(c.Ctx(), ns.TenantID, req.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) EditNamespaceRequireEnrollmentToken(c gateway.Context) error {
+	var req requests.NamespaceEditRequireEnrollmentToken
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.EnrollmentToken.Configure, func() error {
+		return h.service.EditNamespaceRequireEnrollmentToken(c.Ctx(), ns.TenantID, req.RequireEnrollmentToken)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,167 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateEnrollmentToken(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "operator", Role: guard.RoleOperator}},
+	}
+
+	cases := []struct {
+		title         string
+		uid           string
+		body          string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the expiration is missing",
+			uid:           "owner",
+			body:          `{"tags": ["factory"]}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the user is an operator",
+			uid:   "operator",
+			body:  `{"expires_in": 24}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			title: "success when the owner creates the token",
+			uid:   "owner",
+			body:  `{"tags": ["factory"], "max_uses": 10, "expires_in": 24}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("CreateEnrollmentToken", gomock.Anything, namespace.TenantID, "owner", requests.EnrollmentTokenCreate{
+					TenantParam: requests.TenantParam{Tenant: namespace.TenantID},
+					Tags:        []string{"factory"},
+					MaxUses:     10,
+					ExpiresIn:   24,
+				}).Return(&models.EnrollmentToken{ID: "id", Hash: "hash", Token: "id.secret"}, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/"+namespace.TenantID+"/enrollment-tokens", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+
+			if tc.status == http.StatusOK {
+				var body map[string]interface{}
+				assert.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				assert.Equal(t, "id.secret", body["token"])
+				assert.NotContains(t, body, "hash")
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestDeleteEnrollmentToken(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}},
+	}
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title: "fails when the token is not found",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeleteEnrollmentToken", gomock.Anything, namespace.TenantID, "id").Return(svc.NewErrEnrollmentTokenNotFound("id", nil)).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			title: "success when the token is deleted",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeleteEnrollmentToken", gomock.Anything, namespace.TenantID, "id").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/namespaces/"+namespace.TenantID+"/enrollment-tokens/id", nil)
+			req.Header.Set("X-ID", "owner")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEditNamespaceRequireEnrollmentToken(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}},
+	}
+
+	mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+	mock.On("EditNamespaceRequireEnrollmentToken", gomock.Anything, namespace.TenantID, true).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/namespaces/"+namespace.TenantID+"/enrollment", strings.NewReader(`{"require_enrollment_token": true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-ID", "owner")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+)
+
+// JWKSURL serves the keys that verify the tokens, so other services can validate them. The gateway exposes it at the
+// root, as /.well-known/jwks.json.
+const JWKSURL = "/.well-known/jwks.json"
+
+func (h *Handler) GetJWKS(c gateway.Context) error {
+	// The keys only change when the API restarts, so the clients may cache them for a while.
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return c.JSON(http.StatusOK, h.service.Keyring().JWKS())
+}
@@ -0,0 +1,119 @@
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestGetJWKS(t *testing.T) {
+	mock := new(mocks.Service)
+
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	mock.On("Keyring").Return(keyring.New(current, &previous.PublicKey)).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var set keyring.JWKS
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&set))
+	assert.Len(t, set.Keys, 2)
+	assert.Equal(t, keyring.KeyID(&current.PublicKey), set.Keys[0].KeyID)
+	assert.Equal(t, keyring.KeyID(&previous.PublicKey), set.Keys[1].KeyID)
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthRequestKeyRotation(t *testing.T) {
+	mock := new(mocks.Service)
+
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	unknown, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	claims := models.UserAuthClaims{
+		ID:         "id",
+		SessionID:  "session",
+		AuthClaims: models.AuthClaims{Claims: "user"},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	cases := []struct {
+		title         string
+		signer        *rsa.PrivateKey
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the token was signed by an unknown key",
+			signer:        unknown,
+			requiredMocks: func() {},
+			status:        http.StatusUnauthorized,
+		},
+		{
+			title:  "success when the token was signed by the previous key",
+			signer: previous,
+			requiredMocks: func() {
+				mock.On("AuthIsUserSessionActive", gomock.Anything, "session").Return(true, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+		{
+			title:  "success when the token was signed by the current key",
+			signer: current,
+			requiredMocks: func() {
+				mock.On("AuthIsUserSessionActive", gomock.Anything, "session").Return(true, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			token, err := keyring.New(tc.signer).Sign(claims)
+			assert.NoError(t, err)
+
+			mock.On("Keyring").Return(keyring.New(current, &previous.PublicKey)).Once()
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/auth", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,161 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+const (
+	AuthMFAURL                 = "/login/mfa"
+	AuthMFAEnrollURL           = "/login/mfa/enroll"
+	EnrollMFAURL               = "/user/mfa/enroll"
+	EnableMFAURL               = "/user/mfa/enable"
+	DisableMFAURL              = "/user/mfa/disable"
+	EditNamespaceRequireMFAURL = "/namespaces/:tenant/mfa"
+	ValidateMFAURL             = "/mfa/validate"
+)
+
+func (h *Handler) AuthMFA(c gateway.Context) error {
+	var req requests.AuthMFA
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	res, err := h.service.AuthMFA(c.Ctx(), req, c.Request().Header.Get("X-Real-IP"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) AuthMFAEnroll(c gateway.Context) error {
+	var req requests.AuthMFAEnroll
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	enrollment, err := h.service.AuthMFAEnroll(c.Ctx(), req.Token)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *Handler) EnrollMFA(c gateway.Context) error {
+	var id string
+	if v := c.ID(); v != nil {
+		id = v.ID
+	}
+
+	enrollment, err := h.service.EnrollMFA(c.Ctx(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, enrollment)
+}
+
+func (h *Handler) EnableMFA(c gateway.Context) error {
+	var req requests.UserMFACode
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var id string
+	if v := c.ID(); v != nil {
+		id = v.ID
+	}
+
+	if err := h.service.EnableMFA(c.Ctx(), id, req.Code); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) DisableMFA(c gateway.Context) error {
+	var req requests.UserMFACode
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var id string
+	if v := c.ID(); v != nil {
+		id = v.ID
+	}
+
+	if err := h.service.DisableMFA(c.Ctx(), id, req.Code, req.RecoveryCode); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) EditNamespaceRequireMFA(c gateway.Context) error {
+	var req requests.NamespaceEditRequireMFA
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.RequireMFA, func() error {
+		return h.service.EditNamespaceRequireMFA(c.Ctx(), ns.TenantID, req.RequireMFA, req.RequireSSHMFA)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ValidateMFA(c gateway.Context) error {
+	var req requests.MFAValidate
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	id, err := h.service.ValidateMFA(c.Ctx(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, id)
+}
@@ -0,0 +1,254 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestAuthMFA(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		res    *models.UserAuthResponse
+		status int
+	}
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			title:         "fails when neither a code nor a recovery code is sent",
+			body:          `{"token": "token"}`,
+			requiredMocks: func() {},
+			expected:      Expected{nil, http.StatusBadRequest},
+		},
+		{
+			title:         "fails when the code is not numeric",
+			body:          `{"token": "token", "code": "abcdef"}`,
+			requiredMocks: func() {},
+			expected:      Expected{nil, http.StatusBadRequest},
+		},
+		{
+			title: "fails when the code is invalid",
+			body:  `{"token": "token", "code": "000000"}`,
+			requiredMocks: func() {
+				mock.On("AuthMFA", gomock.Anything, requests.AuthMFA{Token: "token", Code: "000000"}, "").Return(nil, svc.NewErrMFACodeInvalid(nil)).Once()
+			},
+			expected: Expected{nil, http.StatusUnauthorized},
+		},
+		{
+			title: "success when the recovery code is valid",
+			body:  `{"token": "token", "recovery_code": "abcde-fghij"}`,
+			requiredMocks: func() {
+				mock.On("AuthMFA", gomock.Anything, requests.AuthMFA{Token: "token", RecoveryCode: "abcde-fghij"}, "").Return(&models.UserAuthResponse{Token: "jwt", ID: "id"}, nil).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{Token: "jwt", ID: "id"}, http.StatusOK},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/mfa", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.res != nil {
+				var res *models.UserAuthResponse
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&res))
+				assert.Equal(t, tc.expected.res, res)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEnableMFA(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the code is missing",
+			body:           `{}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "fails when user has not enrolled MFA",
+			body:  `{"code": "123456"}`,
+			requiredMocks: func() {
+				mock.On("EnableMFA", gomock.Anything, "id", "123456").Return(svc.NewErrMFANotEnrolled("id", nil)).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title: "success when the code is valid",
+			body:  `{"code": "123456"}`,
+			requiredMocks: func() {
+				mock.On("EnableMFA", gomock.Anything, "id", "123456").Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/mfa/enable", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", "id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEditNamespaceRequireMFA(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "adminID", Role: guard.RoleAdministrator},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		userID         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:  "fails when namespace was not found",
+			userID: "ownerID",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title:  "fails when member is not the owner",
+			userID: "adminID",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "success when owner requires MFA",
+			userID: "ownerID",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+				mock.On("EditNamespaceRequireMFA", gomock.Anything, "tenant", true, true).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/tenant/mfa", strings.NewReader(`{"require_mfa": true, "require_ssh_mfa": true}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.userID)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestValidateMFA(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		id     string
+		status int
+	}
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			title:         "fails when the member is not identified",
+			body:          `{"tenant_id": "tenant", "code": "123456"}`,
+			requiredMocks: func() {},
+			expected:      Expected{"", http.StatusBadRequest},
+		},
+		{
+			title: "returns the member when the code is valid",
+			body:  `{"tenant_id": "tenant", "user_id": "id", "code": "123456"}`,
+			requiredMocks: func() {
+				mock.On("ValidateMFA", gomock.Anything, requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: "123456"}).Return("id", nil).Once()
+			},
+			expected: Expected{"id", http.StatusOK},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/internal/mfa/validate", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.status == http.StatusOK {
+				var id string
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&id))
+				assert.Equal(t, tc.expected.id, id)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,89 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+const (
+	TransferNamespaceURL       = "/namespaces/:tenant/members/:uid/transfer"
+	AcceptNamespaceTransferURL = "/namespaces/:tenant/transfer/accept"
+	CancelNamespaceTransferURL = "/namespaces/:tenant/transfer"
+)
+
+func (h *Handler) TransferNamespace(c gateway.Context) error {
+	var req requests.NamespaceTransferOwnership
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.TransferOwnership, func() error {
+		return h.service.TransferNamespace(c.Ctx(), ns.TenantID, uid, req.MemberUID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) AcceptNamespaceTransfer(c gateway.Context) error {
+	var req requests.NamespaceTransferAccept
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	if err := h.service.AcceptNamespaceTransfer(c.Ctx(), req.Tenant, uid); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) CancelNamespaceTransfer(c gateway.Context) error {
+	var req requests.NamespaceTransferCancel
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	if err := h.service.CancelNamespaceTransfer(c.Ctx(), req.Tenant, uid); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestTransferNamespace(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "admin", Role: guard.RoleAdministrator}},
+	}
+
+	cases := []struct {
+		title         string
+		uid           string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title: "fails when the user is not the owner",
+			uid:   "admin",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			title: "success when the owner starts the transfer",
+			uid:   "owner",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("TransferNamespace", gomock.Anything, namespace.TenantID, "owner", "admin").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/"+namespace.TenantID+"/members/admin/transfer", nil)
+			req.Header.Set("X-ID", tc.uid)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAcceptNamespaceTransfer(t *testing.T) {
+	mock := new(mocks.Service)
+
+	tenant := "00000000-0000-4000-0000-000000000000"
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title: "fails when there is no transfer to the user",
+			requiredMocks: func() {
+				mock.On("AcceptNamespaceTransfer", gomock.Anything, tenant, "admin").Return(svc.NewErrNamespaceTransferNotFound(tenant, nil)).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			title: "success when the user accepts the transfer",
+			requiredMocks: func() {
+				mock.On("AcceptNamespaceTransfer", gomock.Anything, tenant, "admin").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/namespaces/"+tenant+"/transfer/accept", nil)
+			req.Header.Set("X-ID", "admin")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestCancelNamespaceTransfer(t *testing.T) {
+	mock := new(mocks.Service)
+
+	tenant := "00000000-0000-4000-0000-000000000000"
+
+	mock.On("CancelNamespaceTransfer", gomock.Anything, tenant, "owner").Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/namespaces/"+tenant+"/transfer", nil)
+	req.Header.Set("X-ID", "owner")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	mock.AssertExpectations(t)
+}
@@ -13,16 +13,21 @@ import (
 )
 
 const (
-	ListNamespaceURL           = "/namespaces"
-	CreateNamespaceURL         = "/namespaces"
-	GetNamespaceURL            = "/namespaces/:tenant"
-	DeleteNamespaceURL         = "/namespaces/:tenant"
-	EditNamespaceURL           = "/namespaces/:tenant"
-	AddNamespaceUserURL        = "/namespaces/:tenant/members"
-	RemoveNamespaceUserURL     = "/namespaces/:tenant/members/:uid"
-	EditNamespaceUserURL       = "/namespaces/:tenant/members/:uid"
-	GetSessionRecordURL        = "/users/security"
-	EditSessionRecordStatusURL = "/users/security/:tenant"
+	ListNamespaceURL            = "/namespaces"
+	CreateNamespaceURL          = "/namespaces"
+	GetNamespaceURL             = "/namespaces/:tenant"
+	DeleteNamespaceURL          = "/namespaces/:tenant"
+	EditNamespaceURL            = "/namespaces/:tenant"
+	AddNamespaceUserURL         = "/namespaces/:tenant/members"
+	RemoveNamespaceUserURL      = "/namespaces/:tenant/members/:uid"
+	EditNamespaceUserURL        = "/namespaces/:tenant/members/:uid"
+	EditNamespaceMemberScopeURL = "/namespaces/:tenant/members/:uid/scope"
+	EvaluateMemberURL           = "/namespaces/:tenant/members/evaluate"
+	GetSessionRecordURL         = "/users/security"
+	EditSessionRecordStatusURL  = "/users/security/:tenant"
+	EditSessionPolicyURL        = "/namespaces/:tenant/session-policy"
+	EditAgentTagsURL            = "/namespaces/:tenant/agent-tags"
+	GetNamespaceSettingsURL     = "/namespaces/:tenant/settings"
 )
 
 const (
@@ -271,6 +276,39 @@ func (h *Handler) EditNamespaceUser(c gateway.Context) error {
 	return c.NoContent(http.StatusOK)
 }
 
+func (h *Handler) EditNamespaceMemberScope(c gateway.Context) error {
+	var req requests.NamespaceEditMemberScope
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.EditMember, func() error {
+		return h.service.EditNamespaceMemberScope(c.Ctx(), ns.TenantID, uid, req.MemberUID, &models.MemberScope{
+			Tags:    req.Tags,
+			Devices: req.Devices,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
 func (h *Handler) EditSessionRecordStatus(c gateway.Context) error {
 	var req requests.SessionEditRecordStatus
 	if err := c.Bind(&req); err != nil {
@@ -316,3 +354,106 @@ func (h *Handler) GetSessionRecord(c gateway.Context) error {
 
 	return c.JSON(http.StatusOK, status)
 }
+
+func (h *Handler) EditSessionPolicy(c gateway.Context) error {
+	var req requests.NamespaceEditSessionPolicy
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.EditSessionPolicy, func() error {
+		return h.service.EditSessionPolicy(c.Ctx(), ns.TenantID, req.IdleTimeout, req.MaxSessionDuration)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) EditAgentTags(c gateway.Context) error {
+	var req requests.NamespaceEditAgentTags
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var uid string
+	if c.ID() != nil {
+		uid = c.ID().ID
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil || ns == nil {
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	err = guard.EvaluateNamespace(ns, uid, guard.Actions.Namespace.EditAgentTags, func() error {
+		return h.service.EditAgentTagsOverwrite(c.Ctx(), ns.TenantID, req.AgentTagsOverwrite)
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// EvaluateMember checks, for the internal services, if a member can connect to a device.
+func (h *Handler) EvaluateMember(c gateway.Context) error {
+	var req requests.NamespaceEvaluateMember
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	allowed, err := h.service.EvaluateMember(c.Ctx(), req.Tenant, req.UserID, req.DeviceUID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, allowed)
+}
+
+// GetNamespaceSettings returns the namespace's settings to the internal services.
+func (h *Handler) GetNamespaceSettings(c gateway.Context) error {
+	var req requests.NamespaceGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	ns, err := h.service.GetNamespace(c.Ctx(), req.Tenant)
+	if err != nil {
+		return err
+	}
+
+	settings := ns.Settings
+	if settings == nil {
+		settings = &models.NamespaceSettings{}
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
@@ -430,3 +430,249 @@ func TestEditNamespace(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestEditSessionPolicy(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "observerID", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		userID         string
+		body           string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when idle timeout is negative",
+			userID:         "ownerID",
+			body:           `{"idle_timeout": -1}`,
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:  "fails when member cannot edit the session policy",
+			userID: "observerID",
+			body:   `{"idle_timeout": 15, "max_session_duration": 480}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "success when owner edits the session policy",
+			userID: "ownerID",
+			body:   `{"idle_timeout": 15, "max_session_duration": 480}`,
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+				mock.On("EditSessionPolicy", gomock.Anything, "tenant", 15, 480).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/tenant/session-policy", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.userID)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEditAgentTags(t *testing.T) {
+	mock := new(mocks.Service)
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "observerID", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		title          string
+		userID         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:  "fails when member cannot edit the agent tags setting",
+			userID: "observerID",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "success when owner edits the agent tags setting",
+			userID: "ownerID",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(namespace, nil).Once()
+				mock.On("EditAgentTagsOverwrite", gomock.Anything, "tenant", true).Return(nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/namespaces/tenant/agent-tags", strings.NewReader(`{"agent_tags_overwrite": true}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-ID", tc.userID)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestGetNamespaceSettings(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		settings *models.NamespaceSettings
+		status   int
+	}
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			title: "fails when namespace was not found",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(nil, svc.ErrNamespaceNotFound).Once()
+			},
+			expected: Expected{nil, http.StatusNotFound},
+		},
+		{
+			title: "success when namespace has no settings",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+			},
+			expected: Expected{&models.NamespaceSettings{}, http.StatusOK},
+		},
+		{
+			title: "success when namespace has settings",
+			requiredMocks: func() {
+				mock.On("GetNamespace", gomock.Anything, "tenant").Return(&models.Namespace{
+					TenantID: "tenant",
+					Settings: &models.NamespaceSettings{IdleTimeout: 15, MaxSessionDuration: 480},
+				}, nil).Once()
+			},
+			expected: Expected{&models.NamespaceSettings{IdleTimeout: 15, MaxSessionDuration: 480}, http.StatusOK},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/namespaces/tenant/settings", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			var settings *models.NamespaceSettings
+			if err := json.NewDecoder(rec.Result().Body).Decode(&settings); err != nil {
+				assert.ErrorIs(t, io.EOF, err)
+			}
+
+			if tc.expected.status == http.StatusOK {
+				assert.Equal(t, tc.expected.settings, settings)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEvaluateMember(t *testing.T) {
+	mock := new(mocks.Service)
+
+	type Expected struct {
+		allowed bool
+		status  int
+	}
+
+	cases := []struct {
+		title         string
+		query         string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			title:         "fails when the device is not informed",
+			query:         "user_id=id",
+			requiredMocks: func() {},
+			expected:      Expected{false, http.StatusBadRequest},
+		},
+		{
+			title: "refuses when the member cannot connect to the device",
+			query: "device_uid=uid&user_id=id",
+			requiredMocks: func() {
+				mock.On("EvaluateMember", gomock.Anything, "tenant", "id", "uid").Return(false, nil).Once()
+			},
+			expected: Expected{false, http.StatusOK},
+		},
+		{
+			title: "allows when the member can connect to the device",
+			query: "device_uid=uid&user_id=id",
+			requiredMocks: func() {
+				mock.On("EvaluateMember", gomock.Anything, "tenant", "id", "uid").Return(true, nil).Once()
+			},
+			expected: Expected{true, http.StatusOK},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/namespaces/tenant/members/evaluate?"+tc.query, nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expected.status, rec.Result().StatusCode)
+
+			if tc.expected.status == http.StatusOK {
+				var allowed bool
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&allowed))
+				assert.Equal(t, tc.expected.allowed, allowed)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+// AuthOIDCURL is under the login's path, so it does not require the user's token at the API gateway.
+const AuthOIDCURL = "/login/oidc"
+
+// OIDCStateCookie is the cookie that binds the login's state to the browser that started it.
+const OIDCStateCookie = "shellhub_oidc_state"
+
+// OIDCAuthURL starts a login through OpenID Connect, returning the identity provider's URL where the user must be sent.
+func (h *Handler) OIDCAuthURL(c gateway.Context) error {
+	url, state, err := h.service.OIDCAuthURL(c.Ctx())
+	if err != nil {
+		return err
+	}
+
+	c.SetCookie(oidcStateCookie(c, state, int(services.OIDCLoginExpiry.Seconds())))
+
+	return c.JSON(http.StatusOK, map[string]string{"url": url})
+}
+
+// AuthOIDC completes a login through OpenID Connect with the code and state sent by the identity provider.
+func (h *Handler) AuthOIDC(c gateway.Context) error {
+	var req requests.AuthOIDC
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if cookie, err := c.Cookie(OIDCStateCookie); err == nil {
+		req.BrowserState = cookie.Value
+	}
+
+	// The state is used only once, so its cookie is removed whatever the login's result.
+	c.SetCookie(oidcStateCookie(c, "", -1))
+
+	res, err := h.service.AuthOIDC(c.Ctx(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+// oidcStateCookie creates the cookie with the login's state. It is not readable by scripts, and it is not sent on the
+// cross-site requests that complete a login, so another site cannot use it.
+func oidcStateCookie(c gateway.Context, state string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     OIDCStateCookie,
+		Value:    state,
+		Path:     "/api" + AuthOIDCURL,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+}
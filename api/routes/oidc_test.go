@@ -0,0 +1,130 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestOIDCAuthURL(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		requiredMocks func()
+		expected      map[string]string
+		status        int
+	}{
+		{
+			title: "fails when OIDC is not configured",
+			requiredMocks: func() {
+				mock.On("OIDCAuthURL", gomock.Anything).Return("", "", svc.NewErrOIDCNotConfigured(nil)).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			title: "success when OIDC is configured",
+			requiredMocks: func() {
+				mock.On("OIDCAuthURL", gomock.Anything).Return("http://idp/authorize?state=state", "state", nil).Once()
+			},
+			expected: map[string]string{"url": "http://idp/authorize?state=state"},
+			status:   http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/login/oidc", nil)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+
+			if tc.expected != nil {
+				var res map[string]string
+				assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&res))
+				assert.Equal(t, tc.expected, res)
+
+				// The state is bound to the browser by a cookie that scripts cannot read.
+				cookies := rec.Result().Cookies()
+				assert.Len(t, cookies, 1)
+				assert.Equal(t, OIDCStateCookie, cookies[0].Name)
+				assert.Equal(t, "state", cookies[0].Value)
+				assert.True(t, cookies[0].HttpOnly)
+				assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthOIDC(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		body          string
+		cookie        string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the state is missing",
+			body:          `{"code": "code"}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the login is not authorized",
+			body:  `{"code": "code", "state": "state"}`,
+			requiredMocks: func() {
+				mock.On("AuthOIDC", gomock.Anything, requests.AuthOIDC{Code: "code", State: "state"}).Return(nil, svc.NewErrAuthUnathorized(nil)).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			title:  "success when the login is authorized",
+			body:   `{"code": "code", "state": "state"}`,
+			cookie: "state",
+			requiredMocks: func() {
+				mock.On("AuthOIDC", gomock.Anything, requests.AuthOIDC{Code: "code", State: "state", BrowserState: "state"}).Return(&models.UserAuthResponse{Token: "jwt", ID: "id"}, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/oidc", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tc.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: OIDCStateCookie, Value: tc.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -33,17 +33,44 @@ func NewRouter(service services.Service) *echo.Echo {
 	internalAPI.GET(AuthRequestURL, gateway.Handler(handler.AuthRequest), gateway.Middleware(AuthMiddleware))
 	publicAPI.POST(AuthDeviceURL, gateway.Handler(handler.AuthDevice))
 	publicAPI.POST(AuthDeviceURLV2, gateway.Handler(handler.AuthDevice))
+	publicAPI.POST(AuthDeviceChallengeURL, gateway.Handler(handler.AuthDeviceChallenge))
+	publicAPI.POST(AuthDeviceRotateKeyURL, gateway.Handler(handler.AuthDeviceRotateKey))
+	publicAPI.POST(AuthDeviceRekeyURL, gateway.Handler(handler.AuthDeviceRekey))
 	publicAPI.POST(AuthUserURL, gateway.Handler(handler.AuthUser))
 	publicAPI.POST(AuthUserURLV2, gateway.Handler(handler.AuthUser))
 	publicAPI.GET(AuthUserURLV2, gateway.Handler(handler.AuthUserInfo))
 	internalAPI.GET(AuthUserTokenURL, gateway.Handler(handler.AuthGetToken))
 	publicAPI.POST(AuthPublicKeyURL, gateway.Handler(handler.AuthPublicKey))
 	publicAPI.GET(AuthUserTokenURL, gateway.Handler(handler.AuthSwapToken))
+	publicAPI.POST(AuthMFAURL, gateway.Handler(handler.AuthMFA))
+	publicAPI.POST(AuthMFAEnrollURL, gateway.Handler(handler.AuthMFAEnroll))
+	publicAPI.POST(AuthRefreshURL, gateway.Handler(handler.AuthRefresh))
+	publicAPI.GET(JWKSURL, gateway.Handler(handler.GetJWKS))
+	publicAPI.POST(RecoverPasswordURL, gateway.Handler(handler.RecoverPassword))
+	publicAPI.POST(ResetPasswordURL, gateway.Handler(handler.ResetPassword))
+	publicAPI.POST(SendEmailConfirmationURL, gateway.Handler(handler.SendEmailConfirmation))
+	publicAPI.POST(ConfirmEmailURL, gateway.Handler(handler.ConfirmEmail))
+
+	publicAPI.GET(ListUserSessionsURL, gateway.Handler(handler.ListUserSessions))
+	publicAPI.DELETE(DeleteUserSessionURL, gateway.Handler(handler.DeleteUserSession))
+
+	publicAPI.POST(EnrollMFAURL, gateway.Handler(handler.EnrollMFA))
+	publicAPI.POST(EnableMFAURL, gateway.Handler(handler.EnableMFA))
+	publicAPI.POST(DisableMFAURL, gateway.Handler(handler.DisableMFA))
+	publicAPI.PUT(EditNamespaceRequireMFAURL, gateway.Handler(handler.EditNamespaceRequireMFA))
+	internalAPI.POST(ValidateMFAURL, gateway.Handler(handler.ValidateMFA))
+
+	publicAPI.GET(AuthOIDCURL, gateway.Handler(handler.OIDCAuthURL))
+	publicAPI.POST(AuthOIDCURL, gateway.Handler(handler.AuthOIDC))
 
 	publicAPI.PATCH(UpdateUserDataURL, gateway.Handler(handler.UpdateUserData))
 	publicAPI.PATCH(UpdateUserPasswordURL, gateway.Handler(handler.UpdateUserPassword))
 	publicAPI.PUT(EditSessionRecordStatusURL, gateway.Handler(handler.EditSessionRecordStatus))
 	publicAPI.GET(GetSessionRecordURL, gateway.Handler(handler.GetSessionRecord))
+	publicAPI.PUT(EditSessionPolicyURL, gateway.Handler(handler.EditSessionPolicy))
+	publicAPI.PUT(EditAgentTagsURL, gateway.Handler(handler.EditAgentTags))
+	internalAPI.GET(GetNamespaceSettingsURL, gateway.Handler(handler.GetNamespaceSettings))
+	internalAPI.GET(EvaluateMemberURL, gateway.Handler(handler.EvaluateMember))
 
 	publicAPI.GET(GetDeviceListURL,
 		apiMiddleware.Authorize(gateway.Handler(handler.GetDeviceList)))
@@ -53,6 +80,8 @@ func NewRouter(service services.Service) *echo.Echo {
 	publicAPI.DELETE(DeleteDeviceURL, gateway.Handler(handler.DeleteDevice))
 	publicAPI.PUT(UpdateDevice, gateway.Handler(handler.UpdateDevice))
 	publicAPI.PATCH(RenameDeviceURL, gateway.Handler(handler.RenameDevice))
+	publicAPI.DELETE(RevokeDeviceKeyURL, gateway.Handler(handler.RevokeDeviceKey))
+	publicAPI.POST(CreateDeviceRekeyTokenURL, gateway.Handler(handler.CreateDeviceRekeyToken))
 	internalAPI.POST(OfflineDeviceURL, gateway.Handler(handler.OfflineDevice))
 	internalAPI.POST(HeartbeatDeviceURL, gateway.Handler(handler.HeartbeatDevice))
 	internalAPI.GET(LookupDeviceURL, gateway.Handler(handler.LookupDevice))
@@ -75,6 +104,8 @@ func NewRouter(service services.Service) *echo.Echo {
 	internalAPI.POST(FinishSessionURL, gateway.Handler(handler.FinishSession))
 	internalAPI.POST(KeepAliveSessionURL, gateway.Handler(handler.KeepAliveSession))
 	internalAPI.POST(RecordSessionURL, gateway.Handler(handler.RecordSession))
+	internalAPI.GET(ShadowSessionURL,
+		apiMiddleware.Authorize(gateway.Handler(handler.ShadowSession)))
 	publicAPI.GET(PlaySessionURL, gateway.Handler(handler.PlaySession))
 	publicAPI.DELETE(RecordSessionURL, gateway.Handler(handler.DeleteRecordedSession))
 
@@ -103,6 +134,22 @@ func NewRouter(service services.Service) *echo.Echo {
 	publicAPI.POST(AddNamespaceUserURL, gateway.Handler(handler.AddNamespaceUser))
 	publicAPI.DELETE(RemoveNamespaceUserURL, gateway.Handler(handler.RemoveNamespaceUser))
 	publicAPI.PATCH(EditNamespaceUserURL, gateway.Handler(handler.EditNamespaceUser))
+	publicAPI.PUT(EditNamespaceMemberScopeURL, gateway.Handler(handler.EditNamespaceMemberScope))
+	publicAPI.POST(TransferNamespaceURL, gateway.Handler(handler.TransferNamespace))
+	publicAPI.POST(AcceptNamespaceTransferURL, gateway.Handler(handler.AcceptNamespaceTransfer))
+	publicAPI.DELETE(CancelNamespaceTransferURL, gateway.Handler(handler.CancelNamespaceTransfer))
+	publicAPI.PUT(EditAccessApprovalTagsURL, gateway.Handler(handler.EditAccessApprovalTags))
+
+	publicAPI.GET(ListAccessRequestsURL, gateway.Handler(handler.GetAccessRequestList))
+	publicAPI.POST(CreateAccessRequestURL, gateway.Handler(handler.CreateAccessRequest))
+	publicAPI.PATCH(ReviewAccessRequestURL, gateway.Handler(handler.ReviewAccessRequest))
+	internalAPI.GET(EvaluateAccessRequestURL, gateway.Handler(handler.EvaluateAccessRequest))
+
+	publicAPI.GET(ListEnrollmentTokensURL, gateway.Handler(handler.ListEnrollmentTokens))
+	publicAPI.POST(CreateEnrollmentTokenURL, gateway.Handler(handler.CreateEnrollmentToken))
+	publicAPI.DELETE(DeleteEnrollmentTokenURL, gateway.Handler(handler.DeleteEnrollmentToken))
+	publicAPI.PUT(EditNamespaceRequireEnrollmentTokenURL, gateway.Handler(handler.EditNamespaceRequireEnrollmentToken))
+
 	publicAPI.GET(HealthCheckURL, gateway.Handler(handler.EvaluateHealth))
 
 	return e
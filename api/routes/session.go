@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -19,6 +20,7 @@ const (
 	KeepAliveSessionURL        = "/sessions/:uid/keepalive"
 	RecordSessionURL           = "/sessions/:uid/record"
 	PlaySessionURL             = "/sessions/:uid/play"
+	ShadowSessionURL           = "/sessions/:uid/shadow"
 )
 
 const (
@@ -62,6 +64,35 @@ func (h *Handler) GetSession(c gateway.Context) error {
 	return c.JSON(http.StatusOK, session)
 }
 
+// ShadowSession checks if the member, identified by the headers set on the gateway, can watch a session.
+func (h *Handler) ShadowSession(c gateway.Context) error {
+	var req requests.SessionGet
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if c.Tenant() == nil {
+		return c.NoContent(http.StatusForbidden)
+	}
+
+	var session *models.Session
+	err := guard.EvaluatePermission(c.Role(), guard.Actions.Session.Shadow, func() error {
+		var err error
+		session, err = h.service.GetSession(c.Ctx(), models.UID(req.UID))
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
 func (h *Handler) SetSessionAuthenticated(c gateway.Context) error {
 	var req requests.SessionAuthenticatedSet
 	if err := c.Bind(&req); err != nil {
@@ -103,7 +134,7 @@ func (h *Handler) FinishSession(c gateway.Context) error {
 		return err
 	}
 
-	return h.service.DeactivateSession(c.Ctx(), models.UID(req.UID))
+	return h.service.DeactivateSession(c.Ctx(), models.UID(req.UID), req.Reason)
 }
 
 func (h *Handler) KeepAliveSession(c gateway.Context) error {
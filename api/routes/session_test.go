@@ -168,6 +168,70 @@ func TestGetSession(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestShadowSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title          string
+		role           string
+		tenant         string
+		requiredMocks  func()
+		expectedStatus int
+	}{
+		{
+			title:          "fails when the request has no tenant",
+			role:           guard.RoleOwner,
+			tenant:         "",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:          "fails when role cannot watch sessions",
+			role:           guard.RoleOperator,
+			tenant:         "tenant",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			title:  "fails when session does not exist",
+			role:   guard.RoleAdministrator,
+			tenant: "tenant",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("1234")).Return(nil, svc.NewErrSessionNotFound(models.UID("1234"), store.ErrNoDocuments)).Once()
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			title:  "success when administrator can watch the session",
+			role:   guard.RoleAdministrator,
+			tenant: "tenant",
+			requiredMocks: func() {
+				mock.On("GetSession", gomock.Anything, models.UID("1234")).Return(&models.Session{UID: "1234", Active: true}, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/internal/sessions/1234/shadow", nil)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Role", tc.role)
+			req.Header.Set("X-Tenant-ID", tc.tenant)
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.expectedStatus, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestCreateSession(t *testing.T) {
 	mock := new(mocks.Service)
 
@@ -267,6 +331,7 @@ func TestFinishSession(t *testing.T) {
 	cases := []struct {
 		title          string
 		uid            string
+		reason         string
 		requiredMocks  func()
 		expectedStatus int
 	}{
@@ -280,7 +345,7 @@ func TestFinishSession(t *testing.T) {
 			title: "fails when try to finishing a non-existing session",
 			uid:   "1234",
 			requiredMocks: func() {
-				mock.On("DeactivateSession", gomock.Anything, models.UID("1234")).Return(svc.ErrSessionNotFound)
+				mock.On("DeactivateSession", gomock.Anything, models.UID("1234"), "").Return(svc.ErrSessionNotFound)
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -288,7 +353,23 @@ func TestFinishSession(t *testing.T) {
 			title: "success when try to finishing an existing session",
 			uid:   "123",
 			requiredMocks: func() {
-				mock.On("DeactivateSession", gomock.Anything, models.UID("123")).Return(nil)
+				mock.On("DeactivateSession", gomock.Anything, models.UID("123"), "").Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			title:          "fails when reason is unknown",
+			uid:            "123",
+			reason:         "unknown",
+			requiredMocks:  func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			title:  "success when try to finishing an existing session with a reason",
+			uid:    "123",
+			reason: models.SessionFinishReasonMaxDuration,
+			requiredMocks: func() {
+				mock.On("DeactivateSession", gomock.Anything, models.UID("123"), models.SessionFinishReasonMaxDuration).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -298,7 +379,10 @@ func TestFinishSession(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			tc.requiredMocks()
 
-			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/internal/sessions/%s/finish", tc.uid), nil)
+			body, err := json.Marshal(map[string]string{"reason": tc.reason})
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/internal/sessions/%s/finish", tc.uid), strings.NewReader(string(body)))
 			req.Header.Set("Content-Type", "application/json")
 			req.Header.Set("X-Role", guard.RoleOwner)
 			rec := httptest.NewRecorder()
@@ -89,6 +89,10 @@ func (h *Handler) CreatePublicKey(c gateway.Context) error {
 		req.TenantID = tenant
 	}
 
+	if c.ID() != nil {
+		req.UserID = c.ID().ID
+	}
+
 	var res *responses.PublicKeyCreate
 	err := guard.EvaluatePermission(c.Role(), guard.Actions.PublicKey.Create, func() error {
 		var err error
@@ -118,6 +122,10 @@ func (h *Handler) UpdatePublicKey(c gateway.Context) error {
 		tenant = c.Tenant().ID
 	}
 
+	if c.ID() != nil {
+		req.UserID = c.ID().ID
+	}
+
 	var key *models.PublicKey
 	err := guard.EvaluatePermission(c.Role(), guard.Actions.PublicKey.Edit, func() error {
 		var err error
@@ -189,7 +197,12 @@ func (h *Handler) EvaluateKey(c gateway.Context) error {
 		return err
 	}
 
-	return c.JSON(http.StatusOK, usernameOk && filterOk)
+	memberOk, err := h.service.EvaluateKeyMember(c.Ctx(), pubKey, device)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, usernameOk && filterOk && memberOk)
 }
 
 func (h *Handler) AddPublicKeyTag(c gateway.Context) error {
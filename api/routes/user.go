@@ -61,7 +61,7 @@ func (h *Handler) UpdateUserPassword(c gateway.Context) error {
 		return err
 	}
 
-	if err := h.service.UpdatePasswordUser(c.Ctx(), req.ID, req.CurrentPassword, req.NewPassword); err != nil {
+	if err := h.service.UpdatePasswordUser(c.Ctx(), req.ID, c.SessionID(), req.CurrentPassword, req.NewPassword); err != nil {
 		return err
 	}
 
@@ -0,0 +1,85 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+// The routes are under /login, so the gateway lets the users without a token, who forgot the password or did not
+// confirm the e-mail, reach them.
+const (
+	RecoverPasswordURL       = "/login/password/recover"
+	ResetPasswordURL         = "/login/password/reset"
+	SendEmailConfirmationURL = "/login/email/resend"
+	ConfirmEmailURL          = "/login/email/confirm"
+)
+
+func (h *Handler) RecoverPassword(c gateway.Context) error {
+	var req requests.UserPasswordRecover
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.RecoverPassword(c.Ctx(), req.Username); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ResetPassword(c gateway.Context) error {
+	var req requests.UserPasswordReset
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.ResetPassword(c.Ctx(), req.ID, req.Token, req.Password); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) SendEmailConfirmation(c gateway.Context) error {
+	var req requests.UserEmailResend
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.SendEmailConfirmation(c.Ctx(), req.Username); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ConfirmEmail(c gateway.Context) error {
+	var req requests.UserEmailConfirm
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	if err := h.service.ConfirmEmail(c.Ctx(), req.ID, req.Token); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
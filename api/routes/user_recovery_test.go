@@ -0,0 +1,183 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestRecoverPassword(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the username is missing",
+			body:          `{}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the e-mail delivery is not configured",
+			body:  `{"username": "john"}`,
+			requiredMocks: func() {
+				mock.On("RecoverPassword", gomock.Anything, "john").Return(svc.NewErrMailNotConfigured(nil)).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			title: "success when the link is sent",
+			body:  `{"username": "john@example.com"}`,
+			requiredMocks: func() {
+				mock.On("RecoverPassword", gomock.Anything, "john@example.com").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/password/recover", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the password is too short",
+			body:          `{"id": "id", "token": "token", "password": "new"}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the token is invalid",
+			body:  `{"id": "id", "token": "wrong", "password": "newpassword"}`,
+			requiredMocks: func() {
+				mock.On("ResetPassword", gomock.Anything, "id", "wrong", "newpassword").Return(svc.NewErrUserTokenInvalid(nil)).Once()
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			title: "success when the token is valid",
+			body:  `{"id": "id", "token": "token", "password": "newpassword"}`,
+			requiredMocks: func() {
+				mock.On("ResetPassword", gomock.Anything, "id", "token", "newpassword").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/password/reset", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestSendEmailConfirmation(t *testing.T) {
+	mock := new(mocks.Service)
+
+	mock.On("SendEmailConfirmation", gomock.Anything, "john").Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/login/email/resend", strings.NewReader(`{"username": "john"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	mock.AssertExpectations(t)
+}
+
+func TestConfirmEmail(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the token is missing",
+			body:          `{"id": "id"}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the token is invalid",
+			body:  `{"id": "id", "token": "wrong"}`,
+			requiredMocks: func() {
+				mock.On("ConfirmEmail", gomock.Anything, "id", "wrong").Return(svc.NewErrUserTokenInvalid(nil)).Once()
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			title: "success when the token is valid",
+			body:  `{"id": "id", "token": "token"}`,
+			requiredMocks: func() {
+				mock.On("ConfirmEmail", gomock.Anything, "id", "token").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/email/confirm", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
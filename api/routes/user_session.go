@@ -0,0 +1,68 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+)
+
+const (
+	AuthRefreshURL       = "/login/refresh"
+	ListUserSessionsURL  = "/user/sessions"
+	DeleteUserSessionURL = "/user/sessions/:id"
+)
+
+func (h *Handler) AuthRefresh(c gateway.Context) error {
+	var req requests.AuthRefresh
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	res, err := h.service.AuthRefresh(c.Ctx(), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, res)
+}
+
+func (h *Handler) ListUserSessions(c gateway.Context) error {
+	var id string
+	if v := c.ID(); v != nil {
+		id = v.ID
+	}
+
+	sessions, err := h.service.UserSessionList(c.Ctx(), id, c.SessionID())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func (h *Handler) DeleteUserSession(c gateway.Context) error {
+	var req requests.UserSessionParam
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	var id string
+	if v := c.ID(); v != nil {
+		id = v.ID
+	}
+
+	if err := h.service.UserSessionDelete(c.Ctx(), id, req.ID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusOK)
+}
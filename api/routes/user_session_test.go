@@ -0,0 +1,136 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	svc "github.com/shellhub-io/shellhub/api/services"
+	"github.com/shellhub-io/shellhub/api/services/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	gomock "github.com/stretchr/testify/mock"
+)
+
+func TestAuthRefresh(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		body          string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title:         "fails when the refresh token is missing",
+			body:          `{"tenant": "tenant"}`,
+			requiredMocks: func() {},
+			status:        http.StatusBadRequest,
+		},
+		{
+			title: "fails when the refresh token is invalid",
+			body:  `{"refresh_token": "session.invalid"}`,
+			requiredMocks: func() {
+				mock.On("AuthRefresh", gomock.Anything, requests.AuthRefresh{RefreshToken: "session.invalid"}).Return(nil, svc.NewErrAuthUnathorized(nil)).Once()
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			title: "success when the refresh token is valid",
+			body:  `{"refresh_token": "session.secret", "tenant": "tenant"}`,
+			requiredMocks: func() {
+				mock.On("AuthRefresh", gomock.Anything, requests.AuthRefresh{RefreshToken: "session.secret", Tenant: "tenant"}).Return(&models.UserAuthResponse{Token: "jwt", RefreshToken: "session.new"}, nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/login/refresh", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestListUserSessions(t *testing.T) {
+	mock := new(mocks.Service)
+
+	sessions := []models.UserSession{{ID: "session", IP: "192.168.1.1", Current: true}, {ID: "other"}}
+
+	mock.On("UserSessionList", gomock.Anything, "id", "session").Return(sessions, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/sessions", nil)
+	req.Header.Set("X-ID", "id")
+	req.Header.Set("X-Session-ID", "session")
+	rec := httptest.NewRecorder()
+
+	e := NewRouter(mock)
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Result().StatusCode)
+
+	var res []models.UserSession
+	assert.NoError(t, json.NewDecoder(rec.Result().Body).Decode(&res))
+	assert.Equal(t, sessions, res)
+
+	mock.AssertExpectations(t)
+}
+
+func TestDeleteUserSession(t *testing.T) {
+	mock := new(mocks.Service)
+
+	cases := []struct {
+		title         string
+		id            string
+		requiredMocks func()
+		status        int
+	}{
+		{
+			title: "fails when the session is not found",
+			id:    "unknown",
+			requiredMocks: func() {
+				mock.On("UserSessionDelete", gomock.Anything, "id", "unknown").Return(svc.NewErrUserSessionNotFound("unknown", nil)).Once()
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			title: "success when the session is revoked",
+			id:    "session",
+			requiredMocks: func() {
+				mock.On("UserSessionDelete", gomock.Anything, "id", "session").Return(nil).Once()
+			},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.title, func(t *testing.T) {
+			tc.requiredMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/sessions/"+tc.id, nil)
+			req.Header.Set("X-ID", "id")
+			rec := httptest.NewRecorder()
+
+			e := NewRouter(mock)
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Result().StatusCode)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
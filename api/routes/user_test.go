@@ -192,7 +192,7 @@ func TestUpdateUserPassword(t *testing.T) {
 				NewPassword:     "new_password",
 			},
 			requiredMocks: func(updatePayloadMock requests.UserPasswordUpdate) {
-				mock.On("UpdatePasswordUser", gomock.Anything, "123", updatePayloadMock.CurrentPassword, updatePayloadMock.NewPassword).Return(svc.ErrUserNotFound).Once()
+				mock.On("UpdatePasswordUser", gomock.Anything, "123", "", updatePayloadMock.CurrentPassword, updatePayloadMock.NewPassword).Return(svc.ErrUserNotFound).Once()
 			},
 			expectedStatus: http.StatusNotFound,
 		},
@@ -207,7 +207,7 @@ func TestUpdateUserPassword(t *testing.T) {
 				NewPassword:     "new_password",
 			},
 			requiredMocks: func(updatePayloadMock requests.UserPasswordUpdate) {
-				mock.On("UpdatePasswordUser", gomock.Anything, "123", updatePayloadMock.CurrentPassword, updatePayloadMock.NewPassword).Return(nil).Once()
+				mock.On("UpdatePasswordUser", gomock.Anything, "123", "", updatePayloadMock.CurrentPassword, updatePayloadMock.NewPassword).Return(nil).Once()
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 
@@ -9,6 +10,9 @@ import (
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
 	"github.com/shellhub-io/shellhub/api/pkg/echo/handlers"
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/ldap"
+	"github.com/shellhub-io/shellhub/api/pkg/mail"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
 	"github.com/shellhub-io/shellhub/api/routes"
 	"github.com/shellhub-io/shellhub/api/services"
 	"github.com/shellhub-io/shellhub/api/store"
@@ -16,6 +20,7 @@ import (
 	"github.com/shellhub-io/shellhub/api/workers"
 	requests "github.com/shellhub-io/shellhub/pkg/api/internalclient"
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/geoip"
 	"github.com/shellhub-io/shellhub/pkg/middleware"
 	log "github.com/sirupsen/logrus"
@@ -64,7 +69,7 @@ var serverCmd = &cobra.Command{
 			log.Info("Workers started")
 		}()
 
-		return startServer(cfg, store, cache)
+		return startServer(ctx, cfg, store, cache)
 	},
 }
 
@@ -85,6 +90,11 @@ type config struct {
 	SessionRecordCleanupSchedule string `envconfig:"session_record_cleanup_schedule" default:"@daily"`
 	// Sentry DSN.
 	SentryDSN string `envconfig:"sentry_dsn" default:""`
+	// Accept the device auth without the signed challenge, sent by the agents older than the proof of key possession.
+	//
+	// It is a temporary opt-in for upgrades, enabled only while the agents already installed are updated, and will be
+	// removed in a future release.
+	LegacyDeviceAuth bool `envconfig:"legacy_device_auth" default:"false"`
 }
 
 func init() {
@@ -119,7 +129,7 @@ func startSentry(dsn string) (*sentry.Client, error) {
 	return nil, errors.New("sentry DSN not provided")
 }
 
-func startServer(cfg *config, store store.Store, cache storecache.Cache) error {
+func startServer(ctx context.Context, cfg *config, store store.Store, cache storecache.Cache) error {
 	log.Info("Starting Sentry client")
 
 	reporter, err := startSentry(cfg.SentryDSN)
@@ -145,7 +155,69 @@ func startServer(cfg *config, store store.Store, cache storecache.Cache) error {
 		locator = geoip.NewNullGeoLite()
 	}
 
-	service := services.NewService(store, nil, nil, cache, requestClient, locator)
+	var options []services.Option
+
+	oidcConfig, err := envs.ParseWithPrefix[oidc.Config]("api")
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load the OIDC configuration")
+	}
+
+	if oidcConfig.Enabled() {
+		log.WithField("issuer", oidcConfig.Issuer).Info("OIDC login is enabled")
+		options = append(options, services.WithOIDC(oidc.NewProvider(*oidcConfig)))
+	}
+
+	ldapConfig, err := envs.ParseWithPrefix[ldap.Config]("api")
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load the LDAP configuration")
+	}
+
+	if ldapConfig.Enabled() {
+		directory, err := ldap.NewDirectory(*ldapConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to configure the LDAP directory")
+		}
+
+		log.WithField("url", ldapConfig.URL).Info("LDAP login is enabled")
+		options = append(options, services.WithLDAP(directory))
+	}
+
+	mailConfig, err := envs.ParseWithPrefix[mail.Config]("api")
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load the SMTP configuration")
+	}
+
+	if mailConfig.Enabled() {
+		mailer, err := mail.NewMailer(*mailConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to configure the SMTP server")
+		}
+
+		log.WithField("host", mailConfig.Host).Info("E-mail delivery is enabled")
+		options = append(options, services.WithMailer(mailer))
+	}
+
+	verificationKeys, err := services.LoadVerificationKeys()
+	if err != nil {
+		log.WithError(err).Fatal("Failed to load the verification keys")
+	}
+
+	if len(verificationKeys) > 0 {
+		log.WithField("keys", len(verificationKeys)).Info("Tokens signed by previous keys are still verified")
+		options = append(options, services.WithVerificationKeys(verificationKeys...))
+	}
+
+	if cfg.LegacyDeviceAuth {
+		log.Warn("Legacy device auth is enabled: devices are accepted without proving the possession of their keys. Disable it once the agents are updated, as it will be removed in a future release")
+		options = append(options, services.WithLegacyDeviceAuth())
+	}
+
+	service := services.NewService(store, nil, nil, cache, requestClient, locator, options...)
+
+	// The access requests are expired by the service, which closes the sessions opened during their window.
+	if err := workers.StartAccessRequestExpirer(ctx, service); err != nil {
+		log.WithError(err).Fatal("Failed to start access request expirer worker")
+	}
 
 	e := routes.NewRouter(service)
 	e.Use(middleware.Log)
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/api/paginator"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+type AccessRequestService interface {
+	ListAccessRequests(ctx context.Context, tenantID, userID, status string, pagination paginator.Query) ([]models.AccessRequest, int, error)
+	CreateAccessRequest(ctx context.Context, tenantID, userID string, req requests.AccessRequestCreate) (*models.AccessRequest, error)
+	ReviewAccessRequest(ctx context.Context, tenantID, reviewerID, id string, approve bool) (*models.AccessRequest, error)
+	EvaluateAccessRequest(ctx context.Context, tenantID, deviceUID, userID string) (bool, error)
+	ExpireAccessRequests(ctx context.Context) (int64, error)
+	EditAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error
+}
+
+// ListAccessRequests lists the access requests of a namespace. When userID is not empty, only the requests made by
+// this member are listed.
+func (s *service) ListAccessRequests(ctx context.Context, tenantID, userID, status string, pagination paginator.Query) ([]models.AccessRequest, int, error) {
+	return s.store.AccessRequestList(ctx, tenantID, userID, status, pagination)
+}
+
+// CreateAccessRequest creates a pending access request from a namespace's member to one of its devices.
+//
+// When the request's start time is not set, the access window begins at the moment of the creation.
+func (s *service) CreateAccessRequest(ctx context.Context, tenantID, userID string, req requests.AccessRequestCreate) (*models.AccessRequest, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return nil, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if _, ok := guard.CheckMember(namespace, userID); !ok {
+		return nil, NewErrNamespaceMemberNotFound(userID, nil)
+	}
+
+	if _, err := s.store.DeviceGetByUID(ctx, models.UID(req.DeviceUID), tenantID); err != nil {
+		return nil, NewErrDeviceNotFound(models.UID(req.DeviceUID), err)
+	}
+
+	now := clock.Now()
+
+	startsAt := req.StartsAt
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+
+	if !req.EndsAt.After(startsAt) || !req.EndsAt.After(now) {
+		return nil, NewErrAccessRequestInvalid(map[string]interface{}{"starts_at": startsAt, "ends_at": req.EndsAt}, nil)
+	}
+
+	return s.store.AccessRequestCreate(ctx, &models.AccessRequest{
+		TenantID:      tenantID,
+		DeviceUID:     req.DeviceUID,
+		UserID:        userID,
+		Justification: req.Justification,
+		StartsAt:      startsAt,
+		EndsAt:        req.EndsAt,
+		Status:        models.AccessRequestPending,
+		CreatedAt:     now,
+	})
+}
+
+// ReviewAccessRequest approves or denies a pending access request.
+//
+// A member cannot review its own access request.
+func (s *service) ReviewAccessRequest(ctx context.Context, tenantID, reviewerID, id string, approve bool) (*models.AccessRequest, error) {
+	request, err := s.store.AccessRequestGet(ctx, tenantID, id)
+	if err != nil {
+		return nil, NewErrAccessRequestNotFound(id, err)
+	}
+
+	if request.UserID == reviewerID {
+		return nil, guard.ErrForbidden
+	}
+
+	if request.Status != models.AccessRequestPending {
+		return nil, NewErrAccessRequestReviewed(id, nil)
+	}
+
+	status := models.AccessRequestDenied
+	if approve {
+		status = models.AccessRequestApproved
+	}
+
+	now := clock.Now()
+	if err := s.store.AccessRequestReview(ctx, tenantID, id, status, reviewerID, now); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return nil, NewErrAccessRequestReviewed(id, err)
+		}
+
+		return nil, err
+	}
+
+	request.Status = status
+	request.ReviewedBy = reviewerID
+	request.ReviewedAt = &now
+
+	return request, nil
+}
+
+// EvaluateAccessRequest checks if a member can connect to a device right now.
+//
+// Devices without any of the namespace's access approval tags are always allowed. Otherwise, the member must either
+// have a role that reviews access requests or an approved access request whose window contains the current time. An
+// empty userID, which happens when the connection is not bound to a member, is never allowed on those devices: the
+// password logins, including the web terminal's, authenticate the device's user and are only bound to a member when it
+// answers the namespace's second factor challenge.
+func (s *service) EvaluateAccessRequest(ctx context.Context, tenantID, deviceUID, userID string) (bool, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return false, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	device, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID)
+	if err != nil {
+		return false, NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	if !namespace.Settings.RequiresApproval(device) {
+		return true, nil
+	}
+
+	member, ok := guard.CheckMember(namespace, userID)
+	if !ok {
+		return false, nil
+	}
+
+	if err := guard.EvaluatePermission(member.Role, guard.Actions.AccessRequest.Review, func() error { return nil }); err == nil {
+		return true, nil
+	}
+
+	if _, err := s.store.AccessRequestGetGranted(ctx, tenantID, deviceUID, userID, clock.Now()); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ExpireAccessRequests marks as expired the access requests whose window has ended, returning how many were expired.
+//
+// The sessions opened by the member during the window of an approved request are closed, unless the member can still
+// connect to the device, like by another approved request.
+func (s *service) ExpireAccessRequests(ctx context.Context) (int64, error) {
+	expired, err := s.store.AccessRequestExpire(ctx, clock.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, request := range expired {
+		if request.Status != models.AccessRequestApproved {
+			continue
+		}
+
+		if err := s.closeAccessRequestSessions(ctx, request); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"tenant_id":  request.TenantID,
+				"device_uid": request.DeviceUID,
+				"user_id":    request.UserID,
+			}).Warn("failed to close the sessions of the expired access request")
+		}
+	}
+
+	return int64(len(expired)), nil
+}
+
+// closeAccessRequestSessions closes the member's open sessions to the device of the expired access request when the
+// member cannot connect to it anymore.
+func (s *service) closeAccessRequestSessions(ctx context.Context, request models.AccessRequest) error {
+	allowed, err := s.EvaluateAccessRequest(ctx, request.TenantID, request.DeviceUID, request.UserID)
+	if err != nil || allowed {
+		return err
+	}
+
+	sessions, err := s.store.SessionListActiveByMember(ctx, request.TenantID, models.UID(request.DeviceUID), request.UserID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.client.(req.Client).CloseSession(session.UID, request.DeviceUID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EditAccessApprovalTags defines the device's tags that require an approved access request to connect.
+func (s *service) EditAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error {
+	if _, err := s.store.NamespaceGet(ctx, tenantID); err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if len(tags) > 0 {
+		existing, _, err := s.store.TagsGet(ctx, tenantID)
+		if err != nil {
+			return NewErrTagEmpty(tenantID, err)
+		}
+
+		for _, tag := range tags {
+			if !contains(existing, tag) {
+				return NewErrTagNotFound(tag, nil)
+			}
+		}
+	}
+
+	return s.store.NamespaceSetAccessApprovalTags(ctx, tenantID, tags)
+}
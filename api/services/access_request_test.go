@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAccessRequest(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "memberID", Role: guard.RoleObserver},
+		},
+	}
+
+	type Expected struct {
+		request *models.AccessRequest
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		userID        string
+		req           requests.AccessRequestCreate
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace was not found",
+			userID:      "memberID",
+			req:         requests.AccessRequestCreate{DeviceUID: "uid", EndsAt: now.Add(time.Hour)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceNotFound(namespace.TenantID, errors.New("error"))},
+		},
+		{
+			description: "fails when user is not a member of the namespace",
+			userID:      "notMemberID",
+			req:         requests.AccessRequestCreate{DeviceUID: "uid", EndsAt: now.Add(time.Hour)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{nil, NewErrNamespaceMemberNotFound("notMemberID", nil)},
+		},
+		{
+			description: "fails when device was not found",
+			userID:      "memberID",
+			req:         requests.AccessRequestCreate{DeviceUID: "uid", EndsAt: now.Add(time.Hour)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{nil, NewErrDeviceNotFound(models.UID("uid"), errors.New("error"))},
+		},
+		{
+			description: "fails when the window ends before it starts",
+			userID:      "memberID",
+			req:         requests.AccessRequestCreate{DeviceUID: "uid", EndsAt: now.Add(-time.Hour)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(&models.Device{UID: "uid"}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: Expected{nil, NewErrAccessRequestInvalid(map[string]interface{}{"starts_at": now, "ends_at": now.Add(-time.Hour)}, nil)},
+		},
+		{
+			description: "success to create the access request",
+			userID:      "memberID",
+			req:         requests.AccessRequestCreate{DeviceUID: "uid", Justification: "incident", EndsAt: now.Add(time.Hour)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(&models.Device{UID: "uid"}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				request := &models.AccessRequest{
+					TenantID:      namespace.TenantID,
+					DeviceUID:     "uid",
+					UserID:        "memberID",
+					Justification: "incident",
+					StartsAt:      now,
+					EndsAt:        now.Add(time.Hour),
+					Status:        models.AccessRequestPending,
+					CreatedAt:     now,
+				}
+				created := *request
+				created.ID = "id"
+				mock.On("AccessRequestCreate", ctx, request).Return(&created, nil).Once()
+			},
+			expected: Expected{
+				&models.AccessRequest{
+					ID:            "id",
+					TenantID:      namespace.TenantID,
+					DeviceUID:     "uid",
+					UserID:        "memberID",
+					Justification: "incident",
+					StartsAt:      now,
+					EndsAt:        now.Add(time.Hour),
+					Status:        models.AccessRequestPending,
+					CreatedAt:     now,
+				},
+				nil,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			request, err := service.CreateAccessRequest(ctx, namespace.TenantID, tc.userID, tc.req)
+			assert.Equal(t, tc.expected, Expected{request, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestReviewAccessRequest(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	const tenant = "00000000-0000-4000-0000-000000000000"
+
+	cases := []struct {
+		description   string
+		reviewerID    string
+		approve       bool
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when access request was not found",
+			reviewerID:  "adminID",
+			requiredMocks: func() {
+				mock.On("AccessRequestGet", ctx, tenant, "id").Return(nil, errors.New("error")).Once()
+			},
+			expected: NewErrAccessRequestNotFound("id", errors.New("error")),
+		},
+		{
+			description: "fails when the reviewer is the requester",
+			reviewerID:  "memberID",
+			requiredMocks: func() {
+				mock.On("AccessRequestGet", ctx, tenant, "id").Return(&models.AccessRequest{ID: "id", UserID: "memberID", Status: models.AccessRequestPending}, nil).Once()
+			},
+			expected: guard.ErrForbidden,
+		},
+		{
+			description: "fails when access request was already reviewed",
+			reviewerID:  "adminID",
+			requiredMocks: func() {
+				mock.On("AccessRequestGet", ctx, tenant, "id").Return(&models.AccessRequest{ID: "id", UserID: "memberID", Status: models.AccessRequestDenied}, nil).Once()
+			},
+			expected: NewErrAccessRequestReviewed("id", nil),
+		},
+		{
+			description: "success to approve the access request",
+			reviewerID:  "adminID",
+			approve:     true,
+			requiredMocks: func() {
+				mock.On("AccessRequestGet", ctx, tenant, "id").Return(&models.AccessRequest{ID: "id", UserID: "memberID", Status: models.AccessRequestPending}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("AccessRequestReview", ctx, tenant, "id", models.AccessRequestApproved, "adminID", now).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			_, err := service.ReviewAccessRequest(ctx, tenant, tc.reviewerID, "id", tc.approve)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEvaluateAccessRequest(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "adminID", Role: guard.RoleAdministrator},
+			{ID: "memberID", Role: guard.RoleOperator},
+		},
+		Settings: &models.NamespaceSettings{AccessApprovalTags: []string{"production"}},
+	}
+
+	production := &models.Device{UID: "uid", Tags: []string{"production"}}
+
+	type Expected struct {
+		allowed bool
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		userID        string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "allows when device does not require approval",
+			userID:      "memberID",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(&models.Device{UID: "uid", Tags: []string{"staging"}}, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+		{
+			description: "denies when connection is not bound to a member",
+			userID:      "",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "allows when member can review access requests",
+			userID:      "adminID",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+		{
+			description: "denies when member has no granted access request",
+			userID:      "memberID",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("AccessRequestGetGranted", ctx, namespace.TenantID, "uid", "memberID", now).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "allows when member has a granted access request",
+			userID:      "memberID",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("AccessRequestGetGranted", ctx, namespace.TenantID, "uid", "memberID", now).Return(&models.AccessRequest{ID: "id"}, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			allowed, err := service.EvaluateAccessRequest(ctx, namespace.TenantID, "uid", tc.userID)
+			assert.Equal(t, tc.expected, Expected{allowed, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestExpireAccessRequests(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members:  []models.Member{{ID: "memberID", Role: guard.RoleOperator}},
+		Settings: &models.NamespaceSettings{AccessApprovalTags: []string{"production"}},
+	}
+
+	production := &models.Device{UID: "uid", Tags: []string{"production"}}
+
+	approved := models.AccessRequest{TenantID: namespace.TenantID, DeviceUID: "uid", UserID: "memberID", Status: models.AccessRequestApproved}
+	pending := models.AccessRequest{TenantID: namespace.TenantID, DeviceUID: "uid", UserID: "otherID", Status: models.AccessRequestPending}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      int64
+	}{
+		{
+			description: "closes the member's sessions when it cannot connect anymore",
+			requiredMocks: func() {
+				clockMock.On("Now").Return(now).Twice()
+				mock.On("AccessRequestExpire", ctx, now).Return([]models.AccessRequest{approved, pending}, nil).Once()
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+				mock.On("AccessRequestGetGranted", ctx, namespace.TenantID, "uid", "memberID", now).Return(nil, store.ErrNoDocuments).Once()
+				mock.On("SessionListActiveByMember", ctx, namespace.TenantID, models.UID("uid"), "memberID").
+					Return([]models.Session{{UID: "first"}, {UID: "second"}}, nil).Once()
+				clientMock.On("CloseSession", "first", "uid").Return(nil).Once()
+				clientMock.On("CloseSession", "second", "uid").Return(nil).Once()
+			},
+			expected: 2,
+		},
+		{
+			description: "keeps the member's sessions when another access request is granted",
+			requiredMocks: func() {
+				clockMock.On("Now").Return(now).Twice()
+				mock.On("AccessRequestExpire", ctx, now).Return([]models.AccessRequest{approved}, nil).Once()
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(production, nil).Once()
+				mock.On("AccessRequestGetGranted", ctx, namespace.TenantID, "uid", "memberID", now).Return(&models.AccessRequest{ID: "id"}, nil).Once()
+			},
+			expected: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			expired, err := service.ExpireAccessRequests(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, expired)
+		})
+	}
+
+	mock.AssertExpectations(t)
+	clientMock.AssertExpectations(t)
+}
@@ -10,30 +10,148 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/cnf/structhash"
 	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DeviceTokenExpiry is the lifetime of the device's token, renewed by the agent while it is connected.
+	DeviceTokenExpiry = time.Hour
+	// DeviceChallengeExpiry is the time the device has to sign the challenge and authenticate with it.
+	DeviceChallengeExpiry = 5 * time.Minute
 )
 
 type AuthService interface {
 	AuthCacheToken(ctx context.Context, tenant, id, token string) error
 	AuthIsCacheToken(ctx context.Context, tenant, id string) (bool, error)
 	AuthUncacheToken(ctx context.Context, tenant, id string) error
+	// AuthDeviceChallenge issues a nonce, valid for a single authentication, that the device signs to prove that it
+	// holds the private key of its public key.
+	AuthDeviceChallenge(ctx context.Context) (*models.DeviceChallenge, error)
+	// AuthDevice authenticates the device by the challenge signed with its private key, registering it when new, and
+	// issues a token that expires after DeviceTokenExpiry.
 	AuthDevice(ctx context.Context, req requests.DeviceAuth, remoteAddr string) (*models.DeviceAuthResponse, error)
-	AuthUser(ctx context.Context, req requests.UserAuth) (*models.UserAuthResponse, error)
+	// AuthUser authenticates the user by its username, or e-mail, and password. The failed attempts are tracked by
+	// user ID and source IP, blocking them for an increasing time and, after too many failures, locking the user.
+	AuthUser(ctx context.Context, req requests.UserAuth, sourceIP string) (*models.UserAuthResponse, error)
 	AuthGetToken(ctx context.Context, tenant string) (*models.UserAuthResponse, error)
-	AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth) (*models.PublicKeyAuthResponse, error)
-	AuthSwapToken(ctx context.Context, ID, tenant string) (*models.UserAuthResponse, error)
+	AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth, sourceIP string) (*models.PublicKeyAuthResponse, error)
+	// AuthSwapToken issues the user's token to another namespace, bound to the same session.
+	AuthSwapToken(ctx context.Context, ID, session, tenant string) (*models.UserAuthResponse, error)
 	AuthUserInfo(ctx context.Context, username, tenant, token string) (*models.UserAuthResponse, error)
-	PublicKey() *rsa.PublicKey
+	// Keyring returns the keys that sign and verify the tokens issued by the service.
+	Keyring() *keyring.Keyring
+}
+
+func (s *service) AuthDeviceChallenge(ctx context.Context) (*models.DeviceChallenge, error) {
+	nonce, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, strings.Join([]string{"device_challenge", nonce}, "/"), true, DeviceChallengeExpiry); err != nil {
+		return nil, err
+	}
+
+	return &models.DeviceChallenge{Nonce: nonce}, nil
+}
+
+// verifyDeviceChallenge checks that the challenge was issued and signed by the private key of the device's public key,
+// consuming it, so a signed challenge cannot be replayed.
+//
+// When the legacy device auth is enabled, a temporary opt-in for upgrades, a request without the challenge, sent by an
+// older agent, is accepted.
+func (s *service) verifyDeviceChallenge(ctx context.Context, req requests.DeviceAuth) error {
+	if req.Challenge == "" && req.Signature == "" && s.legacyDeviceAuth {
+		logrus.WithFields(logrus.Fields{
+			"tenant_id": req.TenantID,
+			"hostname":  req.Hostname,
+		}).Warn("Device authenticated without proving the possession of its key; update its agent, since the legacy device auth is deprecated")
+
+		return nil
+	}
+
+	if err := s.consumeDeviceChallenge(ctx, req.Challenge); err != nil {
+		return err
+	}
+
+	return verifyDeviceSignature(req.PublicKey, req.Challenge, req.Signature)
+}
+
+// consumeDeviceChallenge checks that the challenge was issued, removing it to be used only once.
+func (s *service) consumeDeviceChallenge(ctx context.Context, challenge string) error {
+	key := strings.Join([]string{"device_challenge", challenge}, "/")
+
+	var issued bool
+	if err := s.cache.Get(ctx, key, &issued); err != nil || !issued {
+		return NewErrAuthUnathorized(err)
+	}
+
+	return s.cache.Delete(ctx, key)
+}
+
+// verifyDeviceSignature checks the base64 encoded signature of the message by the private key of the device's public key.
+func verifyDeviceSignature(key string, message string, signature string) error {
+	publicKey, err := parseDevicePublicKey(key)
+	if err != nil {
+		return NewErrAuthUnathorized(err)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return NewErrAuthUnathorized(err)
+	}
+
+	digest := sha256.Sum256([]byte(message))
+	if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], decoded); err != nil {
+		return NewErrAuthUnathorized(err)
+	}
+
+	return nil
+}
+
+// parseDevicePublicKey parses the device's RSA public key, encoded by the agent as a PKCS #1 PEM block.
+func parseDevicePublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, jwt.ErrKeyMustBePEMEncoded
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, jwt.ErrNotRSAPublicKey
+	}
+
+	return publicKey, nil
 }
 
 func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remoteAddr string) (*models.DeviceAuthResponse, error) {
+	if err := s.verifyDeviceChallenge(ctx, req); err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+
 	var identity *models.DeviceIdentity
 	if req.Identity != nil {
 		identity = &models.DeviceIdentity{
@@ -51,14 +169,29 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 
 	key := hex.EncodeToString(uid[:])
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, models.DeviceAuthClaims{
+	// A rotated device keeps the UID derived from its first key, so it is found by the key that it uses now.
+	registered, err := s.store.DeviceGetByPublicKey(ctx, req.TenantID, req.PublicKey)
+	switch {
+	case err == nil:
+		if registered.IsKeyRevoked(req.PublicKey) {
+			return nil, NewErrDeviceKeyRevoked(nil)
+		}
+
+		key = registered.UID
+	case err != store.ErrNoDocuments:
+		return nil, NewErrDeviceNotFound(models.UID(key), err)
+	}
+
+	tokenStr, err := s.keys.Sign(models.DeviceAuthClaims{
 		UID: key,
 		AuthClaims: models.AuthClaims{
 			Claims: "device",
 		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(DeviceTokenExpiry)),
+		},
 	})
-
-	tokenStr, err := token.SignedString(s.privKey)
 	if err != nil {
 		return nil, NewErrTokenSigned(err)
 	}
@@ -94,8 +227,9 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 		Info:       info,
 		PublicKey:  req.PublicKey,
 		TenantID:   req.TenantID,
-		LastSeen:   clock.Now(),
+		LastSeen:   now,
 		RemoteAddr: remoteAddr,
+		Labels:     req.Labels,
 	}
 
 	// The order here is critical as we don't want to register devices if the tenant id is invalid
@@ -106,10 +240,34 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 
 	hostname := strings.ToLower(req.Hostname)
 
+	// The enrollment token is only used by new devices, so the registered ones keep authenticating with it configured.
+	var enrollment *models.EnrollmentToken
+	if req.EnrollmentToken != "" || (namespace.Settings != nil && namespace.Settings.RequireEnrollmentToken) {
+		if _, err := s.store.DeviceGetByUID(ctx, models.UID(device.UID), device.TenantID); err != nil {
+			if err != store.ErrNoDocuments {
+				return nil, NewErrDeviceNotFound(models.UID(device.UID), err)
+			}
+
+			if enrollment, err = s.useEnrollmentToken(ctx, namespace, req.EnrollmentToken); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if err := s.store.DeviceCreate(ctx, device, hostname); err != nil {
 		return nil, NewErrDeviceCreate(device, err)
 	}
 
+	switch {
+	case enrollment != nil:
+		device.Tags = req.Tags
+		s.enrollDevice(ctx, enrollment, &device, hostname)
+	case registered == nil:
+		s.setAgentTags(ctx, &device, req.Tags)
+	case namespace.Settings != nil && namespace.Settings.AgentTagsOverwrite:
+		s.setAgentTags(ctx, registered, req.Tags)
+	}
+
 	if err := s.store.DeviceSetOnline(ctx, models.UID(device.UID), true); err != nil {
 		return nil, NewErrDeviceSetOnline(models.UID(device.UID), err)
 	}
@@ -136,19 +294,45 @@ func (s *service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remot
 	}, nil
 }
 
-func (s *service) AuthUser(ctx context.Context, req requests.UserAuth) (*models.UserAuthResponse, error) {
-	var user *models.User
+func (s *service) AuthUser(ctx context.Context, req requests.UserAuth, sourceIP string) (*models.UserAuthResponse, error) {
+	// The attempts are tracked by the user, so its username and e-mail share the same failures, and the unknown logins
+	// are tracked only by the source IP, as any login string would be a new account to the attacker.
+	var account string
+	user, err := s.userByLogin(ctx, req.Username)
+	if err == nil {
+		account = user.ID
+	} else {
+		user = nil
+	}
 
-	userFromUsername, errUsername := s.store.UserGetByUsername(ctx, strings.ToLower(req.Username))
-	userFromEmail, errEmail := s.store.UserGetByEmail(ctx, strings.ToLower(req.Username))
+	if err := s.checkAttempts(ctx, account, sourceIP); err != nil {
+		return nil, err
+	}
 
+	res, err := s.authUser(ctx, req, user)
 	switch {
-	case errUsername != nil && errEmail != nil:
+	case err == nil:
+		s.resetAttempts(ctx, account)
+	case errors.Is(err, ErrAuthUnathorized):
+		s.failAttempt(ctx, account, sourceIP)
+	}
+
+	return res, err
+}
+
+// authUser checks the user's credentials at the LDAP directory, when configured, and at the local user resolved from
+// the login, if any.
+func (s *service) authUser(ctx context.Context, req requests.UserAuth, user *models.User) (*models.UserAuthResponse, error) {
+	// The directory's users are authenticated first; the local users, like the administrator, are kept as a fallback
+	// for when the directory does not know the user or is unreachable.
+	if s.ldap != nil {
+		if res, ok, err := s.authLDAP(ctx, req); ok {
+			return res, err
+		}
+	}
+
+	if user == nil {
 		return nil, NewErrAuthUnathorized(nil)
-	case errUsername == nil:
-		user = userFromUsername
-	case errEmail == nil:
-		user = userFromEmail
 	}
 
 	if !user.Confirmed {
@@ -157,62 +341,74 @@ func (s *service) AuthUser(ctx context.Context, req requests.UserAuth) (*models.
 
 	namespace, _ := s.store.NamespaceGetFirst(ctx, user.ID)
 
-	var role string
-	var tenant string
+	if password.Compare(req.Password, user.Password) {
+		// Passwords hashed with an outdated algorithm, or parameters, are upgraded while the plain password is known.
+		if password.NeedsRehash(user.Password) {
+			if hash, err := password.Hash(req.Password); err == nil {
+				if err := s.store.UserUpdatePassword(ctx, hash, user.ID); err != nil {
+					logrus.WithError(err).WithFields(logrus.Fields{
+						"id": user.ID,
+					}).Warn("failed to upgrade the user's password hash")
+				}
+			}
+		}
+
+		if requiresMFA(user, namespace) {
+			return s.authMFAToken(user)
+		}
+
+		return s.authUserToken(ctx, user, namespace)
+	}
 
+	return nil, NewErrAuthUnathorized(nil)
+}
+
+// authUserToken issues the user's token to the namespace, completing the login with a new session.
+func (s *service) authUserToken(ctx context.Context, user *models.User, namespace *models.Namespace) (*models.UserAuthResponse, error) {
+	var tenant string
 	if namespace != nil {
 		tenant = namespace.TenantID
+	}
 
-		for _, member := range namespace.Members {
-			if member.ID == user.ID {
-				role = member.Role
+	role, _ := memberRole(namespace, user.ID)
 
-				break
-			}
-		}
+	session, refreshToken, err := s.createUserSession(ctx, user)
+	if err != nil {
+		return nil, err
 	}
 
-	password := sha256.Sum256([]byte(req.Password))
-	if user.Password == hex.EncodeToString(password[:]) {
-		token := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserAuthClaims{
-			Username: user.Username,
-			Admin:    true,
-			Tenant:   tenant,
-			Role:     role,
-			ID:       user.ID,
-			AuthClaims: models.AuthClaims{
-				Claims: "user",
-			},
-			RegisteredClaims: jwt.RegisteredClaims{
-				ExpiresAt: jwt.NewNumericDate(clock.Now().Add(time.Hour * 72)),
-			},
-		})
-
-		tokenStr, err := token.SignedString(s.privKey)
-		if err != nil {
-			return nil, NewErrTokenSigned(err)
-		}
+	tokenStr, err := s.signUserToken(user, tenant, role, session.ID)
+	if err != nil {
+		return nil, err
+	}
 
-		user.LastLogin = clock.Now()
+	user.LastLogin = clock.Now()
 
-		if err := s.store.UserUpdateData(ctx, user.ID, *user); err != nil {
-			return nil, NewErrUserUpdate(user, err)
-		}
+	if err := s.store.UserUpdateData(ctx, user.ID, *user); err != nil {
+		return nil, NewErrUserUpdate(user, err)
+	}
 
-		s.AuthCacheToken(ctx, tenant, user.ID, tokenStr) // nolint: errcheck
+	session.CreatedAt = user.LastLogin
+	session.LastUsedAt = user.LastLogin
+	session.ExpiresAt = user.LastLogin.Add(UserSessionExpiry)
 
-		return &models.UserAuthResponse{
-			Token:  tokenStr,
-			Name:   user.Name,
-			ID:     user.ID,
-			User:   user.Username,
-			Tenant: tenant,
-			Role:   role,
-			Email:  user.Email,
-		}, nil
+	if err := s.store.UserSessionCreate(ctx, session); err != nil {
+		return nil, err
 	}
 
-	return nil, NewErrAuthUnathorized(nil)
+	s.cacheUserSession(ctx, session.ID)
+	s.AuthCacheToken(ctx, tenant, user.ID, tokenStr) // nolint: errcheck
+
+	return &models.UserAuthResponse{
+		Token:        tokenStr,
+		RefreshToken: refreshToken,
+		Name:         user.Name,
+		ID:           user.ID,
+		User:         user.Username,
+		Tenant:       tenant,
+		Role:         role,
+		Email:        user.Email,
+	}, nil
 }
 
 func (s *service) AuthGetToken(ctx context.Context, id string) (*models.UserAuthResponse, error) {
@@ -227,49 +423,53 @@ func (s *service) AuthGetToken(ctx context.Context, id string) (*models.UserAuth
 	var tenant string
 	if namespace != nil {
 		tenant = namespace.TenantID
+		role, _ = memberRole(namespace, user.ID)
+	}
 
-		for _, member := range namespace.Members {
-			if member.ID == user.ID {
-				role = member.Role
+	// The token is bound to a session, like the ones of a login, so it is revoked with the user's sessions and expires
+	// with the other user tokens.
+	session, refreshToken, err := s.createUserSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
 
-				break
-			}
-		}
+	tokenStr, err := s.signUserToken(user, tenant, role, session.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserAuthClaims{
-		Username: user.Username,
-		Admin:    true,
-		Tenant:   tenant,
-		Role:     role,
-		ID:       user.ID,
-		AuthClaims: models.AuthClaims{
-			Claims: "user",
-		},
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(clock.Now().Add(time.Hour * 72)),
-		},
-	})
+	now := clock.Now()
+	session.CreatedAt = now
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(UserSessionExpiry)
 
-	tokenStr, err := token.SignedString(s.privKey)
-	if err != nil {
-		return nil, NewErrTokenSigned(err)
+	if err := s.store.UserSessionCreate(ctx, session); err != nil {
+		return nil, err
 	}
 
+	s.cacheUserSession(ctx, session.ID)
+
 	return &models.UserAuthResponse{
-		Token:  tokenStr,
-		Name:   user.Name,
-		ID:     user.ID,
-		User:   user.Username,
-		Tenant: tenant,
-		Role:   role,
-		Email:  user.Email,
+		Token:        tokenStr,
+		RefreshToken: refreshToken,
+		Name:         user.Name,
+		ID:           user.ID,
+		User:         user.Username,
+		Tenant:       tenant,
+		Role:         role,
+		Email:        user.Email,
 	}, nil
 }
 
-func (s *service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth) (*models.PublicKeyAuthResponse, error) {
+func (s *service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth, sourceIP string) (*models.PublicKeyAuthResponse, error) {
+	if err := s.checkAttempts(ctx, req.Fingerprint, sourceIP); err != nil {
+		return nil, err
+	}
+
 	privKey, err := s.store.PrivateKeyGet(ctx, req.Fingerprint)
 	if err != nil {
+		s.failAttempt(ctx, req.Fingerprint, sourceIP)
+
 		return nil, NewErrPublicKeyNotFound(req.Fingerprint, err)
 	}
 
@@ -294,7 +494,7 @@ func (s *service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth)
 	}, nil
 }
 
-func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models.UserAuthResponse, error) {
+func (s *service) AuthSwapToken(ctx context.Context, id, session, tenant string) (*models.UserAuthResponse, error) {
 	namespace, err := s.store.NamespaceGet(ctx, tenant)
 	if err != nil {
 		return nil, NewErrNamespaceNotFound(tenant, err)
@@ -305,51 +505,32 @@ func (s *service) AuthSwapToken(ctx context.Context, id, tenant string) (*models
 		return nil, NewErrUserNotFound(id, err)
 	}
 
-	var role string
-	for _, member := range namespace.Members {
-		if member.ID == user.ID {
-			role = member.Role
-
-			break
-		}
+	// A user who has not enabled MFA was not asked for a second factor, so cannot access a namespace that requires it.
+	if namespace.Settings != nil && namespace.Settings.RequireMFA && !user.MFA.Enabled {
+		return nil, NewErrMFARequired(nil)
 	}
 
-	for _, member := range namespace.Members {
-		if user.ID == member.ID {
-			token := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserAuthClaims{
-				Username: user.Username,
-				Admin:    true,
-				Tenant:   namespace.TenantID,
-				Role:     role,
-				ID:       user.ID,
-				AuthClaims: models.AuthClaims{
-					Claims: "user",
-				},
-				RegisteredClaims: jwt.RegisteredClaims{
-					ExpiresAt: jwt.NewNumericDate(clock.Now().Add(time.Hour * 72)),
-				},
-			})
-
-			tokenStr, err := token.SignedString(s.privKey)
-			if err != nil {
-				return nil, NewErrTokenSigned(err)
-			}
+	role, ok := memberRole(namespace, user.ID)
+	if !ok {
+		return nil, nil
+	}
 
-			s.AuthCacheToken(ctx, tenant, user.ID, tokenStr) // nolint: errcheck
-
-			return &models.UserAuthResponse{
-				Token:  tokenStr,
-				Name:   user.Name,
-				ID:     user.ID,
-				User:   user.Username,
-				Role:   role,
-				Tenant: namespace.TenantID,
-				Email:  user.Email,
-			}, nil
-		}
+	tokenStr, err := s.signUserToken(user, namespace.TenantID, role, session)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	s.AuthCacheToken(ctx, tenant, user.ID, tokenStr) // nolint: errcheck
+
+	return &models.UserAuthResponse{
+		Token:  tokenStr,
+		Name:   user.Name,
+		ID:     user.ID,
+		User:   user.Username,
+		Role:   role,
+		Tenant: namespace.TenantID,
+		Email:  user.Email,
+	}, nil
 }
 
 func (s *service) AuthUserInfo(ctx context.Context, username, tenant, token string) (*models.UserAuthResponse, error) {
@@ -384,8 +565,8 @@ func (s *service) AuthUserInfo(ctx context.Context, username, tenant, token stri
 	}, nil
 }
 
-func (s *service) PublicKey() *rsa.PublicKey {
-	return s.pubKey
+func (s *service) Keyring() *keyring.Keyring {
+	return s.keys
 }
 
 // AuthCacheToken caches the user's namespace token.
@@ -2,15 +2,21 @@ package services
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/cnf/structhash"
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
@@ -18,20 +24,44 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
 	"github.com/undefinedlabs/go-mpatch"
 )
 
+// signChallenge gets a challenge from the service and signs it with the device's key, as the agent does.
+func signChallenge(t *testing.T, service Service, key *rsa.PrivateKey) (string, string) {
+	t.Helper()
+
+	challenge, err := service.AuthDeviceChallenge(context.TODO())
+	assert.NoError(t, err)
+
+	digest := sha256.Sum256([]byte(challenge.Nonce))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+
+	return challenge.Nonce, base64.StdEncoding.EncodeToString(signature)
+}
+
+// encodeDeviceKey encodes the device's public key as the agent does.
+func encodeDeviceKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)}))
+}
+
 func TestAuthDevice(t *testing.T) {
 	mock := new(mocks.Store)
 
 	ctx := context.TODO()
 
+	deviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
 	authReq := requests.DeviceAuth{
 		TenantID: "tenant",
 		Identity: &requests.DeviceIdentity{
 			MAC: "mac",
 		},
-		Sessions: []string{"session"},
+		PublicKey: encodeDeviceKey(deviceKey),
+		Sessions:  []string{"session"},
 	}
 
 	auth := models.DeviceAuth{
@@ -48,6 +78,7 @@ func TestAuthDevice(t *testing.T) {
 		Identity: &models.DeviceIdentity{
 			MAC: authReq.Identity.MAC,
 		},
+		PublicKey:  authReq.PublicKey,
 		TenantID:   authReq.TenantID,
 		LastSeen:   now,
 		RemoteAddr: "0.0.0.0",
@@ -56,6 +87,8 @@ func TestAuthDevice(t *testing.T) {
 	clockMock.On("Now").Return(now).Twice()
 	namespace := &models.Namespace{Name: "group1", Owner: "hash1", TenantID: "tenant"}
 
+	mock.On("DeviceGetByPublicKey", ctx, authReq.TenantID, authReq.PublicKey).
+		Return(nil, store.ErrNoDocuments).Once()
 	mock.On("DeviceCreate", ctx, *device, "").
 		Return(nil).Once()
 	mock.On("DeviceSetOnline", ctx, models.UID(device.UID), true).
@@ -75,7 +108,9 @@ func TestAuthDevice(t *testing.T) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	assert.NoError(t, err)
 
-	service := NewService(store.Store(mock), privateKey, &privateKey.PublicKey, storecache.NewNullCache(), clientMock, nil)
+	service := NewService(store.Store(mock), privateKey, &privateKey.PublicKey, newMemoryCache(), clientMock, nil)
+
+	authReq.Challenge, authReq.Signature = signChallenge(t, service, deviceKey)
 
 	authRes, err := service.AuthDevice(ctx, authReq, "0.0.0.0")
 	assert.NoError(t, err)
@@ -86,9 +121,78 @@ func TestAuthDevice(t *testing.T) {
 	assert.NotEmpty(t, authRes.Token)
 	assert.Equal(t, device.RemoteAddr, "0.0.0.0")
 
+	// The token expires and must be renewed by the device.
+	claims := new(models.DeviceAuthClaims)
+	_, err = jwt.ParseWithClaims(authRes.Token, claims, service.Keyring().Keyfunc)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(DeviceTokenExpiry).Unix(), claims.ExpiresAt.Unix())
+
 	mock.AssertExpectations(t)
 }
 
+func TestAuthDeviceChallenge(t *testing.T) {
+	ctx := context.TODO()
+
+	deviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	service := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+	req := requests.DeviceAuth{TenantID: "tenant", Hostname: "device", PublicKey: encodeDeviceKey(deviceKey)}
+
+	t.Run("fails when the challenge was not issued", func(t *testing.T) {
+		r := req
+		_, r.Signature = signChallenge(t, service, deviceKey)
+		r.Challenge = "unknown"
+
+		_, err := service.AuthDevice(ctx, r, "0.0.0.0")
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+	})
+
+	t.Run("fails when the challenge is signed by another key", func(t *testing.T) {
+		r := req
+		r.Challenge, r.Signature = signChallenge(t, service, otherKey)
+
+		_, err := service.AuthDevice(ctx, r, "0.0.0.0")
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+	})
+
+	t.Run("fails when the challenge is used again", func(t *testing.T) {
+		r := req
+		r.Challenge, r.Signature = signChallenge(t, service, otherKey)
+
+		_, err := service.AuthDevice(ctx, r, "0.0.0.0")
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+		// The signature is right, but the challenge was consumed by the previous attempt.
+		r.PublicKey = encodeDeviceKey(otherKey)
+
+		_, err = service.AuthDevice(ctx, r, "0.0.0.0")
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+	})
+
+	t.Run("fails without the challenge when the legacy device auth is disabled", func(t *testing.T) {
+		_, err := service.AuthDevice(ctx, req, "0.0.0.0")
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+	})
+
+	legacy := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithLegacyDeviceAuth())
+
+	t.Run("succeeds without the challenge when the legacy device auth is enabled", func(t *testing.T) {
+		assert.NoError(t, legacy.verifyDeviceChallenge(ctx, req))
+	})
+
+	t.Run("fails when the challenge is wrong and the legacy device auth is enabled", func(t *testing.T) {
+		r := req
+		r.Challenge, r.Signature = signChallenge(t, legacy, otherKey)
+
+		assert.ErrorIs(t, legacy.verifyDeviceChallenge(ctx, r), ErrAuthUnathorized)
+	})
+}
+
 func TestAuthUser(t *testing.T) {
 	mock := new(mocks.Store)
 
@@ -114,7 +218,7 @@ func TestAuthUser(t *testing.T) {
 			},
 			expectedErr: errors.New("error", "", 0),
 			requiredMocks: func() {
-				mock.On("UserGetByUsername", ctx, "user").Return(nil, errors.New("error", "", 0))
+				mock.On("UserGetByUsername", ctx, "user").Return(nil, errors.New("error", "", 0)).Once()
 				mock.On("UserGetByEmail", ctx, "user").Return(nil, errors.New("error", "", 0)).Once()
 			},
 			expected: Expected{nil, NewErrAuthUnathorized(nil)},
@@ -144,12 +248,153 @@ func TestAuthUser(t *testing.T) {
 					TenantID: "tenant",
 				}
 
-				mock.On("UserGetByUsername", ctx, "user").Return(user, nil)
-				mock.On("UserGetByEmail", ctx, "user").Return(user, nil).Once()
+				mock.On("UserGetByUsername", ctx, "user").Return(user, nil).Once()
 				mock.On("NamespaceGetFirst", ctx, user.ID).Return(namespace, nil).Once()
+				// The failed attempt is recorded.
+				clockMock.On("Now").Return(now).Once()
 			},
 			expected: Expected{nil, NewErrAuthUnathorized(nil)},
 		},
+		{
+			description: "Successful auth login when password is hashed with bcrypt",
+			req: requests.UserAuth{
+				Username: "admin",
+				Password: "passwd",
+			},
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Username: "admin",
+					},
+					UserPassword: models.UserPassword{
+						Password: mustHash("passwd"),
+					},
+					ID:        "id",
+					Confirmed: true,
+				}
+
+				namespace := &models.Namespace{
+					TenantID: "tenant",
+					Members:  []models.Member{{ID: "id", Role: guard.RoleOwner}},
+				}
+
+				mock.On("UserGetByUsername", ctx, "admin").Return(user, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				clockMock.On("Now").Return(now).Twice()
+
+				updated := *user
+				updated.LastLogin = now
+				mock.On("UserUpdateData", ctx, "id", updated).Return(nil).Once()
+				mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:     "id",
+				User:   "admin",
+				Tenant: "tenant",
+				Role:   guard.RoleOwner,
+			}, nil},
+		},
+		{
+			description: "Successful auth login and upgrade when password is hashed with SHA256",
+			req: requests.UserAuth{
+				Username: "legacy",
+				Password: "passwd",
+			},
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Username: "legacy",
+					},
+					UserPassword: models.UserPassword{
+						Password: fmt.Sprintf("%x", sha256.Sum256([]byte("passwd"))),
+					},
+					ID:        "id",
+					Confirmed: true,
+				}
+
+				namespace := &models.Namespace{
+					TenantID: "tenant",
+					Members:  []models.Member{{ID: "id", Role: guard.RoleOwner}},
+				}
+
+				mock.On("UserGetByUsername", ctx, "legacy").Return(user, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("passwd"), "id").Return(nil).Once()
+				clockMock.On("Now").Return(now).Twice()
+
+				updated := *user
+				updated.LastLogin = now
+				mock.On("UserUpdateData", ctx, "id", updated).Return(nil).Once()
+				mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:     "id",
+				User:   "legacy",
+				Tenant: "tenant",
+				Role:   guard.RoleOwner,
+			}, nil},
+		},
+		{
+			description: "Successful password verification returns a MFA token when user has MFA enabled",
+			req: requests.UserAuth{
+				Username: "mfa",
+				Password: "passwd",
+			},
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Username: "mfa",
+					},
+					UserPassword: models.UserPassword{
+						Password: mustHash("passwd"),
+					},
+					ID:        "id",
+					Confirmed: true,
+					MFA:       models.UserMFA{Enabled: true, Secret: "secret"},
+				}
+
+				mock.On("UserGetByUsername", ctx, "mfa").Return(user, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:   "id",
+				User: "mfa",
+			}, nil},
+		},
+		{
+			description: "Successful password verification requires MFA enrollment when namespace requires MFA",
+			req: requests.UserAuth{
+				Username: "enroll",
+				Password: "passwd",
+			},
+			requiredMocks: func() {
+				user := &models.User{
+					UserData: models.UserData{
+						Username: "enroll",
+					},
+					UserPassword: models.UserPassword{
+						Password: mustHash("passwd"),
+					},
+					ID:        "id",
+					Confirmed: true,
+				}
+
+				namespace := &models.Namespace{
+					TenantID: "tenant",
+					Settings: &models.NamespaceSettings{RequireMFA: true},
+				}
+
+				mock.On("UserGetByUsername", ctx, "enroll").Return(user, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:            "id",
+				User:          "enroll",
+				MFAEnrollment: true,
+			}, nil},
+		},
 	}
 
 	for _, tc := range tests {
@@ -160,7 +405,160 @@ func TestAuthUser(t *testing.T) {
 			assert.NoError(t, err)
 
 			service := NewService(store.Store(mock), privateKey, &privateKey.PublicKey, storecache.NewNullCache(), clientMock, nil)
-			authRes, err := service.AuthUser(ctx, tc.req)
+			authRes, err := service.AuthUser(ctx, tc.req, "")
+
+			// The token is signed by a key generated on each case, so it is only checked to be present.
+			switch {
+			case authRes != nil && authRes.MFAToken != "":
+				assert.Empty(t, authRes.Token)
+				authRes.MFAToken = ""
+			case authRes != nil:
+				assert.NotEmpty(t, authRes.Token)
+				assert.NotEmpty(t, authRes.RefreshToken)
+				authRes.Token = ""
+				authRes.RefreshToken = ""
+			}
+
+			assert.Equal(t, tc.expected, Expected{authRes, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthSwapToken(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members:  []models.Member{{ID: "id", Role: guard.RoleObserver}},
+		Settings: &models.NamespaceSettings{RequireMFA: true},
+	}
+
+	type Expected struct {
+		userAuthResponse *models.UserAuthResponse
+		err              error
+	}
+
+	tests := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "Fails when namespace requires MFA and user has not enabled it",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id"}, 0, nil).Once()
+			},
+			expected: Expected{nil, NewErrMFARequired(nil)},
+		},
+		{
+			description: "Successful swap when user has MFA enabled",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: models.UserMFA{Enabled: true}}, 0, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:     "id",
+				Tenant: "tenant",
+				Role:   guard.RoleObserver,
+			}, nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			authRes, err := service.AuthSwapToken(ctx, "id", "session", "tenant")
+			if authRes != nil {
+				assert.NotEmpty(t, authRes.Token)
+				authRes.Token = ""
+			}
+
+			assert.Equal(t, tc.expected, Expected{authRes, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthGetToken(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	user := &models.User{ID: "id", UserData: models.UserData{Username: "admin"}}
+
+	type Expected struct {
+		userAuthResponse *models.UserAuthResponse
+		err              error
+	}
+
+	tests := []struct {
+		description   string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "Fails when the user does not exist",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(nil, 0, errors.New("error", "", 0)).Once()
+			},
+			expected: Expected{nil, NewErrUserNotFound("id", errors.New("error", "", 0))},
+		},
+		{
+			description: "Fails when the session cannot be created",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(nil, errors.New("error", "", 0)).Once()
+				clockMock.On("Now").Return(now).Twice()
+				mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(errors.New("error", "", 0)).Once()
+			},
+			expected: Expected{nil, errors.New("error", "", 0)},
+		},
+		{
+			description: "Successful token bound to a new session",
+			requiredMocks: func() {
+				namespace := &models.Namespace{
+					TenantID: "tenant",
+					Members:  []models.Member{{ID: "id", Role: guard.RoleOwner}},
+				}
+
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				clockMock.On("Now").Return(now).Twice()
+				mock.On("UserSessionCreate", ctx, testifymock.MatchedBy(func(session *models.UserSession) bool {
+					return session.UserID == "id" && session.ExpiresAt.Equal(now.Add(UserSessionExpiry))
+				})).Return(nil).Once()
+			},
+			expected: Expected{&models.UserAuthResponse{
+				ID:     "id",
+				User:   "admin",
+				Tenant: "tenant",
+				Role:   guard.RoleOwner,
+			}, nil},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			authRes, err := service.AuthGetToken(ctx, "id")
+			if authRes != nil {
+				assert.NotEmpty(t, authRes.Token)
+				assert.NotEmpty(t, authRes.RefreshToken)
+				authRes.Token = ""
+				authRes.RefreshToken = ""
+			}
+
 			assert.Equal(t, tc.expected, Expected{authRes, err})
 		})
 	}
@@ -255,3 +653,99 @@ func TestAuthUserInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthDeviceAgentTags(t *testing.T) {
+	ctx := context.TODO()
+
+	deviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	req := requests.DeviceAuth{
+		TenantID:  "tenant",
+		Hostname:  "sensor",
+		Identity:  &requests.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey: encodeDeviceKey(deviceKey),
+		Tags:      []string{"factory", "lab"},
+		Labels:    map[string]string{"site": "lab"},
+	}
+
+	sum := sha256.Sum256(structhash.Dump(models.DeviceAuth{
+		Hostname:  req.Hostname,
+		Identity:  &models.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey: req.PublicKey,
+		TenantID:  req.TenantID,
+	}, 1))
+	uid := hex.EncodeToString(sum[:])
+
+	device := models.Device{
+		UID:        uid,
+		Identity:   &models.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey:  req.PublicKey,
+		TenantID:   "tenant",
+		LastSeen:   now,
+		RemoteAddr: "0.0.0.0",
+		Labels:     map[string]string{"site": "lab"},
+	}
+
+	cases := []struct {
+		description   string
+		registered    *models.Device
+		namespace     *models.Namespace
+		requiredMocks func(mock *mocks.Store)
+	}{
+		{
+			description: "sets the agent's tags when the device registers",
+			namespace:   &models.Namespace{TenantID: "tenant"},
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceUpdateTag", ctx, models.UID(uid), []string{"factory", "lab"}).Return(nil).Once()
+			},
+		},
+		{
+			description:   "keeps the device's tags when the namespace does not let the agent overwrite them",
+			registered:    &models.Device{UID: uid, TenantID: "tenant", Tags: []string{"api"}},
+			namespace:     &models.Namespace{TenantID: "tenant"},
+			requiredMocks: func(mock *mocks.Store) {},
+		},
+		{
+			description: "overwrites the device's tags when the namespace lets the agent overwrite them",
+			registered:  &models.Device{UID: uid, TenantID: "tenant", Tags: []string{"api"}},
+			namespace:   &models.Namespace{TenantID: "tenant", Settings: &models.NamespaceSettings{AgentTagsOverwrite: true}},
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceUpdateTag", ctx, models.UID(uid), []string{"factory", "lab"}).Return(nil).Once()
+			},
+		},
+		{
+			description:   "does not update the device's tags when they are the agent's ones",
+			registered:    &models.Device{UID: uid, TenantID: "tenant", Tags: []string{"lab", "factory"}},
+			namespace:     &models.Namespace{TenantID: "tenant", Settings: &models.NamespaceSettings{AgentTagsOverwrite: true}},
+			requiredMocks: func(mock *mocks.Store) {},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			mock := new(mocks.Store)
+			service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+			clockMock.On("Now").Return(now).Once()
+			if tc.registered != nil {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", req.PublicKey).Return(tc.registered, nil).Once()
+			} else {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", req.PublicKey).Return(nil, store.ErrNoDocuments).Once()
+			}
+			mock.On("NamespaceGet", ctx, "tenant").Return(tc.namespace, nil).Once()
+			mock.On("DeviceCreate", ctx, device, "sensor").Return(nil).Once()
+			tc.requiredMocks(mock)
+			mock.On("DeviceSetOnline", ctx, models.UID(uid), true).Return(nil).Once()
+			mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(&models.Device{UID: uid, Name: "sensor"}, nil).Once()
+
+			r := req
+			r.Challenge, r.Signature = signChallenge(t, service, deviceKey)
+
+			_, err := service.AuthDevice(ctx, r, "0.0.0.0")
+			assert.NoError(t, err)
+
+			mock.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	req "github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// DeviceRekeyTokenExpiry is the time the device has to replace its key with the re-key token.
+const DeviceRekeyTokenExpiry = 24 * time.Hour
+
+type DeviceKeyService interface {
+	// RotateDeviceKey replaces the device's key by a new one. The current key signs the challenge followed by the new
+	// key, and the new key signs the challenge, proving that the device holds both. The device keeps its UID, and so its
+	// sessions and tags, while the old key cannot authenticate it anymore.
+	RotateDeviceKey(ctx context.Context, req requests.DeviceRotateKey) error
+	// RevokeDeviceKey revokes the device's current key, so it cannot authenticate with it anymore. The tokens already
+	// issued to the device are rejected, and its tunnel is dropped, marking it offline.
+	RevokeDeviceKey(ctx context.Context, tenantID string, uid models.UID) error
+	// AuthIsDeviceTokenActive checks if the device's token, issued at issuedAt, was not issued before the last
+	// revocation of the device's key.
+	AuthIsDeviceTokenActive(ctx context.Context, uid string, issuedAt time.Time) (bool, error)
+	// CreateDeviceRekeyToken issues a one-time token, tied to the device, which authorizes it to replace its key, even
+	// when it was revoked.
+	CreateDeviceRekeyToken(ctx context.Context, tenantID string, uid models.UID) (*models.DeviceRekeyToken, error)
+	// RekeyDevice replaces the device's key by a new one, authorized by the re-key token issued to the device. The new
+	// key signs the challenge, proving that the device holds it. The device keeps its UID, and so its sessions and tags.
+	RekeyDevice(ctx context.Context, req requests.DeviceRekey) error
+}
+
+// deviceRekey is the device that a re-key token was issued to.
+type deviceRekey struct {
+	UID      string `json:"uid"`
+	TenantID string `json:"tenant_id"`
+}
+
+func (s *service) RotateDeviceKey(ctx context.Context, req requests.DeviceRotateKey) error {
+	if err := s.consumeDeviceChallenge(ctx, req.Challenge); err != nil {
+		return err
+	}
+
+	if err := verifyDeviceSignature(req.PublicKey, req.Challenge+req.NewPublicKey, req.Signature); err != nil {
+		return err
+	}
+
+	if err := verifyDeviceSignature(req.NewPublicKey, req.Challenge, req.NewSignature); err != nil {
+		return err
+	}
+
+	device, err := s.store.DeviceGetByPublicKey(ctx, req.TenantID, req.PublicKey)
+	if err != nil {
+		return NewErrAuthUnathorized(err)
+	}
+
+	if device.IsKeyRevoked(req.PublicKey) {
+		return NewErrDeviceKeyRevoked(nil)
+	}
+
+	switch _, err := s.store.DeviceGetByPublicKey(ctx, req.TenantID, req.NewPublicKey); {
+	case err == nil:
+		return NewErrDeviceKeyDuplicated(nil)
+	case err != store.ErrNoDocuments:
+		return err
+	}
+
+	if err := s.store.DeviceRotateKey(ctx, models.UID(device.UID), req.PublicKey, req.NewPublicKey); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrDeviceKeyRevoked(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) RevokeDeviceKey(ctx context.Context, tenantID string, uid models.UID) error {
+	device, err := s.store.DeviceGetByUID(ctx, uid, tenantID)
+	if err != nil {
+		return NewErrDeviceNotFound(uid, err)
+	}
+
+	if err := s.store.DeviceRevokeKey(ctx, uid, device.PublicKey); err != nil {
+		return NewErrDeviceNotFound(uid, err)
+	}
+
+	// The tokens issued until now are rejected for as long as they could be valid, so the device cannot open its
+	// tunnel again until it authenticates with another key.
+	if err := s.cache.Set(ctx, "device_revoked/"+string(uid), clock.Now().Unix(), DeviceTokenExpiry); err != nil {
+		return err
+	}
+
+	if err := s.client.(req.Client).DisconnectDevice(string(uid)); err != nil {
+		return err
+	}
+
+	if err := s.store.DeviceSetOnline(ctx, uid, false); err != nil {
+		return NewErrDeviceSetOnline(uid, err)
+	}
+
+	return nil
+}
+
+func (s *service) AuthIsDeviceTokenActive(ctx context.Context, uid string, issuedAt time.Time) (bool, error) {
+	var revoked int64
+	if err := s.cache.Get(ctx, "device_revoked/"+uid, &revoked); err != nil {
+		return false, err
+	}
+
+	// The tokens' issue time has a precision of seconds, so the ones issued in the second of the revocation are also
+	// rejected.
+	return revoked == 0 || issuedAt.Unix() > revoked, nil
+}
+
+func (s *service) CreateDeviceRekeyToken(ctx context.Context, tenantID string, uid models.UID) (*models.DeviceRekeyToken, error) {
+	if _, err := s.store.DeviceGetByUID(ctx, uid, tenantID); err != nil {
+		return nil, NewErrDeviceNotFound(uid, err)
+	}
+
+	token, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Set(ctx, strings.Join([]string{"device_rekey", token}, "/"), &deviceRekey{UID: string(uid), TenantID: tenantID}, DeviceRekeyTokenExpiry); err != nil {
+		return nil, err
+	}
+
+	return &models.DeviceRekeyToken{Token: token, ExpiresAt: clock.Now().Add(DeviceRekeyTokenExpiry)}, nil
+}
+
+func (s *service) RekeyDevice(ctx context.Context, req requests.DeviceRekey) error {
+	if err := s.consumeDeviceChallenge(ctx, req.Challenge); err != nil {
+		return err
+	}
+
+	if err := verifyDeviceSignature(req.NewPublicKey, req.Challenge, req.NewSignature); err != nil {
+		return err
+	}
+
+	// The token is removed once it is checked, to be used only once.
+	key := strings.Join([]string{"device_rekey", req.Token}, "/")
+
+	var rekey *deviceRekey
+	if err := s.cache.Get(ctx, key, &rekey); err != nil || rekey == nil || rekey.TenantID != req.TenantID {
+		return NewErrAuthUnathorized(err)
+	}
+
+	if err := s.cache.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	device, err := s.store.DeviceGetByUID(ctx, models.UID(rekey.UID), rekey.TenantID)
+	if err != nil {
+		return NewErrDeviceNotFound(models.UID(rekey.UID), err)
+	}
+
+	switch _, err := s.store.DeviceGetByPublicKey(ctx, req.TenantID, req.NewPublicKey); {
+	case err == nil:
+		return NewErrDeviceKeyDuplicated(nil)
+	case err != store.ErrNoDocuments:
+		return err
+	}
+
+	if err := s.store.DeviceRekey(ctx, models.UID(device.UID), device.PublicKey, req.NewPublicKey); err != nil {
+		return NewErrDeviceNotFound(models.UID(device.UID), err)
+	}
+
+	return nil
+}
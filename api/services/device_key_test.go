@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/errors"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// signMessage signs the message with the device's key as the agent does.
+func signMessage(t *testing.T, key *rsa.PrivateKey, message string) string {
+	t.Helper()
+
+	digest := sha256.Sum256([]byte(message))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(signature)
+}
+
+func TestRotateDeviceKey(t *testing.T) {
+	ctx := context.TODO()
+
+	currentKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	current := encodeDeviceKey(currentKey)
+	next := encodeDeviceKey(newKey)
+
+	// rotation builds a request signed by the given keys to a challenge issued by the service.
+	rotation := func(service Service, signer *rsa.PrivateKey) requests.DeviceRotateKey {
+		challenge, err := service.AuthDeviceChallenge(ctx)
+		assert.NoError(t, err)
+
+		return requests.DeviceRotateKey{
+			TenantID:     "tenant",
+			PublicKey:    current,
+			Challenge:    challenge.Nonce,
+			Signature:    signMessage(t, signer, challenge.Nonce+next),
+			NewPublicKey: next,
+			NewSignature: signMessage(t, newKey, challenge.Nonce),
+		}
+	}
+
+	cases := []struct {
+		description   string
+		signer        *rsa.PrivateKey
+		requiredMocks func(mock *mocks.Store)
+		expected      error
+	}{
+		{
+			description:   "fails when the new key is not signed by the current key",
+			signer:        newKey,
+			requiredMocks: func(mock *mocks.Store) {},
+			expected:      NewErrAuthUnathorized(rsa.ErrVerification),
+		},
+		{
+			description: "fails when the current key is unknown",
+			signer:      currentKey,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", current).Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrAuthUnathorized(store.ErrNoDocuments),
+		},
+		{
+			description: "fails when the current key was revoked",
+			signer:      currentKey,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", current).Return(&models.Device{UID: "uid", PublicKey: current, RevokedKeys: []string{current}}, nil).Once()
+			},
+			expected: NewErrDeviceKeyRevoked(nil),
+		},
+		{
+			description: "fails when the new key is used by a device",
+			signer:      currentKey,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", current).Return(&models.Device{UID: "uid", PublicKey: current}, nil).Once()
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", next).Return(&models.Device{UID: "other", PublicKey: next}, nil).Once()
+			},
+			expected: NewErrDeviceKeyDuplicated(nil),
+		},
+		{
+			description: "fails when the device was rotated concurrently",
+			signer:      currentKey,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", current).Return(&models.Device{UID: "uid", PublicKey: current}, nil).Once()
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", next).Return(nil, store.ErrNoDocuments).Once()
+				mock.On("DeviceRotateKey", ctx, models.UID("uid"), current, next).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrDeviceKeyRevoked(store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds keeping the device's UID",
+			signer:      currentKey,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", current).Return(&models.Device{UID: "uid", PublicKey: current}, nil).Once()
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", next).Return(nil, store.ErrNoDocuments).Once()
+				mock.On("DeviceRotateKey", ctx, models.UID("uid"), current, next).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			mock := new(mocks.Store)
+			service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+			tc.requiredMocks(mock)
+
+			req := rotation(service, tc.signer)
+			assert.Equal(t, tc.expected, service.RotateDeviceKey(ctx, req))
+
+			// The challenge is used once, so the request cannot be replayed.
+			assert.Equal(t, NewErrAuthUnathorized(nil), service.RotateDeviceKey(ctx, req))
+
+			mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRevokeDeviceKey(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cache := newMemoryCache()
+	service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+	mock.On("DeviceGetByUID", ctx, models.UID("unknown"), "tenant").Return(nil, store.ErrNoDocuments).Once()
+	assert.Equal(t, NewErrDeviceNotFound("unknown", store.ErrNoDocuments), service.RevokeDeviceKey(ctx, "tenant", "unknown"))
+
+	// The device is kept connected when its tunnel cannot be dropped, so the revocation fails to be tried again.
+	mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(&models.Device{UID: "uid", PublicKey: "key"}, nil).Twice()
+	mock.On("DeviceRevokeKey", ctx, models.UID("uid"), "key").Return(nil).Twice()
+	clockMock.On("Now").Return(now).Twice()
+	clientMock.On("DisconnectDevice", "uid").Return(errors.New("error", "", 0)).Once()
+	assert.Error(t, service.RevokeDeviceKey(ctx, "tenant", "uid"))
+
+	clientMock.On("DisconnectDevice", "uid").Return(nil).Once()
+	mock.On("DeviceSetOnline", ctx, models.UID("uid"), false).Return(nil).Once()
+	assert.NoError(t, service.RevokeDeviceKey(ctx, "tenant", "uid"))
+
+	// The tokens issued until the revocation are rejected.
+	active, err := service.AuthIsDeviceTokenActive(ctx, "uid", now)
+	assert.NoError(t, err)
+	assert.False(t, active)
+
+	active, err = service.AuthIsDeviceTokenActive(ctx, "uid", now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	active, err = service.AuthIsDeviceTokenActive(ctx, "other", now)
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	mock.AssertExpectations(t)
+	clientMock.AssertExpectations(t)
+}
+
+func TestAuthDeviceRevokedKey(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	deviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+	req := requests.DeviceAuth{TenantID: "tenant", Hostname: "device", PublicKey: encodeDeviceKey(deviceKey)}
+	req.Challenge, req.Signature = signChallenge(t, service, deviceKey)
+
+	clockMock.On("Now").Return(now).Once()
+	mock.On("DeviceGetByPublicKey", ctx, "tenant", req.PublicKey).Return(&models.Device{UID: "uid", PublicKey: "rotated", RevokedKeys: []string{req.PublicKey}}, nil).Once()
+
+	_, err = service.AuthDevice(ctx, req, "0.0.0.0")
+	assert.Equal(t, NewErrDeviceKeyRevoked(nil), err)
+
+	mock.AssertExpectations(t)
+}
+
+func TestRekeyDevice(t *testing.T) {
+	ctx := context.TODO()
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	next := encodeDeviceKey(newKey)
+
+	// rekey builds a request signed by the new key to a challenge issued by the service.
+	rekey := func(service Service, tenantID, token string) requests.DeviceRekey {
+		challenge, err := service.AuthDeviceChallenge(ctx)
+		assert.NoError(t, err)
+
+		return requests.DeviceRekey{
+			TenantID:     tenantID,
+			Token:        token,
+			Challenge:    challenge.Nonce,
+			NewPublicKey: next,
+			NewSignature: signMessage(t, newKey, challenge.Nonce),
+		}
+	}
+
+	cases := []struct {
+		description   string
+		tenantID      string
+		token         func(service Service) string
+		requiredMocks func(mock *mocks.Store)
+		expected      error
+	}{
+		{
+			description: "fails when the token was not issued",
+			tenantID:    "tenant",
+			token: func(service Service) string {
+				return "unknown"
+			},
+			requiredMocks: func(mock *mocks.Store) {},
+			expected:      NewErrAuthUnathorized(nil),
+		},
+		{
+			description: "fails when the token was issued to a device of another tenant",
+			tenantID:    "other",
+			token: func(service Service) string {
+				token, err := service.CreateDeviceRekeyToken(ctx, "tenant", "uid")
+				assert.NoError(t, err)
+
+				return token.Token
+			},
+			requiredMocks: func(mock *mocks.Store) {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(&models.Device{UID: "uid", PublicKey: "revoked"}, nil).Once()
+			},
+			expected: NewErrAuthUnathorized(nil),
+		},
+		{
+			description: "fails when the new key is used by a device",
+			tenantID:    "tenant",
+			token: func(service Service) string {
+				token, err := service.CreateDeviceRekeyToken(ctx, "tenant", "uid")
+				assert.NoError(t, err)
+
+				return token.Token
+			},
+			requiredMocks: func(mock *mocks.Store) {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(&models.Device{UID: "uid", PublicKey: "revoked"}, nil).Twice()
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", next).Return(&models.Device{UID: "other", PublicKey: next}, nil).Once()
+			},
+			expected: NewErrDeviceKeyDuplicated(nil),
+		},
+		{
+			description: "succeeds replacing the revoked key and keeping the device's UID",
+			tenantID:    "tenant",
+			token: func(service Service) string {
+				token, err := service.CreateDeviceRekeyToken(ctx, "tenant", "uid")
+				assert.NoError(t, err)
+
+				return token.Token
+			},
+			requiredMocks: func(mock *mocks.Store) {
+				clockMock.On("Now").Return(now).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(&models.Device{UID: "uid", PublicKey: "revoked", RevokedKeys: []string{"revoked"}}, nil).Twice()
+				mock.On("DeviceGetByPublicKey", ctx, "tenant", next).Return(nil, store.ErrNoDocuments).Once()
+				mock.On("DeviceRekey", ctx, models.UID("uid"), "revoked", next).Return(nil).Once()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			mock := new(mocks.Store)
+			service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+			tc.requiredMocks(mock)
+
+			token := tc.token(service)
+			assert.Equal(t, tc.expected, service.RekeyDevice(ctx, rekey(service, tc.tenantID, token)))
+
+			// The token is used once, so it cannot replace the key again.
+			assert.Equal(t, NewErrAuthUnathorized(nil), service.RekeyDevice(ctx, rekey(service, tc.tenantID, token)))
+
+			mock.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateDeviceRekeyToken(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+	clockMock.On("Now").Return(now).Once()
+	mock.On("DeviceGetByUID", ctx, models.UID("unknown"), "tenant").Return(nil, store.ErrNoDocuments).Once()
+	mock.On("DeviceGetByUID", ctx, models.UID("uid"), "tenant").Return(&models.Device{UID: "uid", PublicKey: "key"}, nil).Once()
+
+	_, err := service.CreateDeviceRekeyToken(ctx, "tenant", "unknown")
+	assert.Equal(t, NewErrDeviceNotFound("unknown", store.ErrNoDocuments), err)
+
+	token, err := service.CreateDeviceRekeyToken(ctx, "tenant", "uid")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, now.Add(DeviceRekeyTokenExpiry), token.ExpiresAt)
+
+	mock.AssertExpectations(t)
+}
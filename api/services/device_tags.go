@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
 )
 
 // DeviceTags contains the service's function to manage device tags.
@@ -85,3 +86,34 @@ func (s *service) UpdateDeviceTag(ctx context.Context, uid models.UID, tags []st
 
 	return s.store.DeviceUpdateTag(ctx, uid, set)
 }
+
+// setAgentTags sets the tags declared by the device's agent, replacing the current ones. Nothing is changed when the
+// agent declares no tags or the device already has them. A failure is only logged, since it must not prevent the
+// device from authenticating.
+func (s *service) setAgentTags(ctx context.Context, device *models.Device, tags []string) {
+	if len(tags) == 0 || equalTags(device.Tags, tags) {
+		return
+	}
+
+	if err := s.store.DeviceUpdateTag(ctx, models.UID(device.UID), tags); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"uid":       device.UID,
+			"tenant_id": device.TenantID,
+		}).Warn("failed to set the tags declared by the agent to the device")
+	}
+}
+
+// equalTags checks if both lists have the same tags, regardless of their order.
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for _, tag := range b {
+		if !contains(a, tag) {
+			return false
+		}
+	}
+
+	return true
+}
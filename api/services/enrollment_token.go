@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+type EnrollmentTokenService interface {
+	ListEnrollmentTokens(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error)
+	// CreateEnrollmentToken creates an enrollment token to the namespace, returning it with the token to configure on the
+	// devices, which is not available anymore after it.
+	CreateEnrollmentToken(ctx context.Context, tenantID string, userID string, req requests.EnrollmentTokenCreate) (*models.EnrollmentToken, error)
+	// DeleteEnrollmentToken revokes the enrollment token. The devices already registered with it are kept.
+	DeleteEnrollmentToken(ctx context.Context, tenantID string, id string) error
+	// EditNamespaceRequireEnrollmentToken defines if the namespace rejects new devices without an enrollment token.
+	EditNamespaceRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error
+}
+
+func (s *service) ListEnrollmentTokens(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error) {
+	return s.store.EnrollmentTokenList(ctx, tenantID)
+}
+
+func (s *service) CreateEnrollmentToken(ctx context.Context, tenantID string, userID string, req requests.EnrollmentTokenCreate) (*models.EnrollmentToken, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	// The token has the same format of the refresh token, so it is found by its ID and compared by its hash.
+	plain, hash, err := newRefreshToken(id)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := req.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	now := clock.Now()
+
+	token := &models.EnrollmentToken{
+		ID:           id,
+		TenantID:     tenantID,
+		Hash:         hash,
+		Description:  req.Description,
+		Tags:         tags,
+		NameTemplate: req.NameTemplate,
+		MaxUses:      req.MaxUses,
+		ExpiresAt:    now.Add(time.Duration(req.ExpiresIn) * time.Hour),
+		CreatedBy:    userID,
+		CreatedAt:    now,
+	}
+
+	if err := s.store.EnrollmentTokenCreate(ctx, token); err != nil {
+		return nil, err
+	}
+
+	token.Token = plain
+
+	return token, nil
+}
+
+func (s *service) DeleteEnrollmentToken(ctx context.Context, tenantID string, id string) error {
+	if err := s.store.EnrollmentTokenDelete(ctx, tenantID, id); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrEnrollmentTokenNotFound(id, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) EditNamespaceRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error {
+	if err := s.store.NamespaceSetRequireEnrollmentToken(ctx, tenantID, require); err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	return nil
+}
+
+// useEnrollmentToken checks the enrollment token presented by a new device and counts its use. When the device has no
+// token, it fails only if the namespace requires one.
+func (s *service) useEnrollmentToken(ctx context.Context, namespace *models.Namespace, plain string) (*models.EnrollmentToken, error) {
+	if plain == "" {
+		if namespace.Settings != nil && namespace.Settings.RequireEnrollmentToken {
+			return nil, NewErrEnrollmentTokenRequired(nil)
+		}
+
+		return nil, nil
+	}
+
+	id, _, ok := strings.Cut(plain, ".")
+	if !ok {
+		return nil, NewErrEnrollmentTokenInvalid(nil)
+	}
+
+	token, err := s.store.EnrollmentTokenGet(ctx, id)
+	if err != nil {
+		return nil, NewErrEnrollmentTokenInvalid(err)
+	}
+
+	if token.TenantID != namespace.TenantID || subtle.ConstantTimeCompare([]byte(hashRefreshToken(plain)), []byte(token.Hash)) != 1 {
+		return nil, NewErrEnrollmentTokenInvalid(nil)
+	}
+
+	if err := s.store.EnrollmentTokenUse(ctx, token.ID, clock.Now()); err != nil {
+		return nil, NewErrEnrollmentTokenInvalid(err)
+	}
+
+	return token, nil
+}
+
+// enrollDevice applies the enrollment token's tags and name to the new device and accepts it. The device is kept pending
+// when it cannot be accepted, like when the namespace reached its maximum number of devices.
+//
+// The device's tags, declared by the agent, are kept after the token's ones, up to DeviceMaxTags.
+func (s *service) enrollDevice(ctx context.Context, token *models.EnrollmentToken, device *models.Device, hostname string) {
+	uid := models.UID(device.UID)
+
+	log := logrus.WithFields(logrus.Fields{
+		"uid":              device.UID,
+		"tenant_id":        device.TenantID,
+		"enrollment_token": token.ID,
+	})
+
+	tags := append([]string{}, token.Tags...)
+	for _, tag := range device.Tags {
+		if len(tags) < DeviceMaxTags && !contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) > 0 {
+		if err := s.store.DeviceUpdateTag(ctx, uid, tags); err != nil {
+			log.WithError(err).Warn("failed to set the enrollment token's tags to the device")
+		}
+	}
+
+	var mac string
+	if device.Identity != nil {
+		mac = device.Identity.MAC
+	}
+
+	if name := token.DeviceName(hostname, mac, device.UID); name != "" {
+		if err := s.RenameDevice(ctx, uid, name, device.TenantID); err != nil {
+			log.WithError(err).WithField("name", name).Warn("failed to name the device from the enrollment token")
+		}
+	}
+
+	if err := s.UpdateDeviceStatus(ctx, device.TenantID, uid, models.DeviceStatusAccepted); err != nil {
+		log.WithError(err).Warn("failed to accept the device registered with an enrollment token")
+	}
+}
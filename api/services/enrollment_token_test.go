@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cnf/structhash"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestCreateEnrollmentToken(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	req := requests.EnrollmentTokenCreate{
+		Description:  "factory",
+		Tags:         []string{"factory"},
+		NameTemplate: "sensor-{mac}",
+		MaxUses:      10,
+		ExpiresIn:    24,
+	}
+
+	clockMock.On("Now").Return(now).Once()
+	mock.On("EnrollmentTokenCreate", ctx, testifymock.MatchedBy(func(token *models.EnrollmentToken) bool {
+		return token.TenantID == "tenant" &&
+			token.Description == "factory" &&
+			token.NameTemplate == "sensor-{mac}" &&
+			token.MaxUses == 10 &&
+			token.ExpiresAt.Equal(now.Add(24*time.Hour)) &&
+			token.CreatedBy == "user" &&
+			token.Token == ""
+	})).Return(nil).Once()
+
+	token, err := service.CreateEnrollmentToken(ctx, "tenant", "user", req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"factory"}, token.Tags)
+
+	// Only the hash of the token is stored.
+	assert.True(t, strings.HasPrefix(token.Token, token.ID+"."))
+	assert.Equal(t, hashRefreshToken(token.Token), token.Hash)
+
+	mock.AssertExpectations(t)
+}
+
+func TestDeleteEnrollmentToken(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	mock.On("EnrollmentTokenDelete", ctx, "tenant", "unknown").Return(store.ErrNoDocuments).Once()
+	mock.On("EnrollmentTokenDelete", ctx, "tenant", "id").Return(nil).Once()
+
+	assert.Equal(t, NewErrEnrollmentTokenNotFound("unknown", store.ErrNoDocuments), service.DeleteEnrollmentToken(ctx, "tenant", "unknown"))
+	assert.NoError(t, service.DeleteEnrollmentToken(ctx, "tenant", "id"))
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthDeviceEnrollmentToken(t *testing.T) {
+	ctx := context.TODO()
+
+	const plain = "id.secret"
+
+	deviceKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	req := requests.DeviceAuth{
+		TenantID:  "tenant",
+		Hostname:  "sensor",
+		Identity:  &requests.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey: encodeDeviceKey(deviceKey),
+	}
+
+	sum := sha256.Sum256(structhash.Dump(models.DeviceAuth{
+		Hostname:  req.Hostname,
+		Identity:  &models.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey: req.PublicKey,
+		TenantID:  req.TenantID,
+	}, 1))
+	uid := hex.EncodeToString(sum[:])
+
+	device := models.Device{
+		UID:        uid,
+		Identity:   &models.DeviceIdentity{MAC: "aa:bb"},
+		PublicKey:  req.PublicKey,
+		TenantID:   "tenant",
+		LastSeen:   now,
+		RemoteAddr: "0.0.0.0",
+	}
+
+	token := &models.EnrollmentToken{
+		ID:           "id",
+		TenantID:     "tenant",
+		Hash:         hashRefreshToken(plain),
+		Tags:         []string{"factory"},
+		NameTemplate: "sensor-{mac}",
+		MaxUses:      1,
+		ExpiresAt:    now.Add(time.Hour),
+	}
+
+	cases := []struct {
+		description   string
+		namespace     *models.Namespace
+		token         string
+		requiredMocks func(mock *mocks.Store)
+		expected      error
+	}{
+		{
+			description: "fails when the namespace requires a token and the new device has none",
+			namespace:   &models.Namespace{TenantID: "tenant", Settings: &models.NamespaceSettings{RequireEnrollmentToken: true}},
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(nil, store.ErrNoDocuments).Once()
+			},
+			expected: NewErrEnrollmentTokenRequired(nil),
+		},
+		{
+			description: "fails when the token is from another namespace",
+			namespace:   &models.Namespace{TenantID: "tenant"},
+			token:       plain,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(nil, store.ErrNoDocuments).Once()
+				mock.On("EnrollmentTokenGet", ctx, "id").Return(&models.EnrollmentToken{ID: "id", TenantID: "other", Hash: token.Hash}, nil).Once()
+			},
+			expected: NewErrEnrollmentTokenInvalid(nil),
+		},
+		{
+			description: "fails when the token has reached its maximum uses",
+			namespace:   &models.Namespace{TenantID: "tenant"},
+			token:       plain,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(nil, store.ErrNoDocuments).Once()
+				mock.On("EnrollmentTokenGet", ctx, "id").Return(token, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("EnrollmentTokenUse", ctx, "id", now).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrEnrollmentTokenInvalid(store.ErrNoDocuments),
+		},
+		{
+			description: "succeeds without using the token when the device is already registered",
+			namespace:   &models.Namespace{TenantID: "tenant", Settings: &models.NamespaceSettings{RequireEnrollmentToken: true}},
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(&device, nil).Once()
+				mock.On("DeviceCreate", ctx, device, "sensor").Return(nil).Once()
+				mock.On("DeviceSetOnline", ctx, models.UID(uid), true).Return(nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(&models.Device{UID: uid, Name: "sensor"}, nil).Once()
+			},
+		},
+		{
+			description: "succeeds accepting the new device with the token's tags and name",
+			namespace:   &models.Namespace{TenantID: "tenant", MaxDevices: -1},
+			token:       plain,
+			requiredMocks: func(mock *mocks.Store) {
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(nil, store.ErrNoDocuments).Once()
+				mock.On("EnrollmentTokenGet", ctx, "id").Return(token, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+				mock.On("EnrollmentTokenUse", ctx, "id", now).Return(nil).Once()
+				mock.On("DeviceCreate", ctx, device, "sensor").Return(nil).Once()
+
+				mock.On("DeviceUpdateTag", ctx, models.UID(uid), []string{"factory"}).Return(nil).Once()
+
+				pending := &models.Device{UID: uid, Name: "sensor", Identity: &models.DeviceIdentity{MAC: "aa:bb"}, TenantID: "tenant", Status: models.DeviceStatusPending}
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(pending, nil).Twice()
+				mock.On("DeviceGetByName", ctx, "sensor-aa-bb", "tenant").Return(nil, store.ErrNoDocuments).Once()
+				mock.On("DeviceRename", ctx, models.UID(uid), "sensor-aa-bb").Return(nil).Once()
+
+				mock.On("NamespaceGet", ctx, "tenant").Return(&models.Namespace{TenantID: "tenant", MaxDevices: -1}, nil).Once()
+				mock.On("DeviceGetByMac", ctx, "aa:bb", "tenant", models.DeviceStatusAccepted).Return(nil, store.ErrNoDocuments).Once()
+				envMock.On("Get", "SHELLHUB_CLOUD").Return("false").Once()
+				envMock.On("Get", "SHELLHUB_ENTERPRISE").Return("false").Once()
+				mock.On("DeviceUpdateStatus", ctx, models.UID(uid), models.DeviceStatusAccepted).Return(nil).Once()
+
+				mock.On("DeviceSetOnline", ctx, models.UID(uid), true).Return(nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID(uid), "tenant").Return(&models.Device{UID: uid, Name: "sensor-aa-bb"}, nil).Once()
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			mock := new(mocks.Store)
+			service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+			clockMock.On("Now").Return(now).Once()
+			mock.On("DeviceGetByPublicKey", ctx, "tenant", req.PublicKey).Return(nil, store.ErrNoDocuments).Once()
+			mock.On("NamespaceGet", ctx, "tenant").Return(tc.namespace, nil).Once()
+			tc.requiredMocks(mock)
+
+			r := req
+			r.EnrollmentToken = tc.token
+			r.Challenge, r.Signature = signChallenge(t, service, deviceKey)
+
+			_, err := service.AuthDevice(ctx, r, "0.0.0.0")
+			assert.Equal(t, tc.expected, err)
+
+			mock.AssertExpectations(t)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -29,6 +30,8 @@ const (
 	// ErrCodeStore is the error code for when the store function fails. The store function is responsible for execute
 	// the main service action.
 	ErrCodeStore
+	// ErrCodeTooManyRequests is the error code for when too many requests were made to a resource.
+	ErrCodeTooManyRequests
 )
 
 // ErrDataNotFound structure should be used to add errors.Data to an error when the resource is not found.
@@ -56,6 +59,12 @@ type ErrDataInvalid struct {
 	Data map[string]interface{}
 }
 
+// ErrDataTooManyRequests structure should be used to add errors.Data to an error when too many requests were made.
+type ErrDataTooManyRequests struct {
+	// RetryAfter is how long the client must wait before a new request.
+	RetryAfter time.Duration
+}
+
 var (
 	ErrReport                       = errors.New("report error", ErrLayer, ErrCodeInvalid)
 	ErrPaymentRequired              = errors.New("payment required", ErrLayer, ErrCodePayment)
@@ -119,6 +128,25 @@ var (
 	ErrBillingReportNamespaceDelete = errors.New("billing report namespace delete", ErrLayer, ErrCodePayment)
 	ErrBillingReportDevice          = errors.New("billing report device", ErrLayer, ErrCodePayment)
 	ErrBillingEvaluate              = errors.New("billing evaluate", ErrLayer, ErrCodePayment)
+	ErrAccessRequestNotFound        = errors.New("access request not found", ErrLayer, ErrCodeNotFound)
+	ErrAccessRequestInvalid         = errors.New("access request invalid", ErrLayer, ErrCodeInvalid)
+	ErrAccessRequestReviewed        = errors.New("access request already reviewed", ErrLayer, ErrCodeInvalid)
+	ErrMFACodeInvalid               = errors.New("mfa code invalid", ErrLayer, ErrCodeUnauthorized)
+	ErrMFAEnabled                   = errors.New("mfa already enabled", ErrLayer, ErrCodeInvalid)
+	ErrMFANotEnrolled               = errors.New("mfa not enrolled", ErrLayer, ErrCodeInvalid)
+	ErrMFARequired                  = errors.New("mfa required by the namespace", ErrLayer, ErrCodeForbidden)
+	ErrOIDCNotConfigured            = errors.New("single sign-on not configured", ErrLayer, ErrCodeNotFound)
+	ErrAuthBlocked                  = errors.New("too many failed authentication attempts", ErrLayer, ErrCodeTooManyRequests)
+	ErrUserSessionNotFound          = errors.New("user session not found", ErrLayer, ErrCodeNotFound)
+	ErrMailNotConfigured            = errors.New("e-mail delivery not configured", ErrLayer, ErrCodeNotFound)
+	ErrUserTokenInvalid             = errors.New("user token invalid or expired", ErrLayer, ErrCodeInvalid)
+	ErrNamespaceTransferNotFound    = errors.New("namespace transfer not found", ErrLayer, ErrCodeNotFound)
+	ErrNamespaceTransferInvalid     = errors.New("namespace transfer invalid", ErrLayer, ErrCodeInvalid)
+	ErrEnrollmentTokenNotFound      = errors.New("enrollment token not found", ErrLayer, ErrCodeNotFound)
+	ErrEnrollmentTokenInvalid       = errors.New("enrollment token invalid or expired", ErrLayer, ErrCodeUnauthorized)
+	ErrEnrollmentTokenRequired      = errors.New("enrollment token required by the namespace", ErrLayer, ErrCodeForbidden)
+	ErrDeviceKeyRevoked             = errors.New("device key revoked", ErrLayer, ErrCodeForbidden)
+	ErrDeviceKeyDuplicated          = errors.New("device key already in use", ErrLayer, ErrCodeDuplicated)
 )
 
 // NewErrNotFound returns an error with the ErrDataNotFound and wrap an error.
@@ -154,6 +182,11 @@ func NewErrUnathorized(err error, next error) error {
 	return errors.Wrap(err, next)
 }
 
+// NewErrTooManyRequests returns an error with the ErrDataTooManyRequests and wrap an error.
+func NewErrTooManyRequests(err error, retryAfter time.Duration, next error) error {
+	return errors.Wrap(errors.WithData(err, ErrDataTooManyRequests{RetryAfter: retryAfter}), next)
+}
+
 // NewErrForbidden return a error to be used when the access to a resource is forbidden.
 func NewErrForbidden(err error, next error) error {
 	return errors.Wrap(err, next)
@@ -295,6 +328,104 @@ func NewErrNamespaceMemberInvalid(next error) error {
 	return NewErrInvalid(ErrNamespaceMemberInvalid, nil, next)
 }
 
+// NewErrAccessRequestNotFound returns an error when the access request is not found.
+func NewErrAccessRequestNotFound(id string, next error) error {
+	return NewErrNotFound(ErrAccessRequestNotFound, id, next)
+}
+
+// NewErrAccessRequestInvalid returns an error when the access request is invalid.
+func NewErrAccessRequestInvalid(data map[string]interface{}, next error) error {
+	return NewErrInvalid(ErrAccessRequestInvalid, data, next)
+}
+
+// NewErrAccessRequestReviewed returns an error when the access request is not pending anymore.
+func NewErrAccessRequestReviewed(id string, next error) error {
+	return NewErrInvalid(ErrAccessRequestReviewed, map[string]interface{}{"id": id}, next)
+}
+
+// NewErrMFACodeInvalid returns an error when the TOTP or recovery code is invalid.
+func NewErrMFACodeInvalid(next error) error {
+	return NewErrUnathorized(ErrMFACodeInvalid, next)
+}
+
+// NewErrMFAEnabled returns an error when the user tries to enroll MFA while it is already enabled.
+func NewErrMFAEnabled(id string, next error) error {
+	return NewErrInvalid(ErrMFAEnabled, map[string]interface{}{"id": id}, next)
+}
+
+// NewErrMFANotEnrolled returns an error when the user tries to enable or disable MFA without enrolling it first.
+func NewErrMFANotEnrolled(id string, next error) error {
+	return NewErrInvalid(ErrMFANotEnrolled, map[string]interface{}{"id": id}, next)
+}
+
+// NewErrMFARequired returns an error when the namespace requires MFA, but the user has not enabled it.
+func NewErrMFARequired(next error) error {
+	return NewErrForbidden(ErrMFARequired, next)
+}
+
+// NewErrAuthBlocked returns an error when the account or source IP is blocked by its failed authentication attempts.
+func NewErrAuthBlocked(retryAfter time.Duration, next error) error {
+	return NewErrTooManyRequests(ErrAuthBlocked, retryAfter, next)
+}
+
+// NewErrUserSessionNotFound returns an error when the user's session is not found.
+func NewErrUserSessionNotFound(id string, next error) error {
+	return NewErrNotFound(ErrUserSessionNotFound, id, next)
+}
+
+// NewErrMailNotConfigured returns an error when a flow that sends e-mails is used, but the SMTP server is not configured.
+func NewErrMailNotConfigured(next error) error {
+	return NewErrNotFound(ErrMailNotConfigured, "", next)
+}
+
+// NewErrUserTokenInvalid returns an error when the token sent by e-mail to the user is invalid, expired or already used.
+func NewErrUserTokenInvalid(next error) error {
+	return NewErrInvalid(ErrUserTokenInvalid, nil, next)
+}
+
+// NewErrNamespaceTransferNotFound returns an error when the namespace has no pending transfer to the user, or it expired.
+func NewErrNamespaceTransferNotFound(tenant string, next error) error {
+	return NewErrNotFound(ErrNamespaceTransferNotFound, tenant, next)
+}
+
+// NewErrNamespaceTransferInvalid returns an error when the namespace cannot be transferred to the member, like the owner
+// itself.
+func NewErrNamespaceTransferInvalid(next error) error {
+	return NewErrInvalid(ErrNamespaceTransferInvalid, nil, next)
+}
+
+// NewErrEnrollmentTokenNotFound returns an error when the enrollment token is not found.
+func NewErrEnrollmentTokenNotFound(id string, next error) error {
+	return NewErrNotFound(ErrEnrollmentTokenNotFound, id, next)
+}
+
+// NewErrEnrollmentTokenInvalid returns an error when the device presents an enrollment token that is unknown, from
+// another namespace, expired or already used up to its limit.
+func NewErrEnrollmentTokenInvalid(next error) error {
+	return NewErrUnathorized(ErrEnrollmentTokenInvalid, next)
+}
+
+// NewErrEnrollmentTokenRequired returns an error when a new device registers without an enrollment token in a
+// namespace that requires one.
+func NewErrEnrollmentTokenRequired(next error) error {
+	return NewErrForbidden(ErrEnrollmentTokenRequired, next)
+}
+
+// NewErrDeviceKeyRevoked returns an error when the device authenticates with a key that was rotated or revoked.
+func NewErrDeviceKeyRevoked(next error) error {
+	return NewErrForbidden(ErrDeviceKeyRevoked, next)
+}
+
+// NewErrDeviceKeyDuplicated returns an error when the device rotates to a key that is, or was, used by a device.
+func NewErrDeviceKeyDuplicated(next error) error {
+	return NewErrDuplicated(ErrDeviceKeyDuplicated, nil, next)
+}
+
+// NewErrOIDCNotConfigured returns an error when a login through OpenID Connect is tried, but it is not configured.
+func NewErrOIDCNotConfigured(next error) error {
+	return NewErrNotFound(ErrOIDCNotConfigured, "", next)
+}
+
 // NewErrNamespaceMemberNotFound returns an error to be used when the namespace member is not found.
 func NewErrNamespaceMemberNotFound(id string, next error) error {
 	return NewErrNotFound(ErrNamespaceMemberNotFound, id, next)
@@ -4,6 +4,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -12,6 +13,9 @@ import (
 	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	env_mocks "github.com/shellhub-io/shellhub/pkg/envs/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
+	testifymock "github.com/stretchr/testify/mock"
 )
 
 var (
@@ -35,3 +39,30 @@ func TestMain(m *testing.M) {
 	code := m.Run()
 	os.Exit(code)
 }
+
+// hashOf matches a password's hash created from plain.
+func hashOf(plain string) interface{} {
+	return testifymock.MatchedBy(func(hash string) bool {
+		return !password.NeedsRehash(hash) && password.Compare(plain, hash)
+	})
+}
+
+// withPassword matches a user equal to expected, except by its password, that must be a hash created from plain.
+func withPassword(expected *models.User, plain string) interface{} {
+	return testifymock.MatchedBy(func(user *models.User) bool {
+		actual := *user
+		actual.Password = expected.Password
+
+		return !password.NeedsRehash(user.Password) && password.Compare(plain, user.Password) && reflect.DeepEqual(expected, &actual)
+	})
+}
+
+// mustHash hashes the plain password, failing the tests when it is not possible.
+func mustHash(plain string) string {
+	hash, err := password.Hash(plain)
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+}
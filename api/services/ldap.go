@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/shellhub-io/shellhub/api/pkg/ldap"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/validator"
+	"github.com/sirupsen/logrus"
+)
+
+// MaxNamespacesLDAP is the maximum number of namespaces of the users created by the LDAP's login, where a negative
+// value is unlimited.
+const MaxNamespacesLDAP = -1
+
+// authLDAP authenticates the user at the LDAP directory. It returns false when the directory did not authenticate the
+// user, so the login falls back to the local users; an error after the authentication, like the provisioning's one,
+// fails the login.
+func (s *service) authLDAP(ctx context.Context, req requests.UserAuth) (*models.UserAuthResponse, bool, error) {
+	entry, err := s.ldap.Authenticate(ctx, req.Username, req.Password)
+	if err != nil {
+		if !errors.Is(err, ldap.ErrUserNotFound) && !errors.Is(err, ldap.ErrInvalidCredentials) {
+			logrus.WithError(err).Warn("failed to authenticate the user at the LDAP directory")
+		}
+
+		return nil, false, nil
+	}
+
+	user, err := s.provisionLDAPUser(ctx, entry)
+	if err != nil {
+		return nil, true, err
+	}
+
+	s.syncMemberships(ctx, user, s.ldap.Config().Groups, entry.Groups)
+
+	namespace, _ := s.store.NamespaceGetFirst(ctx, user.ID)
+
+	if requiresMFA(user, namespace) {
+		res, err := s.authMFAToken(user)
+
+		return res, true, err
+	}
+
+	res, err := s.authUserToken(ctx, user, namespace)
+
+	return res, true, err
+}
+
+// provisionLDAPUser gets the user linked to the directory's entry, updating its name and e-mail from the entry, or
+// creates it. A local user with the same username or e-mail is not linked, as the directory cannot prove that both
+// belong to the same person.
+func (s *service) provisionLDAPUser(ctx context.Context, entry *ldap.User) (*models.User, error) {
+	username := strings.ToLower(entry.Username)
+	email := strings.ToLower(entry.Email)
+
+	// The users' e-mails are unique, so an empty one would be taken by the first user without it.
+	if email == "" {
+		return nil, NewErrUserInvalid(map[string]interface{}{"email": email}, nil)
+	}
+
+	name := entry.Name
+	if name == "" {
+		name = username
+	}
+
+	// The changes are saved by the token's issuing, which also updates the last login.
+	if user, err := s.store.UserGetByIdentity(ctx, models.UserIdentityLDAP, username); err == nil {
+		user.Name = name
+		user.Email = email
+
+		return user, nil
+	}
+
+	if !validator.ValidateFieldUsername(username) {
+		return nil, NewErrUserInvalid(map[string]interface{}{"username": username}, nil)
+	}
+
+	user := &models.User{
+		UserData: models.UserData{
+			Name:     name,
+			Email:    email,
+			Username: username,
+		},
+		Confirmed:     true,
+		CreatedAt:     clock.Now(),
+		MaxNamespaces: MaxNamespacesLDAP,
+		Identity:      &models.UserIdentity{Provider: models.UserIdentityLDAP, Subject: username},
+	}
+
+	if err := s.store.UserCreate(ctx, user); err != nil {
+		if err == store.ErrDuplicate {
+			return nil, NewErrUserDuplicated([]string{username, email}, err)
+		}
+
+		return nil, err
+	}
+
+	// The store does not return the created user's ID, so the user is got again.
+	return s.store.UserGetByIdentity(ctx, models.UserIdentityLDAP, username)
+}
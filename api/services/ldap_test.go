@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/ldap"
+	"github.com/shellhub-io/shellhub/api/pkg/membership"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+// ldapDirectory is a LDAP directory with fixed users, keyed by their usernames.
+type ldapDirectory struct {
+	config ldap.Config
+	users  map[string]*ldap.User
+	// passwords are the users' passwords, keyed by their usernames.
+	passwords map[string]string
+	// err fails every authentication, like an unreachable directory.
+	err error
+}
+
+func (d *ldapDirectory) Authenticate(_ context.Context, username, password string) (*ldap.User, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	user, ok := d.users[username]
+	if !ok {
+		return nil, ldap.ErrUserNotFound
+	}
+
+	if d.passwords[username] != password {
+		return nil, ldap.ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func (d *ldapDirectory) Config() ldap.Config {
+	return d.config
+}
+
+func TestAuthUserLDAP(t *testing.T) {
+	directory := &ldapDirectory{
+		config: ldap.Config{
+			Groups: membership.Mappings{
+				{Name: "devs", TenantID: "tenant", Role: guard.RoleOperator},
+			},
+		},
+		users: map[string]*ldap.User{
+			"john": {
+				DN:       "uid=john,ou=users,dc=example,dc=com",
+				Username: "John",
+				Name:     "John Doe",
+				Email:    "John@example.com",
+				Groups:   []string{"cn=devs,ou=groups,dc=example,dc=com", "devs"},
+			},
+			"jane": {
+				DN:       "uid=jane,ou=users,dc=example,dc=com",
+				Username: "jane",
+			},
+		},
+		passwords: map[string]string{"john": "secret", "jane": "secret"},
+	}
+
+	identity := &models.UserIdentity{Provider: models.UserIdentityLDAP, Subject: "john"}
+
+	hash, err := password.Hash("admin")
+	assert.NoError(t, err)
+
+	admin := &models.User{ID: "admin", UserData: models.UserData{Username: "admin", Email: "admin@example.com"}, UserPassword: models.UserPassword{Password: hash}, Confirmed: true}
+
+	t.Run("creates the user and its membership on the first login", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		created := &models.User{
+			UserData:      models.UserData{Name: "John Doe", Username: "john", Email: "john@example.com"},
+			Confirmed:     true,
+			CreatedAt:     now,
+			MaxNamespaces: MaxNamespacesLDAP,
+			Identity:      identity,
+		}
+
+		user := *created
+		user.ID = "id"
+
+		namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "owner", Role: guard.RoleOwner}}}
+		member := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "id", Role: guard.RoleOperator}}}
+
+		// The login is resolved to the local user, whose failed attempts are tracked, before the directory is checked.
+		mock.On("UserGetByUsername", testifymock.Anything, "john").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "john").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityLDAP, "john").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserCreate", testifymock.Anything, created).Return(nil).Once()
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityLDAP, "john").Return(&user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(namespace, nil).Once()
+		mock.On("NamespaceAddMember", testifymock.Anything, "tenant", "id", guard.RoleOperator).Return(member, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(member, nil).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "john", Password: "secret"}, "")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Token)
+		assert.Equal(t, "id", res.ID)
+		assert.Equal(t, "john", res.User)
+		assert.Equal(t, "tenant", res.Tenant)
+		assert.Equal(t, guard.RoleOperator, res.Role)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("updates the user's data from the directory", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John", Username: "john", Email: "old@example.com"}, Confirmed: true, Identity: identity}
+		namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}
+
+		mock.On("UserGetByUsername", testifymock.Anything, "john").Return(user, nil).Once()
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityLDAP, "john").Return(user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(namespace, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(namespace, nil).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.MatchedBy(func(user models.User) bool {
+			return user.Name == "John Doe" && user.Email == "john@example.com"
+		})).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "john", Password: "secret"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", res.Name)
+		assert.Equal(t, "john@example.com", res.Email)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the directory's user does not have an e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		mock.On("UserGetByUsername", testifymock.Anything, "jane").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "jane").Return(nil, store.ErrNoDocuments).Once()
+
+		_, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "jane", Password: "secret"}, "")
+		assert.Equal(t, NewErrUserInvalid(map[string]interface{}{"email": ""}, nil), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when a local user has the directory's user username", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		local := &models.User{ID: "local", UserData: models.UserData{Username: "john", Email: "john@example.org"}, Confirmed: true}
+
+		mock.On("UserGetByUsername", testifymock.Anything, "john").Return(local, nil).Once()
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityLDAP, "john").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserCreate", testifymock.Anything, testifymock.Anything).Return(store.ErrDuplicate).Once()
+
+		_, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "john", Password: "secret"}, "")
+		assert.Equal(t, NewErrUserDuplicated([]string{"john", "john@example.com"}, store.ErrDuplicate), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the local users when the directory does not know the user", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		mock.On("UserGetByUsername", testifymock.Anything, "admin").Return(admin, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "admin").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "admin", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "admin", Password: "admin"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", res.User)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not authenticate the directory's user locally when its password is wrong", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		user := &models.User{ID: "id", UserData: models.UserData{Username: "john", Email: "john@example.com"}, Confirmed: true, Identity: identity}
+
+		mock.On("UserGetByUsername", testifymock.Anything, "john").Return(user, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(nil, store.ErrNoDocuments).Once()
+		// The failed attempt is recorded for the user.
+		clockMock.On("Now").Return(now).Once()
+
+		_, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "john", Password: "wrong"}, "")
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the local users when the directory is unreachable", func(t *testing.T) {
+		directory := &ldapDirectory{err: errors.New("connection refused")}
+
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithLDAP(directory))
+
+		mock.On("UserGetByUsername", testifymock.Anything, "admin").Return(admin, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "admin").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "admin", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthUser(context.TODO(), requests.UserAuth{Username: "admin", Password: "admin"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, "admin", res.User)
+
+		mock.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// checkAttempts fails when the account or the source IP is blocked by its failed authentication attempts. An empty
+// account, like an unknown login, or an empty source IP, like when the request did not pass through the gateway, is not
+// tracked.
+func (s *service) checkAttempts(ctx context.Context, account, sourceIP string) error {
+	var wait time.Duration
+	if account != "" {
+		blocked, err := s.accounts.Blocked(ctx, account)
+		if err != nil {
+			return err
+		}
+
+		wait = blocked
+	}
+
+	if sourceIP != "" {
+		source, err := s.sources.Blocked(ctx, sourceIP)
+		if err != nil {
+			return err
+		}
+
+		if source > wait {
+			wait = source
+		}
+	}
+
+	if wait > 0 {
+		return NewErrAuthBlocked(wait, nil)
+	}
+
+	return nil
+}
+
+// failAttempt records a failed authentication attempt of the account from the source IP.
+func (s *service) failAttempt(ctx context.Context, account, sourceIP string) {
+	logger := logrus.WithFields(logrus.Fields{
+		"account": account,
+		"ip":      sourceIP,
+	})
+
+	if sourceIP != "" {
+		if _, err := s.sources.Fail(ctx, sourceIP, sourceIP); err != nil {
+			logger.WithError(err).Warn("failed to record the failed authentication attempt of the source IP")
+		}
+	}
+
+	if account == "" {
+		logger.Info("failed authentication attempt of an unknown account")
+
+		return
+	}
+
+	record, err := s.accounts.Fail(ctx, account, sourceIP)
+	if err != nil {
+		logger.WithError(err).Warn("failed to record the failed authentication attempt")
+
+		return
+	}
+
+	logger = logger.WithField("failures", record.Failures)
+	if record.Locked {
+		logger.Warn("account locked by too many failed authentication attempts")
+	} else {
+		logger.Info("failed authentication attempt")
+	}
+}
+
+// resetAttempts forgets the failed authentication attempts of the account. The source IP's ones are kept, so an
+// attacker with a valid account cannot use it to keep guessing the others' passwords.
+func (s *service) resetAttempts(ctx context.Context, account string) {
+	if account == "" {
+		return
+	}
+
+	if err := s.accounts.Reset(ctx, account); err != nil {
+		logrus.WithError(err).WithField("account", account).Warn("failed to reset the failed authentication attempts")
+	}
+}
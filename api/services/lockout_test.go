@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/lockout"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/errors"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+// retryAfter returns the wait of an ErrAuthBlocked, whose data makes it different from the error's variable.
+func retryAfter(t *testing.T, err error) time.Duration {
+	t.Helper()
+
+	var e errors.Error
+	if !assert.True(t, errors.As(err, &e)) || !assert.Equal(t, ErrCodeTooManyRequests, e.Code) {
+		return 0
+	}
+
+	return e.Data.(ErrDataTooManyRequests).RetryAfter
+}
+
+func TestAuthUserLockout(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{
+		ID:           "id",
+		UserData:     models.UserData{Username: "john", Email: "john@example.com"},
+		UserPassword: models.UserPassword{Password: mustHash("secret")},
+		Confirmed:    true,
+	}
+
+	t.Run("blocks the account after the free failures", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		// The login is resolved to the user on each attempt, even the blocked one.
+		mock.On("UserGetByUsername", ctx, "john").Return(user, nil).Times(lockout.AccountPolicy.FreeFailures + 2)
+		mock.On("NamespaceGetFirst", ctx, "id").Return(nil, store.ErrNoDocuments).Times(lockout.AccountPolicy.FreeFailures + 1)
+		// Each failure is recorded for the account and the source IP, then the blocked account is checked.
+		clockMock.On("Now").Return(now).Times(2*(lockout.AccountPolicy.FreeFailures+1) + 1)
+
+		for i := 0; i <= lockout.AccountPolicy.FreeFailures; i++ {
+			_, err := service.AuthUser(ctx, requests.UserAuth{Username: "john", Password: "wrong"}, "192.168.1.1")
+			assert.Equal(t, NewErrAuthUnathorized(nil), err)
+		}
+
+		// Even the right password is refused while the account is blocked.
+		_, err := service.AuthUser(ctx, requests.UserAuth{Username: "John", Password: "secret"}, "192.168.1.2")
+		assert.Equal(t, lockout.AccountPolicy.BaseDelay, retryAfter(t, err).Round(lockout.AccountPolicy.BaseDelay))
+
+		record, err := service.accounts.Get(ctx, "id")
+		assert.NoError(t, err)
+		assert.Equal(t, lockout.AccountPolicy.FreeFailures+1, record.Failures)
+		assert.Equal(t, "192.168.1.1", record.Attempts[0].IP)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("shares the failures of the user's username and e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		mock.On("UserGetByUsername", ctx, "john").Return(user, nil).Times(lockout.AccountPolicy.FreeFailures + 1)
+		mock.On("UserGetByUsername", ctx, "john@example.com").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", ctx, "john@example.com").Return(user, nil).Once()
+		mock.On("NamespaceGetFirst", ctx, "id").Return(nil, store.ErrNoDocuments).Times(lockout.AccountPolicy.FreeFailures + 1)
+		// Each failure is recorded for the account and the source IP, then the blocked account is checked.
+		clockMock.On("Now").Return(now).Times(2*(lockout.AccountPolicy.FreeFailures+1) + 1)
+
+		for i := 0; i <= lockout.AccountPolicy.FreeFailures; i++ {
+			_, err := service.AuthUser(ctx, requests.UserAuth{Username: "john", Password: "wrong"}, "192.168.1.1")
+			assert.Equal(t, NewErrAuthUnathorized(nil), err)
+		}
+
+		// The e-mail is not another account to guess the password of.
+		_, err := service.AuthUser(ctx, requests.UserAuth{Username: "john@example.com", Password: "secret"}, "192.168.1.2")
+		assert.Equal(t, lockout.AccountPolicy.BaseDelay, retryAfter(t, err).Round(lockout.AccountPolicy.BaseDelay))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("resets the account's failures on success", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		mock.On("UserGetByUsername", ctx, "john").Return(user, nil).Twice()
+		mock.On("NamespaceGetFirst", ctx, "id").Return(nil, store.ErrNoDocuments).Twice()
+		// The failure is recorded for the account and the source IP, then the login is completed.
+		clockMock.On("Now").Return(now).Times(4)
+		mock.On("UserUpdateData", ctx, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		_, err := service.AuthUser(ctx, requests.UserAuth{Username: "john", Password: "wrong"}, "192.168.1.1")
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+
+		_, err = service.AuthUser(ctx, requests.UserAuth{Username: "john", Password: "secret"}, "192.168.1.1")
+		assert.NoError(t, err)
+
+		record, err := service.accounts.Get(ctx, "id")
+		assert.NoError(t, err)
+		assert.Zero(t, record.Failures)
+
+		// The source IP's failures are kept.
+		record, err = service.sources.Get(ctx, "192.168.1.1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, record.Failures)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("blocks the source IP after its free failures", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		mock.On("UserGetByUsername", ctx, testifymock.Anything).Return(nil, store.ErrNoDocuments).Times(lockout.SourcePolicy.FreeFailures + 2)
+		mock.On("UserGetByEmail", ctx, testifymock.Anything).Return(nil, store.ErrNoDocuments).Times(lockout.SourcePolicy.FreeFailures + 2)
+		// The unknown logins' failures are recorded only for the source IP, then the blocked source IP is checked.
+		clockMock.On("Now").Return(now).Times(lockout.SourcePolicy.FreeFailures + 2)
+
+		// Each attempt uses another account, so only the source IP is blocked.
+		for i := 0; i <= lockout.SourcePolicy.FreeFailures; i++ {
+			_, err := service.AuthUser(ctx, requests.UserAuth{Username: "user" + string(rune('a'+i)), Password: "wrong"}, "192.168.1.1")
+			assert.Equal(t, NewErrAuthUnathorized(nil), err)
+		}
+
+		_, err := service.AuthUser(ctx, requests.UserAuth{Username: "john", Password: "secret"}, "192.168.1.1")
+		assert.Equal(t, lockout.SourcePolicy.BaseDelay, retryAfter(t, err).Round(lockout.SourcePolicy.BaseDelay))
+
+		// The unknown logins are not accounts whose failures are kept.
+		record, err := service.accounts.Get(ctx, "usera")
+		assert.NoError(t, err)
+		assert.Zero(t, record.Failures)
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestAuthMFALockout(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}, MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}
+
+	mock := new(mocks.Store)
+	service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+	mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Times(lockout.AccountPolicy.FreeFailures + 1)
+	// Each code is checked and its failure recorded for the user and the source IP, then the blocked user is checked.
+	clockMock.On("Now").Return(now).Times(3*(lockout.AccountPolicy.FreeFailures+1) + 1)
+
+	token := mustMFAToken("id", false, now.Add(time.Minute))
+
+	for i := 0; i <= lockout.AccountPolicy.FreeFailures; i++ {
+		_, err := service.AuthMFA(ctx, requests.AuthMFA{Token: token, Code: "000000"}, "192.168.1.1")
+		assert.ErrorIs(t, err, ErrMFACodeInvalid)
+	}
+
+	// Even the right code is refused while the user is blocked.
+	_, err := service.AuthMFA(ctx, requests.AuthMFA{Token: token, Code: mustCode(mfaSecret)}, "192.168.1.2")
+	assert.Equal(t, lockout.AccountPolicy.BaseDelay, retryAfter(t, err))
+
+	// The password's failures are counted apart, so a right password does not reset the second factor's ones.
+	record, err := service.accounts.Get(ctx, "id")
+	assert.NoError(t, err)
+	assert.Zero(t, record.Failures)
+
+	mock.AssertExpectations(t)
+}
+
+func TestValidateMFALockout(t *testing.T) {
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}
+	user := &models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}
+
+	mock := new(mocks.Store)
+	service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+	mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Times(lockout.AccountPolicy.FreeFailures + 2)
+	mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Times(lockout.AccountPolicy.FreeFailures + 2)
+	// Each code is checked and its failure recorded, then the blocked user is checked.
+	clockMock.On("Now").Return(now).Times(2*(lockout.AccountPolicy.FreeFailures+1) + 1)
+
+	for i := 0; i <= lockout.AccountPolicy.FreeFailures; i++ {
+		id, err := service.ValidateMFA(ctx, requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: "000000"})
+		assert.NoError(t, err)
+		assert.Empty(t, id)
+	}
+
+	// Even the right code is refused while the user is blocked.
+	_, err := service.ValidateMFA(ctx, requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: mustCode(mfaSecret)})
+	assert.Equal(t, lockout.AccountPolicy.BaseDelay, retryAfter(t, err))
+
+	mock.AssertExpectations(t)
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/membership"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// syncMemberships adds the user to the namespaces mapped to its identity provider's groups, with the mapped role, and
+// removes it from the mapped namespaces whose groups it no longer belongs to. The namespace's owner is never changed.
+//
+// A failure on a namespace does not block the login, as the user may still access the others.
+func (s *service) syncMemberships(ctx context.Context, user *models.User, mappings membership.Mappings, groups []string) {
+	for tenant, role := range mappings.Memberships(groups) {
+		logger := logrus.WithFields(logrus.Fields{
+			"user":   user.ID,
+			"tenant": tenant,
+		})
+
+		namespace, err := s.store.NamespaceGet(ctx, tenant)
+		if err != nil {
+			logger.WithError(err).Warn("failed to get the namespace mapped to a group")
+
+			continue
+		}
+
+		member, ok := guard.CheckMember(namespace, user.ID)
+
+		switch {
+		case ok && member.Role == guard.RoleOwner:
+		case !ok && role != "":
+			_, err = s.store.NamespaceAddMember(ctx, tenant, user.ID, role)
+		case ok && role == "":
+			_, err = s.store.NamespaceRemoveMember(ctx, tenant, user.ID)
+		case ok && member.Role != role:
+			err = s.store.NamespaceEditMember(ctx, tenant, user.ID, role)
+		}
+
+		if err != nil {
+			logger.WithError(err).Warn("failed to synchronize the namespace's membership from the groups")
+		}
+	}
+}
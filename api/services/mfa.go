@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/totp"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MFAIssuer is the issuer shown by the authenticator apps.
+	MFAIssuer = "ShellHub"
+	// MFATokenExpiry is the time that the user has to send the second factor after the password is verified.
+	MFATokenExpiry = 5 * time.Minute
+	// MFARecoveryCodes is the number of recovery codes generated on each enrollment.
+	MFARecoveryCodes = 10
+	// MFATokenMaxAttempts is the number of wrong codes sent with a MFA token before it is invalidated.
+	MFATokenMaxAttempts = 5
+)
+
+// AuthRequestMFAToken is the type of the token issued while a login waits for its second factor.
+const AuthRequestMFAToken = "mfa"
+
+type MFAService interface {
+	// EnrollMFA generates a new TOTP secret and recovery codes for the user. MFA is only enabled after a code generated
+	// from the secret is verified by EnableMFA.
+	EnrollMFA(ctx context.Context, id string) (*models.UserMFAEnrollment, error)
+	// EnableMFA enables the user's MFA when code is valid to the enrolled secret.
+	EnableMFA(ctx context.Context, id string, code string) error
+	// DisableMFA disables the user's MFA when either code or recoveryCode is valid.
+	DisableMFA(ctx context.Context, id string, code string, recoveryCode string) error
+	// AuthMFA completes a login started by AuthUser, issuing the user's token when the second factor is valid. The
+	// failed attempts are limited per user and source IP, like the passwords', and per MFA token, which is also spent
+	// by a successful login.
+	AuthMFA(ctx context.Context, req requests.AuthMFA, sourceIP string) (*models.UserAuthResponse, error)
+	// AuthMFAEnroll enrolls MFA during a login of a user who is required to have it, but has not enabled it yet.
+	AuthMFAEnroll(ctx context.Context, token string) (*models.UserMFAEnrollment, error)
+	// EditNamespaceRequireMFA defines if the namespace's members must log in with MFA and if a TOTP code is required to
+	// connect to the namespace's devices through SSH.
+	EditNamespaceRequireMFA(ctx context.Context, tenantID string, require, requireSSH bool) error
+	// ValidateMFA checks the TOTP code of a namespace's member, identified by its ID or, when empty, by its username or
+	// e-mail. It is used by the SSH server to challenge the connections that require a second factor. The failed attempts
+	// are limited per user, like the logins' second factor, and each code is accepted only once.
+	ValidateMFA(ctx context.Context, req requests.MFAValidate) (string, error)
+}
+
+func (s *service) EnrollMFA(ctx context.Context, id string) (*models.UserMFAEnrollment, error) {
+	user, _, err := s.store.UserGetByID(ctx, id, false)
+	if err != nil {
+		return nil, NewErrUserNotFound(id, err)
+	}
+
+	if user.MFA.Enabled {
+		return nil, NewErrMFAEnabled(id, nil)
+	}
+
+	return s.enrollMFA(ctx, user)
+}
+
+func (s *service) EnableMFA(ctx context.Context, id string, code string) error {
+	user, _, err := s.store.UserGetByID(ctx, id, false)
+	if err != nil {
+		return NewErrUserNotFound(id, err)
+	}
+
+	if user.MFA.Enabled {
+		return NewErrMFAEnabled(id, nil)
+	}
+
+	return s.enableMFA(ctx, user, code)
+}
+
+func (s *service) DisableMFA(ctx context.Context, id string, code string, recoveryCode string) error {
+	user, _, err := s.store.UserGetByID(ctx, id, false)
+	if err != nil {
+		return NewErrUserNotFound(id, err)
+	}
+
+	if !user.MFA.Enabled {
+		return NewErrMFANotEnrolled(id, nil)
+	}
+
+	if err := s.verifyMFA(ctx, user, code, recoveryCode); err != nil {
+		return err
+	}
+
+	if err := s.store.UserUpdateMFA(ctx, user.ID, models.UserMFA{}); err != nil {
+		return NewErrUserUpdate(user, err)
+	}
+
+	return nil
+}
+
+func (s *service) AuthMFA(ctx context.Context, req requests.AuthMFA, sourceIP string) (*models.UserAuthResponse, error) {
+	claims, err := s.parseMFAToken(req.Token)
+	if err != nil {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	if s.mfaTokenSpent(ctx, req.Token) {
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	account := mfaAccount(claims.ID)
+	if err := s.checkAttempts(ctx, account, sourceIP); err != nil {
+		return nil, err
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, claims.ID, false)
+	if err != nil {
+		return nil, NewErrUserNotFound(claims.ID, err)
+	}
+
+	switch {
+	case user.MFA.Enabled:
+		err = s.verifyMFA(ctx, user, req.Code, req.RecoveryCode)
+	case claims.Enrollment:
+		// The login of a user who must enroll MFA is completed by the first code generated from the new secret.
+		err = s.enableMFA(ctx, user, req.Code)
+	default:
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	switch {
+	case err == nil:
+		s.resetAttempts(ctx, account)
+		s.spendMFAToken(ctx, req.Token)
+	case errors.Is(err, ErrMFACodeInvalid):
+		s.failAttempt(ctx, account, sourceIP)
+		s.failMFAToken(ctx, req.Token)
+
+		return nil, err
+	default:
+		return nil, err
+	}
+
+	namespace, _ := s.store.NamespaceGetFirst(ctx, user.ID)
+
+	return s.authUserToken(ctx, user, namespace)
+}
+
+func (s *service) AuthMFAEnroll(ctx context.Context, token string) (*models.UserMFAEnrollment, error) {
+	claims, err := s.parseMFAToken(token)
+	if err != nil || !claims.Enrollment {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	return s.EnrollMFA(ctx, claims.ID)
+}
+
+func (s *service) EditNamespaceRequireMFA(ctx context.Context, tenantID string, require, requireSSH bool) error {
+	if err := s.store.NamespaceSetRequireMFA(ctx, tenantID, require, requireSSH); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrNamespaceNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ValidateMFA checks the TOTP code of a namespace's member, identified by its ID or by its username or e-mail. It
+// returns the ID of the member when the code is valid, or an empty string otherwise, so the caller can bind the
+// connection to the member who answered the challenge.
+func (s *service) ValidateMFA(ctx context.Context, req requests.MFAValidate) (string, error) {
+	namespace, err := s.store.NamespaceGet(ctx, req.TenantID)
+	if err != nil {
+		return "", NewErrNamespaceNotFound(req.TenantID, err)
+	}
+
+	var user *models.User
+	if req.UserID != "" {
+		user, _, err = s.store.UserGetByID(ctx, req.UserID, false)
+	} else {
+		user, err = s.store.UserGetByUsername(ctx, strings.ToLower(req.Username))
+		if err != nil {
+			user, err = s.store.UserGetByEmail(ctx, strings.ToLower(req.Username))
+		}
+	}
+
+	// An unknown user is not distinguished from a wrong code, so the challenge cannot be used to enumerate users.
+	if err != nil || user == nil {
+		return "", nil
+	}
+
+	if _, ok := guard.CheckMember(namespace, user.ID); !ok || !user.MFA.Enabled {
+		return "", nil
+	}
+
+	// The failures are shared with the logins' second factor, so neither the SSH challenge nor the other namespaces of
+	// the user can be used to keep guessing the codes.
+	account := mfaAccount(user.ID)
+	if err := s.checkAttempts(ctx, account, ""); err != nil {
+		return "", err
+	}
+
+	if err := s.acceptTOTP(ctx, user, req.Code); err != nil {
+		if errors.Is(err, ErrMFACodeInvalid) {
+			s.failAttempt(ctx, account, "")
+
+			return "", nil
+		}
+
+		return "", err
+	}
+
+	s.resetAttempts(ctx, account)
+
+	return user.ID, nil
+}
+
+// mfaAccount is the account whose second factor attempts are limited. It is not the one of the password's attempts, so
+// a login with the right password does not reset the second factor's failures.
+func mfaAccount(id string) string {
+	return "mfa/" + id
+}
+
+// mfaTokenKey is the cache key of the MFA token's state. It is derived from the token's hash, so the token itself is not
+// stored.
+func mfaTokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return strings.Join([]string{"mfa_token", hex.EncodeToString(sum[:])}, "/")
+}
+
+// mfaTokenSpent checks if the MFA token has already completed a login or was invalidated by its failed attempts.
+func (s *service) mfaTokenSpent(ctx context.Context, token string) bool {
+	var spent bool
+	if err := s.cache.Get(ctx, mfaTokenKey(token), &spent); err != nil {
+		return false
+	}
+
+	return spent
+}
+
+// spendMFAToken invalidates the MFA token until it expires.
+func (s *service) spendMFAToken(ctx context.Context, token string) {
+	if err := s.cache.Set(ctx, mfaTokenKey(token), true, MFATokenExpiry); err != nil {
+		logrus.WithError(err).Warn("failed to invalidate the mfa token")
+	}
+}
+
+// failMFAToken records a wrong code sent with the MFA token, invalidating it after MFATokenMaxAttempts failures.
+func (s *service) failMFAToken(ctx context.Context, token string) {
+	failures, err := s.cache.Incr(ctx, mfaTokenKey(token)+"/failures", MFATokenExpiry)
+	if err != nil {
+		logrus.WithError(err).Warn("failed to record the failed attempt of the mfa token")
+
+		return
+	}
+
+	if failures >= MFATokenMaxAttempts {
+		s.spendMFAToken(ctx, token)
+	}
+}
+
+// requiresMFA checks if the user must send a second factor to log in the namespace.
+func requiresMFA(user *models.User, namespace *models.Namespace) bool {
+	if user.MFA.Enabled {
+		return true
+	}
+
+	return namespace != nil && namespace.Settings != nil && namespace.Settings.RequireMFA
+}
+
+// authMFAToken issues the short-lived token that allows the user to send the second factor of a login.
+func (s *service) authMFAToken(user *models.User) (*models.UserAuthResponse, error) {
+	enrollment := !user.MFA.Enabled
+
+	token, err := s.keys.Sign(models.UserMFAClaims{
+		ID:         user.ID,
+		Enrollment: enrollment,
+		AuthClaims: models.AuthClaims{
+			Claims: AuthRequestMFAToken,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(clock.Now().Add(MFATokenExpiry)),
+		},
+	})
+	if err != nil {
+		return nil, NewErrTokenSigned(err)
+	}
+
+	return &models.UserAuthResponse{
+		ID:            user.ID,
+		User:          user.Username,
+		MFAToken:      token,
+		MFAEnrollment: enrollment,
+	}, nil
+}
+
+func (s *service) parseMFAToken(token string) (*models.UserMFAClaims, error) {
+	claims := new(models.UserMFAClaims)
+	if _, err := jwt.ParseWithClaims(token, claims, s.keys.Keyfunc); err != nil {
+		return nil, err
+	}
+
+	if claims.Claims != AuthRequestMFAToken || claims.ID == "" {
+		return nil, errors.New("token is not a mfa token")
+	}
+
+	return claims, nil
+}
+
+func (s *service) enrollMFA(ctx context.Context, user *models.User) (*models.UserMFAEnrollment, error) {
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, MFARecoveryCodes)
+	hashes := make([]string, MFARecoveryCodes)
+	for i := range codes {
+		if codes[i], err = generateRecoveryCode(); err != nil {
+			return nil, err
+		}
+
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.store.UserUpdateMFA(ctx, user.ID, models.UserMFA{Secret: secret, RecoveryCodes: hashes}); err != nil {
+		return nil, NewErrUserUpdate(user, err)
+	}
+
+	return &models.UserMFAEnrollment{
+		Secret:        secret,
+		URI:           totp.URI(MFAIssuer, user.Username, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *service) enableMFA(ctx context.Context, user *models.User, code string) error {
+	if user.MFA.Secret == "" {
+		return NewErrMFANotEnrolled(user.ID, nil)
+	}
+
+	step, ok := totp.ValidateStep(user.MFA.Secret, code, clock.Now())
+	if !ok {
+		return NewErrMFACodeInvalid(nil)
+	}
+
+	mfa := user.MFA
+	mfa.Enabled = true
+	mfa.LastStep = step
+
+	if err := s.store.UserUpdateMFA(ctx, user.ID, mfa); err != nil {
+		return NewErrUserUpdate(user, err)
+	}
+
+	user.MFA = mfa
+
+	return nil
+}
+
+// verifyMFA checks the user's TOTP code or, when it is empty, consumes the recovery code.
+func (s *service) verifyMFA(ctx context.Context, user *models.User, code string, recoveryCode string) error {
+	if code != "" {
+		return s.acceptTOTP(ctx, user, code)
+	}
+
+	if recoveryCode == "" {
+		return NewErrMFACodeInvalid(nil)
+	}
+
+	if err := s.store.UserDeleteRecoveryCode(ctx, user.ID, hashRecoveryCode(recoveryCode)); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrMFACodeInvalid(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// acceptTOTP checks the user's TOTP code and records its time step, so each code is accepted only once, even though it
+// is valid during the whole skew.
+func (s *service) acceptTOTP(ctx context.Context, user *models.User, code string) error {
+	step, ok := totp.ValidateStep(user.MFA.Secret, code, clock.Now())
+	if !ok || step <= user.MFA.LastStep {
+		return NewErrMFACodeInvalid(nil)
+	}
+
+	if err := s.store.UserSetMFAStep(ctx, user.ID, step); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrMFACodeInvalid(err)
+		}
+
+		return err
+	}
+
+	user.MFA.LastStep = step
+
+	return nil
+}
+
+// generateRecoveryCode generates a random recovery code formatted as two groups of five characters.
+func generateRecoveryCode() (string, error) {
+	data := make([]byte, 6)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(data))
+
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode hashes a recovery code to be stored, ignoring its case and surrounding spaces.
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,552 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/totp"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+const mfaSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// mustCode generates the TOTP code of secret at now, failing the tests when it is not possible.
+func mustCode(secret string) string {
+	code, err := totp.Code(secret, now)
+	if err != nil {
+		panic(err)
+	}
+
+	return code
+}
+
+// mustMFAToken signs a MFA token to the user, failing the tests when it is not possible.
+func mustMFAToken(id string, enrollment bool, expiresAt time.Time) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, models.UserMFAClaims{
+		ID:         id,
+		Enrollment: enrollment,
+		AuthClaims: models.AuthClaims{
+			Claims: AuthRequestMFAToken,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}).SignedString(privateKey)
+	if err != nil {
+		panic(err)
+	}
+
+	return token
+}
+
+func TestEnrollMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}}
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when user was not found",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(nil, 0, errors.New("error")).Once()
+			},
+			expected: NewErrUserNotFound("id", errors.New("error")),
+		},
+		{
+			description: "fails when MFA is already enabled",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: models.UserMFA{Enabled: true}}, 0, nil).Once()
+			},
+			expected: NewErrMFAEnabled("id", nil),
+		},
+		{
+			description: "success to enroll MFA",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+				mock.On("UserUpdateMFA", ctx, "id", testifymock.MatchedBy(func(mfa models.UserMFA) bool {
+					return !mfa.Enabled && mfa.Secret != "" && len(mfa.RecoveryCodes) == MFARecoveryCodes
+				})).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			enrollment, err := service.EnrollMFA(ctx, "id")
+			assert.Equal(t, tc.expected, err)
+
+			if err == nil {
+				assert.NotEmpty(t, enrollment.Secret)
+				assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/ShellHub:john?"))
+				assert.Len(t, enrollment.RecoveryCodes, MFARecoveryCodes)
+			}
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestEnableMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	enrolled := models.UserMFA{Secret: mfaSecret, RecoveryCodes: []string{hashRecoveryCode("abcde-fghij")}}
+
+	cases := []struct {
+		description   string
+		code          string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when MFA was not enrolled",
+			code:        "123456",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id"}, 0, nil).Once()
+			},
+			expected: NewErrMFANotEnrolled("id", nil),
+		},
+		{
+			description: "fails when the code is invalid",
+			code:        "abcdef",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: enrolled}, 0, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: NewErrMFACodeInvalid(nil),
+		},
+		{
+			description: "success to enable MFA",
+			code:        mustCode(mfaSecret),
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: enrolled}, 0, nil).Once()
+				clockMock.On("Now").Return(now).Once()
+
+				enabled := enrolled
+				enabled.Enabled = true
+				enabled.LastStep = totp.Step(now)
+				mock.On("UserUpdateMFA", ctx, "id", enabled).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.Equal(t, tc.expected, service.EnableMFA(ctx, "id", tc.code))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestDisableMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	enabled := &models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret, RecoveryCodes: []string{hashRecoveryCode("abcde-fghij")}}}
+
+	cases := []struct {
+		description   string
+		recoveryCode  string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description:  "fails when MFA is not enabled",
+			recoveryCode: "abcde-fghij",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id"}, 0, nil).Once()
+			},
+			expected: NewErrMFANotEnrolled("id", nil),
+		},
+		{
+			description:  "fails when the recovery code was already used",
+			recoveryCode: "klmno-pqrst",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(enabled, 0, nil).Once()
+				mock.On("UserDeleteRecoveryCode", ctx, "id", hashRecoveryCode("klmno-pqrst")).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrMFACodeInvalid(store.ErrNoDocuments),
+		},
+		{
+			description:  "success to disable MFA with a recovery code",
+			recoveryCode: " ABCDE-FGHIJ ",
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(enabled, 0, nil).Once()
+				mock.On("UserDeleteRecoveryCode", ctx, "id", hashRecoveryCode("abcde-fghij")).Return(nil).Once()
+				mock.On("UserUpdateMFA", ctx, "id", models.UserMFA{}).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.Equal(t, tc.expected, service.DisableMFA(ctx, "id", "", tc.recoveryCode))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members:  []models.Member{{ID: "id", Role: guard.RoleOwner}},
+	}
+
+	cases := []struct {
+		description   string
+		req           requests.AuthMFA
+		requiredMocks func()
+		expected      *models.UserAuthResponse
+		expectedErr   error
+	}{
+		{
+			description:   "fails when the token has expired",
+			req:           requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(-time.Minute)), Code: "123456"},
+			requiredMocks: func() {},
+			expectedErr:   ErrAuthUnathorized,
+		},
+		{
+			description: "fails when the code is invalid",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(time.Minute)), Code: "abcdef"},
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}, 0, nil).Once()
+				// The code is checked, then the failed attempt is recorded.
+				clockMock.On("Now").Return(now).Twice()
+			},
+			expectedErr: ErrMFACodeInvalid,
+		},
+		{
+			description: "fails when the code was already used",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(time.Minute)), Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				mfa := models.UserMFA{Enabled: true, Secret: mfaSecret, LastStep: totp.Step(now)}
+
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: mfa}, 0, nil).Once()
+				clockMock.On("Now").Return(now).Twice()
+			},
+			expectedErr: ErrMFACodeInvalid,
+		},
+		{
+			description: "fails when the code was used by a concurrent login",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(time.Minute)), Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}, 0, nil).Once()
+				mock.On("UserSetMFAStep", ctx, "id", totp.Step(now)).Return(store.ErrNoDocuments).Once()
+				clockMock.On("Now").Return(now).Twice()
+			},
+			expectedErr: ErrMFACodeInvalid,
+		},
+		{
+			description: "fails when MFA was disabled after the login started",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(time.Minute)), Code: "123456"},
+			requiredMocks: func() {
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id"}, 0, nil).Once()
+			},
+			expectedErr: ErrAuthUnathorized,
+		},
+		{
+			description: "success to log in with the TOTP code",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", false, now.Add(time.Minute)), Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}, MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}
+
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+				mock.On("UserSetMFAStep", ctx, "id", totp.Step(now)).Return(nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				clockMock.On("Now").Return(now).Times(3)
+
+				updated := *user
+				updated.MFA.LastStep = totp.Step(now)
+				updated.LastLogin = now
+				mock.On("UserUpdateData", ctx, "id", updated).Return(nil).Once()
+				mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+			},
+			expected: &models.UserAuthResponse{ID: "id", User: "john", Tenant: "tenant", Role: guard.RoleOwner},
+		},
+		{
+			description: "success to log in while enrolling MFA",
+			req:         requests.AuthMFA{Token: mustMFAToken("id", true, now.Add(time.Minute)), Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}, MFA: models.UserMFA{Secret: mfaSecret}}
+
+				mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+				mock.On("UserUpdateMFA", ctx, "id", models.UserMFA{Enabled: true, Secret: mfaSecret, LastStep: totp.Step(now)}).Return(nil).Once()
+				mock.On("NamespaceGetFirst", ctx, "id").Return(namespace, nil).Once()
+				clockMock.On("Now").Return(now).Times(3)
+
+				updated := *user
+				updated.MFA.Enabled = true
+				updated.MFA.LastStep = totp.Step(now)
+				updated.LastLogin = now
+				mock.On("UserUpdateData", ctx, "id", updated).Return(nil).Once()
+				mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+			},
+			expected: &models.UserAuthResponse{ID: "id", User: "john", Tenant: "tenant", Role: guard.RoleOwner},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			res, err := service.AuthMFA(ctx, tc.req, "")
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotEmpty(t, res.Token)
+			assert.NotEmpty(t, res.RefreshToken)
+			res.Token = ""
+			res.RefreshToken = ""
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestAuthMFATokenSpent(t *testing.T) {
+	ctx := context.TODO()
+
+	token := mustMFAToken("id", false, now.Add(time.Minute))
+
+	t.Run("the token is spent by a successful login", func(t *testing.T) {
+		user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}, MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}
+
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+		mock.On("UserSetMFAStep", ctx, "id", totp.Step(now)).Return(nil).Once()
+		mock.On("NamespaceGetFirst", ctx, "id").Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+		mock.On("UserUpdateData", ctx, "id", testifymock.AnythingOfType("models.User")).Return(nil).Once()
+		mock.On("UserSessionCreate", ctx, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+		clockMock.On("Now").Return(now).Times(3)
+
+		_, err := service.AuthMFA(ctx, requests.AuthMFA{Token: token, Code: mustCode(mfaSecret)}, "")
+		assert.NoError(t, err)
+
+		_, err = service.AuthMFA(ctx, requests.AuthMFA{Token: token, Code: mustCode(mfaSecret)}, "")
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("the token is invalidated by its failed attempts", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil)
+
+		for i := 1; i < MFATokenMaxAttempts; i++ {
+			service.failMFAToken(ctx, token)
+		}
+
+		assert.False(t, service.mfaTokenSpent(ctx, token))
+
+		service.failMFAToken(ctx, token)
+
+		// Even the right code is refused once the token is invalidated.
+		_, err := service.AuthMFA(ctx, requests.AuthMFA{Token: token, Code: mustCode(mfaSecret)}, "")
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestAuthMFAEnroll(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	// A token issued to a user who has MFA enabled cannot be used to enroll it again.
+	_, err := service.AuthMFAEnroll(ctx, mustMFAToken("id", false, now.Add(time.Minute)))
+	assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+	mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id", UserData: models.UserData{Username: "john"}}, 0, nil).Once()
+	mock.On("UserUpdateMFA", ctx, "id", testifymock.AnythingOfType("models.UserMFA")).Return(nil).Once()
+
+	enrollment, err := service.AuthMFAEnroll(ctx, mustMFAToken("id", true, now.Add(time.Minute)))
+	assert.NoError(t, err)
+	assert.Len(t, enrollment.RecoveryCodes, MFARecoveryCodes)
+
+	mock.AssertExpectations(t)
+}
+
+func TestEditNamespaceRequireMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		description   string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when namespace was not found",
+			requiredMocks: func() {
+				mock.On("NamespaceSetRequireMFA", ctx, "xxxx", true, false).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrNamespaceNotFound("xxxx", store.ErrNoDocuments),
+		},
+		{
+			description: "success to require MFA",
+			requiredMocks: func() {
+				mock.On("NamespaceSetRequireMFA", ctx, "xxxx", true, false).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			assert.Equal(t, tc.expected, service.EditNamespaceRequireMFA(ctx, "xxxx", true, false))
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
+func TestValidateMFA(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members:  []models.Member{{ID: "id", Role: guard.RoleOperator}},
+	}
+
+	enabled := &models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret}}
+
+	type Expected struct {
+		id  string
+		err error
+	}
+
+	cases := []struct {
+		description   string
+		req           requests.MFAValidate
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace was not found",
+			req:         requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: "123456"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{"", NewErrNamespaceNotFound("tenant", errors.New("error"))},
+		},
+		{
+			description: "rejects when user was not found",
+			req:         requests.MFAValidate{TenantID: "tenant", Username: "nobody", Code: "123456"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByUsername", ctx, "nobody").Return(nil, errors.New("error")).Once()
+				mock.On("UserGetByEmail", ctx, "nobody").Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{"", nil},
+		},
+		{
+			description: "rejects when user is not a member of the namespace",
+			req:         requests.MFAValidate{TenantID: "tenant", UserID: "other", Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByID", ctx, "other", false).Return(&models.User{ID: "other", MFA: enabled.MFA}, 0, nil).Once()
+			},
+			expected: Expected{"", nil},
+		},
+		{
+			description: "rejects when user has not enabled MFA",
+			req:         requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: "123456"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByID", ctx, "id", false).Return(&models.User{ID: "id"}, 0, nil).Once()
+			},
+			expected: Expected{"", nil},
+		},
+		{
+			description: "accepts the code of a member found by the e-mail",
+			req:         requests.MFAValidate{TenantID: "tenant", Username: "John@Example.com", Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByUsername", ctx, "john@example.com").Return(nil, errors.New("error")).Once()
+				mock.On("UserGetByEmail", ctx, "john@example.com").Return(enabled, nil).Once()
+				mock.On("UserSetMFAStep", ctx, "id", totp.Step(now)).Return(nil).Once()
+				clockMock.On("Now").Return(now).Once()
+			},
+			expected: Expected{"id", nil},
+		},
+		{
+			description: "rejects a code that was already used",
+			req:         requests.MFAValidate{TenantID: "tenant", UserID: "id", Code: mustCode(mfaSecret)},
+			requiredMocks: func() {
+				used := &models.User{ID: "id", MFA: models.UserMFA{Enabled: true, Secret: mfaSecret, LastStep: totp.Step(now)}}
+
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("UserGetByID", ctx, "id", false).Return(used, 0, nil).Once()
+				// The code is checked, then the failed attempt is recorded.
+				clockMock.On("Now").Return(now).Twice()
+			},
+			expected: Expected{"", nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			id, err := service.ValidateMFA(ctx, tc.req)
+			assert.Equal(t, tc.expected, Expected{id, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
@@ -5,7 +5,9 @@ package mocks
 import (
 	context "context"
 
+	keyring "github.com/shellhub-io/shellhub/api/pkg/keyring"
 	internalclient "github.com/shellhub-io/shellhub/pkg/api/internalclient"
+
 	mock "github.com/stretchr/testify/mock"
 
 	models "github.com/shellhub-io/shellhub/pkg/models"
@@ -16,9 +18,9 @@ import (
 
 	responses "github.com/shellhub-io/shellhub/pkg/api/responses"
 
-	rsa "crypto/rsa"
-
 	template "text/template"
+
+	time "time"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -26,6 +28,20 @@ type Service struct {
 	mock.Mock
 }
 
+// AcceptNamespaceTransfer provides a mock function with given fields: ctx, tenantID, userID
+func (_m *Service) AcceptNamespaceTransfer(ctx context.Context, tenantID string, userID string) error {
+	ret := _m.Called(ctx, tenantID, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AddNamespaceUser provides a mock function with given fields: ctx, memberUsername, memberRole, tenantID, userID
 func (_m *Service) AddNamespaceUser(ctx context.Context, memberUsername string, memberRole string, tenantID string, userID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, memberUsername, memberRole, tenantID, userID)
@@ -106,6 +122,32 @@ func (_m *Service) AuthDevice(ctx context.Context, req requests.DeviceAuth, remo
 	return r0, r1
 }
 
+// AuthDeviceChallenge provides a mock function with given fields: ctx
+func (_m *Service) AuthDeviceChallenge(ctx context.Context) (*models.DeviceChallenge, error) {
+	ret := _m.Called(ctx)
+
+	var r0 *models.DeviceChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*models.DeviceChallenge, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *models.DeviceChallenge); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceChallenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AuthGetToken provides a mock function with given fields: ctx, tenant
 func (_m *Service) AuthGetToken(ctx context.Context, tenant string) (*models.UserAuthResponse, error) {
 	ret := _m.Called(ctx, tenant)
@@ -156,24 +198,176 @@ func (_m *Service) AuthIsCacheToken(ctx context.Context, tenant string, id strin
 	return r0, r1
 }
 
-// AuthPublicKey provides a mock function with given fields: ctx, req
-func (_m *Service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth) (*models.PublicKeyAuthResponse, error) {
+// AuthIsDeviceTokenActive provides a mock function with given fields: ctx, uid, issuedAt
+func (_m *Service) AuthIsDeviceTokenActive(ctx context.Context, uid string, issuedAt time.Time) (bool, error) {
+	ret := _m.Called(ctx, uid, issuedAt)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, uid, issuedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, uid, issuedAt)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, uid, issuedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthIsUserSessionActive provides a mock function with given fields: ctx, id
+func (_m *Service) AuthIsUserSessionActive(ctx context.Context, id string) (bool, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthMFA provides a mock function with given fields: ctx, req, sourceIP
+func (_m *Service) AuthMFA(ctx context.Context, req requests.AuthMFA, sourceIP string) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, req, sourceIP)
+
+	var r0 *models.UserAuthResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthMFA, string) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, req, sourceIP)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthMFA, string) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, req, sourceIP)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserAuthResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.AuthMFA, string) error); ok {
+		r1 = rf(ctx, req, sourceIP)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthMFAEnroll provides a mock function with given fields: ctx, token
+func (_m *Service) AuthMFAEnroll(ctx context.Context, token string) (*models.UserMFAEnrollment, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 *models.UserMFAEnrollment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserMFAEnrollment, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserMFAEnrollment); ok {
+		r0 = rf(ctx, token)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserMFAEnrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthOIDC provides a mock function with given fields: ctx, req
+func (_m *Service) AuthOIDC(ctx context.Context, req requests.AuthOIDC) (*models.UserAuthResponse, error) {
 	ret := _m.Called(ctx, req)
 
-	var r0 *models.PublicKeyAuthResponse
+	var r0 *models.UserAuthResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyAuth) (*models.PublicKeyAuthResponse, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthOIDC) (*models.UserAuthResponse, error)); ok {
 		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyAuth) *models.PublicKeyAuthResponse); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthOIDC) *models.UserAuthResponse); ok {
 		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserAuthResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.AuthOIDC) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthPublicKey provides a mock function with given fields: ctx, req, sourceIP
+func (_m *Service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth, sourceIP string) (*models.PublicKeyAuthResponse, error) {
+	ret := _m.Called(ctx, req, sourceIP)
+
+	var r0 *models.PublicKeyAuthResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyAuth, string) (*models.PublicKeyAuthResponse, error)); ok {
+		return rf(ctx, req, sourceIP)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.PublicKeyAuth, string) *models.PublicKeyAuthResponse); ok {
+		r0 = rf(ctx, req, sourceIP)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.PublicKeyAuthResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, requests.PublicKeyAuth) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, requests.PublicKeyAuth, string) error); ok {
+		r1 = rf(ctx, req, sourceIP)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthRefresh provides a mock function with given fields: ctx, req
+func (_m *Service) AuthRefresh(ctx context.Context, req requests.AuthRefresh) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 *models.UserAuthResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthRefresh) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.AuthRefresh) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserAuthResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.AuthRefresh) error); ok {
 		r1 = rf(ctx, req)
 	} else {
 		r1 = ret.Error(1)
@@ -182,25 +376,25 @@ func (_m *Service) AuthPublicKey(ctx context.Context, req requests.PublicKeyAuth
 	return r0, r1
 }
 
-// AuthSwapToken provides a mock function with given fields: ctx, ID, tenant
-func (_m *Service) AuthSwapToken(ctx context.Context, ID string, tenant string) (*models.UserAuthResponse, error) {
-	ret := _m.Called(ctx, ID, tenant)
+// AuthSwapToken provides a mock function with given fields: ctx, ID, session, tenant
+func (_m *Service) AuthSwapToken(ctx context.Context, ID string, session string, tenant string) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, ID, session, tenant)
 
 	var r0 *models.UserAuthResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.UserAuthResponse, error)); ok {
-		return rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, ID, session, tenant)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.UserAuthResponse); ok {
-		r0 = rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, ID, session, tenant)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserAuthResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, ID, tenant)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, ID, session, tenant)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -222,25 +416,25 @@ func (_m *Service) AuthUncacheToken(ctx context.Context, tenant string, id strin
 	return r0
 }
 
-// AuthUser provides a mock function with given fields: ctx, req
-func (_m *Service) AuthUser(ctx context.Context, req requests.UserAuth) (*models.UserAuthResponse, error) {
-	ret := _m.Called(ctx, req)
+// AuthUser provides a mock function with given fields: ctx, req, sourceIP
+func (_m *Service) AuthUser(ctx context.Context, req requests.UserAuth, sourceIP string) (*models.UserAuthResponse, error) {
+	ret := _m.Called(ctx, req, sourceIP)
 
 	var r0 *models.UserAuthResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, requests.UserAuth) (*models.UserAuthResponse, error)); ok {
-		return rf(ctx, req)
+	if rf, ok := ret.Get(0).(func(context.Context, requests.UserAuth, string) (*models.UserAuthResponse, error)); ok {
+		return rf(ctx, req, sourceIP)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, requests.UserAuth) *models.UserAuthResponse); ok {
-		r0 = rf(ctx, req)
+	if rf, ok := ret.Get(0).(func(context.Context, requests.UserAuth, string) *models.UserAuthResponse); ok {
+		r0 = rf(ctx, req, sourceIP)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.UserAuthResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, requests.UserAuth) error); ok {
-		r1 = rf(ctx, req)
+	if rf, ok := ret.Get(1).(func(context.Context, requests.UserAuth, string) error); ok {
+		r1 = rf(ctx, req, sourceIP)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -312,6 +506,86 @@ func (_m *Service) BillingReport(_a0 internalclient.Client, _a1 string, _a2 stri
 	return r0
 }
 
+// CancelNamespaceTransfer provides a mock function with given fields: ctx, tenantID, userID
+func (_m *Service) CancelNamespaceTransfer(ctx context.Context, tenantID string, userID string) error {
+	ret := _m.Called(ctx, tenantID, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConfirmEmail provides a mock function with given fields: ctx, id, token
+func (_m *Service) ConfirmEmail(ctx context.Context, id string, token string) error {
+	ret := _m.Called(ctx, id, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAccessRequest provides a mock function with given fields: ctx, tenantID, userID, req
+func (_m *Service) CreateAccessRequest(ctx context.Context, tenantID string, userID string, req requests.AccessRequestCreate) (*models.AccessRequest, error) {
+	ret := _m.Called(ctx, tenantID, userID, req)
+
+	var r0 *models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, requests.AccessRequestCreate) (*models.AccessRequest, error)); ok {
+		return rf(ctx, tenantID, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, requests.AccessRequestCreate) *models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, requests.AccessRequestCreate) error); ok {
+		r1 = rf(ctx, tenantID, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateDeviceRekeyToken provides a mock function with given fields: ctx, tenantID, uid
+func (_m *Service) CreateDeviceRekeyToken(ctx context.Context, tenantID string, uid models.UID) (*models.DeviceRekeyToken, error) {
+	ret := _m.Called(ctx, tenantID, uid)
+
+	var r0 *models.DeviceRekeyToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID) (*models.DeviceRekeyToken, error)); ok {
+		return rf(ctx, tenantID, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID) *models.DeviceRekeyToken); ok {
+		r0 = rf(ctx, tenantID, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceRekeyToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UID) error); ok {
+		r1 = rf(ctx, tenantID, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateDeviceTag provides a mock function with given fields: ctx, uid, tag
 func (_m *Service) CreateDeviceTag(ctx context.Context, uid models.UID, tag string) error {
 	ret := _m.Called(ctx, uid, tag)
@@ -326,6 +600,32 @@ func (_m *Service) CreateDeviceTag(ctx context.Context, uid models.UID, tag stri
 	return r0
 }
 
+// CreateEnrollmentToken provides a mock function with given fields: ctx, tenantID, userID, req
+func (_m *Service) CreateEnrollmentToken(ctx context.Context, tenantID string, userID string, req requests.EnrollmentTokenCreate) (*models.EnrollmentToken, error) {
+	ret := _m.Called(ctx, tenantID, userID, req)
+
+	var r0 *models.EnrollmentToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, requests.EnrollmentTokenCreate) (*models.EnrollmentToken, error)); ok {
+		return rf(ctx, tenantID, userID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, requests.EnrollmentTokenCreate) *models.EnrollmentToken); ok {
+		r0 = rf(ctx, tenantID, userID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EnrollmentToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, requests.EnrollmentTokenCreate) error); ok {
+		r1 = rf(ctx, tenantID, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateNamespace provides a mock function with given fields: ctx, namespace, userID
 func (_m *Service) CreateNamespace(ctx context.Context, namespace requests.NamespaceCreate, userID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, namespace, userID)
@@ -430,13 +730,13 @@ func (_m *Service) CreateSession(ctx context.Context, session requests.SessionCr
 	return r0, r1
 }
 
-// DeactivateSession provides a mock function with given fields: ctx, uid
-func (_m *Service) DeactivateSession(ctx context.Context, uid models.UID) error {
-	ret := _m.Called(ctx, uid)
+// DeactivateSession provides a mock function with given fields: ctx, uid, reason
+func (_m *Service) DeactivateSession(ctx context.Context, uid models.UID, reason string) error {
+	ret := _m.Called(ctx, uid, reason)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
-		r0 = rf(ctx, uid)
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
+		r0 = rf(ctx, uid, reason)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -458,6 +758,20 @@ func (_m *Service) DeleteDevice(ctx context.Context, uid models.UID, tenant stri
 	return r0
 }
 
+// DeleteEnrollmentToken provides a mock function with given fields: ctx, tenantID, id
+func (_m *Service) DeleteEnrollmentToken(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteNamespace provides a mock function with given fields: ctx, tenantID
 func (_m *Service) DeleteNamespace(ctx context.Context, tenantID string) error {
 	ret := _m.Called(ctx, tenantID)
@@ -514,6 +828,48 @@ func (_m *Service) DeviceHeartbeat(ctx context.Context, uid models.UID) error {
 	return r0
 }
 
+// DisableMFA provides a mock function with given fields: ctx, id, code, recoveryCode
+func (_m *Service) DisableMFA(ctx context.Context, id string, code string, recoveryCode string) error {
+	ret := _m.Called(ctx, id, code, recoveryCode)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, id, code, recoveryCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditAccessApprovalTags provides a mock function with given fields: ctx, tenantID, tags
+func (_m *Service) EditAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error {
+	ret := _m.Called(ctx, tenantID, tags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, tenantID, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditAgentTagsOverwrite provides a mock function with given fields: ctx, tenantID, overwrite
+func (_m *Service) EditAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error {
+	ret := _m.Called(ctx, tenantID, overwrite)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, tenantID, overwrite)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EditNamespace provides a mock function with given fields: ctx, tenantID, name
 func (_m *Service) EditNamespace(ctx context.Context, tenantID string, name string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, tenantID, name)
@@ -540,6 +896,48 @@ func (_m *Service) EditNamespace(ctx context.Context, tenantID string, name stri
 	return r0, r1
 }
 
+// EditNamespaceMemberScope provides a mock function with given fields: ctx, tenantID, userID, memberID, scope
+func (_m *Service) EditNamespaceMemberScope(ctx context.Context, tenantID string, userID string, memberID string, scope *models.MemberScope) error {
+	ret := _m.Called(ctx, tenantID, userID, memberID, scope)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, *models.MemberScope) error); ok {
+		r0 = rf(ctx, tenantID, userID, memberID, scope)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditNamespaceRequireEnrollmentToken provides a mock function with given fields: ctx, tenantID, require
+func (_m *Service) EditNamespaceRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error {
+	ret := _m.Called(ctx, tenantID, require)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, tenantID, require)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditNamespaceRequireMFA provides a mock function with given fields: ctx, tenantID, require, requireSSH
+func (_m *Service) EditNamespaceRequireMFA(ctx context.Context, tenantID string, require bool, requireSSH bool) error {
+	ret := _m.Called(ctx, tenantID, require, requireSSH)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, bool) error); ok {
+		r0 = rf(ctx, tenantID, require, requireSSH)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // EditNamespaceUser provides a mock function with given fields: ctx, tenantID, userID, memberID, memberNewRole
 func (_m *Service) EditNamespaceUser(ctx context.Context, tenantID string, userID string, memberID string, memberNewRole string) error {
 	ret := _m.Called(ctx, tenantID, userID, memberID, memberNewRole)
@@ -548,28 +946,130 @@ func (_m *Service) EditNamespaceUser(ctx context.Context, tenantID string, userI
 	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
 		r0 = rf(ctx, tenantID, userID, memberID, memberNewRole)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditSessionPolicy provides a mock function with given fields: ctx, tenantID, idleTimeout, maxSessionDuration
+func (_m *Service) EditSessionPolicy(ctx context.Context, tenantID string, idleTimeout int, maxSessionDuration int) error {
+	ret := _m.Called(ctx, tenantID, idleTimeout, maxSessionDuration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) error); ok {
+		r0 = rf(ctx, tenantID, idleTimeout, maxSessionDuration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EditSessionRecordStatus provides a mock function with given fields: ctx, sessionRecord, tenantID
+func (_m *Service) EditSessionRecordStatus(ctx context.Context, sessionRecord bool, tenantID string) error {
+	ret := _m.Called(ctx, sessionRecord, tenantID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bool, string) error); ok {
+		r0 = rf(ctx, sessionRecord, tenantID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnableMFA provides a mock function with given fields: ctx, id, code
+func (_m *Service) EnableMFA(ctx context.Context, id string, code string) error {
+	ret := _m.Called(ctx, id, code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnrollMFA provides a mock function with given fields: ctx, id
+func (_m *Service) EnrollMFA(ctx context.Context, id string) (*models.UserMFAEnrollment, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.UserMFAEnrollment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserMFAEnrollment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserMFAEnrollment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserMFAEnrollment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EvaluateAccessRequest provides a mock function with given fields: ctx, tenantID, deviceUID, userID
+func (_m *Service) EvaluateAccessRequest(ctx context.Context, tenantID string, deviceUID string, userID string) (bool, error) {
+	ret := _m.Called(ctx, tenantID, deviceUID, userID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (bool, error)); ok {
+		return rf(ctx, tenantID, deviceUID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) bool); ok {
+		r0 = rf(ctx, tenantID, deviceUID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, deviceUID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EvaluateKeyFilter provides a mock function with given fields: ctx, key, dev
+func (_m *Service) EvaluateKeyFilter(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
+	ret := _m.Called(ctx, key, dev)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) (bool, error)); ok {
+		return rf(ctx, key, dev)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicKey, models.Device) bool); ok {
+		r0 = rf(ctx, key, dev)
+	} else {
+		r0 = ret.Get(0).(bool)
 	}
 
-	return r0
-}
-
-// EditSessionRecordStatus provides a mock function with given fields: ctx, sessionRecord, tenantID
-func (_m *Service) EditSessionRecordStatus(ctx context.Context, sessionRecord bool, tenantID string) error {
-	ret := _m.Called(ctx, sessionRecord, tenantID)
-
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, bool, string) error); ok {
-		r0 = rf(ctx, sessionRecord, tenantID)
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicKey, models.Device) error); ok {
+		r1 = rf(ctx, key, dev)
 	} else {
-		r0 = ret.Error(0)
+		r1 = ret.Error(1)
 	}
 
-	return r0
+	return r0, r1
 }
 
-// EvaluateKeyFilter provides a mock function with given fields: ctx, key, dev
-func (_m *Service) EvaluateKeyFilter(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
+// EvaluateKeyMember provides a mock function with given fields: ctx, key, dev
+func (_m *Service) EvaluateKeyMember(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
 	ret := _m.Called(ctx, key, dev)
 
 	var r0 bool
@@ -616,6 +1116,54 @@ func (_m *Service) EvaluateKeyUsername(ctx context.Context, key *models.PublicKe
 	return r0, r1
 }
 
+// EvaluateMember provides a mock function with given fields: ctx, tenantID, userID, deviceUID
+func (_m *Service) EvaluateMember(ctx context.Context, tenantID string, userID string, deviceUID string) (bool, error) {
+	ret := _m.Called(ctx, tenantID, userID, deviceUID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) (bool, error)); ok {
+		return rf(ctx, tenantID, userID, deviceUID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) bool); ok {
+		r0 = rf(ctx, tenantID, userID, deviceUID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string) error); ok {
+		r1 = rf(ctx, tenantID, userID, deviceUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpireAccessRequests provides a mock function with given fields: ctx
+func (_m *Service) ExpireAccessRequests(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetDevice provides a mock function with given fields: ctx, uid
 func (_m *Service) GetDevice(ctx context.Context, uid models.UID) (*models.Device, error) {
 	ret := _m.Called(ctx, uid)
@@ -843,6 +1391,55 @@ func (_m *Service) KeepAliveSession(ctx context.Context, uid models.UID) error {
 	return r0
 }
 
+// Keyring provides a mock function with given fields:
+func (_m *Service) Keyring() *keyring.Keyring {
+	ret := _m.Called()
+
+	var r0 *keyring.Keyring
+	if rf, ok := ret.Get(0).(func() *keyring.Keyring); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*keyring.Keyring)
+		}
+	}
+
+	return r0
+}
+
+// ListAccessRequests provides a mock function with given fields: ctx, tenantID, userID, status, pagination
+func (_m *Service) ListAccessRequests(ctx context.Context, tenantID string, userID string, status string, pagination paginator.Query) ([]models.AccessRequest, int, error) {
+	ret := _m.Called(ctx, tenantID, userID, status, pagination)
+
+	var r0 []models.AccessRequest
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, paginator.Query) ([]models.AccessRequest, int, error)); ok {
+		return rf(ctx, tenantID, userID, status, pagination)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, paginator.Query) []models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, paginator.Query) int); ok {
+		r1 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, paginator.Query) error); ok {
+		r2 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // ListDevices provides a mock function with given fields: ctx, tenant, pagination, filter, status, sort, order
 func (_m *Service) ListDevices(ctx context.Context, tenant string, pagination paginator.Query, filter []models.Filter, status models.DeviceStatus, sort string, order string) ([]models.Device, int, error) {
 	ret := _m.Called(ctx, tenant, pagination, filter, status, sort, order)
@@ -876,6 +1473,32 @@ func (_m *Service) ListDevices(ctx context.Context, tenant string, pagination pa
 	return r0, r1, r2
 }
 
+// ListEnrollmentTokens provides a mock function with given fields: ctx, tenantID
+func (_m *Service) ListEnrollmentTokens(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.EnrollmentToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.EnrollmentToken, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.EnrollmentToken); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EnrollmentToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ListNamespaces provides a mock function with given fields: ctx, pagination, filter, export
 func (_m *Service) ListNamespaces(ctx context.Context, pagination paginator.Query, filter []models.Filter, export bool) ([]models.Namespace, int, error) {
 	ret := _m.Called(ctx, pagination, filter, export)
@@ -1001,6 +1624,37 @@ func (_m *Service) LookupDevice(ctx context.Context, namespace string, name stri
 	return r0, r1
 }
 
+// OIDCAuthURL provides a mock function with given fields: ctx
+func (_m *Service) OIDCAuthURL(ctx context.Context) (string, string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 string
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context) (string, string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) string); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context) error); ok {
+		r2 = rf(ctx)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // OffineDevice provides a mock function with given fields: ctx, uid, online
 func (_m *Service) OffineDevice(ctx context.Context, uid models.UID, online bool) error {
 	ret := _m.Called(ctx, uid, online)
@@ -1015,17 +1669,15 @@ func (_m *Service) OffineDevice(ctx context.Context, uid models.UID, online bool
 	return r0
 }
 
-// PublicKey provides a mock function with given fields:
-func (_m *Service) PublicKey() *rsa.PublicKey {
-	ret := _m.Called()
+// RecoverPassword provides a mock function with given fields: ctx, username
+func (_m *Service) RecoverPassword(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
 
-	var r0 *rsa.PublicKey
-	if rf, ok := ret.Get(0).(func() *rsa.PublicKey); ok {
-		r0 = rf()
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*rsa.PublicKey)
-		}
+		r0 = ret.Error(0)
 	}
 
 	return r0
@@ -1113,6 +1765,102 @@ func (_m *Service) RenameTag(ctx context.Context, tenant string, oldTag string,
 	return r0
 }
 
+// ResetPassword provides a mock function with given fields: ctx, id, token, newPassword
+func (_m *Service) ResetPassword(ctx context.Context, id string, token string, newPassword string) error {
+	ret := _m.Called(ctx, id, token, newPassword)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, id, token, newPassword)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReviewAccessRequest provides a mock function with given fields: ctx, tenantID, reviewerID, id, approve
+func (_m *Service) ReviewAccessRequest(ctx context.Context, tenantID string, reviewerID string, id string, approve bool) (*models.AccessRequest, error) {
+	ret := _m.Called(ctx, tenantID, reviewerID, id, approve)
+
+	var r0 *models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, bool) (*models.AccessRequest, error)); ok {
+		return rf(ctx, tenantID, reviewerID, id, approve)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, bool) *models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, reviewerID, id, approve)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, bool) error); ok {
+		r1 = rf(ctx, tenantID, reviewerID, id, approve)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RekeyDevice provides a mock function with given fields: ctx, req
+func (_m *Service) RekeyDevice(ctx context.Context, req requests.DeviceRekey) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.DeviceRekey) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeDeviceKey provides a mock function with given fields: ctx, tenantID, uid
+func (_m *Service) RevokeDeviceKey(ctx context.Context, tenantID string, uid models.UID) error {
+	ret := _m.Called(ctx, tenantID, uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID) error); ok {
+		r0 = rf(ctx, tenantID, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateDeviceKey provides a mock function with given fields: ctx, req
+func (_m *Service) RotateDeviceKey(ctx context.Context, req requests.DeviceRotateKey) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.DeviceRotateKey) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendEmailConfirmation provides a mock function with given fields: ctx, username
+func (_m *Service) SendEmailConfirmation(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetDevicePosition provides a mock function with given fields: ctx, uid, ip
 func (_m *Service) SetDevicePosition(ctx context.Context, uid models.UID, ip string) error {
 	ret := _m.Called(ctx, uid, ip)
@@ -1216,6 +1964,20 @@ func (_m *Service) SystemGetInfo(ctx context.Context, req requests.SystemGetInfo
 	return r0, r1
 }
 
+// TransferNamespace provides a mock function with given fields: ctx, tenantID, ownerID, memberID
+func (_m *Service) TransferNamespace(ctx context.Context, tenantID string, ownerID string, memberID string) error {
+	ret := _m.Called(ctx, tenantID, ownerID, memberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, ownerID, memberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateDataUser provides a mock function with given fields: ctx, id, userData
 func (_m *Service) UpdateDataUser(ctx context.Context, id string, userData requests.UserDataUpdate) ([]string, error) {
 	ret := _m.Called(ctx, id, userData)
@@ -1284,13 +2046,13 @@ func (_m *Service) UpdateDeviceTag(ctx context.Context, uid models.UID, tags []s
 	return r0
 }
 
-// UpdatePasswordUser provides a mock function with given fields: ctx, id, currentPassword, newPassword
-func (_m *Service) UpdatePasswordUser(ctx context.Context, id string, currentPassword string, newPassword string) error {
-	ret := _m.Called(ctx, id, currentPassword, newPassword)
+// UpdatePasswordUser provides a mock function with given fields: ctx, id, session, currentPassword, newPassword
+func (_m *Service) UpdatePasswordUser(ctx context.Context, id string, session string, currentPassword string, newPassword string) error {
+	ret := _m.Called(ctx, id, session, currentPassword, newPassword)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
-		r0 = rf(ctx, id, currentPassword, newPassword)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string) error); ok {
+		r0 = rf(ctx, id, session, currentPassword, newPassword)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1338,6 +2100,70 @@ func (_m *Service) UpdatePublicKeyTags(ctx context.Context, tenant string, finge
 	return r0
 }
 
+// UserSessionDelete provides a mock function with given fields: ctx, userID, id
+func (_m *Service) UserSessionDelete(ctx context.Context, userID string, id string) error {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionList provides a mock function with given fields: ctx, userID, current
+func (_m *Service) UserSessionList(ctx context.Context, userID string, current string) ([]models.UserSession, error) {
+	ret := _m.Called(ctx, userID, current)
+
+	var r0 []models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]models.UserSession, error)); ok {
+		return rf(ctx, userID, current)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []models.UserSession); ok {
+		r0 = rf(ctx, userID, current)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, current)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateMFA provides a mock function with given fields: ctx, req
+func (_m *Service) ValidateMFA(ctx context.Context, req requests.MFAValidate) (string, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, requests.MFAValidate) (string, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, requests.MFAValidate) string); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, requests.MFAValidate) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewService interface {
 	mock.TestingT
 	Cleanup(func())
@@ -24,8 +24,12 @@ type NamespaceService interface {
 	AddNamespaceUser(ctx context.Context, memberUsername, memberRole, tenantID, userID string) (*models.Namespace, error)
 	RemoveNamespaceUser(ctx context.Context, tenantID, memberID, userID string) (*models.Namespace, error)
 	EditNamespaceUser(ctx context.Context, tenantID, userID, memberID, memberNewRole string) error
+	EditNamespaceMemberScope(ctx context.Context, tenantID, userID, memberID string, scope *models.MemberScope) error
+	EvaluateMember(ctx context.Context, tenantID, userID, deviceUID string) (bool, error)
 	EditSessionRecordStatus(ctx context.Context, sessionRecord bool, tenantID string) error
 	GetSessionRecord(ctx context.Context, tenantID string) (bool, error)
+	EditSessionPolicy(ctx context.Context, tenantID string, idleTimeout, maxSessionDuration int) error
+	EditAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error
 }
 
 // ListNamespaces lists selected namespaces from a user.
@@ -175,7 +179,7 @@ func (s *service) fillMembersData(ctx context.Context, members []models.Member)
 			return nil, NewErrUserNotFound(member.ID, err)
 		}
 
-		members[index] = models.Member{ID: user.ID, Username: user.Username, Role: member.Role}
+		members[index] = models.Member{ID: user.ID, Username: user.Username, Role: member.Role, Scope: member.Scope}
 	}
 
 	return members, nil
@@ -366,6 +370,106 @@ func (s *service) EditNamespaceUser(ctx context.Context, tenantID, userID, membe
 	return nil
 }
 
+// EditNamespaceMemberScope restricts the devices what a member can reach inside the namespace.
+//
+// It receives a context, used to "control" the request flow, the tenant ID from models.Namespace, user ID from
+// models.User who is editing the member, the member's ID and the scope. An empty scope removes any restriction from the
+// member.
+//
+// The scope's tags must exist in the namespace and the scope's devices must belong to it. If the user has a role what
+// does not allow to act over the member, EditNamespaceMemberScope will return error.
+func (s *service) EditNamespaceMemberScope(ctx context.Context, tenantID, userID, memberID string, scope *models.MemberScope) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	// checks if the active member is in the namespace. user is the active member.
+	active, ok := guard.CheckMember(namespace, userID)
+	if !ok {
+		return NewErrNamespaceMemberNotFound(userID, nil)
+	}
+
+	// checks if the passive member is in the namespace. member is the passive member.
+	passive, ok := guard.CheckMember(namespace, memberID)
+	if !ok {
+		return NewErrNamespaceMemberNotFound(memberID, nil)
+	}
+
+	// checks if the active member can act over the passive member.
+	if !guard.CheckRole(active.Role, passive.Role) {
+		return guard.ErrForbidden
+	}
+
+	if len(scope.Tags) > 0 {
+		tags, _, err := s.store.TagsGet(ctx, tenantID)
+		if err != nil {
+			return NewErrTagEmpty(tenantID, err)
+		}
+
+		for _, tag := range scope.Tags {
+			if !contains(tags, tag) {
+				return NewErrTagNotFound(tag, nil)
+			}
+		}
+	}
+
+	for _, uid := range scope.Devices {
+		if _, err := s.store.DeviceGetByUID(ctx, models.UID(uid), tenantID); err != nil {
+			return NewErrDeviceNotFound(models.UID(uid), err)
+		}
+	}
+
+	return s.store.NamespaceEditMemberScope(ctx, tenantID, memberID, scope)
+}
+
+// EvaluateMember checks if a namespace's member can connect to the device through SSH.
+//
+// It receives a context, used to "control" the request flow, the tenant ID from models.Namespace, the member's ID and
+// the device's UID. An empty userID means that the connection is not bound to any member, as the ones authenticated by
+// the device's password before the member identifies itself.
+func (s *service) EvaluateMember(ctx context.Context, tenantID, userID, deviceUID string) (bool, error) {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return false, NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	device, err := s.store.DeviceGetByUID(ctx, models.UID(deviceUID), tenantID)
+	if err != nil {
+		return false, NewErrDeviceNotFound(models.UID(deviceUID), err)
+	}
+
+	return evaluateMember(namespace, userID, device), nil
+}
+
+// evaluateMember checks if the member can connect to the device. The member must still be in the namespace, its role
+// must allow the connection and the device must be inside its scope.
+//
+// A connection without member could be made by anyone in the namespace, including the members with a scope, so it is
+// only allowed when no member has one.
+func evaluateMember(namespace *models.Namespace, userID string, device *models.Device) bool {
+	if userID == "" {
+		for _, member := range namespace.Members {
+			if !member.Scope.IsEmpty() {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	member, ok := guard.CheckMember(namespace, userID)
+	if !ok {
+		return false
+	}
+
+	if err := guard.EvaluatePermission(member.Role, guard.Actions.Device.Connect, func() error { return nil }); err != nil {
+		return false
+	}
+
+	return member.Scope.Allows(device)
+}
+
 // EditSessionRecordStatus defines if the sessions will be recorded.
 //
 // It receives a context, used to "control" the request flow, a boolean to define if the sessions will be recorded and
@@ -387,3 +491,31 @@ func (s *service) GetSessionRecord(ctx context.Context, tenantID string) (bool,
 
 	return s.store.NamespaceGetSessionRecord(ctx, tenantID)
 }
+
+// EditSessionPolicy defines, in minutes, how long a session can stay idle and how long it can last before being closed.
+// Zero disables the respective limit.
+func (s *service) EditSessionPolicy(ctx context.Context, tenantID string, idleTimeout, maxSessionDuration int) error {
+	if err := s.store.NamespaceSetSessionPolicy(ctx, tenantID, idleTimeout, maxSessionDuration); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrNamespaceNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// EditAgentTagsOverwrite defines if the tags declared by the agents replace the device's tags whenever they
+// authenticate, instead of only when the device registers.
+func (s *service) EditAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error {
+	if err := s.store.NamespaceSetAgentTagsOverwrite(ctx, tenantID, overwrite); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrNamespaceNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -1914,6 +1914,229 @@ func TestEditNamespaceUser(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestEditNamespaceMemberScope(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		Name:     "group1",
+		Owner:    "ownerID",
+		TenantID: "a736a52b-5777-4f92-b0b8-e359bf484713",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "activeMemberID", Role: guard.RoleAdministrator},
+			{ID: "passiveMemberID", Role: guard.RoleObserver},
+		},
+	}
+
+	cases := []struct {
+		description   string
+		TenantID      string
+		UserID        string
+		MemberID      string
+		Scope         *models.MemberScope
+		RequiredMocks func()
+		Expected      error
+	}{
+		{
+			description: "fails when namespace was not found",
+			TenantID:    "tenantIDNotFound",
+			UserID:      "activeMemberID",
+			MemberID:    "passiveMemberID",
+			Scope:       &models.MemberScope{Tags: []string{"customer"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenantIDNotFound").Return(nil, errors.New("error")).Once()
+			},
+			Expected: NewErrNamespaceNotFound("tenantIDNotFound", errors.New("error")),
+		},
+		{
+			description: "fails when passive member was not found inside namespace",
+			TenantID:    namespace.TenantID,
+			UserID:      "activeMemberID",
+			MemberID:    "invalidMemberPassiveID",
+			Scope:       &models.MemberScope{Tags: []string{"customer"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			Expected: NewErrNamespaceMemberNotFound("invalidMemberPassiveID", nil),
+		},
+		{
+			description: "fails when active member cannot act over the passive member",
+			TenantID:    namespace.TenantID,
+			UserID:      "activeMemberID",
+			MemberID:    "ownerID",
+			Scope:       &models.MemberScope{Tags: []string{"customer"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			Expected: guard.ErrForbidden,
+		},
+		{
+			description: "fails when a scope's tag does not exist",
+			TenantID:    namespace.TenantID,
+			UserID:      "activeMemberID",
+			MemberID:    "passiveMemberID",
+			Scope:       &models.MemberScope{Tags: []string{"customer"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("TagsGet", ctx, namespace.TenantID).Return([]string{"other"}, 1, nil).Once()
+			},
+			Expected: NewErrTagNotFound("customer", nil),
+		},
+		{
+			description: "fails when a scope's device does not belong to the namespace",
+			TenantID:    namespace.TenantID,
+			UserID:      "activeMemberID",
+			MemberID:    "passiveMemberID",
+			Scope:       &models.MemberScope{Devices: []string{"uid"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(nil, errors.New("error")).Once()
+			},
+			Expected: NewErrDeviceNotFound(models.UID("uid"), errors.New("error")),
+		},
+		{
+			description: "success to edit the member's scope",
+			TenantID:    namespace.TenantID,
+			UserID:      "activeMemberID",
+			MemberID:    "passiveMemberID",
+			Scope:       &models.MemberScope{Tags: []string{"customer"}, Devices: []string{"uid"}},
+			RequiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+				mock.On("TagsGet", ctx, namespace.TenantID).Return([]string{"customer"}, 1, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("uid"), namespace.TenantID).Return(&models.Device{UID: "uid"}, nil).Once()
+				mock.On("NamespaceEditMemberScope", ctx, namespace.TenantID, "passiveMemberID", &models.MemberScope{Tags: []string{"customer"}, Devices: []string{"uid"}}).Return(nil).Once()
+			},
+			Expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.RequiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.EditNamespaceMemberScope(ctx, tc.TenantID, tc.UserID, tc.MemberID, tc.Scope)
+			assert.Equal(t, tc.Expected, err)
+		})
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestEvaluateMember(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "contractorID", Role: guard.RoleOperator, Scope: &models.MemberScope{Tags: []string{"customer"}}},
+		},
+	}
+
+	inside := &models.Device{UID: "inside", TenantID: "tenant", Tags: []string{"customer"}}
+	outside := &models.Device{UID: "outside", TenantID: "tenant"}
+
+	type Expected struct {
+		allowed bool
+		err     error
+	}
+
+	cases := []struct {
+		description   string
+		userID        string
+		deviceUID     string
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "fails when namespace was not found",
+			userID:      "ownerID",
+			deviceUID:   "inside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{false, NewErrNamespaceNotFound("tenant", errors.New("error"))},
+		},
+		{
+			description: "fails when device was not found",
+			userID:      "ownerID",
+			deviceUID:   "inside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("inside"), "tenant").Return(nil, errors.New("error")).Once()
+			},
+			expected: Expected{false, NewErrDeviceNotFound(models.UID("inside"), errors.New("error"))},
+		},
+		{
+			description: "refuses a connection without member when a member has a scope",
+			deviceUID:   "inside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("inside"), "tenant").Return(inside, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "allows a connection without member when no member has a scope",
+			deviceUID:   "outside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(&models.Namespace{
+					TenantID: "tenant",
+					Members:  []models.Member{{ID: "ownerID", Role: guard.RoleOwner}},
+				}, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("outside"), "tenant").Return(outside, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+		{
+			description: "refuses a member outside the namespace",
+			userID:      "removedID",
+			deviceUID:   "inside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("inside"), "tenant").Return(inside, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "refuses a device out of the member's scope",
+			userID:      "contractorID",
+			deviceUID:   "outside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("outside"), "tenant").Return(outside, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "allows a device inside the member's scope",
+			userID:      "contractorID",
+			deviceUID:   "inside",
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+				mock.On("DeviceGetByUID", ctx, models.UID("inside"), "tenant").Return(inside, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			allowed, err := service.EvaluateMember(ctx, "tenant", tc.userID, tc.deviceUID)
+			assert.Equal(t, tc.expected, Expected{allowed, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestGetSessionRecord(t *testing.T) {
 	mock := new(mocks.Store)
 
@@ -2084,3 +2307,53 @@ func TestEditSessionRecord(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestEditSessionPolicy(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	cases := []struct {
+		description   string
+		tenantID      string
+		requiredMocks func()
+		expected      error
+	}{
+		{
+			description: "fails when namespace was not found",
+			tenantID:    "xxxx",
+			requiredMocks: func() {
+				mock.On("NamespaceSetSessionPolicy", ctx, "xxxx", 15, 480).Return(store.ErrNoDocuments).Once()
+			},
+			expected: NewErrNamespaceNotFound("xxxx", store.ErrNoDocuments),
+		},
+		{
+			description: "fails when namespace set session policy fails",
+			tenantID:    "xxxx",
+			requiredMocks: func() {
+				mock.On("NamespaceSetSessionPolicy", ctx, "xxxx", 15, 480).Return(errors.New("error")).Once()
+			},
+			expected: errors.New("error"),
+		},
+		{
+			description: "succeeds",
+			tenantID:    "xxxx",
+			requiredMocks: func() {
+				mock.On("NamespaceSetSessionPolicy", ctx, "xxxx", 15, 480).Return(nil).Once()
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			err := service.EditSessionPolicy(ctx, tc.tenantID, 15, 480)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
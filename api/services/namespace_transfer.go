@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+// NamespaceTransferExpiry is the time that the new owner has to accept the transfer of the namespace's ownership.
+const NamespaceTransferExpiry = 7 * 24 * time.Hour
+
+type NamespaceTransferService interface {
+	// TransferNamespace starts the transfer of the namespace's ownership from its owner to a member, replacing a pending
+	// one. The ownership only changes when the member accepts it.
+	TransferNamespace(ctx context.Context, tenantID, ownerID, memberID string) error
+	// AcceptNamespaceTransfer makes the user the namespace's owner, when there is a pending transfer to it, demoting the
+	// previous owner to administrator. The namespace is counted in the user's limit of namespaces.
+	AcceptNamespaceTransfer(ctx context.Context, tenantID, userID string) error
+	// CancelNamespaceTransfer removes the namespace's pending transfer, by the owner or, declining it, by the new owner.
+	CancelNamespaceTransfer(ctx context.Context, tenantID, userID string) error
+}
+
+func (s *service) TransferNamespace(ctx context.Context, tenantID, ownerID, memberID string) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if namespace.Owner != ownerID {
+		return guard.ErrForbidden
+	}
+
+	if memberID == ownerID {
+		return NewErrNamespaceTransferInvalid(nil)
+	}
+
+	if _, ok := guard.CheckMember(namespace, memberID); !ok {
+		return NewErrNamespaceMemberNotFound(memberID, nil)
+	}
+
+	now := clock.Now()
+
+	return s.store.NamespaceSetTransfer(ctx, tenantID, &models.NamespaceTransfer{
+		To:        memberID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(NamespaceTransferExpiry),
+	})
+}
+
+func (s *service) AcceptNamespaceTransfer(ctx context.Context, tenantID, userID string) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if namespace.Transfer == nil || namespace.Transfer.To != userID || !clock.Now().Before(namespace.Transfer.ExpiresAt) {
+		return NewErrNamespaceTransferNotFound(tenantID, nil)
+	}
+
+	// The member may have been removed after the transfer started.
+	if _, ok := guard.CheckMember(namespace, userID); !ok {
+		return NewErrNamespaceMemberNotFound(userID, nil)
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, userID, false)
+	if err != nil {
+		return NewErrUserNotFound(userID, err)
+	}
+
+	// When MaxNamespaces is less than zero, it means that the user has no limit of namespaces.
+	if user.MaxNamespaces > 0 && user.MaxNamespaces <= user.Namespaces {
+		return NewErrNamespaceLimitReached(user.MaxNamespaces, nil)
+	}
+
+	if err := s.store.NamespaceTransferOwnership(ctx, tenantID, namespace.Owner, userID); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrNamespaceTransferNotFound(tenantID, err)
+		}
+
+		return err
+	}
+
+	// Both roles changed, so their tokens to the namespace must be issued again.
+	s.AuthUncacheToken(ctx, tenantID, namespace.Owner) // nolint: errcheck
+	s.AuthUncacheToken(ctx, tenantID, userID)          // nolint: errcheck
+
+	return nil
+}
+
+func (s *service) CancelNamespaceTransfer(ctx context.Context, tenantID, userID string) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenantID)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenantID, err)
+	}
+
+	if namespace.Transfer == nil {
+		return NewErrNamespaceTransferNotFound(tenantID, nil)
+	}
+
+	if userID != namespace.Owner && userID != namespace.Transfer.To {
+		return guard.ErrForbidden
+	}
+
+	return s.store.NamespaceSetTransfer(ctx, tenantID, nil)
+}
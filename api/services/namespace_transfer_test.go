@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferNamespace(t *testing.T) {
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "member", Role: guard.RoleOperator}},
+	}
+
+	t.Run("starts the transfer to the member", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("NamespaceSetTransfer", ctx, "tenant", &models.NamespaceTransfer{
+			To:        "member",
+			CreatedAt: now,
+			ExpiresAt: now.Add(NamespaceTransferExpiry),
+		}).Return(nil).Once()
+
+		assert.NoError(t, service.TransferNamespace(ctx, "tenant", "owner", "member"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the user is not the owner", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+
+		assert.Equal(t, guard.ErrForbidden, service.TransferNamespace(ctx, "tenant", "member", "owner"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the owner transfers to itself", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+
+		assert.Equal(t, NewErrNamespaceTransferInvalid(nil), service.TransferNamespace(ctx, "tenant", "owner", "owner"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the user is not a member", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+
+		assert.Equal(t, NewErrNamespaceMemberNotFound("stranger", nil), service.TransferNamespace(ctx, "tenant", "owner", "stranger"))
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestAcceptNamespaceTransfer(t *testing.T) {
+	ctx := context.TODO()
+
+	// newNamespace returns a namespace with a pending transfer to the member that expires at expiresAt.
+	newNamespace := func(expiresAt time.Time) *models.Namespace {
+		return &models.Namespace{
+			TenantID: "tenant",
+			Owner:    "owner",
+			Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "member", Role: guard.RoleOperator}},
+			Transfer: &models.NamespaceTransfer{To: "member", ExpiresAt: expiresAt},
+		}
+	}
+
+	t.Run("makes the member the owner", func(t *testing.T) {
+		mock := new(mocks.Store)
+		cache := newMemoryCache()
+		service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+		assert.NoError(t, service.AuthCacheToken(ctx, "tenant", "owner", "token"))
+		assert.NoError(t, service.AuthCacheToken(ctx, "tenant", "member", "token"))
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(newNamespace(now.Add(time.Hour)), nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetByID", ctx, "member", false).Return(&models.User{ID: "member", MaxNamespaces: 1}, 0, nil).Once()
+		mock.On("NamespaceTransferOwnership", ctx, "tenant", "owner", "member").Return(nil).Once()
+
+		assert.NoError(t, service.AcceptNamespaceTransfer(ctx, "tenant", "member"))
+
+		// The tokens with the previous roles are no longer accepted.
+		for _, id := range []string{"owner", "member"} {
+			cached, err := service.AuthIsCacheToken(ctx, "tenant", id)
+			assert.NoError(t, err)
+			assert.False(t, cached)
+		}
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the transfer is to another member", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(newNamespace(now.Add(time.Hour)), nil).Once()
+
+		assert.Equal(t, NewErrNamespaceTransferNotFound("tenant", nil), service.AcceptNamespaceTransfer(ctx, "tenant", "owner"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the transfer expired", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(newNamespace(now), nil).Once()
+		clockMock.On("Now").Return(now).Once()
+
+		assert.Equal(t, NewErrNamespaceTransferNotFound("tenant", nil), service.AcceptNamespaceTransfer(ctx, "tenant", "member"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the member reached its limit of namespaces", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(newNamespace(now.Add(time.Hour)), nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetByID", ctx, "member", false).Return(&models.User{ID: "member", MaxNamespaces: 1, Namespaces: 1}, 0, nil).Once()
+
+		assert.Equal(t, NewErrNamespaceLimitReached(1, nil), service.AcceptNamespaceTransfer(ctx, "tenant", "member"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the owner changed meanwhile", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		mock.On("NamespaceGet", ctx, "tenant").Return(newNamespace(now.Add(time.Hour)), nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetByID", ctx, "member", false).Return(&models.User{ID: "member", MaxNamespaces: -1}, 0, nil).Once()
+		mock.On("NamespaceTransferOwnership", ctx, "tenant", "owner", "member").Return(store.ErrNoDocuments).Once()
+
+		assert.Equal(t, NewErrNamespaceTransferNotFound("tenant", store.ErrNoDocuments), service.AcceptNamespaceTransfer(ctx, "tenant", "member"))
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestCancelNamespaceTransfer(t *testing.T) {
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Owner:    "owner",
+		Members:  []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "member", Role: guard.RoleOperator}, {ID: "other", Role: guard.RoleAdministrator}},
+		Transfer: &models.NamespaceTransfer{To: "member"},
+	}
+
+	mock := new(mocks.Store)
+	service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	// Both the owner and the new owner can cancel it.
+	mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Times(3)
+	mock.On("NamespaceSetTransfer", ctx, "tenant", (*models.NamespaceTransfer)(nil)).Return(nil).Twice()
+
+	assert.NoError(t, service.CancelNamespaceTransfer(ctx, "tenant", "owner"))
+	assert.NoError(t, service.CancelNamespaceTransfer(ctx, "tenant", "member"))
+	assert.Equal(t, guard.ErrForbidden, service.CancelNamespaceTransfer(ctx, "tenant", "other"))
+
+	mock.On("NamespaceGet", ctx, "pending").Return(&models.Namespace{TenantID: "pending", Owner: "owner"}, nil).Once()
+
+	assert.Equal(t, NewErrNamespaceTransferNotFound("pending", nil), service.CancelNamespaceTransfer(ctx, "pending", "owner"))
+
+	mock.AssertExpectations(t)
+}
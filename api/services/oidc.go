@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/validator"
+)
+
+// OIDCLoginExpiry is the time that the user has to authenticate at the identity provider after the login starts.
+const OIDCLoginExpiry = 10 * time.Minute
+
+// MaxNamespacesOIDC is the maximum number of namespaces of the users created by the OpenID Connect's login, where a
+// negative value is unlimited.
+const MaxNamespacesOIDC = -1
+
+// oidcLogin is the state of a login through OpenID Connect, kept while the user authenticates at the identity provider.
+type oidcLogin struct {
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+}
+
+type OIDCService interface {
+	// OIDCAuthURL starts a login through OpenID Connect, returning the URL where the user authenticates at the identity
+	// provider and the login's state, which must be bound to the user's browser.
+	OIDCAuthURL(ctx context.Context) (string, string, error)
+	// AuthOIDC completes a login through OpenID Connect. The login is only completed by the browser that started it,
+	// which sends the state bound to it. The user is created on its first login, and its namespaces' memberships are
+	// synchronized from its groups at the identity provider.
+	AuthOIDC(ctx context.Context, req requests.AuthOIDC) (*models.UserAuthResponse, error)
+}
+
+func (s *service) OIDCAuthURL(ctx context.Context) (string, string, error) {
+	if s.oidc == nil {
+		return "", "", NewErrOIDCNotConfigured(nil)
+	}
+
+	state, err := oidc.GenerateRandom()
+	if err != nil {
+		return "", "", err
+	}
+
+	login := oidcLogin{}
+	if login.Nonce, err = oidc.GenerateRandom(); err != nil {
+		return "", "", err
+	}
+
+	if login.Verifier, err = oidc.GenerateRandom(); err != nil {
+		return "", "", err
+	}
+
+	if err := s.cache.Set(ctx, oidcLoginKey(state), login, OIDCLoginExpiry); err != nil {
+		return "", "", err
+	}
+
+	url, err := s.oidc.AuthCodeURL(ctx, state, login.Nonce, login.Verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	return url, state, nil
+}
+
+func (s *service) AuthOIDC(ctx context.Context, req requests.AuthOIDC) (*models.UserAuthResponse, error) {
+	if s.oidc == nil {
+		return nil, NewErrOIDCNotConfigured(nil)
+	}
+
+	// The state sent by the identity provider must be the one bound to the browser, so an attacker cannot make the user
+	// complete a login started by the attacker.
+	if req.BrowserState == "" || subtle.ConstantTimeCompare([]byte(req.State), []byte(req.BrowserState)) != 1 {
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	// The login's state is removed before the code is exchanged, so each state is used only once.
+	var login oidcLogin
+	if err := s.cache.Get(ctx, oidcLoginKey(req.State), &login); err != nil || login.Verifier == "" {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	if err := s.cache.Delete(ctx, oidcLoginKey(req.State)); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.oidc.Exchange(ctx, req.Code, login.Verifier, login.Nonce)
+	if err != nil {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	user, err := s.provisionOIDCUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	s.syncMemberships(ctx, user, s.oidc.Config().Groups, claims.Groups)
+
+	namespace, _ := s.store.NamespaceGetFirst(ctx, user.ID)
+
+	if requiresMFA(user, namespace) {
+		return s.authMFAToken(user)
+	}
+
+	return s.authUserToken(ctx, user, namespace)
+}
+
+func oidcLoginKey(state string) string {
+	return "oidc/" + state
+}
+
+// provisionOIDCUser gets the user linked to the identity provider's account. When there is none, a user is created from
+// the claims. A local user with the same e-mail is only linked to the account when the link by e-mail is enabled and the
+// e-mail is verified; otherwise, the login fails as the user is duplicated.
+func (s *service) provisionOIDCUser(ctx context.Context, claims *oidc.Claims) (*models.User, error) {
+	if user, err := s.store.UserGetByIdentity(ctx, models.UserIdentityOIDC, claims.Subject); err == nil {
+		return user, nil
+	}
+
+	identity := &models.UserIdentity{Provider: models.UserIdentityOIDC, Subject: claims.Subject}
+	email := strings.ToLower(claims.Email)
+
+	// An unverified e-mail could be set by anyone at the identity provider, so it is not trusted to link an account.
+	if email != "" && claims.EmailVerified {
+		if user, err := s.store.UserGetByEmail(ctx, email); err == nil {
+			if user.Identity != nil || !s.oidc.Config().LinkByEmail {
+				return nil, NewErrUserDuplicated([]string{email}, nil)
+			}
+
+			if err := s.store.UserUpdateIdentity(ctx, user.ID, identity); err != nil {
+				return nil, NewErrUserUpdate(user, err)
+			}
+
+			user.Identity = identity
+
+			return user, nil
+		}
+	}
+
+	username := strings.ToLower(claims.PreferredUsername)
+	if username == "" {
+		username = email
+	}
+
+	if !validator.ValidateFieldUsername(username) {
+		return nil, NewErrUserInvalid(map[string]interface{}{"username": username}, nil)
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = username
+	}
+
+	user := &models.User{
+		UserData: models.UserData{
+			Name:     name,
+			Email:    email,
+			Username: username,
+		},
+		Confirmed:     true,
+		CreatedAt:     clock.Now(),
+		MaxNamespaces: MaxNamespacesOIDC,
+		Identity:      identity,
+	}
+
+	if err := s.store.UserCreate(ctx, user); err != nil {
+		if err == store.ErrDuplicate {
+			return nil, NewErrUserDuplicated([]string{username, email}, err)
+		}
+
+		return nil, err
+	}
+
+	// The store does not return the created user's ID, so the user is got again.
+	return s.store.UserGetByIdentity(ctx, models.UserIdentityOIDC, claims.Subject)
+}
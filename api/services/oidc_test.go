@@ -0,0 +1,371 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/membership"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc/oidctest"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+// memoryCache is a cache.Cache that keeps the values in memory, ignoring their expiration.
+type memoryCache struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{values: map[string][]byte{}}
+}
+
+func (c *memoryCache) Get(_ context.Context, key string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, ok := c.values[key]
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.values[key] = data
+
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.values, key)
+
+	return nil
+}
+
+func (c *memoryCache) Incr(_ context.Context, key string, _ time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var value int64
+	if data, ok := c.values[key]; ok {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return 0, err
+		}
+	}
+
+	value++
+
+	data, err := json.Marshal(value)
+	c.values[key] = data
+
+	return value, err
+}
+
+var _ storecache.Cache = (*memoryCache)(nil)
+
+// mustOIDCLogin starts a login through the service and authenticates it at the identity provider.
+func mustOIDCLogin(t *testing.T, service *APIService, server *oidctest.Server) requests.AuthOIDC {
+	t.Helper()
+
+	authURL, bound, err := service.OIDCAuthURL(context.TODO())
+	assert.NoError(t, err)
+
+	code, state, err := server.Login(authURL)
+	assert.NoError(t, err)
+	assert.Equal(t, bound, state)
+
+	return requests.AuthOIDC{Code: code, State: state, BrowserState: bound}
+}
+
+func TestOIDCAuthURL(t *testing.T) {
+	t.Run("fails when OIDC is not configured", func(t *testing.T) {
+		service := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		_, _, err := service.OIDCAuthURL(context.TODO())
+		assert.Equal(t, NewErrOIDCNotConfigured(nil), err)
+	})
+
+	t.Run("success when OIDC is configured", func(t *testing.T) {
+		server := oidctest.NewServer("shellhub", "secret")
+		defer server.Close()
+
+		provider := oidc.NewProvider(server.Config("http://localhost/login/oidc"))
+		service := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		authURL, state, err := service.OIDCAuthURL(context.TODO())
+		assert.NoError(t, err)
+
+		parsed, err := url.Parse(authURL)
+		assert.NoError(t, err)
+		assert.Equal(t, server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+		assert.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+		assert.NotEmpty(t, state)
+		assert.Equal(t, state, parsed.Query().Get("state"))
+		assert.NotEmpty(t, parsed.Query().Get("nonce"))
+	})
+}
+
+func TestAuthOIDC(t *testing.T) {
+	server := oidctest.NewServer("shellhub", "secret")
+	defer server.Close()
+
+	config := server.Config("http://localhost/login/oidc")
+	config.Groups = membership.Mappings{
+		{Name: "devs", TenantID: "tenant", Role: guard.RoleOperator},
+	}
+
+	provider := oidc.NewProvider(config)
+
+	claims := map[string]interface{}{
+		"sub":                "subject",
+		"email":              "John@example.com",
+		"email_verified":     true,
+		"name":               "John Doe",
+		"preferred_username": "john",
+		"groups":             []string{"devs"},
+	}
+
+	identity := &models.UserIdentity{Provider: models.UserIdentityOIDC, Subject: "subject"}
+
+	t.Run("fails when the state is unknown", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		req := mustOIDCLogin(t, service, server)
+		req.State = "unknown"
+
+		_, err := service.AuthOIDC(context.TODO(), req)
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the state is not bound to the browser", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		// The attacker starts a login and sends its code and state to the user, whose browser has no state or another.
+		req := mustOIDCLogin(t, service, server)
+
+		for _, browser := range []string{"", "other"} {
+			req.BrowserState = browser
+
+			_, err := service.AuthOIDC(context.TODO(), req)
+			assert.ErrorIs(t, err, ErrAuthUnathorized)
+		}
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the state is used twice", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John Doe", Username: "john", Email: "john@example.com"}, Identity: identity}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(&models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		_, err := service.AuthOIDC(context.TODO(), req)
+		assert.NoError(t, err)
+
+		_, err = service.AuthOIDC(context.TODO(), req)
+		assert.ErrorIs(t, err, ErrAuthUnathorized)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("creates the user and its membership on the first login", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		created := &models.User{
+			UserData:      models.UserData{Name: "John Doe", Username: "john", Email: "john@example.com"},
+			Confirmed:     true,
+			CreatedAt:     now,
+			MaxNamespaces: MaxNamespacesOIDC,
+			Identity:      identity,
+		}
+
+		user := *created
+		user.ID = "id"
+
+		namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "owner", Role: guard.RoleOwner}}}
+		member := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "owner", Role: guard.RoleOwner}, {ID: "id", Role: guard.RoleOperator}}}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "john@example.com").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserCreate", testifymock.Anything, created).Return(nil).Once()
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(&user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(namespace, nil).Once()
+		mock.On("NamespaceAddMember", testifymock.Anything, "tenant", "id", guard.RoleOperator).Return(member, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(member, nil).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthOIDC(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Token)
+		assert.Equal(t, "id", res.ID)
+		assert.Equal(t, "john", res.User)
+		assert.Equal(t, "tenant", res.Tenant)
+		assert.Equal(t, guard.RoleOperator, res.Role)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the local user with the same e-mail is not linked by e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John", Username: "johnny", Email: "john@example.com"}}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "john@example.com").Return(user, nil).Once()
+
+		_, err := service.AuthOIDC(context.TODO(), req)
+		assert.Equal(t, NewErrUserDuplicated([]string{"john@example.com"}, nil), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("links the local user with the same verified e-mail when enabled", func(t *testing.T) {
+		linking := config
+		linking.LinkByEmail = true
+
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(oidc.NewProvider(linking)))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John", Username: "johnny", Email: "john@example.com"}}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "john@example.com").Return(user, nil).Once()
+		mock.On("UserUpdateIdentity", testifymock.Anything, "id", identity).Return(nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(&models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthOIDC(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, "johnny", res.User)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the e-mail of a new user is used by a user linked to another account", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Email: "john@example.com"}, Identity: &models.UserIdentity{Provider: models.UserIdentityOIDC, Subject: "other"}}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", testifymock.Anything, "john@example.com").Return(user, nil).Once()
+
+		_, err := service.AuthOIDC(context.TODO(), req)
+		assert.Equal(t, NewErrUserDuplicated([]string{"john@example.com"}, nil), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("removes the membership when the user leaves the group", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		withoutGroups := map[string]interface{}{}
+		for key, value := range claims {
+			withoutGroups[key] = value
+		}
+
+		delete(withoutGroups, "groups")
+
+		server.SetUser(withoutGroups)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John Doe", Username: "john", Email: "john@example.com"}, Identity: identity}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(&models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}, nil).Once()
+		mock.On("NamespaceRemoveMember", testifymock.Anything, "tenant", "id").Return(&models.Namespace{TenantID: "tenant"}, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(nil, store.ErrNoDocuments).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthOIDC(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Empty(t, res.Tenant)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not change the namespace's owner", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, newMemoryCache(), clientMock, nil, WithOIDC(provider))
+
+		server.SetUser(claims)
+		req := mustOIDCLogin(t, service, server)
+
+		user := &models.User{ID: "id", UserData: models.UserData{Name: "John Doe", Username: "john", Email: "john@example.com"}, Identity: identity}
+		namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOwner}}}
+
+		mock.On("UserGetByIdentity", testifymock.Anything, models.UserIdentityOIDC, "subject").Return(user, nil).Once()
+		mock.On("NamespaceGet", testifymock.Anything, "tenant").Return(namespace, nil).Once()
+		mock.On("NamespaceGetFirst", testifymock.Anything, "id").Return(namespace, nil).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserUpdateData", testifymock.Anything, "id", testifymock.Anything).Return(nil).Once()
+		mock.On("UserSessionCreate", testifymock.Anything, testifymock.AnythingOfType("*models.UserSession")).Return(nil).Once()
+
+		res, err := service.AuthOIDC(context.TODO(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, guard.RoleOwner, res.Role)
+
+		mock.AssertExpectations(t)
+	})
+}
@@ -3,6 +3,11 @@ package services
 import (
 	"crypto/rsa"
 
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
+	"github.com/shellhub-io/shellhub/api/pkg/ldap"
+	"github.com/shellhub-io/shellhub/api/pkg/lockout"
+	"github.com/shellhub-io/shellhub/api/pkg/mail"
+	"github.com/shellhub-io/shellhub/api/pkg/oidc"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/geoip"
@@ -15,12 +20,63 @@ type APIService struct {
 var _ Service = (*APIService)(nil)
 
 type service struct {
-	store   store.Store
-	privKey *rsa.PrivateKey
-	pubKey  *rsa.PublicKey
+	store store.Store
+	// keys sign and verify the tokens issued by the service.
+	keys    *keyring.Keyring
 	cache   cache.Cache
 	client  interface{}
 	locator geoip.Locator
+	oidc    *oidc.Provider
+	ldap    ldap.Authenticator
+	mailer  *mail.Mailer
+	// accounts and sources track the failed authentication attempts of the accounts and source IPs.
+	accounts *lockout.Limiter
+	sources  *lockout.Limiter
+	// legacyDeviceAuth accepts the device auth without the signed challenge.
+	legacyDeviceAuth bool
+}
+
+// Option configures an optional feature of the service.
+type Option func(*service)
+
+// WithOIDC enables the login through the OpenID Connect's identity provider.
+func WithOIDC(provider *oidc.Provider) Option {
+	return func(s *service) {
+		s.oidc = provider
+	}
+}
+
+// WithLDAP enables the login through a LDAP directory, before the local users.
+func WithLDAP(directory ldap.Authenticator) Option {
+	return func(s *service) {
+		s.ldap = directory
+	}
+}
+
+// WithMailer enables the flows that send e-mails, like the password reset and the e-mail confirmation.
+func WithMailer(mailer *mail.Mailer) Option {
+	return func(s *service) {
+		s.mailer = mailer
+	}
+}
+
+// WithLegacyDeviceAuth accepts the device auth without the signed challenge, sent by the agents older than the proof of
+// key possession.
+//
+// It is a temporary opt-in for upgrades, as anyone who knows a device's public key can authenticate as the device.
+//
+// Deprecated: it is kept while the agents are updated and will be removed in a future release.
+func WithLegacyDeviceAuth() Option {
+	return func(s *service) {
+		s.legacyDeviceAuth = true
+	}
+}
+
+// WithVerificationKeys keeps verifying the tokens signed by the keys, like the previous signing keys after a rotation.
+func WithVerificationKeys(keys ...*rsa.PublicKey) Option {
+	return func(s *service) {
+		s.keys = s.keys.With(keys...)
+	}
 }
 
 //go:generate mockery --name Service --dir ./services/ --output ./services/mocks --filename services.go
@@ -34,13 +90,21 @@ type Service interface {
 	SSHKeysTagsService
 	SessionService
 	NamespaceService
+	NamespaceTransferService
 	AuthService
 	StatsService
 	SetupService
 	SystemService
+	AccessRequestService
+	MFAService
+	OIDCService
+	UserSessionService
+	UserRecoveryService
+	EnrollmentTokenService
+	DeviceKeyService
 }
 
-func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKey, cache cache.Cache, c interface{}, l geoip.Locator) *APIService {
+func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKey, cache cache.Cache, c interface{}, l geoip.Locator, options ...Option) *APIService {
 	if privKey == nil || pubKey == nil {
 		var err error
 		privKey, pubKey, err = LoadKeys()
@@ -49,5 +113,18 @@ func NewService(store store.Store, privKey *rsa.PrivateKey, pubKey *rsa.PublicKe
 		}
 	}
 
-	return &APIService{service: &service{store, privKey, pubKey, cache, c, l}}
+	s := &service{
+		store:    store,
+		keys:     keyring.New(privKey, pubKey),
+		cache:    cache,
+		client:   c,
+		locator:  l,
+		accounts: lockout.NewLimiter(cache, "account", lockout.AccountPolicy),
+		sources:  lockout.NewLimiter(cache, "source", lockout.SourcePolicy),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return &APIService{service: s}
 }
@@ -14,7 +14,7 @@ type SessionService interface {
 	ListSessions(ctx context.Context, pagination paginator.Query) ([]models.Session, int, error)
 	GetSession(ctx context.Context, uid models.UID) (*models.Session, error)
 	CreateSession(ctx context.Context, session requests.SessionCreate) (*models.Session, error)
-	DeactivateSession(ctx context.Context, uid models.UID) error
+	DeactivateSession(ctx context.Context, uid models.UID, reason string) error
 	KeepAliveSession(ctx context.Context, uid models.UID) error
 	SetSessionAuthenticated(ctx context.Context, uid models.UID, authenticated bool) error
 }
@@ -42,6 +42,7 @@ func (s *service) CreateSession(ctx context.Context, session requests.SessionCre
 		IPAddress: session.IPAddress,
 		Type:      session.Type,
 		Term:      session.Term,
+		Member:    session.Member,
 		Position: models.SessionPosition{
 			Longitude: position.Longitude,
 			Latitude:  position.Latitude,
@@ -49,8 +50,9 @@ func (s *service) CreateSession(ctx context.Context, session requests.SessionCre
 	})
 }
 
-func (s *service) DeactivateSession(ctx context.Context, uid models.UID) error {
-	err := s.store.SessionDeleteActives(ctx, uid)
+// DeactivateSession closes the session. Reason is set when the session was closed by ShellHub, instead of the user.
+func (s *service) DeactivateSession(ctx context.Context, uid models.UID, reason string) error {
+	err := s.store.SessionDeleteActives(ctx, uid, reason)
 	if err == store.ErrNoDocuments {
 		return NewErrSessionNotFound(uid, err)
 	}
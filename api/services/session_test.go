@@ -224,6 +224,7 @@ func TestDeactivateSession(t *testing.T) {
 	cases := []struct {
 		name          string
 		uid           models.UID
+		reason        string
 		requiredMocks func()
 		expected      error
 	}{
@@ -231,7 +232,7 @@ func TestDeactivateSession(t *testing.T) {
 			name: "fails when session is not found",
 			uid:  models.UID("_uid"),
 			requiredMocks: func() {
-				mock.On("SessionDeleteActives", ctx, models.UID("_uid")).
+				mock.On("SessionDeleteActives", ctx, models.UID("_uid"), "").
 					Return(store.ErrNoDocuments).Once()
 			},
 			expected: NewErrSessionNotFound("_uid", store.ErrNoDocuments),
@@ -240,7 +241,7 @@ func TestDeactivateSession(t *testing.T) {
 			name: "fails",
 			uid:  models.UID("_uid"),
 			requiredMocks: func() {
-				mock.On("SessionDeleteActives", ctx, models.UID("_uid")).
+				mock.On("SessionDeleteActives", ctx, models.UID("_uid"), "").
 					Return(goerrors.New("error")).Once()
 			},
 			expected: goerrors.New("error"),
@@ -249,7 +250,17 @@ func TestDeactivateSession(t *testing.T) {
 			name: "succeeds",
 			uid:  models.UID("uid"),
 			requiredMocks: func() {
-				mock.On("SessionDeleteActives", ctx, models.UID("uid")).
+				mock.On("SessionDeleteActives", ctx, models.UID("uid"), "").
+					Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			name:   "succeeds with a reason",
+			uid:    models.UID("uid"),
+			reason: models.SessionFinishReasonIdle,
+			requiredMocks: func() {
+				mock.On("SessionDeleteActives", ctx, models.UID("uid"), models.SessionFinishReasonIdle).
 					Return(nil).Once()
 			},
 			expected: nil,
@@ -261,7 +272,7 @@ func TestDeactivateSession(t *testing.T) {
 			tc.requiredMocks()
 
 			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			err := service.DeactivateSession(ctx, tc.uid)
+			err := service.DeactivateSession(ctx, tc.uid, tc.reason)
 			assert.Equal(t, tc.expected, err)
 		})
 	}
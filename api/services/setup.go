@@ -7,7 +7,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/models"
-	"github.com/shellhub-io/shellhub/pkg/validator"
+	"github.com/shellhub-io/shellhub/pkg/password"
 )
 
 type SetupService interface {
@@ -21,8 +21,13 @@ func (s *service) Setup(ctx context.Context, req requests.Setup) error {
 		Username: req.Username,
 	}
 
+	hash, err := password.Hash(req.Password)
+	if err != nil {
+		return NewErrUserPasswordInvalid(err)
+	}
+
 	userPass := models.UserPassword{
-		Password: validator.HashPassword(req.Password),
+		Password: hash,
 	}
 
 	user := &models.User{
@@ -31,7 +36,7 @@ func (s *service) Setup(ctx context.Context, req requests.Setup) error {
 		Confirmed:    true,
 		CreatedAt:    clock.Now(),
 	}
-	err := s.store.UserCreate(ctx, user)
+	err = s.store.UserCreate(ctx, user)
 	if err != nil {
 		return NewErrUserDuplicated([]string{req.Username}, err)
 	}
@@ -12,7 +12,6 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	uuid_mocks "github.com/shellhub-io/shellhub/pkg/uuid/mocks"
-	"github.com/shellhub-io/shellhub/pkg/validator"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,13 +43,10 @@ func TestSetup(t *testing.T) {
 						Email:    "teste@google.com",
 						Username: "userteste",
 					},
-					UserPassword: models.UserPassword{
-						Password: validator.HashPassword("123456"),
-					},
 					Confirmed: true,
 					CreatedAt: now,
 				}
-				mock.On("UserCreate", ctx, user).Return(errors.New("error", "", 0)).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "123456")).Return(errors.New("error", "", 0)).Once()
 			},
 			expected: NewErrUserDuplicated([]string{"userteste"}, errors.New("error", "", 0)),
 		},
@@ -73,9 +69,6 @@ func TestSetup(t *testing.T) {
 						Email:    "teste@google.com",
 						Username: "userteste",
 					},
-					UserPassword: models.UserPassword{
-						Password: validator.HashPassword("123456"),
-					},
 					Confirmed: true,
 					CreatedAt: now,
 				}
@@ -91,7 +84,7 @@ func TestSetup(t *testing.T) {
 					},
 					CreatedAt: now,
 				}
-				mock.On("UserCreate", ctx, user).Return(nil).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "123456")).Return(nil).Once()
 				mock.On("NamespaceCreate", ctx, namespace).Return(namespace, errors.New("error", "", 0)).Once()
 			},
 			expected: NewErrNamespaceDuplicated(errors.New("error", "", 0)),
@@ -115,9 +108,6 @@ func TestSetup(t *testing.T) {
 						Email:    "teste@google.com",
 						Username: "userteste",
 					},
-					UserPassword: models.UserPassword{
-						Password: validator.HashPassword("123456"),
-					},
 					Confirmed: true,
 					CreatedAt: now,
 				}
@@ -133,7 +123,7 @@ func TestSetup(t *testing.T) {
 					},
 					CreatedAt: now,
 				}
-				mock.On("UserCreate", ctx, user).Return(nil).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "123456")).Return(nil).Once()
 				mock.On("NamespaceCreate", ctx, namespace).Return(namespace, nil).Once()
 			},
 			expected: nil,
@@ -8,6 +8,7 @@ import (
 	"encoding/pem"
 	"regexp"
 
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
@@ -20,6 +21,7 @@ import (
 type SSHKeysService interface {
 	EvaluateKeyFilter(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error)
 	EvaluateKeyUsername(ctx context.Context, key *models.PublicKey, username string) (bool, error)
+	EvaluateKeyMember(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error)
 	ListPublicKeys(ctx context.Context, pagination paginator.Query) ([]models.PublicKey, int, error)
 	GetPublicKey(ctx context.Context, fingerprint, tenant string) (*models.PublicKey, error)
 	CreatePublicKey(ctx context.Context, req requests.PublicKeyCreate, tenant string) (*responses.PublicKeyCreate, error)
@@ -66,6 +68,20 @@ func (s *service) EvaluateKeyUsername(_ context.Context, key *models.PublicKey,
 	return ok, nil
 }
 
+// EvaluateKeyMember checks if the member who the public key belongs to can connect to the device.
+//
+// The member must still be in the namespace, its role must allow the connection and the device must be inside its
+// scope. A public key without member can be used by anyone who has its private key, so it is refused when any member of
+// the namespace has a scope; otherwise, it is evaluated only by its filter and username.
+func (s *service) EvaluateKeyMember(ctx context.Context, key *models.PublicKey, dev models.Device) (bool, error) {
+	namespace, err := s.store.NamespaceGet(ctx, key.TenantID)
+	if err != nil {
+		return false, NewErrNamespaceNotFound(key.TenantID, err)
+	}
+
+	return evaluateMember(namespace, key.Member, &dev), nil
+}
+
 func (s *service) GetPublicKey(ctx context.Context, fingerprint, tenant string) (*models.PublicKey, error) {
 	_, err := s.store.NamespaceGet(ctx, tenant)
 	if err != nil {
@@ -91,6 +107,12 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 		}
 	}
 
+	if req.Member != "" || req.UserID != "" {
+		if err := s.checkPublicKeyMember(ctx, tenant, req.UserID, req.Member); err != nil {
+			return nil, err
+		}
+	}
+
 	pubKey, _, _, _, err := ssh.ParseAuthorizedKey(req.Data) //nolint:dogsled
 	if err != nil {
 		return nil, NewErrPublicKeyDataInvalid(req.Data, nil)
@@ -119,6 +141,10 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 				Hostname: req.Filter.Hostname,
 				Tags:     req.Filter.Tags,
 			},
+			Member:       req.Member,
+			Restrictions: (*models.PublicKeyRestrictions)(req.Restrictions),
+			RequireMFA:   req.RequireMFA,
+			Automation:   req.Automation,
 		},
 	}
 
@@ -128,12 +154,16 @@ func (s *service) CreatePublicKey(ctx context.Context, req requests.PublicKeyCre
 	}
 
 	return &responses.PublicKeyCreate{
-		Data:        model.Data,
-		Filter:      responses.PublicKeyFilter(model.Filter),
-		Name:        model.Name,
-		Username:    model.Username,
-		TenantID:    model.TenantID,
-		Fingerprint: model.Fingerprint,
+		Data:         model.Data,
+		Filter:       responses.PublicKeyFilter(model.Filter),
+		Name:         model.Name,
+		Username:     model.Username,
+		Member:       model.Member,
+		Restrictions: (*responses.PublicKeyRestrictions)(model.Restrictions),
+		RequireMFA:   model.RequireMFA,
+		Automation:   model.Automation,
+		TenantID:     model.TenantID,
+		Fingerprint:  model.Fingerprint,
 	}, nil
 }
 
@@ -157,6 +187,12 @@ func (s *service) UpdatePublicKey(ctx context.Context, fingerprint, tenant strin
 		}
 	}
 
+	if key.Member != "" || key.UserID != "" {
+		if err := s.checkPublicKeyMember(ctx, tenant, key.UserID, key.Member); err != nil {
+			return nil, err
+		}
+	}
+
 	model := models.PublicKeyUpdate{
 		PublicKeyFields: models.PublicKeyFields{
 			Name:     key.Name,
@@ -165,12 +201,41 @@ func (s *service) UpdatePublicKey(ctx context.Context, fingerprint, tenant strin
 				Hostname: key.Filter.Hostname,
 				Tags:     key.Filter.Tags,
 			},
+			Member:       key.Member,
+			Restrictions: (*models.PublicKeyRestrictions)(key.Restrictions),
+			RequireMFA:   key.RequireMFA,
+			Automation:   key.Automation,
 		},
 	}
 
 	return s.store.PublicKeyUpdate(ctx, fingerprint, tenant, &model)
 }
 
+// checkPublicKeyMember checks if the member, who a public key will belong to, is in the namespace.
+//
+// userID is the member who creates or edits the public key. When it has a scope, the public key must belong to itself,
+// as a public key without member, or with another one, would reach the devices outside its scope.
+func (s *service) checkPublicKeyMember(ctx context.Context, tenant, userID, member string) error {
+	namespace, err := s.store.NamespaceGet(ctx, tenant)
+	if err != nil {
+		return NewErrNamespaceNotFound(tenant, err)
+	}
+
+	if editor, ok := guard.CheckMember(namespace, userID); ok && !editor.Scope.IsEmpty() && member != userID {
+		return guard.ErrForbidden
+	}
+
+	if member == "" {
+		return nil
+	}
+
+	if _, ok := guard.CheckMember(namespace, member); !ok {
+		return NewErrNamespaceMemberNotFound(member, nil)
+	}
+
+	return nil
+}
+
 func (s *service) DeletePublicKey(ctx context.Context, fingerprint, tenant string) error {
 	_, err := s.store.NamespaceGet(ctx, tenant)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
@@ -133,6 +134,119 @@ func TestEvaluateKeyFilter(t *testing.T) {
 	mock.AssertExpectations(t)
 }
 
+func TestEvaluateKeyMember(t *testing.T) {
+	mock := &mocks.Store{}
+
+	ctx := context.TODO()
+
+	type Expected struct {
+		bool
+		error
+	}
+
+	namespace := &models.Namespace{
+		TenantID: "00000000-0000-4000-0000-000000000000",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "contractorID", Role: guard.RoleObserver, Scope: &models.MemberScope{Tags: []string{"customer"}}},
+		},
+	}
+
+	cases := []struct {
+		description   string
+		key           *models.PublicKey
+		device        models.Device
+		requiredMocks func()
+		expected      Expected
+	}{
+		{
+			description: "success to evaluate when key has no member and no member has a scope",
+			key: &models.PublicKey{
+				TenantID: "tenant",
+			},
+			device: models.Device{},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, "tenant").Return(&models.Namespace{
+					TenantID: "tenant",
+					Members:  []models.Member{{ID: "ownerID", Role: guard.RoleOwner}},
+				}, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+		{
+			description: "fail to evaluate when key has no member and a member has a scope",
+			key: &models.PublicKey{
+				TenantID: namespace.TenantID,
+			},
+			device: models.Device{UID: "uid", Tags: []string{"customer"}},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "fail to evaluate when key's member is not in the namespace",
+			key: &models.PublicKey{
+				TenantID:        namespace.TenantID,
+				PublicKeyFields: models.PublicKeyFields{Member: "removedID"},
+			},
+			device: models.Device{},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "fail to evaluate when device is out of the member's scope",
+			key: &models.PublicKey{
+				TenantID:        namespace.TenantID,
+				PublicKeyFields: models.PublicKeyFields{Member: "contractorID"},
+			},
+			device: models.Device{UID: "uid", Tags: []string{"other"}},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{false, nil},
+		},
+		{
+			description: "success to evaluate when device is inside the member's scope",
+			key: &models.PublicKey{
+				TenantID:        namespace.TenantID,
+				PublicKeyFields: models.PublicKeyFields{Member: "contractorID"},
+			},
+			device: models.Device{UID: "uid", Tags: []string{"customer"}},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+		{
+			description: "success to evaluate when member has no scope",
+			key: &models.PublicKey{
+				TenantID:        namespace.TenantID,
+				PublicKeyFields: models.PublicKeyFields{Member: "ownerID"},
+			},
+			device: models.Device{UID: "uid"},
+			requiredMocks: func() {
+				mock.On("NamespaceGet", ctx, namespace.TenantID).Return(namespace, nil).Once()
+			},
+			expected: Expected{true, nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			tc.requiredMocks()
+
+			service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+			ok, err := service.EvaluateKeyMember(ctx, tc.key, tc.device)
+			assert.Equal(t, tc.expected, Expected{ok, err})
+		})
+	}
+
+	mock.AssertExpectations(t)
+}
+
 func TestListPublicKeys(t *testing.T) {
 	mock := &mocks.Store{}
 
@@ -432,6 +546,46 @@ func TestUpdatePublicKeys(t *testing.T) {
 				},
 			}, nil},
 		},
+		{
+			description: "Successful update the key with restrictions",
+			fingerprint: "fingerprint",
+			tenantID:    "tenant",
+			keyUpdate: requests.PublicKeyUpdate{
+				Filter: requests.PublicKeyFilter{
+					Hostname: ".*",
+				},
+				Restrictions: &requests.PublicKeyRestrictions{
+					SessionTypes:    []string{models.PublicKeySessionExec},
+					AllowedCommands: []string{"restic .*"},
+				},
+			},
+			requiredMocks: func() {
+				model := models.PublicKeyUpdate{
+					PublicKeyFields: models.PublicKeyFields{
+						Filter: models.PublicKeyFilter{
+							Hostname: ".*",
+						},
+						Restrictions: &models.PublicKeyRestrictions{
+							SessionTypes:    []string{models.PublicKeySessionExec},
+							AllowedCommands: []string{"restic .*"},
+						},
+					},
+				}
+
+				mock.On("PublicKeyUpdate", ctx, "fingerprint", "tenant", &model).Return(&models.PublicKey{PublicKeyFields: model.PublicKeyFields}, nil).Once()
+			},
+			expected: Expected{&models.PublicKey{
+				PublicKeyFields: models.PublicKeyFields{
+					Filter: models.PublicKeyFilter{
+						Hostname: ".*",
+					},
+					Restrictions: &models.PublicKeyRestrictions{
+						SessionTypes:    []string{models.PublicKeySessionExec},
+						AllowedCommands: []string{"restic .*"},
+					},
+				},
+			}, nil},
+		},
 	}
 
 	for _, tc := range cases {
@@ -995,3 +1149,70 @@ func TestCreatePublicKeys(t *testing.T) {
 
 	mock.AssertExpectations(t)
 }
+
+func TestPublicKeyScopedMember(t *testing.T) {
+	mock := new(mocks.Store)
+
+	ctx := context.TODO()
+
+	namespace := &models.Namespace{
+		TenantID: "tenant",
+		Members: []models.Member{
+			{ID: "ownerID", Role: guard.RoleOwner},
+			{ID: "scopedID", Role: guard.RoleAdministrator, Scope: &models.MemberScope{Tags: []string{"production"}}},
+		},
+	}
+
+	s := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	cases := []struct {
+		description string
+		userID      string
+		member      string
+		expected    error
+	}{
+		{
+			description: "fails when a scoped member creates a key without member",
+			userID:      "scopedID",
+			member:      "",
+			expected:    guard.ErrForbidden,
+		},
+		{
+			description: "fails when a scoped member creates a key to another member",
+			userID:      "scopedID",
+			member:      "ownerID",
+			expected:    guard.ErrForbidden,
+		},
+		{
+			description: "allows a scoped member to create its own key",
+			userID:      "scopedID",
+			member:      "scopedID",
+			expected:    NewErrPublicKeyDataInvalid(nil, nil),
+		},
+		{
+			description: "allows a member without scope to create a key without member",
+			userID:      "ownerID",
+			member:      "",
+			expected:    NewErrPublicKeyDataInvalid(nil, nil),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+
+			// The invalid data stops the creation right after the member is checked.
+			_, err := s.CreatePublicKey(ctx, requests.PublicKeyCreate{TenantID: "tenant", UserID: tc.userID, Member: tc.member}, "tenant")
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+
+	t.Run("fails when a scoped member removes the member of a key", func(t *testing.T) {
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+
+		_, err := s.UpdatePublicKey(ctx, "fingerprint", "tenant", requests.PublicKeyUpdate{UserID: "scopedID"})
+		assert.Equal(t, guard.ErrForbidden, err)
+	})
+
+	mock.AssertExpectations(t)
+}
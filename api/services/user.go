@@ -5,12 +5,13 @@ import (
 
 	"github.com/shellhub-io/shellhub/pkg/api/requests"
 	"github.com/shellhub-io/shellhub/pkg/models"
-	"github.com/shellhub-io/shellhub/pkg/validator"
+	"github.com/shellhub-io/shellhub/pkg/password"
 )
 
 type UserService interface {
 	UpdateDataUser(ctx context.Context, id string, userData requests.UserDataUpdate) ([]string, error)
-	UpdatePasswordUser(ctx context.Context, id string, currentPassword, newPassword string) error
+	// UpdatePasswordUser changes the user's password, revoking the user's sessions except the current one.
+	UpdatePasswordUser(ctx context.Context, id, session string, currentPassword, newPassword string) error
 }
 
 // UpdateDataUser update user data.
@@ -50,19 +51,24 @@ func (s *service) UpdateDataUser(ctx context.Context, id string, userData reques
 	return nil, s.store.UserUpdateData(ctx, id, user)
 }
 
-func (s *service) UpdatePasswordUser(ctx context.Context, id, currentPassword, newPassword string) error {
+func (s *service) UpdatePasswordUser(ctx context.Context, id, session, currentPassword, newPassword string) error {
 	user, _, err := s.store.UserGetByID(ctx, id, false)
 	if user == nil {
 		return NewErrUserNotFound(id, err)
 	}
 
-	currentPassword = validator.HashPassword(currentPassword)
-
-	if user.Password != currentPassword {
+	if !password.Compare(currentPassword, user.Password) {
 		return NewErrUserPasswordNotMatch(nil)
 	}
 
-	newPassword = validator.HashPassword(newPassword)
+	hash, err := password.Hash(newPassword)
+	if err != nil {
+		return NewErrUserPasswordInvalid(err)
+	}
+
+	if err := s.store.UserUpdatePassword(ctx, hash, id); err != nil {
+		return err
+	}
 
-	return s.store.UserUpdatePassword(ctx, newPassword, id)
+	return s.revokeUserSessions(ctx, id, session)
 }
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/mail"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// PasswordResetExpiry is the time that the user has to reset the password with the link sent by e-mail.
+	PasswordResetExpiry = time.Hour
+	// EmailConfirmationExpiry is the time that the user has to confirm the e-mail with the link sent by e-mail.
+	EmailConfirmationExpiry = 24 * time.Hour
+	// UserTokenThrottle is the minimum time between two e-mails sent to the same user, so the flows cannot be used to
+	// flood the user's mailbox.
+	UserTokenThrottle = time.Minute
+)
+
+// The kinds of the tokens sent by e-mail. A token is only valid to the flow that issued it.
+const (
+	userTokenPasswordReset     = "password_reset"
+	userTokenEmailConfirmation = "email_confirmation"
+)
+
+type UserRecoveryService interface {
+	// RecoverPassword sends to the user, identified by its username or e-mail, a link to reset its password. It does not
+	// fail when the user is not found, so it cannot be used to discover the users.
+	RecoverPassword(ctx context.Context, username string) error
+	// ResetPassword changes the user's password when the token sent by RecoverPassword is valid, revoking all the
+	// user's sessions.
+	ResetPassword(ctx context.Context, id, token, newPassword string) error
+	// SendEmailConfirmation sends to the not confirmed user, identified by its username or e-mail, a link to confirm its
+	// e-mail. Like RecoverPassword, it does not fail when the user is not found.
+	SendEmailConfirmation(ctx context.Context, username string) error
+	// ConfirmEmail confirms the user's e-mail when the token sent by SendEmailConfirmation is valid.
+	ConfirmEmail(ctx context.Context, id, token string) error
+}
+
+func (s *service) RecoverPassword(ctx context.Context, username string) error {
+	if s.mailer == nil {
+		return NewErrMailNotConfigured(nil)
+	}
+
+	user, err := s.userByLogin(ctx, username)
+	// The users of an identity provider do not have a local password, and the not confirmed ones must confirm their
+	// e-mail first.
+	if err != nil || user.Identity != nil || !user.Confirmed {
+		return nil
+	}
+
+	return s.sendUserToken(ctx, user, userTokenPasswordReset, func(link string) mail.Message {
+		return mail.Message{
+			To:      user.Email,
+			Subject: "Reset your ShellHub password",
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"A password reset was requested to your ShellHub account. Use the link below, valid for %s, to choose a new password:\n\n"+
+				"%s\n\n"+
+				"If you did not request it, ignore this e-mail and your password will not change.\n",
+				user.Username, PasswordResetExpiry, link),
+		}
+	})
+}
+
+func (s *service) ResetPassword(ctx context.Context, id, token, newPassword string) error {
+	if s.mailer == nil {
+		return NewErrMailNotConfigured(nil)
+	}
+
+	if err := s.verifyUserToken(ctx, id, userTokenPasswordReset, token, PasswordResetExpiry); err != nil {
+		return err
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, id, false)
+	if err != nil {
+		return NewErrUserNotFound(id, err)
+	}
+
+	hash, err := password.Hash(newPassword)
+	if err != nil {
+		return NewErrUserPasswordInvalid(err)
+	}
+
+	if err := s.store.UserUpdatePassword(ctx, hash, user.ID); err != nil {
+		return NewErrUserUpdate(user, err)
+	}
+
+	// The password may have been reset because the account was locked by someone guessing it.
+	s.resetAttempts(ctx, strings.ToLower(user.Username))
+	s.resetAttempts(ctx, strings.ToLower(user.Email))
+
+	return s.revokeUserSessions(ctx, user.ID, "")
+}
+
+func (s *service) SendEmailConfirmation(ctx context.Context, username string) error {
+	if s.mailer == nil {
+		return NewErrMailNotConfigured(nil)
+	}
+
+	user, err := s.userByLogin(ctx, username)
+	if err != nil || user.Confirmed {
+		return nil
+	}
+
+	return s.sendUserToken(ctx, user, userTokenEmailConfirmation, func(link string) mail.Message {
+		return mail.Message{
+			To:      user.Email,
+			Subject: "Confirm your ShellHub e-mail",
+			Body: fmt.Sprintf("Hello %s,\n\n"+
+				"Use the link below, valid for %s, to confirm the e-mail of your ShellHub account:\n\n"+
+				"%s\n\n"+
+				"If you did not create the account, ignore this e-mail.\n",
+				user.Username, EmailConfirmationExpiry, link),
+		}
+	})
+}
+
+func (s *service) ConfirmEmail(ctx context.Context, id, token string) error {
+	if s.mailer == nil {
+		return NewErrMailNotConfigured(nil)
+	}
+
+	if err := s.verifyUserToken(ctx, id, userTokenEmailConfirmation, token, EmailConfirmationExpiry); err != nil {
+		return err
+	}
+
+	if err := s.store.UserUpdateAccountStatus(ctx, id); err != nil {
+		return NewErrUserNotFound(id, err)
+	}
+
+	return nil
+}
+
+// userByLogin gets the user by its username or, when not found, by its e-mail.
+func (s *service) userByLogin(ctx context.Context, login string) (*models.User, error) {
+	login = strings.ToLower(login)
+
+	user, err := s.store.UserGetByUsername(ctx, login)
+	if err != nil {
+		return s.store.UserGetByEmail(ctx, login)
+	}
+
+	return user, nil
+}
+
+// sendUserToken replaces the user's token by a new one of the kind and sends it to the user's e-mail in the link to the
+// flow's page. When a token was sent less than UserTokenThrottle ago, nothing is sent.
+func (s *service) sendUserToken(ctx context.Context, user *models.User, kind string, message func(link string) mail.Message) error {
+	now := clock.Now()
+
+	if previous, err := s.store.UserGetToken(ctx, user.ID); err == nil && now.Sub(previous.CreatedAt) < UserTokenThrottle {
+		return nil
+	}
+
+	token, err := randomString(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UserDeleteTokens(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err := s.store.UserCreateToken(ctx, &models.UserTokenRecover{Token: hashUserToken(kind, token), User: user.ID, CreatedAt: now}); err != nil {
+		return err
+	}
+
+	path := map[string]string{
+		userTokenPasswordReset:     "/reset-password",
+		userTokenEmailConfirmation: "/confirm-email",
+	}[kind]
+
+	if err := s.mailer.Send(ctx, message(s.mailer.URL(path, url.Values{"id": {user.ID}, "token": {token}}))); err != nil {
+		logrus.WithError(err).WithField("id", user.ID).Error("failed to send the e-mail to the user")
+
+		return err
+	}
+
+	return nil
+}
+
+// verifyUserToken checks if the token is the user's token of the kind and was not expired, deleting it, so it can only
+// be used once.
+func (s *service) verifyUserToken(ctx context.Context, id, kind, token string, expiry time.Duration) error {
+	stored, err := s.store.UserGetToken(ctx, id)
+	if err != nil {
+		return NewErrUserTokenInvalid(err)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashUserToken(kind, token)), []byte(stored.Token)) != 1 {
+		return NewErrUserTokenInvalid(nil)
+	}
+
+	if !clock.Now().Before(stored.CreatedAt.Add(expiry)) {
+		return NewErrUserTokenInvalid(nil)
+	}
+
+	// The token is consumed by its deletion, which only one of the concurrent requests with the same token does.
+	if err := s.store.UserDeleteToken(ctx, id, stored.Token); err != nil {
+		if errors.Is(err, store.ErrNoDocuments) {
+			return NewErrUserTokenInvalid(err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// hashUserToken hashes a token sent by e-mail to be stored, binding it to its kind.
+func hashUserToken(kind, token string) string {
+	sum := sha256.Sum256([]byte(kind + ":" + token))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"io"
+	"mime/quotedprintable"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/mail"
+	"github.com/shellhub-io/shellhub/api/pkg/mail/mailtest"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+// newTestMailer creates a mailer that delivers the e-mails to a local SMTP sink.
+func newTestMailer(t *testing.T) (*mail.Mailer, *mailtest.Server) {
+	t.Helper()
+
+	server := mailtest.NewServer()
+	t.Cleanup(server.Close)
+
+	mailer, err := mail.NewMailer(mail.Config{
+		Host:    server.Host,
+		Port:    server.Port,
+		From:    "ShellHub <noreply@example.com>",
+		TLS:     mail.TLSNone,
+		BaseURL: "https://shellhub.example.com",
+		Timeout: 5 * time.Second,
+	})
+	assert.NoError(t, err)
+
+	return mailer, server
+}
+
+// linkOf returns the link sent in the e-mail.
+func linkOf(t *testing.T, msg mailtest.Message) *url.URL {
+	t.Helper()
+
+	_, encoded, _ := strings.Cut(msg.Data, "\r\n\r\n")
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(encoded)))
+	assert.NoError(t, err)
+
+	link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(string(body)))
+	assert.NoError(t, err)
+
+	return link
+}
+
+func TestRecoverPassword(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{ID: "507f1f77bcf86cd799439011", Confirmed: true, UserData: models.UserData{Username: "john", Email: "john@example.com"}}
+
+	t.Run("sends the link to reset the password", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		var stored *models.UserTokenRecover
+
+		mock.On("UserGetByUsername", ctx, "john@example.com").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", ctx, "john@example.com").Return(user, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetToken", ctx, user.ID).Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserDeleteTokens", ctx, user.ID).Return(nil).Once()
+		mock.On("UserCreateToken", ctx, testifymock.MatchedBy(func(token *models.UserTokenRecover) bool {
+			stored = token
+
+			return token.User == user.ID && token.CreatedAt.Equal(now)
+		})).Return(nil).Once()
+
+		assert.NoError(t, service.RecoverPassword(ctx, "John@example.com"))
+
+		messages := server.Messages()
+		assert.Len(t, messages, 1)
+		assert.Equal(t, []string{"john@example.com"}, messages[0].To)
+
+		link := linkOf(t, messages[0])
+		assert.Equal(t, "/reset-password", link.Path)
+		assert.Equal(t, user.ID, link.Query().Get("id"))
+		// Only the token's hash is stored.
+		assert.Equal(t, hashUserToken(userTokenPasswordReset, link.Query().Get("token")), stored.Token)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not fail when the user is not found", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetByUsername", ctx, "unknown").Return(nil, store.ErrNoDocuments).Once()
+		mock.On("UserGetByEmail", ctx, "unknown").Return(nil, store.ErrNoDocuments).Once()
+
+		assert.NoError(t, service.RecoverPassword(ctx, "unknown"))
+		assert.Empty(t, server.Messages())
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not send to the users of an identity provider", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		external := *user
+		external.Identity = &models.UserIdentity{Provider: models.UserIdentityLDAP, Subject: "uid=john"}
+
+		mock.On("UserGetByUsername", ctx, "john").Return(&external, nil).Once()
+
+		assert.NoError(t, service.RecoverPassword(ctx, "john"))
+		assert.Empty(t, server.Messages())
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not send again before the throttle", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetByUsername", ctx, "john").Return(user, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetToken", ctx, user.ID).Return(&models.UserTokenRecover{User: user.ID, CreatedAt: now.Add(-time.Second)}, nil).Once()
+
+		assert.NoError(t, service.RecoverPassword(ctx, "john"))
+		assert.Empty(t, server.Messages())
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the e-mail delivery is not configured", func(t *testing.T) {
+		service := NewService(store.Store(new(mocks.Store)), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		assert.Equal(t, NewErrMailNotConfigured(nil), service.RecoverPassword(ctx, "john"))
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{ID: "507f1f77bcf86cd799439011", Confirmed: true, UserData: models.UserData{Username: "john", Email: "john@example.com"}}
+	token := &models.UserTokenRecover{Token: hashUserToken(userTokenPasswordReset, "token"), User: user.ID, CreatedAt: now.Add(-time.Minute)}
+
+	t.Run("changes the password and revokes the sessions", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetToken", ctx, user.ID).Return(token, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserDeleteToken", ctx, user.ID, token.Token).Return(nil).Once()
+		mock.On("UserGetByID", ctx, user.ID, false).Return(user, 0, nil).Once()
+		mock.On("UserUpdatePassword", ctx, hashOf("newpassword"), user.ID).Return(nil).Once()
+		mock.On("UserSessionList", ctx, user.ID).Return([]models.UserSession{{ID: "session"}}, nil).Once()
+		mock.On("UserSessionDeleteAll", ctx, user.ID, "").Return(nil).Once()
+
+		assert.NoError(t, service.ResetPassword(ctx, user.ID, "token", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token was already used", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		// A concurrent request with the same token consumed it between the checks and the deletion.
+		mock.On("UserGetToken", ctx, user.ID).Return(token, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserDeleteToken", ctx, user.ID, token.Token).Return(store.ErrNoDocuments).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(store.ErrNoDocuments), service.ResetPassword(ctx, user.ID, "token", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token is wrong", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetToken", ctx, user.ID).Return(token, nil).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(nil), service.ResetPassword(ctx, user.ID, "wrong", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token was issued to confirm the e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		confirmation := *token
+		confirmation.Token = hashUserToken(userTokenEmailConfirmation, "token")
+
+		mock.On("UserGetToken", ctx, user.ID).Return(&confirmation, nil).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(nil), service.ResetPassword(ctx, user.ID, "token", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token expired", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		expired := *token
+		expired.CreatedAt = now.Add(-PasswordResetExpiry)
+
+		mock.On("UserGetToken", ctx, user.ID).Return(&expired, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(nil), service.ResetPassword(ctx, user.ID, "token", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token was used", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetToken", ctx, user.ID).Return(nil, store.ErrNoDocuments).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(store.ErrNoDocuments), service.ResetPassword(ctx, user.ID, "token", "newpassword"))
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestSendEmailConfirmation(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{ID: "507f1f77bcf86cd799439011", UserData: models.UserData{Username: "john", Email: "john@example.com"}}
+
+	t.Run("sends the link to confirm the e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		var stored *models.UserTokenRecover
+
+		mock.On("UserGetByUsername", ctx, "john").Return(user, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetToken", ctx, user.ID).Return(&models.UserTokenRecover{User: user.ID, CreatedAt: now.Add(-UserTokenThrottle)}, nil).Once()
+		mock.On("UserDeleteTokens", ctx, user.ID).Return(nil).Once()
+		mock.On("UserCreateToken", ctx, testifymock.MatchedBy(func(token *models.UserTokenRecover) bool {
+			stored = token
+
+			return token.User == user.ID
+		})).Return(nil).Once()
+
+		assert.NoError(t, service.SendEmailConfirmation(ctx, "john"))
+
+		messages := server.Messages()
+		assert.Len(t, messages, 1)
+
+		link := linkOf(t, messages[0])
+		assert.Equal(t, "/confirm-email", link.Path)
+		assert.Equal(t, hashUserToken(userTokenEmailConfirmation, link.Query().Get("token")), stored.Token)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("does not send to the confirmed users", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, server := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		confirmed := *user
+		confirmed.Confirmed = true
+
+		mock.On("UserGetByUsername", ctx, "john").Return(&confirmed, nil).Once()
+
+		assert.NoError(t, service.SendEmailConfirmation(ctx, "john"))
+		assert.Empty(t, server.Messages())
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestConfirmEmail(t *testing.T) {
+	ctx := context.TODO()
+
+	id := "507f1f77bcf86cd799439011"
+
+	t.Run("confirms the e-mail", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetToken", ctx, id).Return(&models.UserTokenRecover{Token: hashUserToken(userTokenEmailConfirmation, "token"), User: id, CreatedAt: now.Add(-time.Hour)}, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserDeleteToken", ctx, id, hashUserToken(userTokenEmailConfirmation, "token")).Return(nil).Once()
+		mock.On("UserUpdateAccountStatus", ctx, id).Return(nil).Once()
+
+		assert.NoError(t, service.ConfirmEmail(ctx, id, "token"))
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the token expired", func(t *testing.T) {
+		mock := new(mocks.Store)
+		mailer, _ := newTestMailer(t)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil, WithMailer(mailer))
+
+		mock.On("UserGetToken", ctx, id).Return(&models.UserTokenRecover{Token: hashUserToken(userTokenEmailConfirmation, "token"), User: id, CreatedAt: now.Add(-EmailConfirmationExpiry)}, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+
+		assert.Equal(t, NewErrUserTokenInvalid(nil), service.ConfirmEmail(ctx, id, "token"))
+
+		mock.AssertExpectations(t)
+	})
+}
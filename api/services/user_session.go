@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// UserTokenExpiry is the lifetime of the user's token. After it, a new token is got with the refresh token.
+	UserTokenExpiry = 15 * time.Minute
+	// UserSessionExpiry is the time that a session is kept without being refreshed.
+	UserSessionExpiry = 30 * 24 * time.Hour
+)
+
+type UserSessionService interface {
+	// AuthRefresh issues a new token to the session of the refresh token, rotating it. When a refresh token is used
+	// again after its rotation, it may have been stolen, so its session is revoked.
+	AuthRefresh(ctx context.Context, req requests.AuthRefresh) (*models.UserAuthResponse, error)
+	// AuthIsUserSessionActive checks if the session was neither revoked nor expired, so its tokens are still valid.
+	AuthIsUserSessionActive(ctx context.Context, id string) (bool, error)
+	// UserSessionList lists the user's active sessions, marking the current one.
+	UserSessionList(ctx context.Context, userID string, current string) ([]models.UserSession, error)
+	// UserSessionDelete revokes the user's session, invalidating its refresh token and tokens.
+	UserSessionDelete(ctx context.Context, userID string, id string) error
+}
+
+func (s *service) AuthRefresh(ctx context.Context, req requests.AuthRefresh) (*models.UserAuthResponse, error) {
+	id, _, ok := strings.Cut(req.RefreshToken, ".")
+	if !ok {
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	session, err := s.store.UserSessionGet(ctx, id)
+	if err != nil {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	now := clock.Now()
+	if !now.Before(session.ExpiresAt) {
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	previous := hashRefreshToken(req.RefreshToken)
+	if subtle.ConstantTimeCompare([]byte(previous), []byte(session.RefreshToken)) != 1 {
+		logrus.WithFields(logrus.Fields{
+			"id":      session.ID,
+			"user_id": session.UserID,
+		}).Warn("refresh token reused, revoking the user's session")
+
+		if err := s.UserSessionDelete(ctx, session.UserID, session.ID); err != nil {
+			logrus.WithError(err).WithField("id", session.ID).Warn("failed to revoke the user's session")
+		}
+
+		return nil, NewErrAuthUnathorized(nil)
+	}
+
+	user, _, err := s.store.UserGetByID(ctx, session.UserID, false)
+	if err != nil {
+		return nil, NewErrAuthUnathorized(err)
+	}
+
+	var namespace *models.Namespace
+	if req.Tenant != "" {
+		if namespace, err = s.store.NamespaceGet(ctx, req.Tenant); err != nil {
+			return nil, NewErrNamespaceNotFound(req.Tenant, err)
+		}
+
+		if _, ok := memberRole(namespace, user.ID); !ok {
+			return nil, NewErrNamespaceMemberNotFound(user.ID, nil)
+		}
+
+		if namespace.Settings != nil && namespace.Settings.RequireMFA && !user.MFA.Enabled {
+			return nil, NewErrMFARequired(nil)
+		}
+	} else {
+		namespace, _ = s.store.NamespaceGetFirst(ctx, user.ID)
+	}
+
+	var tenant string
+	if namespace != nil {
+		tenant = namespace.TenantID
+	}
+
+	role, _ := memberRole(namespace, user.ID)
+
+	refreshToken, hash, err := newRefreshToken(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.signUserToken(user, tenant, role, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.RefreshToken = hash
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(UserSessionExpiry)
+
+	if ip, userAgent := gateway.ClientFromContext(ctx); ip != "" || userAgent != "" {
+		session.IP = ip
+		session.UserAgent = userAgent
+	}
+
+	if err := s.store.UserSessionRefresh(ctx, session, previous); err != nil {
+		if err == store.ErrNoDocuments {
+			return nil, NewErrAuthUnathorized(err)
+		}
+
+		return nil, err
+	}
+
+	s.cacheUserSession(ctx, session.ID)
+	s.AuthCacheToken(ctx, tenant, user.ID, token) // nolint: errcheck
+
+	return &models.UserAuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		Name:         user.Name,
+		ID:           user.ID,
+		User:         user.Username,
+		Tenant:       tenant,
+		Role:         role,
+		Email:        user.Email,
+	}, nil
+}
+
+func (s *service) AuthIsUserSessionActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	if err := s.cache.Get(ctx, "user_session/"+id, &active); err == nil && active {
+		return true, nil
+	}
+
+	session, err := s.store.UserSessionGet(ctx, id)
+	switch {
+	case err == store.ErrNoDocuments:
+		return false, nil
+	case err != nil:
+		return false, err
+	case !clock.Now().Before(session.ExpiresAt):
+		return false, nil
+	}
+
+	s.cacheUserSession(ctx, session.ID)
+
+	return true, nil
+}
+
+func (s *service) UserSessionList(ctx context.Context, userID string, current string) ([]models.UserSession, error) {
+	sessions, err := s.store.UserSessionList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := clock.Now()
+
+	// The expired sessions are removed by the database some time after their expiration.
+	active := make([]models.UserSession, 0, len(sessions))
+	for _, session := range sessions {
+		if !now.Before(session.ExpiresAt) {
+			continue
+		}
+
+		session.Current = session.ID == current
+		active = append(active, session)
+	}
+
+	return active, nil
+}
+
+func (s *service) UserSessionDelete(ctx context.Context, userID string, id string) error {
+	if err := s.store.UserSessionDelete(ctx, userID, id); err != nil {
+		if err == store.ErrNoDocuments {
+			return NewErrUserSessionNotFound(id, err)
+		}
+
+		return err
+	}
+
+	s.uncacheUserSession(ctx, id)
+
+	return nil
+}
+
+// createUserSession generates a new session to the user's login, returning it and its refresh token. Its times are set
+// when the login is completed.
+func (s *service) createUserSession(ctx context.Context, user *models.User) (*models.UserSession, string, error) {
+	id, err := randomString(16)
+	if err != nil {
+		return nil, "", err
+	}
+
+	refreshToken, hash, err := newRefreshToken(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ip, userAgent := gateway.ClientFromContext(ctx)
+
+	session := &models.UserSession{
+		ID:           id,
+		UserID:       user.ID,
+		RefreshToken: hash,
+		UserAgent:    userAgent,
+		IP:           ip,
+	}
+
+	return session, refreshToken, nil
+}
+
+// revokeUserSessions deletes the user's sessions, except the one whose ID is except, invalidating their tokens.
+func (s *service) revokeUserSessions(ctx context.Context, userID string, except string) error {
+	sessions, err := s.store.UserSessionList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UserSessionDeleteAll(ctx, userID, except); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID != except {
+			s.uncacheUserSession(ctx, session.ID)
+		}
+	}
+
+	return nil
+}
+
+// signUserToken signs the user's token to the namespace, bound to the session.
+func (s *service) signUserToken(user *models.User, tenant, role, session string) (string, error) {
+	token, err := s.keys.Sign(models.UserAuthClaims{
+		Username:  user.Username,
+		Admin:     true,
+		Tenant:    tenant,
+		Role:      role,
+		ID:        user.ID,
+		SessionID: session,
+		AuthClaims: models.AuthClaims{
+			Claims: "user",
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(clock.Now().Add(UserTokenExpiry)),
+		},
+	})
+	if err != nil {
+		return "", NewErrTokenSigned(err)
+	}
+
+	return token, nil
+}
+
+// cacheUserSession caches that the session is active for the lifetime of a token, sparing the store on each request.
+func (s *service) cacheUserSession(ctx context.Context, id string) {
+	if err := s.cache.Set(ctx, "user_session/"+id, true, UserTokenExpiry); err != nil {
+		logrus.WithError(err).WithField("id", id).Warn("failed to cache the user's session")
+	}
+}
+
+func (s *service) uncacheUserSession(ctx context.Context, id string) {
+	if err := s.cache.Delete(ctx, "user_session/"+id); err != nil {
+		logrus.WithError(err).WithField("id", id).Warn("failed to uncache the user's session")
+	}
+}
+
+// memberRole returns the user's role in the namespace and if the user is a member of it.
+func memberRole(namespace *models.Namespace, userID string) (string, bool) {
+	if namespace == nil {
+		return "", false
+	}
+
+	for _, member := range namespace.Members {
+		if member.ID == userID {
+			return member.Role, true
+		}
+	}
+
+	return "", false
+}
+
+// newRefreshToken generates a refresh token to the session, returning it and its hash.
+func newRefreshToken(session string) (string, string, error) {
+	secret, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := session + "." + secret
+
+	return token, hashRefreshToken(token), nil
+}
+
+// hashRefreshToken hashes a refresh token to be stored.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString generates a URL safe string from size random bytes.
+func randomString(size int) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
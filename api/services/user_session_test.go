@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mocks"
+	"github.com/shellhub-io/shellhub/pkg/api/requests"
+	storecache "github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+)
+
+func TestAuthRefresh(t *testing.T) {
+	ctx := context.TODO()
+
+	user := &models.User{ID: "id", UserData: models.UserData{Username: "john"}}
+	namespace := &models.Namespace{TenantID: "tenant", Members: []models.Member{{ID: "id", Role: guard.RoleOperator}}}
+
+	// newSession returns a session whose refresh token is the returned one.
+	newSession := func(t *testing.T) (*models.UserSession, string) {
+		t.Helper()
+
+		refreshToken, hash, err := newRefreshToken("session")
+		assert.NoError(t, err)
+
+		return &models.UserSession{ID: "session", UserID: "id", RefreshToken: hash, ExpiresAt: now.Add(time.Hour)}, refreshToken
+	}
+
+	t.Run("issues a new token and rotates the refresh token", func(t *testing.T) {
+		mock := new(mocks.Store)
+		cache := newMemoryCache()
+		service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+		session, refreshToken := newSession(t)
+		previous := session.RefreshToken
+
+		mock.On("UserSessionGet", ctx, "session").Return(session, nil).Once()
+		clockMock.On("Now").Return(now).Twice()
+		mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+		mock.On("NamespaceGet", ctx, "tenant").Return(namespace, nil).Once()
+		mock.On("UserSessionRefresh", ctx, testifymock.MatchedBy(func(session *models.UserSession) bool {
+			return session.RefreshToken != previous && session.LastUsedAt.Equal(now) && session.ExpiresAt.Equal(now.Add(UserSessionExpiry))
+		}), previous).Return(nil).Once()
+
+		res, err := service.AuthRefresh(ctx, requests.AuthRefresh{RefreshToken: refreshToken, Tenant: "tenant"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, res.Token)
+		assert.NotEqual(t, refreshToken, res.RefreshToken)
+		assert.Equal(t, "tenant", res.Tenant)
+		assert.Equal(t, guard.RoleOperator, res.Role)
+
+		var active bool
+		assert.NoError(t, cache.Get(ctx, "user_session/session", &active))
+		assert.True(t, active)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("revokes the session when a rotated refresh token is used", func(t *testing.T) {
+		mock := new(mocks.Store)
+		cache := newMemoryCache()
+		service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+		session, _ := newSession(t)
+		rotated, _, err := newRefreshToken("session")
+		assert.NoError(t, err)
+
+		assert.NoError(t, cache.Set(ctx, "user_session/session", true, time.Minute))
+
+		mock.On("UserSessionGet", ctx, "session").Return(session, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserSessionDelete", ctx, "id", "session").Return(nil).Once()
+
+		_, err = service.AuthRefresh(ctx, requests.AuthRefresh{RefreshToken: rotated})
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+
+		var active bool
+		assert.NoError(t, cache.Get(ctx, "user_session/session", &active))
+		assert.False(t, active)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the session expired", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		session, refreshToken := newSession(t)
+		session.ExpiresAt = now
+
+		mock.On("UserSessionGet", ctx, "session").Return(session, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+
+		_, err := service.AuthRefresh(ctx, requests.AuthRefresh{RefreshToken: refreshToken})
+		assert.Equal(t, NewErrAuthUnathorized(nil), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the session was revoked", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		_, refreshToken := newSession(t)
+
+		mock.On("UserSessionGet", ctx, "session").Return(nil, store.ErrNoDocuments).Once()
+
+		_, err := service.AuthRefresh(ctx, requests.AuthRefresh{RefreshToken: refreshToken})
+		assert.Equal(t, NewErrAuthUnathorized(store.ErrNoDocuments), err)
+
+		mock.AssertExpectations(t)
+	})
+
+	t.Run("fails when the user is not a member of the namespace", func(t *testing.T) {
+		mock := new(mocks.Store)
+		service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+		session, refreshToken := newSession(t)
+
+		mock.On("UserSessionGet", ctx, "session").Return(session, nil).Once()
+		clockMock.On("Now").Return(now).Once()
+		mock.On("UserGetByID", ctx, "id", false).Return(user, 0, nil).Once()
+		mock.On("NamespaceGet", ctx, "other").Return(&models.Namespace{TenantID: "other"}, nil).Once()
+
+		_, err := service.AuthRefresh(ctx, requests.AuthRefresh{RefreshToken: refreshToken, Tenant: "other"})
+		assert.Equal(t, NewErrNamespaceMemberNotFound("id", nil), err)
+
+		mock.AssertExpectations(t)
+	})
+}
+
+func TestAuthIsUserSessionActive(t *testing.T) {
+	ctx := context.TODO()
+
+	mock := new(mocks.Store)
+	cache := newMemoryCache()
+	service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+	// The session is got from the store once, then from the cache.
+	mock.On("UserSessionGet", ctx, "active").Return(&models.UserSession{ID: "active", ExpiresAt: now.Add(time.Hour)}, nil).Once()
+	clockMock.On("Now").Return(now).Once()
+
+	for i := 0; i < 2; i++ {
+		active, err := service.AuthIsUserSessionActive(ctx, "active")
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+
+	mock.On("UserSessionGet", ctx, "expired").Return(&models.UserSession{ID: "expired", ExpiresAt: now}, nil).Once()
+	clockMock.On("Now").Return(now).Once()
+
+	active, err := service.AuthIsUserSessionActive(ctx, "expired")
+	assert.NoError(t, err)
+	assert.False(t, active)
+
+	mock.On("UserSessionGet", ctx, "revoked").Return(nil, store.ErrNoDocuments).Once()
+
+	active, err = service.AuthIsUserSessionActive(ctx, "revoked")
+	assert.NoError(t, err)
+	assert.False(t, active)
+
+	mock.AssertExpectations(t)
+}
+
+func TestUserSessionList(t *testing.T) {
+	ctx := context.TODO()
+
+	mock := new(mocks.Store)
+	service := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
+
+	mock.On("UserSessionList", ctx, "id").Return([]models.UserSession{
+		{ID: "current", ExpiresAt: now.Add(time.Hour)},
+		{ID: "other", ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", ExpiresAt: now},
+	}, nil).Once()
+	clockMock.On("Now").Return(now).Once()
+
+	sessions, err := service.UserSessionList(ctx, "id", "current")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UserSession{
+		{ID: "current", ExpiresAt: now.Add(time.Hour), Current: true},
+		{ID: "other", ExpiresAt: now.Add(time.Hour)},
+	}, sessions)
+
+	mock.AssertExpectations(t)
+}
+
+func TestUserSessionDelete(t *testing.T) {
+	ctx := context.TODO()
+
+	mock := new(mocks.Store)
+	cache := newMemoryCache()
+	service := NewService(store.Store(mock), privateKey, publicKey, cache, clientMock, nil)
+
+	assert.NoError(t, cache.Set(ctx, "user_session/session", true, time.Minute))
+
+	mock.On("UserSessionDelete", ctx, "id", "session").Return(nil).Once()
+	mock.On("UserSessionDelete", ctx, "id", "unknown").Return(store.ErrNoDocuments).Once()
+
+	assert.NoError(t, service.UserSessionDelete(ctx, "id", "session"))
+	assert.Equal(t, NewErrUserSessionNotFound("unknown", store.ErrNoDocuments), service.UserSessionDelete(ctx, "id", "unknown"))
+
+	var active bool
+	assert.NoError(t, cache.Get(ctx, "user_session/session", &active))
+	assert.False(t, active)
+
+	mock.AssertExpectations(t)
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
 	"testing"
 
 	"github.com/shellhub-io/shellhub/api/store"
@@ -10,7 +12,6 @@ import (
 	storecache "github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/errors"
 	"github.com/shellhub-io/shellhub/pkg/models"
-	"github.com/shellhub-io/shellhub/pkg/validator"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -244,7 +245,7 @@ func TestUpdatePasswordUser(t *testing.T) {
 			newPassword:     "newPassword",
 			requiredMocks: func() {
 				user := &models.User{
-					UserPassword: models.UserPassword{Password: validator.HashPassword("passwordNoMatch")},
+					UserPassword: models.UserPassword{Password: mustHash("passwordNoMatch")},
 				}
 
 				mock.On("UserGetByID", ctx, "1", false).Return(user, 1, nil).Once()
@@ -258,11 +259,30 @@ func TestUpdatePasswordUser(t *testing.T) {
 			newPassword:     "newPassword",
 			requiredMocks: func() {
 				user := &models.User{
-					UserPassword: models.UserPassword{Password: validator.HashPassword("password")},
+					UserPassword: models.UserPassword{Password: mustHash("password")},
 				}
 
 				mock.On("UserGetByID", ctx, "1", false).Return(user, 1, nil).Once()
-				mock.On("UserUpdatePassword", ctx, validator.HashPassword("newPassword"), "1").Return(nil).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("newPassword"), "1").Return(nil).Once()
+				mock.On("UserSessionList", ctx, "1").Return([]models.UserSession{{ID: "session"}, {ID: "other"}}, nil).Once()
+				mock.On("UserSessionDeleteAll", ctx, "1", "session").Return(nil).Once()
+			},
+			expected: nil,
+		},
+		{
+			description:     "Success to update user's password hashed with SHA256",
+			id:              "1",
+			currentPassword: "password",
+			newPassword:     "newPassword",
+			requiredMocks: func() {
+				user := &models.User{
+					UserPassword: models.UserPassword{Password: fmt.Sprintf("%x", sha256.Sum256([]byte("password")))},
+				}
+
+				mock.On("UserGetByID", ctx, "1", false).Return(user, 1, nil).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("newPassword"), "1").Return(nil).Once()
+				mock.On("UserSessionList", ctx, "1").Return([]models.UserSession{{ID: "session"}, {ID: "other"}}, nil).Once()
+				mock.On("UserSessionDeleteAll", ctx, "1", "session").Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -273,7 +293,7 @@ func TestUpdatePasswordUser(t *testing.T) {
 			tc.requiredMocks()
 
 			services := NewService(store.Store(mock), privateKey, publicKey, storecache.NewNullCache(), clientMock, nil)
-			err := services.UpdatePasswordUser(ctx, tc.id, tc.currentPassword, tc.newPassword)
+			err := services.UpdatePasswordUser(ctx, tc.id, "session", tc.currentPassword, tc.newPassword)
 			assert.Equal(t, tc.expected, err)
 		})
 	}
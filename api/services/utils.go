@@ -5,6 +5,7 @@ import (
 	"os"
 
 	jwt "github.com/golang-jwt/jwt/v4"
+	"github.com/shellhub-io/shellhub/api/pkg/keyring"
 )
 
 func LoadKeys() (*rsa.PrivateKey, *rsa.PublicKey, error) {
@@ -31,6 +32,23 @@ func LoadKeys() (*rsa.PrivateKey, *rsa.PublicKey, error) {
 	return privKey, pubKey, nil
 }
 
+// LoadVerificationKeys loads the public keys of the previous signing keys, concatenated in the PEM file set by the
+// VERIFICATION_KEYS environment variable, to keep verifying the tokens they signed. It returns no keys when the variable
+// is not set.
+func LoadVerificationKeys() ([]*rsa.PublicKey, error) {
+	path := os.Getenv("VERIFICATION_KEYS")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring.ParsePublicKeys(data)
+}
+
 func contains(list []string, item string) bool {
 	for _, i := range list {
 		if i == item {
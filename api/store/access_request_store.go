@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/paginator"
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type AccessRequestStore interface {
+	// AccessRequestList lists the access requests of a namespace. When userID or status are not empty, only the
+	// requests with them are returned.
+	AccessRequestList(ctx context.Context, tenantID string, userID string, status string, pagination paginator.Query) ([]models.AccessRequest, int, error)
+	AccessRequestGet(ctx context.Context, tenantID string, id string) (*models.AccessRequest, error)
+	AccessRequestCreate(ctx context.Context, request *models.AccessRequest) (*models.AccessRequest, error)
+	// AccessRequestReview sets the status of a pending access request.
+	AccessRequestReview(ctx context.Context, tenantID string, id string, status string, reviewer string, reviewedAt time.Time) error
+	// AccessRequestGetGranted gets an approved access request that allows the user to connect to the device at the time.
+	AccessRequestGetGranted(ctx context.Context, tenantID string, deviceUID string, userID string, now time.Time) (*models.AccessRequest, error)
+	// AccessRequestExpire marks as expired every pending or approved access request whose window ended before the time,
+	// returning the expired requests with the status they had before.
+	AccessRequestExpire(ctx context.Context, now time.Time) ([]models.AccessRequest, error)
+}
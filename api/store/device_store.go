@@ -33,6 +33,16 @@ type DeviceStore interface {
 	DeviceGetByMac(ctx context.Context, mac string, tenantID string, status models.DeviceStatus) (*models.Device, error)
 	DeviceGetByName(ctx context.Context, name string, tenantID string) (*models.Device, error)
 	DeviceGetByUID(ctx context.Context, uid models.UID, tenantID string) (*models.Device, error)
+	// DeviceGetByPublicKey gets the namespace's device that uses, or used before rotating or revoking it, the public key.
+	DeviceGetByPublicKey(ctx context.Context, tenantID string, publicKey string) (*models.Device, error)
+	// DeviceRotateKey replaces the device's public key, revoking the current one. It returns ErrNoDocuments when the
+	// device does not use the current key anymore, so concurrent rotations cannot both succeed.
+	DeviceRotateKey(ctx context.Context, uid models.UID, current string, key string) error
+	// DeviceRekey replaces the device's public key, revoking the current one, even when it is already revoked. It returns
+	// ErrNoDocuments when the device's key is not current anymore.
+	DeviceRekey(ctx context.Context, uid models.UID, current string, key string) error
+	// DeviceRevokeKey revokes the public key from the device.
+	DeviceRevokeKey(ctx context.Context, uid models.UID, key string) error
 	DeviceSetPosition(ctx context.Context, uid models.UID, position models.DevicePosition) error
 	DeviceListByUsage(ctx context.Context, tenantID string) ([]models.UID, error)
 	DeviceChooser(ctx context.Context, tenantID string, chosen []string) error
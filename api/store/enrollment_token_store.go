@@ -0,0 +1,19 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type EnrollmentTokenStore interface {
+	// EnrollmentTokenList lists the namespace's enrollment tokens, from the most recently created.
+	EnrollmentTokenList(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error)
+	EnrollmentTokenGet(ctx context.Context, id string) (*models.EnrollmentToken, error)
+	EnrollmentTokenCreate(ctx context.Context, token *models.EnrollmentToken) error
+	EnrollmentTokenDelete(ctx context.Context, tenantID string, id string) error
+	// EnrollmentTokenUse increments the token's uses. It returns ErrNoDocuments when the token has expired or reached
+	// its maximum uses at the time, so concurrent registrations cannot use it beyond its limit.
+	EnrollmentTokenUse(ctx context.Context, id string, now time.Time) error
+}
@@ -22,6 +22,157 @@ type Store struct {
 	mock.Mock
 }
 
+// AccessRequestCreate provides a mock function with given fields: ctx, request
+func (_m *Store) AccessRequestCreate(ctx context.Context, request *models.AccessRequest) (*models.AccessRequest, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 *models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessRequest) (*models.AccessRequest, error)); ok {
+		return rf(ctx, request)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessRequest) *models.AccessRequest); ok {
+		r0 = rf(ctx, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AccessRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessRequestExpire provides a mock function with given fields: ctx, now
+func (_m *Store) AccessRequestExpire(ctx context.Context, now time.Time) ([]models.AccessRequest, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]models.AccessRequest, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []models.AccessRequest); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessRequestGet provides a mock function with given fields: ctx, tenantID, id
+func (_m *Store) AccessRequestGet(ctx context.Context, tenantID string, id string) (*models.AccessRequest, error) {
+	ret := _m.Called(ctx, tenantID, id)
+
+	var r0 *models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.AccessRequest, error)); ok {
+		return rf(ctx, tenantID, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessRequestGetGranted provides a mock function with given fields: ctx, tenantID, deviceUID, userID, now
+func (_m *Store) AccessRequestGetGranted(ctx context.Context, tenantID string, deviceUID string, userID string, now time.Time) (*models.AccessRequest, error) {
+	ret := _m.Called(ctx, tenantID, deviceUID, userID, now)
+
+	var r0 *models.AccessRequest
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) (*models.AccessRequest, error)); ok {
+		return rf(ctx, tenantID, deviceUID, userID, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) *models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, deviceUID, userID, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, time.Time) error); ok {
+		r1 = rf(ctx, tenantID, deviceUID, userID, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessRequestList provides a mock function with given fields: ctx, tenantID, userID, status, pagination
+func (_m *Store) AccessRequestList(ctx context.Context, tenantID string, userID string, status string, pagination paginator.Query) ([]models.AccessRequest, int, error) {
+	ret := _m.Called(ctx, tenantID, userID, status, pagination)
+
+	var r0 []models.AccessRequest
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, paginator.Query) ([]models.AccessRequest, int, error)); ok {
+		return rf(ctx, tenantID, userID, status, pagination)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, paginator.Query) []models.AccessRequest); ok {
+		r0 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.AccessRequest)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, paginator.Query) int); ok {
+		r1 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, paginator.Query) error); ok {
+		r2 = rf(ctx, tenantID, userID, status, pagination)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AccessRequestReview provides a mock function with given fields: ctx, tenantID, id, status, reviewer, reviewedAt
+func (_m *Store) AccessRequestReview(ctx context.Context, tenantID string, id string, status string, reviewer string, reviewedAt time.Time) error {
+	ret := _m.Called(ctx, tenantID, id, status, reviewer, reviewedAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, string, time.Time) error); ok {
+		r0 = rf(ctx, tenantID, id, status, reviewer, reviewedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AnnouncementCreate provides a mock function with given fields: ctx, announcement
 func (_m *Store) AnnouncementCreate(ctx context.Context, announcement *models.Announcement) error {
 	ret := _m.Called(ctx, announcement)
@@ -271,6 +422,32 @@ func (_m *Store) DeviceGetByName(ctx context.Context, name string, tenantID stri
 	return r0, r1
 }
 
+// DeviceGetByPublicKey provides a mock function with given fields: ctx, tenantID, publicKey
+func (_m *Store) DeviceGetByPublicKey(ctx context.Context, tenantID string, publicKey string) (*models.Device, error) {
+	ret := _m.Called(ctx, tenantID, publicKey)
+
+	var r0 *models.Device
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Device, error)); ok {
+		return rf(ctx, tenantID, publicKey)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Device); ok {
+		r0 = rf(ctx, tenantID, publicKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Device)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, tenantID, publicKey)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeviceGetByPublicURLAddress provides a mock function with given fields: ctx, address
 func (_m *Store) DeviceGetByPublicURLAddress(ctx context.Context, address string) (*models.Device, error) {
 	ret := _m.Called(ctx, address)
@@ -547,6 +724,48 @@ func (_m *Store) DeviceRename(ctx context.Context, uid models.UID, hostname stri
 	return r0
 }
 
+// DeviceRekey provides a mock function with given fields: ctx, uid, current, key
+func (_m *Store) DeviceRekey(ctx context.Context, uid models.UID, current string, key string) error {
+	ret := _m.Called(ctx, uid, current, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string, string) error); ok {
+		r0 = rf(ctx, uid, current, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceRevokeKey provides a mock function with given fields: ctx, uid, key
+func (_m *Store) DeviceRevokeKey(ctx context.Context, uid models.UID, key string) error {
+	ret := _m.Called(ctx, uid, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
+		r0 = rf(ctx, uid, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceRotateKey provides a mock function with given fields: ctx, uid, current, key
+func (_m *Store) DeviceRotateKey(ctx context.Context, uid models.UID, current string, key string) error {
+	ret := _m.Called(ctx, uid, current, key)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string, string) error); ok {
+		r0 = rf(ctx, uid, current, key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeviceSetOnline provides a mock function with given fields: ctx, uid, online
 func (_m *Store) DeviceSetOnline(ctx context.Context, uid models.UID, online bool) error {
 	ret := _m.Called(ctx, uid, online)
@@ -645,6 +864,100 @@ func (_m *Store) DeviceUpdateTag(ctx context.Context, uid models.UID, tags []str
 	return r0
 }
 
+// EnrollmentTokenCreate provides a mock function with given fields: ctx, token
+func (_m *Store) EnrollmentTokenCreate(ctx context.Context, token *models.EnrollmentToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.EnrollmentToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnrollmentTokenDelete provides a mock function with given fields: ctx, tenantID, id
+func (_m *Store) EnrollmentTokenDelete(ctx context.Context, tenantID string, id string) error {
+	ret := _m.Called(ctx, tenantID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, tenantID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EnrollmentTokenGet provides a mock function with given fields: ctx, id
+func (_m *Store) EnrollmentTokenGet(ctx context.Context, id string) (*models.EnrollmentToken, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.EnrollmentToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.EnrollmentToken, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.EnrollmentToken); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.EnrollmentToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnrollmentTokenList provides a mock function with given fields: ctx, tenantID
+func (_m *Store) EnrollmentTokenList(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error) {
+	ret := _m.Called(ctx, tenantID)
+
+	var r0 []models.EnrollmentToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.EnrollmentToken, error)); ok {
+		return rf(ctx, tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.EnrollmentToken); ok {
+		r0 = rf(ctx, tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.EnrollmentToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnrollmentTokenUse provides a mock function with given fields: ctx, id, now
+func (_m *Store) EnrollmentTokenUse(ctx context.Context, id string, now time.Time) error {
+	ret := _m.Called(ctx, id, now)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, now)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // FirewallRuleAddTag provides a mock function with given fields: ctx, id, tag
 func (_m *Store) FirewallRuleAddTag(ctx context.Context, id string, tag string) error {
 	ret := _m.Called(ctx, id, tag)
@@ -1007,6 +1320,20 @@ func (_m *Store) NamespaceEditMember(ctx context.Context, tenantID string, membe
 	return r0
 }
 
+// NamespaceEditMemberScope provides a mock function with given fields: ctx, tenantID, memberID, scope
+func (_m *Store) NamespaceEditMemberScope(ctx context.Context, tenantID string, memberID string, scope *models.MemberScope) error {
+	ret := _m.Called(ctx, tenantID, memberID, scope)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, *models.MemberScope) error); ok {
+		r0 = rf(ctx, tenantID, memberID, scope)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NamespaceGet provides a mock function with given fields: ctx, tenantID
 func (_m *Store) NamespaceGet(ctx context.Context, tenantID string) (*models.Namespace, error) {
 	ret := _m.Called(ctx, tenantID)
@@ -1194,6 +1521,76 @@ func (_m *Store) NamespaceRename(ctx context.Context, tenantID string, name stri
 	return r0, r1
 }
 
+// NamespaceSetAccessApprovalTags provides a mock function with given fields: ctx, tenantID, tags
+func (_m *Store) NamespaceSetAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error {
+	ret := _m.Called(ctx, tenantID, tags)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) error); ok {
+		r0 = rf(ctx, tenantID, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetAgentTagsOverwrite provides a mock function with given fields: ctx, tenantID, overwrite
+func (_m *Store) NamespaceSetAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error {
+	ret := _m.Called(ctx, tenantID, overwrite)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, tenantID, overwrite)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetRequireEnrollmentToken provides a mock function with given fields: ctx, tenantID, require
+func (_m *Store) NamespaceSetRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error {
+	ret := _m.Called(ctx, tenantID, require)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, tenantID, require)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetRequireMFA provides a mock function with given fields: ctx, tenantID, require, requireSSH
+func (_m *Store) NamespaceSetRequireMFA(ctx context.Context, tenantID string, require bool, requireSSH bool) error {
+	ret := _m.Called(ctx, tenantID, require, requireSSH)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool, bool) error); ok {
+		r0 = rf(ctx, tenantID, require, requireSSH)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceSetSessionPolicy provides a mock function with given fields: ctx, tenantID, idleTimeout, maxSessionDuration
+func (_m *Store) NamespaceSetSessionPolicy(ctx context.Context, tenantID string, idleTimeout int, maxSessionDuration int) error {
+	ret := _m.Called(ctx, tenantID, idleTimeout, maxSessionDuration)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) error); ok {
+		r0 = rf(ctx, tenantID, idleTimeout, maxSessionDuration)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NamespaceSetSessionRecord provides a mock function with given fields: ctx, sessionRecord, tenantID
 func (_m *Store) NamespaceSetSessionRecord(ctx context.Context, sessionRecord bool, tenantID string) error {
 	ret := _m.Called(ctx, sessionRecord, tenantID)
@@ -1208,6 +1605,34 @@ func (_m *Store) NamespaceSetSessionRecord(ctx context.Context, sessionRecord bo
 	return r0
 }
 
+// NamespaceSetTransfer provides a mock function with given fields: ctx, tenantID, transfer
+func (_m *Store) NamespaceSetTransfer(ctx context.Context, tenantID string, transfer *models.NamespaceTransfer) error {
+	ret := _m.Called(ctx, tenantID, transfer)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.NamespaceTransfer) error); ok {
+		r0 = rf(ctx, tenantID, transfer)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NamespaceTransferOwnership provides a mock function with given fields: ctx, tenantID, from, to
+func (_m *Store) NamespaceTransferOwnership(ctx context.Context, tenantID string, from string, to string) error {
+	ret := _m.Called(ctx, tenantID, from, to)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, tenantID, from, to)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NamespaceUpdate provides a mock function with given fields: ctx, tenantID, namespace
 func (_m *Store) NamespaceUpdate(ctx context.Context, tenantID string, namespace *models.Namespace) error {
 	ret := _m.Called(ctx, tenantID, namespace)
@@ -1518,13 +1943,13 @@ func (_m *Store) SessionCreateRecordFrame(ctx context.Context, uid models.UID, r
 	return r0
 }
 
-// SessionDeleteActives provides a mock function with given fields: ctx, uid
-func (_m *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error {
-	ret := _m.Called(ctx, uid)
+// SessionDeleteActives provides a mock function with given fields: ctx, uid, reason
+func (_m *Store) SessionDeleteActives(ctx context.Context, uid models.UID, reason string) error {
+	ret := _m.Called(ctx, uid, reason)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, models.UID) error); ok {
-		r0 = rf(ctx, uid)
+	if rf, ok := ret.Get(0).(func(context.Context, models.UID, string) error); ok {
+		r0 = rf(ctx, uid, reason)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1638,6 +2063,32 @@ func (_m *Store) SessionList(ctx context.Context, pagination paginator.Query) ([
 	return r0, r1, r2
 }
 
+// SessionListActiveByMember provides a mock function with given fields: ctx, tenantID, deviceUID, member
+func (_m *Store) SessionListActiveByMember(ctx context.Context, tenantID string, deviceUID models.UID, member string) ([]models.Session, error) {
+	ret := _m.Called(ctx, tenantID, deviceUID, member)
+
+	var r0 []models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, string) ([]models.Session, error)); ok {
+		return rf(ctx, tenantID, deviceUID, member)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UID, string) []models.Session); ok {
+		r0 = rf(ctx, tenantID, deviceUID, member)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.UID, string) error); ok {
+		r1 = rf(ctx, tenantID, deviceUID, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SessionSetAuthenticated provides a mock function with given fields: ctx, uid, authenticated
 func (_m *Store) SessionSetAuthenticated(ctx context.Context, uid models.UID, authenticated bool) error {
 	ret := _m.Called(ctx, uid, authenticated)
@@ -1797,6 +2248,48 @@ func (_m *Store) UserDelete(ctx context.Context, id string) error {
 	return r0
 }
 
+// UserDeleteRecoveryCode provides a mock function with given fields: ctx, id, hash
+func (_m *Store) UserDeleteRecoveryCode(ctx context.Context, id string, hash string) error {
+	ret := _m.Called(ctx, id, hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSetMFAStep provides a mock function with given fields: ctx, id, step
+func (_m *Store) UserSetMFAStep(ctx context.Context, id string, step uint64) error {
+	ret := _m.Called(ctx, id, step)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint64) error); ok {
+		r0 = rf(ctx, id, step)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserDeleteToken provides a mock function with given fields: ctx, id, token
+func (_m *Store) UserDeleteToken(ctx context.Context, id string, token string) error {
+	ret := _m.Called(ctx, id, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UserDeleteTokens provides a mock function with given fields: ctx, id
 func (_m *Store) UserDeleteTokens(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -1896,6 +2389,32 @@ func (_m *Store) UserGetByID(ctx context.Context, id string, ns bool) (*models.U
 	return r0, r1, r2
 }
 
+// UserGetByIdentity provides a mock function with given fields: ctx, provider, subject
+func (_m *Store) UserGetByIdentity(ctx context.Context, provider string, subject string) (*models.User, error) {
+	ret := _m.Called(ctx, provider, subject)
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.User, error)); ok {
+		return rf(ctx, provider, subject)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.User); ok {
+		r0 = rf(ctx, provider, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, provider, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UserGetByUsername provides a mock function with given fields: ctx, username
 func (_m *Store) UserGetByUsername(ctx context.Context, username string) (*models.User, error) {
 	ret := _m.Called(ctx, username)
@@ -1981,6 +2500,114 @@ func (_m *Store) UserList(ctx context.Context, pagination paginator.Query, filte
 	return r0, r1, r2
 }
 
+// UserSessionCreate provides a mock function with given fields: ctx, session
+func (_m *Store) UserSessionCreate(ctx context.Context, session *models.UserSession) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserSession) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionDelete provides a mock function with given fields: ctx, userID, id
+func (_m *Store) UserSessionDelete(ctx context.Context, userID string, id string) error {
+	ret := _m.Called(ctx, userID, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionDeleteAll provides a mock function with given fields: ctx, userID, except
+func (_m *Store) UserSessionDeleteAll(ctx context.Context, userID string, except string) error {
+	ret := _m.Called(ctx, userID, except)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, userID, except)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSessionGet provides a mock function with given fields: ctx, id
+func (_m *Store) UserSessionGet(ctx context.Context, id string) (*models.UserSession, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.UserSession, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.UserSession); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSessionList provides a mock function with given fields: ctx, userID
+func (_m *Store) UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []models.UserSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]models.UserSession, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []models.UserSession); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.UserSession)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSessionRefresh provides a mock function with given fields: ctx, session, previous
+func (_m *Store) UserSessionRefresh(ctx context.Context, session *models.UserSession, previous string) error {
+	ret := _m.Called(ctx, session, previous)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.UserSession, string) error); ok {
+		r0 = rf(ctx, session, previous)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UserUpdateAccountStatus provides a mock function with given fields: ctx, id
 func (_m *Store) UserUpdateAccountStatus(ctx context.Context, id string) error {
 	ret := _m.Called(ctx, id)
@@ -2023,6 +2650,34 @@ func (_m *Store) UserUpdateFromAdmin(ctx context.Context, name string, username
 	return r0
 }
 
+// UserUpdateIdentity provides a mock function with given fields: ctx, id, identity
+func (_m *Store) UserUpdateIdentity(ctx context.Context, id string, identity *models.UserIdentity) error {
+	ret := _m.Called(ctx, id, identity)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.UserIdentity) error); ok {
+		r0 = rf(ctx, id, identity)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserUpdateMFA provides a mock function with given fields: ctx, id, mfa
+func (_m *Store) UserUpdateMFA(ctx context.Context, id string, mfa models.UserMFA) error {
+	ret := _m.Called(ctx, id, mfa)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.UserMFA) error); ok {
+		r0 = rf(ctx, id, mfa)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UserUpdatePassword provides a mock function with given fields: ctx, newPassword, id
 func (_m *Store) UserUpdatePassword(ctx context.Context, newPassword string, id string) error {
 	ret := _m.Called(ctx, newPassword, id)
@@ -0,0 +1,174 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
+	"github.com/shellhub-io/shellhub/pkg/api/paginator"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *Store) AccessRequestList(ctx context.Context, tenantID string, userID string, status string, pagination paginator.Query) ([]models.AccessRequest, int, error) {
+	match := bson.M{"tenant_id": tenantID}
+	if userID != "" {
+		match["user_id"] = userID
+	}
+
+	if status != "" {
+		match["status"] = status
+	}
+
+	query := []bson.M{
+		{
+			"$match": match,
+		},
+		{
+			"$sort": bson.M{
+				"created_at": -1,
+			},
+		},
+	}
+
+	queryCount := query
+	queryCount = append(queryCount, bson.M{"$count": "count"})
+	count, err := AggregateCount(ctx, s.db.Collection("access_requests"), queryCount)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	query = append(query, queries.BuildPaginationQuery(pagination)...)
+
+	requests := make([]models.AccessRequest, 0)
+	cursor, err := s.db.Collection("access_requests").Aggregate(ctx, query)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		request := new(models.AccessRequest)
+		if err := cursor.Decode(&request); err != nil {
+			return requests, count, FromMongoError(err)
+		}
+
+		requests = append(requests, *request)
+	}
+
+	return requests, count, nil
+}
+
+func (s *Store) AccessRequestGet(ctx context.Context, tenantID string, id string) (*models.AccessRequest, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	request := new(models.AccessRequest)
+	if err := s.db.Collection("access_requests").FindOne(ctx, bson.M{"_id": objID, "tenant_id": tenantID}).Decode(&request); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return request, nil
+}
+
+func (s *Store) AccessRequestCreate(ctx context.Context, request *models.AccessRequest) (*models.AccessRequest, error) {
+	doc := bson.M{
+		"tenant_id":     request.TenantID,
+		"device_uid":    request.DeviceUID,
+		"user_id":       request.UserID,
+		"justification": request.Justification,
+		"starts_at":     request.StartsAt,
+		"ends_at":       request.EndsAt,
+		"status":        request.Status,
+		"created_at":    request.CreatedAt,
+	}
+
+	res, err := s.db.Collection("access_requests").InsertOne(ctx, doc)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return s.AccessRequestGet(ctx, request.TenantID, res.InsertedID.(primitive.ObjectID).Hex()) //nolint:forcetypeassert
+}
+
+func (s *Store) AccessRequestReview(ctx context.Context, tenantID string, id string, status string, reviewer string, reviewedAt time.Time) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	res, err := s.db.Collection("access_requests").UpdateOne(ctx,
+		bson.M{"_id": objID, "tenant_id": tenantID, "status": models.AccessRequestPending},
+		bson.M{"$set": bson.M{"status": status, "reviewed_by": reviewer, "reviewed_at": reviewedAt}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) AccessRequestGetGranted(ctx context.Context, tenantID string, deviceUID string, userID string, now time.Time) (*models.AccessRequest, error) {
+	request := new(models.AccessRequest)
+	if err := s.db.Collection("access_requests").FindOne(ctx, bson.M{
+		"tenant_id":  tenantID,
+		"device_uid": deviceUID,
+		"user_id":    userID,
+		"status":     models.AccessRequestApproved,
+		"starts_at":  bson.M{"$lte": now},
+		"ends_at":    bson.M{"$gt": now},
+	}).Decode(&request); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return request, nil
+}
+
+func (s *Store) AccessRequestExpire(ctx context.Context, now time.Time) ([]models.AccessRequest, error) {
+	filter := bson.M{
+		"status":  bson.M{"$in": []string{models.AccessRequestPending, models.AccessRequestApproved}},
+		"ends_at": bson.M{"$lte": now},
+	}
+
+	cursor, err := s.db.Collection("access_requests").Find(ctx, filter)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	requests := make([]models.AccessRequest, 0)
+	ids := make([]primitive.ObjectID, 0)
+	for cursor.Next(ctx) {
+		request := new(models.AccessRequest)
+		if err := cursor.Decode(&request); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		id, err := primitive.ObjectIDFromHex(request.ID)
+		if err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		requests = append(requests, *request)
+		ids = append(ids, id)
+	}
+
+	if len(ids) == 0 {
+		return requests, nil
+	}
+
+	filter["_id"] = bson.M{"$in": ids}
+	if _, err := s.db.Collection("access_requests").UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": models.AccessRequestExpired}}); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return requests, nil
+}
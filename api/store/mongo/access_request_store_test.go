@@ -0,0 +1,98 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/dbtest"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/api/paginator"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessRequestCreate(t *testing.T) {
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	request, err := mongostore.AccessRequestCreate(initData().Context, &models.AccessRequest{
+		TenantID:      "00000000-0000-4000-0000-000000000000",
+		DeviceUID:     "2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c",
+		UserID:        "507f1f77bcf86cd799439011",
+		Justification: "incident",
+		StartsAt:      now,
+		EndsAt:        now.Add(time.Hour),
+		Status:        models.AccessRequestPending,
+		CreatedAt:     now,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, request.ID)
+	assert.Equal(t, models.AccessRequestPending, request.Status)
+
+	requests, count, err := mongostore.AccessRequestList(initData().Context, "00000000-0000-4000-0000-000000000000", "", models.AccessRequestPending, paginator.Query{Page: -1, PerPage: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, request.ID, requests[0].ID)
+}
+
+func TestAccessRequestReview(t *testing.T) {
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	request, err := mongostore.AccessRequestCreate(initData().Context, &models.AccessRequest{
+		TenantID:  "00000000-0000-4000-0000-000000000000",
+		DeviceUID: "2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c",
+		UserID:    "507f1f77bcf86cd799439011",
+		StartsAt:  now,
+		EndsAt:    now.Add(time.Hour),
+		Status:    models.AccessRequestPending,
+		CreatedAt: now,
+	})
+	assert.NoError(t, err)
+
+	err = mongostore.AccessRequestReview(initData().Context, request.TenantID, request.ID, models.AccessRequestApproved, "507f1f77bcf86cd799439012", now)
+	assert.NoError(t, err)
+
+	granted, err := mongostore.AccessRequestGetGranted(initData().Context, request.TenantID, request.DeviceUID, request.UserID, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, request.ID, granted.ID)
+
+	err = mongostore.AccessRequestReview(initData().Context, request.TenantID, request.ID, models.AccessRequestDenied, "507f1f77bcf86cd799439012", now)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestAccessRequestExpire(t *testing.T) {
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	request, err := mongostore.AccessRequestCreate(initData().Context, &models.AccessRequest{
+		TenantID:  "00000000-0000-4000-0000-000000000000",
+		DeviceUID: "2300230e3ca2f637636b4d025d2235269014865db5204b6d115386cbee89809c",
+		UserID:    "507f1f77bcf86cd799439011",
+		StartsAt:  now.Add(-2 * time.Hour),
+		EndsAt:    now.Add(-time.Hour),
+		Status:    models.AccessRequestApproved,
+		CreatedAt: now,
+	})
+	assert.NoError(t, err)
+
+	expired, err := mongostore.AccessRequestExpire(initData().Context, now)
+	assert.NoError(t, err)
+	assert.Len(t, expired, 1)
+	assert.Equal(t, request.ID, expired[0].ID)
+	assert.Equal(t, models.AccessRequestApproved, expired[0].Status)
+
+	got, err := mongostore.AccessRequestGet(initData().Context, request.TenantID, request.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AccessRequestExpired, got.Status)
+}
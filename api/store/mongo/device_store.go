@@ -147,6 +147,16 @@ func (s *Store) DeviceList(ctx context.Context, pagination paginator.Query, filt
 		})
 	}
 
+	// Only match for the devices inside the member's scope if it is restricted
+	scope, err := s.memberScopeFromContext(ctx)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	if scope != nil {
+		query = append(query, buildMemberScopeQuery(scope, "uid", "tags"))
+	}
+
 	queryCount := query
 	queryCount = append(queryCount, bson.M{"$count": "count"})
 	count, err := AggregateCount(ctx, s.db.Collection("devices"), queryCount)
@@ -221,6 +231,16 @@ func (s *Store) DeviceGet(ctx context.Context, uid models.UID) (*models.Device,
 		})
 	}
 
+	// Only match for the devices inside the member's scope if it is restricted
+	scope, err := s.memberScopeFromContext(ctx)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	if scope != nil {
+		query = append(query, buildMemberScopeQuery(scope, "uid", "tags"))
+	}
+
 	device := new(models.Device)
 
 	cursor, err := s.db.Collection("devices").Aggregate(ctx, query)
@@ -454,6 +474,75 @@ func (s *Store) DeviceGetByName(ctx context.Context, name string, tenantID strin
 	return device, nil
 }
 
+func (s *Store) DeviceGetByPublicKey(ctx context.Context, tenantID string, publicKey string) (*models.Device, error) {
+	device := new(models.Device)
+	if err := s.db.Collection("devices").FindOne(ctx, bson.M{
+		"tenant_id": tenantID,
+		"$or":       []bson.M{{"public_key": publicKey}, {"revoked_keys": publicKey}},
+	}).Decode(&device); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return device, nil
+}
+
+func (s *Store) DeviceRotateKey(ctx context.Context, uid models.UID, current string, key string) error {
+	res, err := s.db.Collection("devices").UpdateOne(ctx,
+		bson.M{"uid": uid, "public_key": current, "revoked_keys": bson.M{"$ne": current}},
+		bson.M{"$set": bson.M{"public_key": key}, "$addToSet": bson.M{"revoked_keys": current}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"device", string(uid)}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceRekey(ctx context.Context, uid models.UID, current string, key string) error {
+	res, err := s.db.Collection("devices").UpdateOne(ctx,
+		bson.M{"uid": uid, "public_key": current},
+		bson.M{"$set": bson.M{"public_key": key}, "$addToSet": bson.M{"revoked_keys": current}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"device", string(uid)}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) DeviceRevokeKey(ctx context.Context, uid models.UID, key string) error {
+	res, err := s.db.Collection("devices").UpdateOne(ctx, bson.M{"uid": uid}, bson.M{"$addToSet": bson.M{"revoked_keys": key}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"device", string(uid)}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
 func (s *Store) DeviceGetByUID(ctx context.Context, uid models.UID, tenantID string) (*models.Device, error) {
 	var device *models.Device
 	if err := s.cache.Get(ctx, strings.Join([]string{"device", string(uid)}, "/"), &device); err != nil {
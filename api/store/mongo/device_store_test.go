@@ -207,6 +207,62 @@ func TestDeviceGetByUID(t *testing.T) {
 	assert.NotEmpty(t, d)
 }
 
+func TestDeviceRotateKey(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	device := data.Device
+	device.PublicKey = "old"
+
+	err = mongostore.DeviceCreate(data.Context, device, "hostname")
+	assert.NoError(t, err)
+
+	err = mongostore.DeviceRotateKey(data.Context, models.UID(device.UID), "old", "new")
+	assert.NoError(t, err)
+
+	// The device was already rotated from the old key.
+	err = mongostore.DeviceRotateKey(data.Context, models.UID(device.UID), "old", "other")
+	assert.Equal(t, store.ErrNoDocuments, err)
+
+	for _, key := range []string{"old", "new"} {
+		d, err := mongostore.DeviceGetByPublicKey(data.Context, device.TenantID, key)
+		assert.NoError(t, err)
+		assert.Equal(t, device.UID, d.UID)
+		assert.Equal(t, "new", d.PublicKey)
+		assert.Equal(t, []string{"old"}, d.RevokedKeys)
+	}
+
+	err = mongostore.DeviceRevokeKey(data.Context, models.UID(device.UID), "new")
+	assert.NoError(t, err)
+
+	d, err := mongostore.DeviceGetByPublicKey(data.Context, device.TenantID, "new")
+	assert.NoError(t, err)
+	assert.True(t, d.IsKeyRevoked("new"))
+
+	_, err = mongostore.DeviceGetByPublicKey(data.Context, "other", "new")
+	assert.Equal(t, store.ErrNoDocuments, err)
+
+	// The revoked key is replaced by the re-key, unlike by the rotation.
+	err = mongostore.DeviceRotateKey(data.Context, models.UID(device.UID), "new", "rekeyed")
+	assert.Equal(t, store.ErrNoDocuments, err)
+
+	err = mongostore.DeviceRekey(data.Context, models.UID(device.UID), "new", "rekeyed")
+	assert.NoError(t, err)
+
+	d, err = mongostore.DeviceGetByPublicKey(data.Context, device.TenantID, "rekeyed")
+	assert.NoError(t, err)
+	assert.Equal(t, device.UID, d.UID)
+	assert.False(t, d.IsKeyRevoked("rekeyed"))
+	assert.Equal(t, []string{"old", "new"}, d.RevokedKeys)
+}
+
 func TestDevicesList(t *testing.T) {
 	data := initData()
 
@@ -0,0 +1,85 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Store) EnrollmentTokenList(ctx context.Context, tenantID string) ([]models.EnrollmentToken, error) {
+	cursor, err := s.db.Collection("enrollment_tokens").Find(ctx, bson.M{"tenant_id": tenantID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	tokens := make([]models.EnrollmentToken, 0)
+	for cursor.Next(ctx) {
+		token := new(models.EnrollmentToken)
+		if err := cursor.Decode(&token); err != nil {
+			return tokens, FromMongoError(err)
+		}
+
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, nil
+}
+
+func (s *Store) EnrollmentTokenGet(ctx context.Context, id string) (*models.EnrollmentToken, error) {
+	token := new(models.EnrollmentToken)
+	if err := s.db.Collection("enrollment_tokens").FindOne(ctx, bson.M{"_id": id}).Decode(&token); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return token, nil
+}
+
+func (s *Store) EnrollmentTokenCreate(ctx context.Context, token *models.EnrollmentToken) error {
+	if _, err := s.db.Collection("enrollment_tokens").InsertOne(ctx, token); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) EnrollmentTokenDelete(ctx context.Context, tenantID string, id string) error {
+	res, err := s.db.Collection("enrollment_tokens").DeleteOne(ctx, bson.M{"_id": id, "tenant_id": tenantID})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.DeletedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) EnrollmentTokenUse(ctx context.Context, id string, now time.Time) error {
+	// The limit is checked by the filter, so the increment is atomic.
+	res, err := s.db.Collection("enrollment_tokens").UpdateOne(ctx,
+		bson.M{
+			"_id":        id,
+			"expires_at": bson.M{"$gt": now},
+			"$or": []bson.M{
+				{"max_uses": 0},
+				{"$expr": bson.M{"$lt": []string{"$uses", "$max_uses"}}},
+			},
+		},
+		bson.M{"$inc": bson.M{"uses": 1}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/dbtest"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnrollmentToken(t *testing.T) {
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	ctx := context.TODO()
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := &models.EnrollmentToken{ID: "first", TenantID: "tenant", Hash: "hash", Tags: []string{"factory"}, MaxUses: 1, ExpiresAt: now.Add(time.Hour), CreatedAt: now}
+	second := &models.EnrollmentToken{ID: "second", TenantID: "tenant", Hash: "hash", Tags: []string{}, ExpiresAt: now.Add(time.Hour), CreatedAt: now.Add(time.Minute)}
+	other := &models.EnrollmentToken{ID: "other", TenantID: "other", Hash: "hash", Tags: []string{}, ExpiresAt: now, CreatedAt: now}
+
+	for _, token := range []*models.EnrollmentToken{first, second, other} {
+		assert.NoError(t, mongostore.EnrollmentTokenCreate(ctx, token))
+	}
+
+	token, err := mongostore.EnrollmentTokenGet(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, first, token)
+
+	tokens, err := mongostore.EnrollmentTokenList(ctx, "tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.EnrollmentToken{*second, *first}, tokens)
+
+	// The tokens are only used while they have not expired nor reached their maximum uses.
+	assert.NoError(t, mongostore.EnrollmentTokenUse(ctx, "first", now))
+	assert.EqualError(t, mongostore.EnrollmentTokenUse(ctx, "first", now), store.ErrNoDocuments.Error())
+	assert.NoError(t, mongostore.EnrollmentTokenUse(ctx, "second", now))
+	assert.NoError(t, mongostore.EnrollmentTokenUse(ctx, "second", now))
+	assert.EqualError(t, mongostore.EnrollmentTokenUse(ctx, "other", now), store.ErrNoDocuments.Error())
+
+	token, err = mongostore.EnrollmentTokenGet(ctx, "second")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, token.Uses)
+
+	// The tokens are only deleted by their namespaces.
+	assert.EqualError(t, mongostore.EnrollmentTokenDelete(ctx, "other", "first"), store.ErrNoDocuments.Error())
+	assert.NoError(t, mongostore.EnrollmentTokenDelete(ctx, "tenant", "first"))
+
+	_, err = mongostore.EnrollmentTokenGet(ctx, "first")
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// memberScopeFromContext gets the models.MemberScope from the member who is acting on the request.
+//
+// The member is identified by the tenant and the user's ID got from the context. When the context has none of them, the
+// member isn't found or the member has no scope, it returns nil, what means no restriction.
+func (s *Store) memberScopeFromContext(ctx context.Context) (*models.MemberScope, error) {
+	tenant := gateway.TenantFromContext(ctx)
+	id := gateway.IDFromContext(ctx)
+	if tenant == nil || id == nil {
+		return nil, nil
+	}
+
+	namespace, err := s.NamespaceGet(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range namespace.Members {
+		if member.ID == id.ID && !member.Scope.IsEmpty() {
+			return member.Scope, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// buildMemberScopeQuery creates a MongoDB's match stage that only keeps the documents inside the scope. uid and tags
+// are the fields' names where the device's UID and device's tags are in the document.
+func buildMemberScopeQuery(scope *models.MemberScope, uid, tags string) bson.M {
+	devices := scope.Devices
+	if devices == nil {
+		devices = []string{}
+	}
+
+	labels := scope.Tags
+	if labels == nil {
+		labels = []string{}
+	}
+
+	return bson.M{
+		"$match": bson.M{
+			"$or": []bson.M{
+				{uid: bson.M{"$in": devices}},
+				{tags: bson.M{"$in": labels}},
+			},
+		},
+	}
+}
@@ -68,6 +68,10 @@ func GenerateMigrations() []migrate.Migration {
 		migration56,
 		migration57,
 		migration58,
+		migration59,
+		migration60,
+		migration61,
+		migration62,
 	}
 }
 
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration59 = migrate.Migration{
+	Version:     59,
+	Description: "create index for access requests lookup",
+	Up: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   59,
+			"action":    "Up",
+		}).Info("Applying migration up")
+		Name := "tenant_id_device_uid_user_id_status"
+
+		if _, err := database.Collection("access_requests").Indexes().CreateOne(context.Background(), mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "device_uid", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+			Options: &options.IndexOptions{ //nolint:exhaustruct
+				Name: &Name,
+			},
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Down: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   59,
+			"action":    "Down",
+		}).Info("Applying migration down")
+		Name := "tenant_id_device_uid_user_id_status"
+
+		if _, err := database.Collection("access_requests").Indexes().DropOne(context.Background(), Name); err != nil {
+			return err
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration60 = migrate.Migration{
+	Version:     60,
+	Description: "create indexes for user sessions lookup and expiration",
+	Up: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   60,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		if _, err := database.Collection("user_sessions").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("user_id"),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetName("expires_at").SetExpireAfterSeconds(0),
+			},
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Down: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   60,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		for _, name := range []string{"user_id", "expires_at"} {
+			if _, err := database.Collection("user_sessions").Indexes().DropOne(context.Background(), name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
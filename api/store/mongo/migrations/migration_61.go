@@ -0,0 +1,49 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration61 = migrate.Migration{
+	Version:     61,
+	Description: "create indexes for enrollment tokens lookup",
+	Up: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   61,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		if _, err := database.Collection("enrollment_tokens").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}},
+				Options: options.Index().SetName("tenant_id"),
+			},
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Down: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   61,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		for _, name := range []string{"tenant_id"} {
+			if _, err := database.Collection("enrollment_tokens").Indexes().DropOne(context.Background(), name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
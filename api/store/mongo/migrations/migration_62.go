@@ -0,0 +1,53 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	migrate "github.com/xakep666/mongo-migrate"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var migration62 = migrate.Migration{
+	Version:     62,
+	Description: "create indexes for devices lookup by public key",
+	Up: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   62,
+			"action":    "Up",
+		}).Info("Applying migration up")
+
+		if _, err := database.Collection("devices").Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "public_key", Value: 1}},
+				Options: options.Index().SetName("public_key"),
+			},
+			{
+				Keys:    bson.D{{Key: "revoked_keys", Value: 1}},
+				Options: options.Index().SetName("revoked_keys").SetSparse(true),
+			},
+		}); err != nil {
+			return err
+		}
+
+		return nil
+	},
+	Down: func(database *mongo.Database) error {
+		logrus.WithFields(logrus.Fields{
+			"component": "migration",
+			"version":   62,
+			"action":    "Down",
+		}).Info("Applying migration down")
+
+		for _, name := range []string{"public_key", "revoked_keys"} {
+			if _, err := database.Collection("devices").Indexes().DropOne(context.Background(), name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -13,6 +15,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func (s *Store) NamespaceList(ctx context.Context, pagination paginator.Query, filters []models.Filter, export bool) ([]models.Namespace, int, error) {
@@ -311,6 +314,28 @@ func (s *Store) NamespaceEditMember(ctx context.Context, tenantID string, member
 	return nil
 }
 
+func (s *Store) NamespaceEditMemberScope(ctx context.Context, tenantID string, memberID string, scope *models.MemberScope) error {
+	update := bson.M{"$set": bson.M{"members.$.scope": scope}}
+	if scope.IsEmpty() {
+		update = bson.M{"$unset": bson.M{"members.$.scope": ""}}
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID, "members.id": memberID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
 func (s *Store) NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error) {
 	ns := new(models.Namespace)
 	if err := s.db.Collection("namespaces").FindOne(ctx, bson.M{"members": bson.M{"$elemMatch": bson.M{"id": id}}}).Decode(&ns); err != nil {
@@ -339,3 +364,190 @@ func (s *Store) NamespaceGetSessionRecord(ctx context.Context, tenantID string)
 
 	return settings.Settings.SessionRecord, nil
 }
+
+func (s *Store) NamespaceSetAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error {
+	update := bson.M{"$set": bson.M{"settings.access_approval_tags": tags}}
+	if len(tags) == 0 {
+		update = bson.M{"$unset": bson.M{"settings.access_approval_tags": ""}}
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetSessionPolicy(ctx context.Context, tenantID string, idleTimeout, maxSessionDuration int) error {
+	update := bson.M{
+		"$set": bson.M{
+			"settings.idle_timeout":         idleTimeout,
+			"settings.max_session_duration": maxSessionDuration,
+		},
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetRequireMFA(ctx context.Context, tenantID string, require, requireSSH bool) error {
+	update := bson.M{
+		"$set": bson.M{
+			"settings.require_mfa":     require,
+			"settings.require_ssh_mfa": requireSSH,
+		},
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error {
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$set": bson.M{"settings.require_enrollment_token": require}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error {
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, bson.M{"$set": bson.M{"settings.agent_tags_overwrite": overwrite}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceSetTransfer(ctx context.Context, tenantID string, transfer *models.NamespaceTransfer) error {
+	update := bson.M{"$set": bson.M{"transfer": transfer}}
+	if transfer == nil {
+		update = bson.M{"$unset": bson.M{"transfer": ""}}
+	}
+
+	res, err := s.db.Collection("namespaces").UpdateOne(ctx, bson.M{"tenant_id": tenantID}, update)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
+
+func (s *Store) NamespaceTransferOwnership(ctx context.Context, tenantID string, from, to string) error {
+	fromID, err := primitive.ObjectIDFromHex(from)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	toID, err := primitive.ObjectIDFromHex(to)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	session, err := s.db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	if _, err := session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		res, err := s.db.Collection("namespaces").UpdateOne(sessCtx,
+			bson.M{"tenant_id": tenantID, "owner": from, "members.id": to},
+			bson.M{
+				"$set": bson.M{
+					"owner":                to,
+					"members.$[from].role": guard.RoleAdministrator,
+					"members.$[to].role":   guard.RoleOwner,
+				},
+				"$unset": bson.M{"transfer": ""},
+			},
+			options.Update().SetArrayFilters(options.ArrayFilters{
+				Filters: []interface{}{bson.M{"from.id": from}, bson.M{"to.id": to}},
+			}),
+		)
+		if err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		if res.MatchedCount < 1 {
+			return nil, store.ErrNoDocuments
+		}
+
+		// The users' count of namespaces limits how many namespaces they own.
+		if _, err := s.db.Collection("users").UpdateOne(sessCtx, bson.M{"_id": fromID}, bson.M{"$inc": bson.M{"namespaces": -1}}); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		if _, err := s.db.Collection("users").UpdateOne(sessCtx, bson.M{"_id": toID}, bson.M{"$inc": bson.M{"namespaces": 1}}); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		return nil, nil
+	}); err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(ctx, strings.Join([]string{"namespace", tenantID}, "/")); err != nil {
+		logrus.Error(err)
+	}
+
+	return nil
+}
@@ -2,9 +2,11 @@ package mongo
 
 import (
 	"testing"
+	"time"
 
 	"github.com/shellhub-io/shellhub/api/pkg/dbtest"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -207,6 +209,222 @@ func TestNamespaceRemoveMember(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNamespaceEditMemberScope(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	err := mongostore.UserCreate(data.Context, &data.User)
+	assert.NoError(t, err)
+
+	member := data.User
+	member.ID = "507f1f77bcf86cd799439012"
+	member.Username = "memberFromNamespace"
+
+	err = mongostore.UserCreate(data.Context, &member)
+	assert.NoError(t, err)
+
+	_, err = mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	u, err := mongostore.UserGetByUsername(data.Context, member.Username)
+	assert.NoError(t, err)
+
+	_, err = mongostore.NamespaceAddMember(data.Context, "00000000-0000-4000-0000-000000000000", u.ID, guard.RoleObserver)
+	assert.NoError(t, err)
+
+	scope := &models.MemberScope{Tags: []string{"customer"}}
+	err = mongostore.NamespaceEditMemberScope(data.Context, "00000000-0000-4000-0000-000000000000", u.ID, scope)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, "00000000-0000-4000-0000-000000000000")
+	assert.NoError(t, err)
+
+	found, ok := guard.CheckMember(ns, u.ID)
+	assert.True(t, ok)
+	assert.Equal(t, scope, found.Scope)
+
+	err = mongostore.NamespaceEditMemberScope(data.Context, "00000000-0000-4000-0000-000000000000", "notAMember", scope)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceSetSessionPolicy(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	err = mongostore.NamespaceSetSessionPolicy(data.Context, "00000000-0000-4000-0000-000000000000", 15, 480)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, "00000000-0000-4000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, 15, ns.Settings.IdleTimeout)
+	assert.Equal(t, 480, ns.Settings.MaxSessionDuration)
+
+	err = mongostore.NamespaceSetSessionPolicy(data.Context, "nonexistent", 15, 480)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceSetRequireMFA(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	err = mongostore.NamespaceSetRequireMFA(data.Context, "00000000-0000-4000-0000-000000000000", true, true)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, "00000000-0000-4000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.True(t, ns.Settings.RequireMFA)
+	assert.True(t, ns.Settings.RequireSSHMFA)
+
+	err = mongostore.NamespaceSetRequireMFA(data.Context, "nonexistent", true, false)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceSetRequireEnrollmentToken(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	err = mongostore.NamespaceSetRequireEnrollmentToken(data.Context, "00000000-0000-4000-0000-000000000000", true)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, "00000000-0000-4000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.True(t, ns.Settings.RequireEnrollmentToken)
+
+	err = mongostore.NamespaceSetRequireEnrollmentToken(data.Context, "nonexistent", true)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceSetAgentTagsOverwrite(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	err = mongostore.NamespaceSetAgentTagsOverwrite(data.Context, "00000000-0000-4000-0000-000000000000", true)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, "00000000-0000-4000-0000-000000000000")
+	assert.NoError(t, err)
+	assert.True(t, ns.Settings.AgentTagsOverwrite)
+
+	err = mongostore.NamespaceSetAgentTagsOverwrite(data.Context, "nonexistent", true)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceSetTransfer(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	_, err := mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	transfer := &models.NamespaceTransfer{To: "507f1f77bcf86cd799439012", CreatedAt: time.Unix(0, 0).UTC(), ExpiresAt: time.Unix(3600, 0).UTC()}
+
+	err = mongostore.NamespaceSetTransfer(data.Context, data.Namespace.TenantID, transfer)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, data.Namespace.TenantID)
+	assert.NoError(t, err)
+	assert.Equal(t, transfer, ns.Transfer)
+
+	err = mongostore.NamespaceSetTransfer(data.Context, data.Namespace.TenantID, nil)
+	assert.NoError(t, err)
+
+	ns, err = mongostore.NamespaceGet(data.Context, data.Namespace.TenantID)
+	assert.NoError(t, err)
+	assert.Nil(t, ns.Transfer)
+
+	err = mongostore.NamespaceSetTransfer(data.Context, "nonexistent", nil)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestNamespaceTransferOwnership(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	err := mongostore.UserCreate(data.Context, &data.User)
+	assert.NoError(t, err)
+
+	member := data.User
+	member.ID = "507f1f77bcf86cd799439012"
+	member.Username = "member"
+	member.Email = "member@shellhub.io"
+
+	err = mongostore.UserCreate(data.Context, &member)
+	assert.NoError(t, err)
+
+	_, err = mongostore.NamespaceCreate(data.Context, &data.Namespace)
+	assert.NoError(t, err)
+
+	_, err = mongostore.NamespaceAddMember(data.Context, data.Namespace.TenantID, member.ID, guard.RoleOperator)
+	assert.NoError(t, err)
+
+	err = mongostore.NamespaceSetTransfer(data.Context, data.Namespace.TenantID, &models.NamespaceTransfer{To: member.ID})
+	assert.NoError(t, err)
+
+	// Only the owner can transfer the namespace.
+	err = mongostore.NamespaceTransferOwnership(data.Context, data.Namespace.TenantID, member.ID, data.User.ID)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+
+	err = mongostore.NamespaceTransferOwnership(data.Context, data.Namespace.TenantID, data.User.ID, member.ID)
+	assert.NoError(t, err)
+
+	ns, err := mongostore.NamespaceGet(data.Context, data.Namespace.TenantID)
+	assert.NoError(t, err)
+	assert.Equal(t, member.ID, ns.Owner)
+	assert.Nil(t, ns.Transfer)
+	assert.Equal(t, []models.Member{
+		{ID: data.User.ID, Role: guard.RoleAdministrator},
+		{ID: member.ID, Role: guard.RoleOwner},
+	}, ns.Members)
+
+	previous, _, err := mongostore.UserGetByID(data.Context, data.User.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, previous.Namespaces)
+
+	owner, _, err := mongostore.UserGetByID(data.Context, member.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, owner.Namespaces)
+}
+
 func TestNamespaceGetByName(t *testing.T) {
 	data := initData()
 
@@ -51,6 +51,29 @@ func (s *Store) SessionList(ctx context.Context, pagination paginator.Query) ([]
 		})
 	}
 
+	// Only match for the sessions from devices inside the member's scope if it is restricted
+	scope, err := s.memberScopeFromContext(ctx)
+	if err != nil {
+		return nil, 0, FromMongoError(err)
+	}
+
+	if scope != nil {
+		query = append(query, []bson.M{
+			{
+				"$lookup": bson.M{
+					"from":         "devices",
+					"localField":   "device_uid",
+					"foreignField": "uid",
+					"as":           "scope",
+				},
+			},
+			buildMemberScopeQuery(scope, "device_uid", "scope.tags"),
+			{
+				"$unset": "scope",
+			},
+		}...)
+	}
+
 	queryCount := query
 	queryCount = append(queryCount, bson.M{"$count": "count"})
 	count, err := AggregateCount(ctx, s.db.Collection("sessions"), queryCount)
@@ -115,6 +138,29 @@ func (s *Store) SessionGet(ctx context.Context, uid models.UID) (*models.Session
 		})
 	}
 
+	// Only match for the sessions from devices inside the member's scope if it is restricted
+	scope, err := s.memberScopeFromContext(ctx)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	if scope != nil {
+		query = append(query, []bson.M{
+			{
+				"$lookup": bson.M{
+					"from":         "devices",
+					"localField":   "device_uid",
+					"foreignField": "uid",
+					"as":           "scope",
+				},
+			},
+			buildMemberScopeQuery(scope, "device_uid", "scope.tags"),
+			{
+				"$unset": "scope",
+			},
+		}...)
+	}
+
 	session := new(models.Session)
 
 	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query)
@@ -211,7 +257,7 @@ func (s *Store) SessionSetLastSeen(ctx context.Context, uid models.UID) error {
 	return nil
 }
 
-func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error {
+func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID, reason string) error {
 	session := new(models.Session)
 	if err := s.db.Collection("sessions").FindOne(ctx, bson.M{"uid": uid}).Decode(&session); err != nil {
 		return FromMongoError(err)
@@ -219,6 +265,7 @@ func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error
 
 	session.LastSeen = clock.Now()
 	session.Closed = true
+	session.FinishReason = reason
 
 	opts := options.Update().SetUpsert(true)
 	_, err := s.db.Collection("sessions").UpdateOne(ctx, bson.M{"uid": session.UID}, bson.M{"$set": session}, opts)
@@ -231,6 +278,55 @@ func (s *Store) SessionDeleteActives(ctx context.Context, uid models.UID) error
 	return FromMongoError(err)
 }
 
+func (s *Store) SessionListActiveByMember(ctx context.Context, tenantID string, deviceUID models.UID, member string) ([]models.Session, error) {
+	query := []bson.M{
+		{
+			"$match": bson.M{
+				"tenant_id":  tenantID,
+				"device_uid": deviceUID,
+				"member":     member,
+				"closed":     false,
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "active_sessions",
+				"localField":   "uid",
+				"foreignField": "uid",
+				"as":           "active",
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"active": bson.M{"$anyElementTrue": []interface{}{"$active"}},
+			},
+		},
+		{
+			"$match": bson.M{
+				"active": true,
+			},
+		},
+	}
+
+	cursor, err := s.db.Collection("sessions").Aggregate(ctx, query)
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := make([]models.Session, 0)
+	for cursor.Next(ctx) {
+		session := new(models.Session)
+		if err := cursor.Decode(&session); err != nil {
+			return nil, FromMongoError(err)
+		}
+
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, FromMongoError(cursor.Err())
+}
+
 func (s *Store) SessionCreateRecordFrame(ctx context.Context, uid models.UID, recordSession *models.RecordedSession) error {
 	if _, err := s.db.Collection("recorded_sessions").InsertOne(ctx, &recordSession); err != nil {
 		return FromMongoError(err)
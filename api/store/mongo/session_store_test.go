@@ -171,8 +171,12 @@ func TestSessionDeleteActives(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEmpty(t, s)
 
-	err = mongostore.SessionDeleteActives(data.Context, models.UID(data.Session.UID))
+	err = mongostore.SessionDeleteActives(data.Context, models.UID(data.Session.UID), models.SessionFinishReasonIdle)
 	assert.NoError(t, err)
+
+	s, err = mongostore.SessionGet(data.Context, models.UID(data.Session.UID))
+	assert.NoError(t, err)
+	assert.Equal(t, models.SessionFinishReasonIdle, s.FinishReason)
 }
 
 func TestSessionCreateRecordFrame(t *testing.T) {
@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (s *Store) UserSessionCreate(ctx context.Context, session *models.UserSession) error {
+	if _, err := s.db.Collection("user_sessions").InsertOne(ctx, session); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
+
+func (s *Store) UserSessionGet(ctx context.Context, id string) (*models.UserSession, error) {
+	session := new(models.UserSession)
+	if err := s.db.Collection("user_sessions").FindOne(ctx, bson.M{"_id": id}).Decode(&session); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return session, nil
+}
+
+func (s *Store) UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error) {
+	cursor, err := s.db.Collection("user_sessions").Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.M{"last_used_at": -1}))
+	if err != nil {
+		return nil, FromMongoError(err)
+	}
+	defer cursor.Close(ctx)
+
+	sessions := make([]models.UserSession, 0)
+	for cursor.Next(ctx) {
+		session := new(models.UserSession)
+		if err := cursor.Decode(&session); err != nil {
+			return sessions, FromMongoError(err)
+		}
+
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, nil
+}
+
+func (s *Store) UserSessionRefresh(ctx context.Context, session *models.UserSession, previous string) error {
+	// The filter on the previous refresh token makes the rotation atomic, so concurrent refreshes cannot use it twice.
+	res, err := s.db.Collection("user_sessions").UpdateOne(ctx,
+		bson.M{"_id": session.ID, "refresh_token": previous},
+		bson.M{"$set": bson.M{
+			"refresh_token": session.RefreshToken,
+			"user_agent":    session.UserAgent,
+			"ip":            session.IP,
+			"last_used_at":  session.LastUsedAt,
+			"expires_at":    session.ExpiresAt,
+		}},
+	)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserSessionDelete(ctx context.Context, userID string, id string) error {
+	res, err := s.db.Collection("user_sessions").DeleteOne(ctx, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.DeletedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserSessionDeleteAll(ctx context.Context, userID string, except string) error {
+	if _, err := s.db.Collection("user_sessions").DeleteMany(ctx, bson.M{"user_id": userID, "_id": bson.M{"$ne": except}}); err != nil {
+		return FromMongoError(err)
+	}
+
+	return nil
+}
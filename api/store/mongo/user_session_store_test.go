@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/api/pkg/dbtest"
+	"github.com/shellhub-io/shellhub/api/store"
+	"github.com/shellhub-io/shellhub/pkg/cache"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSession(t *testing.T) {
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	ctx := context.TODO()
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	first := &models.UserSession{ID: "first", UserID: "user", RefreshToken: "hash", IP: "192.168.1.1", CreatedAt: now, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+	second := &models.UserSession{ID: "second", UserID: "user", RefreshToken: "hash", CreatedAt: now, LastUsedAt: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)}
+	other := &models.UserSession{ID: "other", UserID: "other", RefreshToken: "hash", CreatedAt: now, LastUsedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	for _, session := range []*models.UserSession{first, second, other} {
+		assert.NoError(t, mongostore.UserSessionCreate(ctx, session))
+	}
+
+	session, err := mongostore.UserSessionGet(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, first, session)
+
+	sessions, err := mongostore.UserSessionList(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UserSession{*second, *first}, sessions)
+
+	// The refresh token can only be replaced once.
+	refreshed := *first
+	refreshed.RefreshToken = "new"
+	refreshed.LastUsedAt = now.Add(2 * time.Minute)
+
+	assert.NoError(t, mongostore.UserSessionRefresh(ctx, &refreshed, "hash"))
+	assert.EqualError(t, mongostore.UserSessionRefresh(ctx, &refreshed, "hash"), store.ErrNoDocuments.Error())
+
+	session, err = mongostore.UserSessionGet(ctx, "first")
+	assert.NoError(t, err)
+	assert.Equal(t, &refreshed, session)
+
+	// The sessions are only deleted by their users.
+	assert.EqualError(t, mongostore.UserSessionDelete(ctx, "other", "first"), store.ErrNoDocuments.Error())
+	assert.NoError(t, mongostore.UserSessionDelete(ctx, "user", "second"))
+
+	assert.NoError(t, mongostore.UserSessionCreate(ctx, second))
+	assert.NoError(t, mongostore.UserSessionDeleteAll(ctx, "user", "second"))
+
+	sessions, err = mongostore.UserSessionList(ctx, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.UserSession{*second}, sessions)
+
+	_, err = mongostore.UserSessionGet(ctx, "other")
+	assert.NoError(t, err)
+}
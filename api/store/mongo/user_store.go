@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/shellhub-io/shellhub/api/pkg/gateway"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mongo/queries"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -110,6 +111,16 @@ func (s *Store) UserGetByEmail(ctx context.Context, email string) (*models.User,
 	return user, nil
 }
 
+func (s *Store) UserGetByIdentity(ctx context.Context, provider string, subject string) (*models.User, error) {
+	user := new(models.User)
+
+	if err := s.db.Collection("users").FindOne(ctx, bson.M{"identity.provider": provider, "identity.subject": subject}).Decode(&user); err != nil {
+		return nil, FromMongoError(err)
+	}
+
+	return user, nil
+}
+
 func (s *Store) UserGetByID(ctx context.Context, id string, ns bool) (*models.User, int, error) {
 	user := new(models.User)
 	objID, err := primitive.ObjectIDFromHex(id)
@@ -213,6 +224,88 @@ func (s *Store) UserUpdatePassword(ctx context.Context, newPassword string, id s
 	return nil
 }
 
+func (s *Store) UserUpdateMFA(ctx context.Context, id string, mfa models.UserMFA) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	res, err := s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"mfa": mfa}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserDeleteRecoveryCode(ctx context.Context, id string, hash string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	// The filter on the code makes the removal atomic, so concurrent logins cannot use the same code.
+	res, err := s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID, "mfa.recovery_codes": hash}, bson.M{"$pull": bson.M{"mfa.recovery_codes": hash}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.ModifiedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserSetMFAStep(ctx context.Context, id string, step uint64) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	// The filter on the step makes the update atomic, so concurrent logins cannot use the same code.
+	filter := bson.M{
+		"_id": objID,
+		"$or": []bson.M{
+			{"mfa.last_step": bson.M{"$exists": false}},
+			{"mfa.last_step": bson.M{"$lt": step}},
+		},
+	}
+
+	res, err := s.db.Collection("users").UpdateOne(ctx, filter, bson.M{"$set": bson.M{"mfa.last_step": step}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.ModifiedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (s *Store) UserUpdateIdentity(ctx context.Context, id string, identity *models.UserIdentity) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	res, err := s.db.Collection("users").UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"identity": identity}})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.MatchedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
 func (s *Store) UserUpdateFromAdmin(ctx context.Context, name string, username string, email string, password string, id string) error {
 	user, _, err := s.UserGetByID(ctx, id, false)
 	objID, _ := primitive.ObjectIDFromHex(id)
@@ -267,6 +360,19 @@ func (s *Store) UserGetToken(ctx context.Context, id string) (*models.UserTokenR
 	return token, nil
 }
 
+func (s *Store) UserDeleteToken(ctx context.Context, id string, token string) error {
+	res, err := s.db.Collection("recovery_tokens").DeleteOne(ctx, bson.M{"user": id, "token": token})
+	if err != nil {
+		return FromMongoError(err)
+	}
+
+	if res.DeletedCount < 1 {
+		return store.ErrNoDocuments
+	}
+
+	return nil
+}
+
 func (s *Store) UserDeleteTokens(ctx context.Context, id string) error {
 	if _, err := s.db.Collection("recovery_tokens").DeleteMany(ctx, bson.M{"user": id}); err != nil {
 		return FromMongoError(err)
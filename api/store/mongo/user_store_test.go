@@ -6,6 +6,7 @@ import (
 
 	"github.com/shellhub-io/shellhub/api/pkg/dbtest"
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/pkg/api/paginator"
 	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -222,6 +223,155 @@ func TestUserUpdatePassword(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUserUpdateMFA(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	user := models.User{UserData: models.UserData{Name: "name", Username: "username", Email: "email"}, UserPassword: models.UserPassword{Password: "password"}}
+
+	result, err := db.Client().Database("test").Collection("users").InsertOne(data.Context, user)
+	assert.NoError(t, err)
+
+	objID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	mfa := models.UserMFA{Enabled: true, Secret: "secret", RecoveryCodes: []string{"first", "second"}}
+
+	err = mongostore.UserUpdateMFA(data.Context, objID, mfa)
+	assert.NoError(t, err)
+
+	us, _, err := mongostore.UserGetByID(data.Context, objID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, mfa, us.MFA)
+
+	err = mongostore.UserUpdateMFA(data.Context, primitive.NewObjectID().Hex(), mfa)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestUserDeleteRecoveryCode(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	user := models.User{
+		UserData:     models.UserData{Name: "name", Username: "username", Email: "email"},
+		UserPassword: models.UserPassword{Password: "password"},
+		MFA:          models.UserMFA{Enabled: true, Secret: "secret", RecoveryCodes: []string{"first", "second"}},
+	}
+
+	result, err := db.Client().Database("test").Collection("users").InsertOne(data.Context, user)
+	assert.NoError(t, err)
+
+	objID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	err = mongostore.UserDeleteRecoveryCode(data.Context, objID, "first")
+	assert.NoError(t, err)
+
+	us, _, err := mongostore.UserGetByID(data.Context, objID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second"}, us.MFA.RecoveryCodes)
+
+	err = mongostore.UserDeleteRecoveryCode(data.Context, objID, "first")
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestUserSetMFAStep(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	user := models.User{
+		UserData:     models.UserData{Name: "name", Username: "username", Email: "email"},
+		UserPassword: models.UserPassword{Password: "password"},
+		MFA:          models.UserMFA{Enabled: true, Secret: "secret"},
+	}
+
+	result, err := db.Client().Database("test").Collection("users").InsertOne(data.Context, user)
+	assert.NoError(t, err)
+
+	objID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	err = mongostore.UserSetMFAStep(data.Context, objID, 10)
+	assert.NoError(t, err)
+
+	us, _, err := mongostore.UserGetByID(data.Context, objID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), us.MFA.LastStep)
+
+	err = mongostore.UserSetMFAStep(data.Context, objID, 10)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+
+	err = mongostore.UserSetMFAStep(data.Context, objID, 9)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+
+	err = mongostore.UserSetMFAStep(data.Context, objID, 11)
+	assert.NoError(t, err)
+}
+
+func TestUserGetByIdentity(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	user := models.User{
+		UserData: models.UserData{Name: "name", Username: "username", Email: "email"},
+		Identity: &models.UserIdentity{Provider: models.UserIdentityOIDC, Subject: "subject"},
+	}
+
+	_, err := db.Client().Database("test").Collection("users").InsertOne(data.Context, user)
+	assert.NoError(t, err)
+
+	us, err := mongostore.UserGetByIdentity(data.Context, models.UserIdentityOIDC, "subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "username", us.Username)
+
+	_, err = mongostore.UserGetByIdentity(data.Context, models.UserIdentityOIDC, "other")
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
+func TestUserUpdateIdentity(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	user := models.User{
+		UserData:     models.UserData{Name: "name", Username: "username", Email: "email"},
+		UserPassword: models.UserPassword{Password: "password"},
+	}
+
+	result, err := db.Client().Database("test").Collection("users").InsertOne(data.Context, user)
+	assert.NoError(t, err)
+
+	objID := result.InsertedID.(primitive.ObjectID).Hex()
+
+	identity := &models.UserIdentity{Provider: models.UserIdentityOIDC, Subject: "subject"}
+
+	err = mongostore.UserUpdateIdentity(data.Context, objID, identity)
+	assert.NoError(t, err)
+
+	us, _, err := mongostore.UserGetByID(data.Context, objID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, identity, us.Identity)
+
+	err = mongostore.UserUpdateIdentity(data.Context, "000000000000000000000000", identity)
+	assert.EqualError(t, err, store.ErrNoDocuments.Error())
+}
+
 func TestUpdateUserFromAdmin(t *testing.T) {
 	data := initData()
 
@@ -266,6 +416,26 @@ func TestUserCreateToken(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestUserDeleteToken(t *testing.T) {
+	data := initData()
+
+	db := dbtest.DBServer{}
+	defer db.Stop()
+
+	mongostore := NewStore(db.Client().Database("test"), cache.NewNullCache())
+
+	id := "507f1f77bcf86cd799439011"
+
+	err := mongostore.UserCreateToken(data.Context, &models.UserTokenRecover{Token: "token", User: id})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, mongostore.UserDeleteToken(data.Context, id, "other"), store.ErrNoDocuments.Error())
+	assert.NoError(t, mongostore.UserDeleteToken(data.Context, id, "token"))
+
+	// The token is consumed by its first deletion.
+	assert.EqualError(t, mongostore.UserDeleteToken(data.Context, id, "token"), store.ErrNoDocuments.Error())
+}
+
 func TestUserUpdateAccountStatus(t *testing.T) {
 	data := initData()
 
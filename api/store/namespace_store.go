@@ -18,7 +18,18 @@ type NamespaceStore interface {
 	NamespaceAddMember(ctx context.Context, tenantID string, memberID string, memberRole string) (*models.Namespace, error)
 	NamespaceRemoveMember(ctx context.Context, tenantID string, memberID string) (*models.Namespace, error)
 	NamespaceEditMember(ctx context.Context, tenantID string, memberID string, memberNewRole string) error
+	NamespaceEditMemberScope(ctx context.Context, tenantID string, memberID string, scope *models.MemberScope) error
 	NamespaceGetFirst(ctx context.Context, id string) (*models.Namespace, error)
 	NamespaceSetSessionRecord(ctx context.Context, sessionRecord bool, tenantID string) error
 	NamespaceGetSessionRecord(ctx context.Context, tenantID string) (bool, error)
+	NamespaceSetAccessApprovalTags(ctx context.Context, tenantID string, tags []string) error
+	NamespaceSetSessionPolicy(ctx context.Context, tenantID string, idleTimeout, maxSessionDuration int) error
+	NamespaceSetRequireMFA(ctx context.Context, tenantID string, require, requireSSH bool) error
+	NamespaceSetRequireEnrollmentToken(ctx context.Context, tenantID string, require bool) error
+	NamespaceSetAgentTagsOverwrite(ctx context.Context, tenantID string, overwrite bool) error
+	// NamespaceSetTransfer sets the namespace's pending ownership transfer. A nil transfer removes it.
+	NamespaceSetTransfer(ctx context.Context, tenantID string, transfer *models.NamespaceTransfer) error
+	// NamespaceTransferOwnership makes the member to the namespace's owner, demoting the current owner to administrator
+	// and removing the pending transfer. It fails with ErrNoDocuments when from is not the namespace's owner.
+	NamespaceTransferOwnership(ctx context.Context, tenantID string, from, to string) error
 }
@@ -13,7 +13,9 @@ type SessionStore interface {
 	SessionCreate(ctx context.Context, session models.Session) (*models.Session, error)
 	SessionSetAuthenticated(ctx context.Context, uid models.UID, authenticated bool) error
 	SessionSetLastSeen(ctx context.Context, uid models.UID) error
-	SessionDeleteActives(ctx context.Context, uid models.UID) error
+	SessionDeleteActives(ctx context.Context, uid models.UID, reason string) error
+	// SessionListActiveByMember lists the open sessions of the namespace's member to the device.
+	SessionListActiveByMember(ctx context.Context, tenantID string, deviceUID models.UID, member string) ([]models.Session, error)
 	SessionCreateRecordFrame(ctx context.Context, uid models.UID, recordSession *models.RecordedSession) error
 	SessionUpdateDeviceUID(ctx context.Context, oldUID models.UID, newUID models.UID) error
 	SessionGetRecordFrame(ctx context.Context, uid models.UID) ([]models.RecordedSession, int, error)
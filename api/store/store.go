@@ -8,6 +8,7 @@ type Store interface {
 	DeviceTagsStore
 	SessionStore
 	UserStore
+	UserSessionStore
 	FirewallStore
 	FirewallTagsStore
 	NamespaceStore
@@ -16,4 +17,6 @@ type Store interface {
 	PrivateKeyStore
 	LicenseStore
 	StatsStore
+	AccessRequestStore
+	EnrollmentTokenStore
 }
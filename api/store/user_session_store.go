@@ -0,0 +1,20 @@
+package store
+
+import (
+	"context"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+)
+
+type UserSessionStore interface {
+	UserSessionCreate(ctx context.Context, session *models.UserSession) error
+	UserSessionGet(ctx context.Context, id string) (*models.UserSession, error)
+	// UserSessionList lists the user's sessions, from the most recently used.
+	UserSessionList(ctx context.Context, userID string) ([]models.UserSession, error)
+	// UserSessionRefresh replaces the session's refresh token, usage and expiration. It returns ErrNoDocuments when the
+	// session's refresh token is not the previous one anymore, so a refresh token can only be used once.
+	UserSessionRefresh(ctx context.Context, session *models.UserSession, previous string) error
+	UserSessionDelete(ctx context.Context, userID string, id string) error
+	// UserSessionDeleteAll deletes the user's sessions, except the one whose ID is except.
+	UserSessionDeleteAll(ctx context.Context, userID string, except string) error
+}
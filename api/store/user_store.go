@@ -13,11 +13,26 @@ type UserStore interface {
 	UserGetByUsername(ctx context.Context, username string) (*models.User, error)
 	UserGetByEmail(ctx context.Context, email string) (*models.User, error)
 	UserGetByID(ctx context.Context, id string, ns bool) (*models.User, int, error)
+	// UserGetByIdentity gets the user linked to the subject's account at the identity provider.
+	UserGetByIdentity(ctx context.Context, provider string, subject string) (*models.User, error)
 	UserUpdateData(ctx context.Context, id string, user models.User) error
 	UserUpdatePassword(ctx context.Context, newPassword string, id string) error
+	// UserUpdateMFA replaces the user's MFA configuration.
+	UserUpdateMFA(ctx context.Context, id string, mfa models.UserMFA) error
+	// UserDeleteRecoveryCode removes the recovery code's hash from the user's MFA configuration. It returns
+	// ErrNoDocuments when the user does not have it, so a recovery code can only be used once.
+	UserDeleteRecoveryCode(ctx context.Context, id string, hash string) error
+	// UserSetMFAStep records step as the time step of the last TOTP code accepted from the user. It returns
+	// ErrNoDocuments when the recorded step is not older than step, so a TOTP code can only be used once.
+	UserSetMFAStep(ctx context.Context, id string, step uint64) error
+	// UserUpdateIdentity links the user to an account at an external identity provider.
+	UserUpdateIdentity(ctx context.Context, id string, identity *models.UserIdentity) error
 	UserUpdateFromAdmin(ctx context.Context, name string, username string, email string, password string, id string) error
 	UserCreateToken(ctx context.Context, token *models.UserTokenRecover) error
 	UserGetToken(ctx context.Context, id string) (*models.UserTokenRecover, error)
+	// UserDeleteToken deletes the user's token. It returns ErrNoDocuments when the token is not stored anymore, so a
+	// token can only be used once.
+	UserDeleteToken(ctx context.Context, id string, token string) error
 	UserDeleteTokens(ctx context.Context, id string) error
 	UserUpdateAccountStatus(ctx context.Context, id string) error
 	UserDetachInfo(ctx context.Context, id string) (map[string][]*models.Namespace, error)
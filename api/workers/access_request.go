@@ -0,0 +1,70 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/shellhub-io/shellhub/api/services"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	accessRequestQueue      = "access_request"
+	accessRequestExpireTask = "access_request:expire"
+)
+
+// StartAccessRequestExpirer starts a worker to mark as expired the access requests whose window has ended. It runs with
+// the frequency defined by SHELLHUB_ACCESS_REQUEST_EXPIRE_SCHEDULE.
+//
+// New connections are refused at the end of the window regardless of the worker, as they are evaluated against the
+// current time; the worker closes the sessions opened during the window, so they last until its next run at most.
+//
+// Unlike the other workers, it does not block the caller.
+func StartAccessRequestExpirer(_ context.Context, service services.AccessRequestService) error {
+	envs, err := getEnvs()
+	if err != nil {
+		return fmt.Errorf("failed to get the envs: %w", err)
+	}
+
+	addr, err := asynq.ParseRedisURI(envs.RedisURI)
+	if err != nil {
+		return fmt.Errorf("failed to parse redis uri: %w", err)
+	}
+
+	srv := asynq.NewServer(
+		addr,
+		asynq.Config{ //nolint:exhaustruct
+			Concurrency: 1,
+			Queues:      map[string]int{accessRequestQueue: 1},
+		},
+	)
+
+	mux := asynq.NewServeMux()
+
+	mux.HandleFunc(accessRequestExpireTask, func(ctx context.Context, task *asynq.Task) error {
+		expired, err := service.ExpireAccessRequests(ctx)
+		if err != nil {
+			return err
+		}
+
+		if expired > 0 {
+			log.WithField("expired", expired).Info("access requests expired")
+		}
+
+		return nil
+	})
+
+	if err := srv.Start(mux); err != nil {
+		return fmt.Errorf("failed to start the server: %w", err)
+	}
+
+	scheduler := asynq.NewScheduler(addr, nil)
+
+	if _, err := scheduler.Register(envs.AccessRequestExpireSchedule,
+		asynq.NewTask(accessRequestExpireTask, nil, asynq.TaskID(accessRequestExpireTask), asynq.Queue(accessRequestQueue))); err != nil {
+		return fmt.Errorf("failed to register the task: %w", err)
+	}
+
+	return scheduler.Start()
+}
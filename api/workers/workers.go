@@ -7,6 +7,8 @@ type Envs struct {
 	RedisURI                      string `envconfig:"redis_uri" default:"redis://redis:6379"`
 	SessionRecordCleanupSchedule  string `envconfig:"session_record_cleanup_schedule" default:"@daily"`
 	SessionRecordCleanupRetention int    `envconfig:"record_retention" default:"0"`
+	// AccessRequestExpireSchedule is how often the access requests whose window has ended are marked as expired.
+	AccessRequestExpireSchedule string `envconfig:"access_request_expire_schedule" default:"@every 1m"`
 	// AsynqGroupMaxDelay is the maximum duration to wait before processing a group of tasks.
 	//
 	// Its time unit is second.
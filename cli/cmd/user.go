@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/shellhub-io/shellhub/cli/pkg/inputs"
 	"github.com/shellhub-io/shellhub/cli/services"
 	"github.com/spf13/cobra"
@@ -19,6 +21,7 @@ func UserCommands(service services.Services) *cobra.Command {
 	cmd.AddCommand(userCreate(service))
 	cmd.AddCommand(userResetPassword(service))
 	cmd.AddCommand(userDelete(service))
+	cmd.AddCommand(userUnlock(service))
 
 	return cmd
 }
@@ -103,3 +106,35 @@ func userDelete(service services.Services) *cobra.Command {
 		},
 	}
 }
+
+func userUnlock(service services.Services) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unlock <username>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Unlock a user",
+		Long: `Unlocks a user blocked by too many failed login attempts, listing the attempts that were recorded.
+The source IPs blocked by the failed attempts are not unlocked.`,
+		Example: `cli user unlock john_doe`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var input inputs.UserUnlock
+
+			if err := bind(args, &input); err != nil {
+				return err
+			}
+
+			attempts, err := service.UserUnlock(cmd.Context(), &input)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println("User unlocked successfully")
+			cmd.Println("Username:", input.Username)
+
+			for _, attempt := range attempts {
+				cmd.Println("Failed attempt:", attempt.Time.Format(time.RFC3339), attempt.IP)
+			}
+
+			return nil
+		},
+	}
+}
@@ -18,6 +18,11 @@ type UserDelete struct {
 	Username string `validate:"required,username"`
 }
 
+// UserUnlock defines the structure for inputs when unlocking a user.
+type UserUnlock struct {
+	Username string `validate:"required,username"`
+}
+
 // UserPassword the structure for validate passowrd.
 type UserPassword struct {
 	Password string `validate:"required,password"`
@@ -2,13 +2,13 @@ package services
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"strings"
 
+	"github.com/shellhub-io/shellhub/api/pkg/lockout"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/cli/pkg/inputs"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/validator"
 )
@@ -27,6 +27,9 @@ type Services interface {
 	UserDelete(ctx context.Context, input *inputs.UserDelete) error
 	// UserUpdate updates a user's data based on the provided username.
 	UserUpdate(ctx context.Context, input *inputs.UserUpdate) error
+	// UserUnlock forgets the failed login attempts of a user, unlocking its account, and returns the attempts that were
+	// recorded.
+	UserUnlock(ctx context.Context, input *inputs.UserUnlock) ([]lockout.Attempt, error)
 	// NamespaceCreate initializes a new namespace, making the specified user its owner.
 	// The tenant defaults to a UUID if not provided.
 	// Max device limit is based on the envs.IsCloud() setting.
@@ -40,22 +43,17 @@ type Services interface {
 }
 
 // service is an internal struct that implements the Services interface.
-// It contains a store, which provides a mechanism to interact with the data store.
+// It contains a store, which provides a mechanism to interact with the data store, the cache shared with the API, and
+// the limiter of the accounts' failed login attempts, which are kept at the cache.
 type service struct {
-	store store.Store
+	store    store.Store
+	cache    cache.Cache
+	accounts *lockout.Limiter
 }
 
-// NewService creates and returns a new instance of the service with the provided store.
-func NewService(store store.Store) Services {
-	return &service{store}
-}
-
-// hashPassword computes the sha256 hash of the given password and returns
-// the hex encoded string representation of the hash.
-func hashPassword(password string) string {
-	hash := sha256.Sum256([]byte(password))
-
-	return hex.EncodeToString(hash[:])
+// NewService creates and returns a new instance of the service with the provided store and cache.
+func NewService(store store.Store, cache cache.Cache) Services {
+	return &service{store: store, cache: cache, accounts: lockout.NewLimiter(cache, "account", lockout.AccountPolicy)}
 }
 
 // normalizeField converts the provided string data to lowercase.
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 
+	"github.com/shellhub-io/shellhub/api/pkg/lockout"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/cli/pkg/inputs"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
 )
 
 // UserCreate adds a new user based on the provided user's data. This method validates data and
@@ -20,6 +22,11 @@ func (s *service) UserCreate(ctx context.Context, input *inputs.UserCreate) (*mo
 		return nil, ErrUserDataInvalid
 	}
 
+	hash, err := password.Hash(input.Password)
+	if err != nil {
+		return nil, ErrUserPasswordInvalid
+	}
+
 	name := normalizeField(input.Username)
 	mail := normalizeField(input.Email)
 	userData := models.UserData{
@@ -31,7 +38,7 @@ func (s *service) UserCreate(ctx context.Context, input *inputs.UserCreate) (*mo
 	user := &models.User{
 		UserData: userData,
 		UserPassword: models.UserPassword{
-			Password: hashPassword(input.Password),
+			Password: hash,
 		},
 		Confirmed:     true,
 		CreatedAt:     clock.Now(),
@@ -115,9 +122,70 @@ func (s *service) UserUpdate(ctx context.Context, input *inputs.UserUpdate) erro
 		return ErrUserNotFound
 	}
 
-	if err := s.store.UserUpdatePassword(ctx, hashPassword(input.Password), user.ID); err != nil {
+	hash, err := password.Hash(input.Password)
+	if err != nil {
+		return ErrUserPasswordInvalid
+	}
+
+	if err := s.store.UserUpdatePassword(ctx, hash, user.ID); err != nil {
+		return ErrFailedUpdateUser
+	}
+
+	// The password may be changed because it leaked, so the user's sessions are revoked, as the API does when the user
+	// changes or resets it.
+	if err := s.revokeUserSessions(ctx, user.ID); err != nil {
 		return ErrFailedUpdateUser
 	}
 
 	return nil
 }
+
+// revokeUserSessions deletes the user's sessions, invalidating their refresh tokens and tokens. The API caches the
+// active sessions, so they are also removed from the cache.
+func (s *service) revokeUserSessions(ctx context.Context, userID string) error {
+	sessions, err := s.store.UserSessionList(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.UserSessionDeleteAll(ctx, userID, ""); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := s.cache.Delete(ctx, "user_session/"+session.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UserUnlock forgets the failed login attempts of a user, unlocking its account, and returns the attempts that were
+// recorded. The attempts are kept by the user's ID, for its password and, apart, for its second factor.
+func (s *service) UserUnlock(ctx context.Context, input *inputs.UserUnlock) ([]lockout.Attempt, error) {
+	if err := validate(input); err != nil {
+		return nil, ErrUserDataInvalid
+	}
+
+	user, err := s.store.UserGetByUsername(ctx, input.Username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	var attempts []lockout.Attempt
+	for _, account := range []string{user.ID, "mfa/" + user.ID} {
+		record, err := s.accounts.Get(ctx, account)
+		if err != nil {
+			return nil, ErrFailedUnlockUser
+		}
+
+		attempts = append(attempts, record.Attempts...)
+
+		if err := s.accounts.Reset(ctx, account); err != nil {
+			return nil, ErrFailedUnlockUser
+		}
+	}
+
+	return attempts, nil
+}
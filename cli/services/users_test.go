@@ -2,19 +2,43 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/shellhub-io/shellhub/api/pkg/guard"
+	"github.com/shellhub-io/shellhub/api/pkg/lockout"
 	"github.com/shellhub-io/shellhub/api/store"
 	"github.com/shellhub-io/shellhub/api/store/mocks"
 	"github.com/shellhub-io/shellhub/cli/pkg/inputs"
+	"github.com/shellhub-io/shellhub/pkg/cache"
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	clockmock "github.com/shellhub-io/shellhub/pkg/clock/mocks"
 	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/password"
 	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
 )
 
+// hashOf matches a password's hash created from plain.
+func hashOf(plain string) interface{} {
+	return testifymock.MatchedBy(func(hash string) bool {
+		return !password.NeedsRehash(hash) && password.Compare(plain, hash)
+	})
+}
+
+// withPassword matches a user equal to expected, except by its password, that must be a hash created from plain.
+func withPassword(expected *models.User, plain string) interface{} {
+	return testifymock.MatchedBy(func(user *models.User) bool {
+		actual := *user
+		actual.Password = expected.Password
+
+		return !password.NeedsRehash(user.Password) && password.Compare(plain, user.Password) && reflect.DeepEqual(expected, &actual)
+	})
+}
+
 func TestUserCreate(t *testing.T) {
 	type Expected struct {
 		user *models.User
@@ -76,23 +100,17 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(store.ErrDuplicate).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(store.ErrDuplicate).Once()
 				currentUser := &models.User{
 					UserData: models.UserData{
 						Name:     "jane_doe",
 						Email:    "john.doe@test.com",
 						Username: "jane_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
@@ -114,23 +132,17 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(store.ErrDuplicate).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(store.ErrDuplicate).Once()
 				currentUser := &models.User{
 					UserData: models.UserData{
 						Name:     "john_doe",
 						Email:    "jane.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
@@ -152,23 +164,17 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(store.ErrDuplicate).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(store.ErrDuplicate).Once()
 				currentUser := &models.User{
 					UserData: models.UserData{
 						Name:     "john_doe",
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
@@ -190,14 +196,11 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(store.ErrDuplicate).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(store.ErrDuplicate).Once()
 				mock.On("UserGetByUsername", ctx, "john_doe").Return(nil, nil).Once()
 				mock.On("UserGetByEmail", ctx, "john.doe@test.com").Return(nil, nil).Once()
 			},
@@ -215,14 +218,11 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(errors.New("error")).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(errors.New("error")).Once()
 			},
 			expected: Expected{nil, ErrCreateNewUser},
 		},
@@ -238,14 +238,11 @@ func TestUserCreate(t *testing.T) {
 						Email:    "john.doe@test.com",
 						Username: "john_doe",
 					},
-					UserPassword: models.UserPassword{
-						Password: hashPassword("password"),
-					},
 					Confirmed:     true,
 					CreatedAt:     clock.Now(),
 					MaxNamespaces: MaxNumberNamespacesCommunity,
 				}
-				mock.On("UserCreate", ctx, user).Return(nil).Once()
+				mock.On("UserCreate", ctx, withPassword(user, "password")).Return(nil).Once()
 			},
 			expected: Expected{&models.User{
 				UserData: models.UserData{
@@ -253,9 +250,6 @@ func TestUserCreate(t *testing.T) {
 					Email:    "john.doe@test.com",
 					Username: "john_doe",
 				},
-				UserPassword: models.UserPassword{
-					Password: hashPassword("password"),
-				},
 				Confirmed:     true,
 				CreatedAt:     clock.Now(),
 				MaxNamespaces: MaxNumberNamespacesCommunity,
@@ -267,8 +261,12 @@ func TestUserCreate(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			tc.requiredMocks()
 
-			service := NewService(store.Store(mock))
+			service := NewService(store.Store(mock), cache.NewNullCache())
 			user, err := service.UserCreate(ctx, &inputs.UserCreate{Username: tc.username, Password: tc.password, Email: tc.email})
+			if user != nil {
+				assert.True(t, password.Compare(tc.password, user.Password))
+				user.Password = ""
+			}
 
 			assert.Equal(t, tc.expected, Expected{user, err})
 		})
@@ -399,7 +397,7 @@ func TestUserDelete(t *testing.T) {
 		t.Run(tc.description, func(t *testing.T) {
 			tc.requiredMocks()
 
-			service := NewService(store.Store(mock))
+			service := NewService(store.Store(mock), cache.NewNullCache())
 			err := service.UserDelete(ctx, &inputs.UserDelete{Username: tc.username})
 			assert.Equal(t, tc.expected, err)
 		})
@@ -450,12 +448,31 @@ func TestUserResetPassword(t *testing.T) {
 					},
 				}
 				mock.On("UserGetByUsername", ctx, "john_doe").Return(user, nil).Once()
-				mock.On("UserUpdatePassword", ctx, hashPassword("password"), "507f191e810c19729de860ea").Return(errors.New("error")).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("password"), "507f191e810c19729de860ea").Return(errors.New("error")).Once()
+			},
+			expected: ErrFailedUpdateUser,
+		},
+		{
+			description: "fails to revoke the user's sessions",
+			username:    "john_doe",
+			password:    "password",
+			requiredMocks: func() {
+				user := &models.User{
+					ID: "507f191e810c19729de860ea",
+					UserData: models.UserData{
+						Name:     "John Doe",
+						Email:    "john.doe@test.com",
+						Username: "john_doe",
+					},
+				}
+				mock.On("UserGetByUsername", ctx, "john_doe").Return(user, nil).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("password"), "507f191e810c19729de860ea").Return(nil).Once()
+				mock.On("UserSessionList", ctx, "507f191e810c19729de860ea").Return(nil, errors.New("error")).Once()
 			},
 			expected: ErrFailedUpdateUser,
 		},
 		{
-			description: "successfully reset the user password",
+			description: "successfully reset the user password and revoke its sessions",
 			username:    "john_doe",
 			password:    "password",
 			requiredMocks: func() {
@@ -468,7 +485,9 @@ func TestUserResetPassword(t *testing.T) {
 					},
 				}
 				mock.On("UserGetByUsername", ctx, "john_doe").Return(user, nil).Once()
-				mock.On("UserUpdatePassword", ctx, hashPassword("password"), "507f191e810c19729de860ea").Return(nil).Once()
+				mock.On("UserUpdatePassword", ctx, hashOf("password"), "507f191e810c19729de860ea").Return(nil).Once()
+				mock.On("UserSessionList", ctx, "507f191e810c19729de860ea").Return([]models.UserSession{{ID: "session"}}, nil).Once()
+				mock.On("UserSessionDeleteAll", ctx, "507f191e810c19729de860ea", "").Return(nil).Once()
 			},
 			expected: nil,
 		},
@@ -477,11 +496,109 @@ func TestUserResetPassword(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.description, func(t *testing.T) {
 			tc.requiredMocks()
-			service := NewService(store.Store(mock))
+
+			sessions := memoryCache{"user_session/session": []byte("true")}
+
+			service := NewService(store.Store(mock), sessions)
 			err := service.UserUpdate(ctx, &inputs.UserUpdate{Username: tc.username, Password: tc.password})
 			assert.Equal(t, tc.expected, err)
+
+			if tc.expected == nil {
+				assert.NotContains(t, sessions, "user_session/session")
+			}
 		})
 	}
 
 	mock.AssertExpectations(t)
 }
+
+// memoryCache is a cache.Cache that keeps the values in memory, ignoring their expiration.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(_ context.Context, key string, value interface{}) error {
+	if data, ok := c[key]; ok {
+		return json.Unmarshal(data, value)
+	}
+
+	return nil
+}
+
+func (c memoryCache) Set(_ context.Context, key string, value interface{}, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	c[key] = data
+
+	return err
+}
+
+func (c memoryCache) Delete(_ context.Context, key string) error {
+	delete(c, key)
+
+	return nil
+}
+
+func (c memoryCache) Incr(_ context.Context, key string, _ time.Duration) (int64, error) {
+	var value int64
+	if data, ok := c[key]; ok {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return 0, err
+		}
+	}
+
+	value++
+
+	data, err := json.Marshal(value)
+	c[key] = data
+
+	return value, err
+}
+
+func TestUserUnlock(t *testing.T) {
+	mock := new(mocks.Store)
+	ctx := context.TODO()
+
+	user := &models.User{
+		ID: "507f191e810c19729de860ea",
+		UserData: models.UserData{
+			Name:     "John Doe",
+			Email:    "john.doe@test.com",
+			Username: "john_doe",
+		},
+	}
+
+	t.Run("fails when could not find a user", func(t *testing.T) {
+		mock.On("UserGetByUsername", ctx, "john_doe").Return(nil, errors.New("error")).Once()
+
+		service := NewService(store.Store(mock), memoryCache{})
+		_, err := service.UserUnlock(ctx, &inputs.UserUnlock{Username: "john_doe"})
+		assert.Equal(t, ErrUserNotFound, err)
+	})
+
+	t.Run("successfully unlock the user", func(t *testing.T) {
+		mock.On("UserGetByUsername", ctx, "john_doe").Return(user, nil).Once()
+
+		cache := memoryCache{}
+		accounts := lockout.NewLimiter(cache, "account", lockout.AccountPolicy)
+
+		for i := 0; i < lockout.AccountPolicy.MaxFailures; i++ {
+			_, err := accounts.Fail(ctx, user.ID, "192.168.1.1")
+			assert.NoError(t, err)
+		}
+
+		_, err := accounts.Fail(ctx, "mfa/"+user.ID, "192.168.1.2")
+		assert.NoError(t, err)
+
+		service := NewService(store.Store(mock), cache)
+		attempts, err := service.UserUnlock(ctx, &inputs.UserUnlock{Username: "john_doe"})
+		assert.NoError(t, err)
+		assert.Len(t, attempts, lockout.AccountPolicy.History+1)
+		assert.Equal(t, "192.168.1.2", attempts[len(attempts)-1].IP)
+
+		for _, account := range []string{user.ID, "mfa/" + user.ID} {
+			wait, err := accounts.Blocked(ctx, account)
+			assert.NoError(t, err)
+			assert.Zero(t, wait)
+		}
+	})
+
+	mock.AssertExpectations(t)
+}
@@ -3,6 +3,7 @@ module github.com/shellhub-io/shellhub
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/GehirnInc/crypt v0.0.0-20230320061759-8cc1b52080c5
 	github.com/Masterminds/semver v1.5.0
 	github.com/creack/pty v1.1.18
@@ -28,6 +29,7 @@ require (
 	github.com/stretchr/testify v1.8.2
 	golang.org/x/crypto v0.7.0
 	golang.org/x/sys v0.6.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -81,6 +83,5 @@ require (
 	golang.org/x/time v0.3.0 // indirect
 	golang.org/x/tools v0.6.0 // indirect
 	google.golang.org/protobuf v1.26.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 	gotest.tools/v3 v3.5.0 // indirect
 )
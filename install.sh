@@ -4,6 +4,10 @@ docker_install() {
     KEEPALIVE_INTERVAL_ARG="-e SHELLHUB_KEEPALIVE_INTERVAL=$KEEPALIVE_INTERVAL"
     PREFERRED_HOSTNAME_ARG="-e SHELLHUB_PREFERRED_HOSTNAME=$PREFERRED_HOSTNAME"
     PREFERRED_IDENTITY_ARG="-e SHELLHUB_PREFERRED_IDENTITY=$PREFERRED_IDENTITY"
+    ENROLLMENT_TOKEN_ARG="-e SHELLHUB_ENROLLMENT_TOKEN=$ENROLLMENT_TOKEN"
+    TAGS_ARG="-e SHELLHUB_TAGS=$TAGS"
+    LABELS_ARG="-e SHELLHUB_LABELS=$LABELS"
+    PROXY_ARG="-e SHELLHUB_PROXY=$PROXY"
 
     docker run -d \
        --name=$CONTAINER_NAME \
@@ -25,6 +29,10 @@ docker_install() {
        $KEEPALIVE_INTERVAL_ARG \
        $PREFERRED_HOSTNAME_ARG \
        $PREFERRED_IDENTITY_ARG \
+       $ENROLLMENT_TOKEN_ARG \
+       $TAGS_ARG \
+       $LABELS_ARG \
+       $PROXY_ARG \
        shellhubio/agent:$AGENT_VERSION
 }
 
@@ -70,6 +78,10 @@ bundle_install() {
 
     sed -i "s,__SERVER_ADDRESS__,$SERVER_ADDRESS,g" $TMP_DIR/config.json
     sed -i "s,__TENANT_ID__,$TENANT_ID,g" $TMP_DIR/config.json
+    sed -i "s,__ENROLLMENT_TOKEN__,$ENROLLMENT_TOKEN,g" $TMP_DIR/config.json
+    sed -i "s|__TAGS__|$TAGS|g" $TMP_DIR/config.json
+    sed -i "s|__LABELS__|$LABELS|g" $TMP_DIR/config.json
+    sed -i "s|__PROXY__|$PROXY|g" $TMP_DIR/config.json
     sed -i "s,__ROOT_PATH__,$INSTALL_DIR/rootfs,g" $TMP_DIR/config.json
     sed -i "s,__INSTALL_DIR__,$INSTALL_DIR,g" $TMP_DIR/shellhub-agent.service
 
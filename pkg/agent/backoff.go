@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Limits of the delay between the attempts to reconnect to the server.
+const (
+	reconnectMinDelay = time.Second
+	reconnectMaxDelay = 2 * time.Minute
+)
+
+// backoff computes the delays between consecutive attempts, doubling them from min up to max, with a random jitter,
+// so the devices disconnected at once, like when the server restarts, do not reconnect in lockstep.
+type backoff struct {
+	min     time.Duration
+	max     time.Duration
+	attempt int
+}
+
+func newBackoff(min, max time.Duration) *backoff {
+	return &backoff{min: min, max: max}
+}
+
+// next returns the delay before the next attempt. Half of it is random, so the attempts are still spaced while the
+// ones from different devices spread.
+func (b *backoff) next() time.Duration {
+	delay := b.max
+	if b.attempt < 32 {
+		if d := b.min << b.attempt; d > 0 && d < b.max {
+			delay = d
+		}
+	}
+
+	b.attempt++
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1)) //nolint:gosec
+}
+
+// reset starts the delays from min again, after a successful attempt.
+func (b *backoff) reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrConfigUnknownKey is returned when the configuration file has a key that is not a setting of the agent.
+	ErrConfigUnknownKey = errors.New("unknown configuration key")
+	// ErrConfigMissingKey is returned when a required setting is neither on the environment nor on the file.
+	ErrConfigMissingKey = errors.New("required configuration key missing value")
+	// ErrConfigInvalidValue is returned when a setting's value cannot be parsed as its type.
+	ErrConfigInvalidValue = errors.New("invalid configuration value")
+)
+
+// configPrefix is the prefix of the environment variables that configure the agent.
+const configPrefix = "shellhub"
+
+// configRedacted replaces the secrets of the configuration when it is logged.
+const configRedacted = "REDACTED"
+
+// Redacted returns a copy of the configuration without its secrets, the enrollment token, the single-user password
+// and the proxy's password, so it can be logged.
+func (c *Config) Redacted() *Config {
+	cfg := *c
+
+	if cfg.EnrollmentToken != "" {
+		cfg.EnrollmentToken = configRedacted
+	}
+
+	if cfg.SingleUserPassword != "" {
+		cfg.SingleUserPassword = configRedacted
+	}
+
+	if cfg.Proxy != "" {
+		// A proxy that is not a valid URL may still have the password somewhere, so it is not logged at all.
+		if proxy, err := url.Parse(cfg.Proxy); err == nil {
+			cfg.Proxy = proxy.Redacted()
+		} else {
+			cfg.Proxy = configRedacted
+		}
+	}
+
+	return &cfg
+}
+
+// LoadConfig loads the agent's configuration from the environment variables and, when path is not empty, from the
+// YAML or TOML configuration file on it. The environment variables take precedence over the file, and the settings
+// on neither get their default values.
+//
+// The file is decoded into the configuration directly, keeping the environment untouched, so a file loaded again, like
+// on a reload, replaces all of its previous values, and the processes started by the agent, like the one of an update,
+// do not take them as the user's variables.
+func LoadConfig(path string) (*Config, error) {
+	values := make(map[string]interface{})
+	if path != "" {
+		var err error
+		if values, err = LoadConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := new(Config)
+
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := t.Field(i).Tag.Lookup("envconfig")
+		if !ok {
+			continue
+		}
+
+		// Like the envconfig package, the variables are also read without the prefix, for backward compatibility.
+		value, ok := os.LookupEnv(strings.ToUpper(configPrefix + "_" + key))
+		if !ok {
+			value, ok = os.LookupEnv(strings.ToUpper(key))
+		}
+
+		if !ok {
+			if item, found := values[key]; found {
+				value, ok = formatConfigValue(item), true
+			}
+		}
+
+		if def := t.Field(i).Tag.Get("default"); !ok && def != "" {
+			value, ok = def, true
+		}
+
+		if !ok {
+			if t.Field(i).Tag.Get("required") == "true" {
+				return nil, fmt.Errorf("%w: %s", ErrConfigMissingKey, key)
+			}
+
+			continue
+		}
+
+		if err := setConfigValue(v.Field(i), value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrConfigInvalidValue, key, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// LoadConfigFile reads the settings of the agent's configuration from a YAML or TOML file, chosen by its extension.
+// The keys are the environment variables' names without the prefix, in lower case, like "server_address".
+func LoadConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		err = yaml.Unmarshal(data, &values)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the configuration file %s: %w", path, err)
+	}
+
+	keys := configKeys()
+	for key := range values {
+		if !keys[key] {
+			return nil, fmt.Errorf("%w: %s", ErrConfigUnknownKey, key)
+		}
+	}
+
+	return values, nil
+}
+
+// configKeys returns the keys accepted on the configuration file: the settings of Config.
+func configKeys() map[string]bool {
+	keys := make(map[string]bool)
+
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if key, ok := t.Field(i).Tag.Lookup("envconfig"); ok {
+			keys[key] = true
+		}
+	}
+
+	return keys
+}
+
+// formatConfigValue formats a value from the configuration file as its environment variable, so both are parsed the
+// same way. Lists are joined by comma and maps are joined as "key:value" pairs.
+func formatConfigValue(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		pairs := make([]string, 0, len(v))
+		for key, item := range v {
+			pairs = append(pairs, key+":"+fmt.Sprint(item))
+		}
+
+		sort.Strings(pairs)
+
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setConfigValue parses the value of a setting, in the format of its environment variable, into the field.
+func setConfigValue(field reflect.Value, value string) error {
+	switch field.Kind() { //nolint:exhaustive
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		field.SetBool(b)
+	case reflect.Slice:
+		items := []string{}
+		if strings.TrimSpace(value) != "" {
+			items = strings.Split(value, ",")
+		}
+
+		field.Set(reflect.ValueOf(items))
+	case reflect.Map:
+		pairs := make(map[string]string)
+		if strings.TrimSpace(value) != "" {
+			for _, pair := range strings.Split(value, ",") {
+				kv := strings.SplitN(pair, ":", 2)
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid map item: %q", pair)
+				}
+
+				pairs[kv[0]] = kv[1]
+			}
+		}
+
+		field.Set(reflect.ValueOf(pairs))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+
+	return nil
+}
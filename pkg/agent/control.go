@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultControlSocket is the path of the Unix socket where the agent serves its status and control interface.
+const DefaultControlSocket = "/var/run/shellhub-agent.sock"
+
+// Connection states of the agent's reverse listener, reported by [Status].
+const (
+	StateConnecting   = "connecting"
+	StateConnected    = "connected"
+	StateDisconnected = "disconnected"
+)
+
+// Status is the agent's state reported by the control interface.
+type Status struct {
+	State         string    `json:"state"`
+	ServerAddress string    `json:"server_address"`
+	UID           string    `json:"uid,omitempty"`
+	Name          string    `json:"name,omitempty"`
+	Namespace     string    `json:"namespace,omitempty"`
+	LastPing      time.Time `json:"last_ping,omitempty"`
+	Sessions      []string  `json:"sessions"`
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+// Status reports the connection state of the agent, its device and the active sessions.
+func (a *Agent) Status() *Status {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	status := &Status{
+		State:         a.state,
+		ServerAddress: a.failover.address().String(),
+		LastPing:      a.lastPing,
+		Sessions:      make([]string, 0),
+	}
+
+	if status.State == "" {
+		status.State = StateConnecting
+	}
+
+	if a.lastError != nil {
+		status.LastError = a.lastError.Error()
+	}
+
+	if a.authData != nil {
+		status.UID = a.authData.UID
+		status.Name = a.authData.Name
+		status.Namespace = a.authData.Namespace
+	}
+
+	if a.server != nil {
+		status.Sessions = a.server.SessionIDs()
+	}
+
+	return status
+}
+
+// Reconnect closes the current reverse listener, so the agent opens a fresh tunnel to the server.
+func (a *Agent) Reconnect() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return errors.New("agent is not connected")
+	}
+
+	return a.listener.Close()
+}
+
+// setState records the connection state of the reverse listener and, when not nil, the error that caused it.
+func (a *Agent) setState(state string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.state = state
+	if err != nil {
+		a.lastError = err
+	}
+}
+
+// ServeControl serves the agent's status and control interface on a Unix socket at path, accessible only by the
+// agent's user. It blocks until the socket is closed.
+//
+//	GET  /status    reports the agent's [Status].
+//	POST /reconnect forces a fresh tunnel to the server.
+func (a *Agent) ServeControl(path string) error {
+	// A socket left by a previous agent's process would refuse the new one.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	listener, err := listenControl(path)
+	if err != nil {
+		return err
+	}
+
+	defer listener.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a.Status()) //nolint:errcheck
+	})
+	mux.HandleFunc("/reconnect", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		if err := a.Reconnect(); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+
+			return
+		}
+
+		log.Info("Reconnection requested through the control interface")
+
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return http.Serve(listener, mux) //nolint:gosec
+}
+
+// controlClient creates an HTTP client to the agent's control interface on the Unix socket at path.
+func controlClient(path string) *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+// GetStatus gets the status of the agent running with the control interface on the Unix socket at path.
+func GetStatus(path string) (*Status, error) {
+	res, err := controlClient(path).Get("http://agent/status")
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	status := new(Status)
+	if err := json.NewDecoder(res.Body).Decode(status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// RequestReconnect asks the agent running with the control interface on the Unix socket at path to open a fresh
+// tunnel to the server.
+func RequestReconnect(path string) error {
+	res, err := controlClient(path).Post("http://agent/reconnect", "", nil)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// listenControl listens on the Unix socket at path, accessible only by the agent's user.
+//
+// The socket is created in a directory accessible only by the agent's user, where it is restricted before being moved
+// to path, so it is never exposed with the permissions given by the umask.
+func listenControl(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".shellhub-agent-")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	tmp := filepath.Join(dir, "control.sock")
+
+	listener, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+
+	// The socket is unlinked from path, where it is moved to, instead of from where it was created.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0o600); err != nil {
+		listener.Close()
+
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		listener.Close()
+
+		return nil, err
+	}
+
+	return &controlListener{Listener: listener, path: path}, nil
+}
+
+// controlListener removes the control socket when it is closed.
+type controlListener struct {
+	net.Listener
+	path string
+}
+
+func (l *controlListener) Close() error {
+	defer os.Remove(l.path) //nolint:errcheck
+
+	return l.Listener.Close()
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeControl(t *testing.T) {
+	ag, err := NewAgentWithConfig(&Config{
+		ServerAddress: "http://localhost:80",
+		TenantID:      "00000000-0000-4000-0000-000000000000",
+		PrivateKey:    "./shellhub.key",
+	})
+	assert.NoError(t, err)
+
+	ag.authData = &models.DeviceAuthResponse{UID: "uid", Name: "device", Namespace: "namespace"}
+
+	path := filepath.Join(t.TempDir(), "agent.sock")
+	go ag.ServeControl(path) //nolint:errcheck
+
+	var status *Status
+	assert.Eventually(t, func() bool {
+		status, err = GetStatus(path)
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// The socket is accessible only by the agent's user, and its temporary directory is removed.
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.Equal(t, &Status{
+		State:         StateConnecting,
+		ServerAddress: "http://localhost:80",
+		UID:           "uid",
+		Name:          "device",
+		Namespace:     "namespace",
+		Sessions:      []string{},
+	}, status)
+
+	// The agent cannot reconnect before it opens a tunnel.
+	assert.Error(t, RequestReconnect(path))
+}
@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/client"
+	"github.com/shellhub-io/shellhub/pkg/revdial"
+)
+
+// diagnosticTimeout is the time limit of each network operation of [Agent.Diagnose].
+const diagnosticTimeout = 15 * time.Second
+
+// ErrAgentRunning is returned by the tunnel's stages of [Agent.Diagnose] when an agent is running on the device, as the
+// diagnostic's tunnel would replace the one of the running agent on the server.
+var ErrAgentRunning = errors.New("an agent is running on the device")
+
+// Stages of the connection to the server checked by [Agent.Diagnose].
+const (
+	StageResolve   = "resolve"
+	StageInfo      = "info"
+	StageAuth      = "auth"
+	StageWebsocket = "websocket"
+	StagePing      = "ping"
+)
+
+// Diagnostic is the result of a stage of the connection to the server.
+type Diagnostic struct {
+	Stage       string
+	Description string
+	// Skipped is true when a previous stage has failed.
+	Skipped bool
+	Err     error
+	// Hint is the suggested fix for the failure.
+	Hint string
+}
+
+// Diagnose checks, in turn, each stage of the connection to the server: resolving the server address, reaching its
+// information endpoint, authenticating the device, opening the tunnel's websocket and pinging the server through it.
+// The stages after the first failing one are skipped.
+//
+// The tunnel's stages are refused while an agent answers on the control socket at control, since the server keeps a
+// single tunnel for each device. It is meant to run instead of [Agent.Initialize], since it uses its own client, which
+// does not retry the requests. The private key is not generated when it does not exist. When there are many server
+// addresses, the first one is checked.
+func (a *Agent) Diagnose(control string) []Diagnostic {
+	address := a.failover.address()
+
+	a.cli = client.NewClient(a.clientOptions(address,
+		client.WithRetries(0),
+		client.WithTimeout(diagnosticTimeout),
+	)...)
+	a.authRetries = 0
+
+	var listener *revdial.Listener
+	defer func() {
+		if listener != nil {
+			listener.Close()
+		}
+	}()
+
+	stages := []struct {
+		name        string
+		description string
+		check       func() error
+	}{
+		{
+			name:        StageResolve,
+			description: fmt.Sprintf("resolve the server address %s", address.Hostname()),
+			check: func() error {
+				_, err := net.LookupHost(address.Hostname())
+
+				return err
+			},
+		},
+		{
+			name:        StageInfo,
+			description: "reach the server information endpoint",
+			check: func() error {
+				info, err := a.cli.GetInfo(AgentVersion)
+				if err != nil {
+					return err
+				}
+
+				if info == nil || info.Endpoints.API == "" {
+					return errors.New("unexpected response from the server")
+				}
+
+				a.setServerInfo(info)
+
+				return nil
+			},
+		},
+		{
+			name:        StageAuth,
+			description: "authenticate the device",
+			check: func() error {
+				if _, err := os.Stat(a.config.PrivateKey); err != nil {
+					return err
+				}
+
+				if err := a.readPublicKey(); err != nil {
+					return err
+				}
+
+				if err := a.generateDeviceIdentity(); err != nil {
+					return err
+				}
+
+				if err := a.loadDeviceInfo(); err != nil {
+					return err
+				}
+
+				if err := a.authorize(); err != nil {
+					return err
+				}
+
+				if a.token() == "" {
+					return errors.New("unexpected response from the server")
+				}
+
+				return nil
+			},
+		},
+		{
+			name:        StageWebsocket,
+			description: "open the tunnel's websocket",
+			check: func() error {
+				if status, err := GetStatus(control); err == nil {
+					return fmt.Errorf("%w with the state %s", ErrAgentRunning, status.State)
+				}
+
+				var err error
+				listener, err = a.NewReverseListener()
+
+				return err
+			},
+		},
+		{
+			name:        StagePing,
+			description: "ping the server through the tunnel",
+			check: func() error {
+				ctx, cancel := context.WithTimeout(context.Background(), diagnosticTimeout)
+				defer cancel()
+
+				if err := listener.Ping(ctx); err != nil {
+					return fmt.Errorf("no answer to the ping from the server: %w", err)
+				}
+
+				return nil
+			},
+		},
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(stages))
+
+	failed := false
+	for _, stage := range stages {
+		diagnostic := Diagnostic{Stage: stage.name, Description: stage.description}
+
+		if failed {
+			diagnostic.Skipped = true
+		} else if err := stage.check(); err != nil {
+			diagnostic.Err = err
+			diagnostic.Hint = a.hint(stage.name, err)
+
+			failed = true
+		}
+
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	return diagnostics
+}
+
+// hint suggests a fix to the failure of a stage, checking the most common causes first.
+func (a *Agent) hint(stage string, err error) string {
+	var dnsErr *net.DNSError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateErr x509.CertificateInvalidError
+	var netErr net.Error
+
+	address := a.failover.address().String()
+
+	switch {
+	case errors.Is(err, ErrAgentRunning):
+		return "Stop the running agent to diagnose the tunnel, or check its connection with the status command."
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("Check the DNS servers of the device, at /etc/resolv.conf, and the server address %s.", address)
+	case errors.As(err, &unknownAuthorityErr), errors.As(err, &hostnameErr), errors.As(err, &certificateErr):
+		return "The server's TLS certificate is not trusted. A proxy or firewall may be intercepting TLS; allow the server address on it or add its certificate authority to the device."
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Sprintf("The server refused the connection. Check the port of the server address %s and if the server is running.", address)
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "The connection timed out. Check if a firewall blocks the outbound connections to the server, or if a proxy is required."
+	case errors.Is(err, os.ErrNotExist) && stage == StageAuth:
+		return fmt.Sprintf("The private key %s does not exist. Start the agent once to generate it.", a.config.PrivateKey)
+	case strings.Contains(err.Error(), http.StatusText(http.StatusProxyAuthRequired)):
+		return "The proxy rejected the connection. Check the user and password of the proxy setting."
+	case errors.Is(err, client.ErrUnauthorized):
+		return "The server rejected the device. Check the tenant ID and if the device's key was revoked."
+	}
+
+	switch stage {
+	case StageInfo:
+		return "Check if the server address points to a ShellHub server, and not to a proxy or captive portal."
+	case StageAuth:
+		return "Check the tenant ID and if the namespace accepts new devices."
+	case StagePing:
+		if errors.Is(err, context.DeadlineExceeded) {
+			return "The tunnel was opened, but the server did not answer. A proxy may be buffering the websocket, or the server may be older than the agent."
+		}
+
+		fallthrough
+	case StageWebsocket:
+		if strings.HasPrefix(address, "http://") {
+			return "A proxy may be blocking websocket connections. Try an https:// server address."
+		}
+
+		return "A proxy or firewall may be blocking websocket connections to the server."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,122 @@
+package agent
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/agent/pkg/keygen"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnose(t *testing.T) {
+	// A listener closed right away gives an address that refuses the connections.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	closed.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case "/api/devices/auth/challenge":
+			json.NewEncoder(w).Encode(&models.DeviceChallenge{Nonce: "nonce"}) //nolint:errcheck
+		case "/api/devices/auth":
+			json.NewEncoder(w).Encode(&models.DeviceAuthResponse{UID: "uid", Token: "token"}) //nolint:errcheck
+		default:
+			json.NewEncoder(w).Encode(&models.Info{Endpoints: models.Endpoints{API: "localhost:80", SSH: "localhost:22"}}) //nolint:errcheck
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		description string
+		address     string
+		// running starts an agent's control interface, as an agent running on the device.
+		running bool
+		failed  string
+		hint    string
+	}{
+		{
+			description: "fails to reach the server when it refuses the connection",
+			address:     "http://" + closed.Addr().String(),
+			failed:      StageInfo,
+			hint:        "The server refused the connection. Check the port of the server address http://" + closed.Addr().String() + " and if the server is running.",
+		},
+		{
+			description: "fails to authenticate the device when the private key does not exist",
+			address:     server.URL,
+			failed:      StageAuth,
+		},
+		{
+			description: "refuses to open the tunnel while an agent is running",
+			address:     server.URL,
+			running:     true,
+			failed:      StageWebsocket,
+			hint:        "Stop the running agent to diagnose the tunnel, or check its connection with the status command.",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			config := &Config{
+				ServerAddress: tc.address,
+				TenantID:      "00000000-0000-4000-0000-000000000000",
+				PrivateKey:    filepath.Join(t.TempDir(), "shellhub.key"),
+			}
+
+			ag, err := NewAgentWithConfig(config)
+			assert.NoError(t, err)
+
+			// The test's temporary directory is not used, as its long path does not fit the address of a Unix socket.
+			dir, err := os.MkdirTemp("", "doctor")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			control := filepath.Join(dir, "control.sock")
+			if tc.running {
+				assert.NoError(t, keygen.GeneratePrivateKey(config.PrivateKey))
+
+				running, err := NewAgentWithConfig(config)
+				assert.NoError(t, err)
+
+				go running.ServeControl(control) //nolint:errcheck
+
+				assert.Eventually(t, func() bool {
+					_, err := GetStatus(control)
+
+					return err == nil
+				}, time.Second, 10*time.Millisecond)
+			}
+
+			diagnostics := ag.Diagnose(control)
+			assert.Len(t, diagnostics, 5)
+
+			failed := false
+			for _, diagnostic := range diagnostics {
+				switch {
+				case diagnostic.Stage == tc.failed:
+					failed = true
+
+					assert.Error(t, diagnostic.Err)
+					if tc.running {
+						assert.ErrorIs(t, diagnostic.Err, ErrAgentRunning)
+					}
+					if tc.hint != "" {
+						assert.Equal(t, tc.hint, diagnostic.Hint)
+					}
+				case failed:
+					assert.True(t, diagnostic.Skipped)
+				default:
+					assert.NoError(t, diagnostic.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/client"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/pkg/revdial"
+)
+
+const (
+	// failoverCooldown is how long a server address that failed is avoided, after which it is preferred again by its
+	// order.
+	failoverCooldown = 5 * time.Minute
+	// failoverRetries is how many times a failed request is retried, when there are other server addresses to fail
+	// over to, instead of retrying it indefinitely.
+	failoverRetries = 3
+	// failoverTimeout is the time limit of each request, when there are other server addresses to fail over to.
+	failoverTimeout = 30 * time.Second
+)
+
+// endpoint is a server address the agent can connect to, with its client and health.
+type endpoint struct {
+	address  *url.URL
+	client   client.Client
+	failures int
+	failedAt time.Time
+}
+
+// healthy reports if the endpoint has not failed in the last cooldown.
+func (e *endpoint) healthy(now time.Time) bool {
+	return e.failures == 0 || now.Sub(e.failedAt) > failoverCooldown
+}
+
+// failover is a client to the server that sends the requests to one of an ordered list of server addresses, failing
+// over to the next healthy one when the agent cannot connect to it.
+//
+// It is shared with the SSH server, so the sessions are authenticated by the server the agent is connected to.
+type failover struct {
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	current   *endpoint
+}
+
+var _ client.Client = (*failover)(nil)
+
+// newFailover creates a failover client to the addresses, in order of preference, created with the options returned
+// by opts for each address.
+func newFailover(addresses []*url.URL, opts func(address *url.URL) []client.Opt) *failover {
+	endpoints := make([]*endpoint, 0, len(addresses))
+	for _, address := range addresses {
+		options := opts(address)
+		if len(addresses) > 1 {
+			// The requests to an unreachable server are given up, so the agent can fail over to the next one.
+			options = append(options, client.WithRetries(failoverRetries), client.WithTimeout(failoverTimeout))
+		}
+
+		endpoints = append(endpoints, &endpoint{address: address, client: client.NewClient(options...)})
+	}
+
+	return &failover{endpoints: endpoints, current: endpoints[0]}
+}
+
+// address returns the server address the agent is connected to.
+func (f *failover) address() *url.URL {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.current.address
+}
+
+func (f *failover) client() client.Client {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.current.client
+}
+
+// succeed records the server address the agent is connected to as healthy.
+func (f *failover) succeed() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.current.failures = 0
+}
+
+// fail records a failure of the server address the agent is connected to and selects the next one: the first healthy
+// address, in order, or the one with the fewest failures when none is. It reports if the address has changed.
+func (f *failover) fail() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	previous := f.current
+	previous.failures++
+	previous.failedAt = now
+
+	f.current = f.endpoints[0]
+	for _, e := range f.endpoints {
+		if e.healthy(now) {
+			f.current = e
+
+			break
+		}
+
+		if e.failures < f.current.failures {
+			f.current = e
+		}
+	}
+
+	return f.current != previous
+}
+
+// failback moves back to a server address preferred over the current one, whose cooldown has passed, when probe
+// succeeds on it. It reports if the address has changed.
+func (f *failover) failback(probe func(cli client.Client) error) bool {
+	f.mu.RLock()
+	preferred := make([]*endpoint, 0)
+	for _, e := range f.endpoints {
+		if e == f.current {
+			break
+		}
+
+		if e.healthy(time.Now()) {
+			preferred = append(preferred, e)
+		}
+	}
+	f.mu.RUnlock()
+
+	for _, e := range preferred {
+		err := probe(e.client)
+
+		f.mu.Lock()
+		if err == nil {
+			e.failures = 0
+			f.current = e
+		} else {
+			e.failures++
+			e.failedAt = time.Now()
+		}
+		f.mu.Unlock()
+
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// probe checks if the server answers on the client.
+func probe(cli client.Client) error {
+	info, err := cli.GetInfo(AgentVersion)
+	if err != nil {
+		return err
+	}
+
+	if info == nil {
+		return errors.New("unexpected response from the server")
+	}
+
+	return nil
+}
+
+func (f *failover) ListDevices() ([]models.Device, error) {
+	return f.client().ListDevices()
+}
+
+func (f *failover) GetDevice(uid string) (*models.Device, error) {
+	return f.client().GetDevice(uid)
+}
+
+func (f *failover) GetInfo(agentVersion string) (*models.Info, error) {
+	return f.client().GetInfo(agentVersion)
+}
+
+func (f *failover) Endpoints() (*models.Endpoints, error) {
+	return f.client().Endpoints()
+}
+
+func (f *failover) AuthDeviceChallenge() (*models.DeviceChallenge, error) {
+	return f.client().AuthDeviceChallenge()
+}
+
+func (f *failover) AuthDevice(req *models.DeviceAuthRequest) (*models.DeviceAuthResponse, error) {
+	return f.client().AuthDevice(req)
+}
+
+func (f *failover) AuthDeviceRotateKey(req *models.DeviceKeyRotation) error {
+	return f.client().AuthDeviceRotateKey(req)
+}
+
+func (f *failover) AuthDeviceRekey(req *models.DeviceRekey) error {
+	return f.client().AuthDeviceRekey(req)
+}
+
+func (f *failover) NewReverseListener(token string) (*revdial.Listener, error) {
+	return f.client().NewReverseListener(token)
+}
+
+func (f *failover) AuthPublicKey(req *models.PublicKeyAuthRequest, token string) (*models.PublicKeyAuthResponse, error) {
+	return f.client().AuthPublicKey(req, token)
+}
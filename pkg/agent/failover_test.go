@@ -0,0 +1,115 @@
+package agent
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/shellhub-io/shellhub/pkg/api/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailover(t *testing.T) {
+	addresses := make([]*url.URL, 0)
+	for _, address := range []string{"http://primary", "http://secondary", "http://tertiary"} {
+		u, err := url.Parse(address)
+		assert.NoError(t, err)
+
+		addresses = append(addresses, u)
+	}
+
+	cases := []struct {
+		description string
+		run         func(f *failover) bool
+		changed     bool
+		expected    string
+	}{
+		{
+			description: "keeps the first address while it is healthy",
+			run: func(f *failover) bool {
+				f.succeed()
+
+				return false
+			},
+			changed:  false,
+			expected: "http://primary",
+		},
+		{
+			description: "fails over to the next healthy address",
+			run: func(f *failover) bool {
+				return f.fail()
+			},
+			changed:  true,
+			expected: "http://secondary",
+		},
+		{
+			description: "fails over to the address with the fewest failures when none is healthy",
+			run: func(f *failover) bool {
+				f.fail()
+				f.fail()
+				f.fail()
+
+				return f.fail()
+			},
+			changed:  true,
+			expected: "http://secondary",
+		},
+		{
+			description: "does not fail back before the cooldown",
+			run: func(f *failover) bool {
+				f.fail()
+
+				return f.failback(func(client.Client) error { return nil })
+			},
+			changed:  false,
+			expected: "http://secondary",
+		},
+		{
+			description: "fails back to the preferred address after the cooldown when it answers",
+			run: func(f *failover) bool {
+				f.fail()
+				f.endpoints[0].failedAt = time.Now().Add(-failoverCooldown - time.Second)
+
+				return f.failback(func(client.Client) error { return nil })
+			},
+			changed:  true,
+			expected: "http://primary",
+		},
+		{
+			description: "does not fail back to the preferred address when it does not answer",
+			run: func(f *failover) bool {
+				f.fail()
+				f.endpoints[0].failedAt = time.Now().Add(-failoverCooldown - time.Second)
+
+				return f.failback(func(client.Client) error { return errors.New("error") })
+			},
+			changed:  false,
+			expected: "http://secondary",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			f := newFailover(addresses, func(address *url.URL) []client.Opt {
+				return []client.Opt{client.WithURL(address)}
+			})
+
+			assert.Equal(t, tc.changed, tc.run(f))
+			assert.Equal(t, tc.expected, f.address().String())
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := newBackoff(time.Second, 8*time.Second)
+
+	for _, limit := range []time.Duration{1, 2, 4, 8, 8} {
+		delay := b.next()
+		assert.GreaterOrEqual(t, delay, limit*time.Second/2)
+		assert.LessOrEqual(t, delay, limit*time.Second)
+	}
+
+	b.reset()
+	assert.LessOrEqual(t, b.next(), time.Second)
+}
@@ -49,7 +49,7 @@ func GeneratePrivateKey(filename string) error {
 	return f.Sync()
 }
 
-func ReadPublicKey(filename string) (*rsa.PublicKey, error) {
+func ReadPrivateKey(filename string) (*rsa.PrivateKey, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
@@ -60,7 +60,11 @@ func ReadPublicKey(filename string) (*rsa.PublicKey, error) {
 		return nil, ErrPemDecode
 	}
 
-	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+func ReadPublicKey(filename string) (*rsa.PublicKey, error) {
+	key, err := ReadPrivateKey(filename)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,321 @@
+package selfupdater
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Masterminds/semver"
+	log "github.com/sirupsen/logrus"
+)
+
+// PublicKey is the base64 encoded Ed25519 public key which verifies the signatures of the agent's manifests. It is
+// injected at build time, like `go build -ldflags "-X
+// github.com/shellhub-io/shellhub/pkg/agent/pkg/selfupdater.PublicKey=..."`, and, when empty, the native update is
+// disabled.
+var PublicKey string
+
+// ReleasesURL is where the agent's binaries, and their signed manifests, of each version are downloaded from.
+var ReleasesURL = "https://github.com/shellhub-io/shellhub/releases/download"
+
+var (
+	ErrUpdateNoPublicKey = errors.New("the agent was built without the public key to verify the updates")
+	ErrUpdateSignature   = errors.New("invalid signature of the agent's manifest")
+	ErrUpdateManifest    = errors.New("the agent's manifest does not match the update")
+	ErrUpdateChecksum    = errors.New("the agent's binary does not match its manifest")
+	ErrUpdateRolledBack  = errors.New("the update to this version was rolled back")
+)
+
+// updateGracePeriod is how long the updated agent has to connect to the server before the update is rolled back.
+var updateGracePeriod = 5 * time.Minute
+
+const (
+	// updateMaxStarts is how many times the updated agent may start, without connecting to the server, before the
+	// update is rolled back, like when it crashes and is restarted by its service manager.
+	updateMaxStarts = 3
+	// updateTimeout is the time limit to download the binary and its signature.
+	updateTimeout = 10 * time.Minute
+)
+
+// execve replaces the running process by the agent's binary.
+var execve = syscall.Exec
+
+// pendingUpdate is the update waiting for the updated agent to connect to the server, recorded beside its binary.
+type pendingUpdate struct {
+	Version string `json:"version"`
+	Starts  int    `json:"starts"`
+}
+
+// manifest describes the agent's binary of a release, published beside it as `shellhub-agent-<platform>-<arch>.manifest`
+// with its detached signature. The manifest is signed, instead of the binary, so the signature also binds the binary to
+// its version, platform and architecture, refusing the one of another release, like an older one, or architecture.
+type manifest struct {
+	Version  string `json:"version"`
+	Platform string `json:"platform"`
+	Arch     string `json:"arch"`
+	// SHA256 is the hex encoded SHA256 checksum of the binary.
+	SHA256 string `json:"sha256"`
+}
+
+// nativeUpdater updates the agent's binary in place, downloading the one built for its platform and architecture.
+//
+// The downloaded binary replaces the running one only when it matches the release's manifest, whose detached signature
+// is valid, keeping the previous one to roll back to when the updated agent does not connect to the server in the grace period.
+type nativeUpdater struct {
+	version  string
+	platform string
+	// executable is the path of the running agent's binary.
+	executable string
+
+	mu        sync.Mutex
+	confirmed chan struct{}
+}
+
+func newNativeUpdater(version, platform string) (*nativeUpdater, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	executable, err = filepath.EvalSymlinks(executable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &nativeUpdater{version: version, platform: platform, executable: executable}, nil
+}
+
+func (n *nativeUpdater) CurrentVersion() (*semver.Version, error) {
+	return semver.NewVersion(n.version)
+}
+
+// ApplyUpdate downloads the binary of the version v, verifies it against its signed manifest, replaces the running one and executes
+// it. It only returns on failure.
+func (n *nativeUpdater) ApplyUpdate(v *semver.Version) error {
+	if PublicKey == "" {
+		return ErrUpdateNoPublicKey
+	}
+
+	version := "v" + v.String()
+
+	if data, err := os.ReadFile(n.executable + ".rollback"); err == nil && strings.TrimSpace(string(data)) == version {
+		return fmt.Errorf("%w: %s", ErrUpdateRolledBack, version)
+	}
+
+	filename := n.executable + ".new"
+	defer os.Remove(filename) //nolint:errcheck
+
+	if err := n.download(version, filename); err != nil {
+		return err
+	}
+
+	// The previous binary is kept to roll back to. It is linked, instead of moved, so the agent's binary is replaced
+	// at once, never missing.
+	if err := os.Remove(n.executable + ".old"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Link(n.executable, n.executable+".old"); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(&pendingUpdate{Version: version})
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(n.executable+".update", data, 0o600); err != nil {
+		return err
+	}
+
+	if err := os.Rename(filename, n.executable); err != nil {
+		os.Remove(n.executable + ".update") //nolint:errcheck
+
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"version":      n.version,
+		"next_version": version,
+	}).Info("Executing the updated agent")
+
+	return execve(n.executable, os.Args, os.Environ())
+}
+
+// download downloads the binary of the version to filename, verifying it against the release's manifest, which is
+// verified by its signature.
+func (n *nativeUpdater) download(version, filename string) error {
+	key, err := base64.StdEncoding.DecodeString(PublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return errors.New("invalid public key to verify the updates")
+	}
+
+	url := fmt.Sprintf("%s/%s/shellhub-agent-%s-%s", strings.TrimSuffix(ReleasesURL, "/"), version, n.platform, runtime.GOARCH)
+
+	data, err := get(url + ".manifest")
+	if err != nil {
+		return err
+	}
+
+	signature, err := get(url + ".manifest.sig")
+	if err != nil {
+		return err
+	}
+
+	signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(ed25519.PublicKey(key), data, signature) {
+		return ErrUpdateSignature
+	}
+
+	release := new(manifest)
+	if err := json.Unmarshal(data, release); err != nil {
+		return fmt.Errorf("%w: %w", ErrUpdateManifest, err)
+	}
+
+	if release.Version != version || release.Platform != n.platform || release.Arch != runtime.GOARCH {
+		return fmt.Errorf("%w: %s %s/%s", ErrUpdateManifest, release.Version, release.Platform, release.Arch)
+	}
+
+	binary, err := get(url)
+	if err != nil {
+		return err
+	}
+
+	if sum := sha256.Sum256(binary); !strings.EqualFold(hex.EncodeToString(sum[:]), release.SHA256) {
+		return ErrUpdateChecksum
+	}
+
+	return os.WriteFile(filename, binary, 0o755) //nolint:gosec
+}
+
+func get(url string) ([]byte, error) {
+	res, err := (&http.Client{Timeout: updateTimeout}).Get(url) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: unexpected status code %d", url, res.StatusCode)
+	}
+
+	return io.ReadAll(res.Body)
+}
+
+// CompleteUpdate checks the pending update, when there is one, rolling it back when the updated agent has started too
+// many times or does not confirm it in the grace period.
+func (n *nativeUpdater) CompleteUpdate() error {
+	data, err := os.ReadFile(n.executable + ".update")
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// A corrupt record does not tell the version to roll back, so it is discarded, keeping the running binary.
+	pending := new(pendingUpdate)
+	if err := json.Unmarshal(data, pending); err != nil || pending.Version == "" {
+		log.WithError(err).Warn("Discarding the corrupt record of the pending update")
+
+		return os.Remove(n.executable + ".update")
+	}
+
+	pending.Starts++
+	if pending.Starts > updateMaxStarts {
+		return n.rollback(pending.Version)
+	}
+
+	if data, err = json.Marshal(pending); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(n.executable+".update", data, 0o600); err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	n.confirmed = make(chan struct{})
+	confirmed := n.confirmed
+	n.mu.Unlock()
+
+	grace := time.After(updateGracePeriod)
+
+	go func() {
+		select {
+		case <-confirmed:
+		case <-grace:
+			// The update may be confirmed while the grace period expires, so it is checked again under the lock, which
+			// is kept until the rollback, only returning on failure, to not be confirmed during it.
+			n.mu.Lock()
+			defer n.mu.Unlock()
+
+			if n.confirmed != confirmed {
+				return
+			}
+
+			if err := n.rollback(pending.Version); err != nil {
+				log.WithError(err).WithFields(log.Fields{
+					"version": pending.Version,
+				}).Error("Failed to roll back the update")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ConfirmUpdate confirms the pending update, when there is one, removing the previous binary.
+func (n *nativeUpdater) ConfirmUpdate() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.confirmed == nil {
+		return nil
+	}
+
+	close(n.confirmed)
+	n.confirmed = nil
+
+	if err := os.Remove(n.executable + ".update"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.Remove(n.executable + ".old"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// rollback restores the previous binary and executes it, recording the version rolled back so it is not applied
+// again. It only returns on failure.
+func (n *nativeUpdater) rollback(version string) error {
+	log.WithFields(log.Fields{
+		"version": version,
+	}).Warn("Rolling back the update")
+
+	if err := os.Rename(n.executable+".old", n.executable); err != nil {
+		return err
+	}
+
+	os.Remove(n.executable + ".update")                                 //nolint:errcheck
+	os.WriteFile(n.executable+".rollback", []byte(version+"\n"), 0o600) //nolint:errcheck,gosec
+
+	return execve(n.executable, os.Args, os.Environ())
+}
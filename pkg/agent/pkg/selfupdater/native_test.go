@@ -0,0 +1,182 @@
+package selfupdater
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNativeUpdater(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	binary := []byte("new binary")
+
+	// sign creates the manifest of the binary, signed by key.
+	sign := func(key ed25519.PrivateKey, release manifest) [2][]byte {
+		data, err := json.Marshal(&release)
+		assert.NoError(t, err)
+
+		return [2][]byte{data, []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))}
+	}
+
+	sum := sha256.Sum256(binary)
+	checksum := hex.EncodeToString(sum[:])
+
+	manifests := map[string][2][]byte{
+		"v1.1.0": sign(privateKey, manifest{Version: "v1.1.0", Platform: "native", Arch: runtime.GOARCH, SHA256: checksum}),
+		"v1.2.0": sign(otherKey, manifest{Version: "v1.2.0", Platform: "native", Arch: runtime.GOARCH, SHA256: checksum}),
+		// The manifest of another release, like an older one, served as the one of this version.
+		"v1.3.0": sign(privateKey, manifest{Version: "v1.1.0", Platform: "native", Arch: runtime.GOARCH, SHA256: checksum}),
+		"v1.4.0": sign(privateKey, manifest{Version: "v1.4.0", Platform: "native", Arch: runtime.GOARCH, SHA256: "00"}),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for version, signed := range manifests {
+			name := fmt.Sprintf("/%s/shellhub-agent-native-%s", version, runtime.GOARCH)
+
+			switch r.URL.Path {
+			case name:
+				w.Write(binary) //nolint:errcheck
+
+				return
+			case name + ".manifest":
+				w.Write(signed[0]) //nolint:errcheck
+
+				return
+			case name + ".manifest.sig":
+				w.Write(signed[1]) //nolint:errcheck
+
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	ReleasesURL = server.URL
+	PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+
+	executed := 0
+	execve = func(string, []string, []string) error {
+		executed++
+
+		return nil
+	}
+
+	newUpdater := func(t *testing.T) *nativeUpdater {
+		executable := filepath.Join(t.TempDir(), "agent")
+		assert.NoError(t, os.WriteFile(executable, []byte("old binary"), 0o600))
+
+		executed = 0
+
+		return &nativeUpdater{version: "v1.0.0", platform: "native", executable: executable}
+	}
+
+	t.Run("replaces the binary when its signature is valid", func(t *testing.T) {
+		n := newUpdater(t)
+
+		assert.NoError(t, n.ApplyUpdate(semver.MustParse("v1.1.0")))
+		assert.Equal(t, 1, executed)
+		assert.FileExists(t, n.executable+".update")
+
+		data, _ := os.ReadFile(n.executable)
+		assert.Equal(t, binary, data)
+
+		assert.NoError(t, n.CompleteUpdate())
+		assert.NoError(t, n.ConfirmUpdate())
+		assert.NoFileExists(t, n.executable+".update")
+		assert.NoFileExists(t, n.executable+".old")
+	})
+
+	t.Run("keeps the binary when it cannot be verified", func(t *testing.T) {
+		cases := map[string]error{
+			"v1.2.0": ErrUpdateSignature,
+			"v1.3.0": ErrUpdateManifest,
+			"v1.4.0": ErrUpdateChecksum,
+		}
+
+		for version, expected := range cases {
+			n := newUpdater(t)
+
+			assert.ErrorIs(t, n.ApplyUpdate(semver.MustParse(version)), expected)
+			assert.Equal(t, 0, executed)
+			assert.NoFileExists(t, n.executable+".new")
+
+			data, _ := os.ReadFile(n.executable)
+			assert.Equal(t, []byte("old binary"), data)
+		}
+	})
+
+	t.Run("rolls back the update when the agent starts too many times", func(t *testing.T) {
+		n := newUpdater(t)
+
+		assert.NoError(t, n.ApplyUpdate(semver.MustParse("v1.1.0")))
+
+		data, err := json.Marshal(&pendingUpdate{Version: "v1.1.0", Starts: updateMaxStarts})
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(n.executable+".update", data, 0o600))
+
+		assert.NoError(t, n.CompleteUpdate())
+		assert.Equal(t, 2, executed)
+		assert.NoFileExists(t, n.executable+".update")
+
+		data, _ = os.ReadFile(n.executable)
+		assert.Equal(t, []byte("old binary"), data)
+
+		assert.ErrorIs(t, n.ApplyUpdate(semver.MustParse("v1.1.0")), ErrUpdateRolledBack)
+	})
+
+	t.Run("discards a corrupt record of the pending update", func(t *testing.T) {
+		n := newUpdater(t)
+
+		assert.NoError(t, n.ApplyUpdate(semver.MustParse("v1.1.0")))
+		assert.NoError(t, os.WriteFile(n.executable+".update", []byte("corrupt"), 0o600))
+
+		assert.NoError(t, n.CompleteUpdate())
+		assert.Equal(t, 1, executed)
+		assert.NoFileExists(t, n.executable+".update")
+		assert.NoFileExists(t, n.executable+".rollback")
+
+		data, _ := os.ReadFile(n.executable)
+		assert.Equal(t, binary, data)
+	})
+
+	t.Run("keeps the update confirmed when the grace period expires", func(t *testing.T) {
+		n := newUpdater(t)
+
+		gracePeriod := updateGracePeriod
+		updateGracePeriod = 10 * time.Millisecond
+		defer func() { updateGracePeriod = gracePeriod }()
+
+		assert.NoError(t, n.ApplyUpdate(semver.MustParse("v1.1.0")))
+		assert.NoError(t, n.CompleteUpdate())
+		assert.NoError(t, n.ConfirmUpdate())
+
+		time.Sleep(10 * updateGracePeriod)
+
+		assert.Equal(t, 1, executed)
+		assert.NoFileExists(t, n.executable+".rollback")
+
+		data, _ := os.ReadFile(n.executable)
+		assert.Equal(t, binary, data)
+	})
+}
@@ -137,6 +137,11 @@ func (d *dockerUpdater) CompleteUpdate() error {
 	return nil
 }
 
+// ConfirmUpdate does nothing, since the previous container is removed when the update is completed.
+func (d *dockerUpdater) ConfirmUpdate() error {
+	return nil
+}
+
 func (d *dockerUpdater) getContainer(id string) (*dockerContainer, error) {
 	ctx := context.Background()
 
@@ -219,10 +224,10 @@ func (d *dockerUpdater) updateContainer(container *dockerContainer, image, name
 	return d.getContainer(clone.ID)
 }
 
-func NewUpdater(version string) (Updater, error) {
-	// ensure we are running inside a docker container, otherwise returns a dummy updater implementation
+func NewUpdater(version, platform string) (Updater, error) {
+	// ensure we are running inside a docker container, otherwise updates the agent's binary
 	if _, err := os.Stat("/.dockerenv"); os.IsNotExist(err) {
-		return &nativeUpdater{version}, nil
+		return newNativeUpdater(version, platform)
 	}
 
 	api, err := client.NewClientWithOpts(client.FromEnv)
@@ -3,6 +3,7 @@
 
 package selfupdater
 
-func NewUpdater(version string) (Updater, error) {
-	return &nativeUpdater{version}, nil
+// NewUpdater creates an updater of the agent's binary, built for the platform, whose version is version.
+func NewUpdater(version, platform string) (Updater, error) {
+	return newNativeUpdater(version, platform)
 }
@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/shellhub-io/shellhub/pkg/api/client"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newProxy starts an HTTP proxy, authenticated by user and password, which tunnels the CONNECT requests and forwards
+// the other ones. It counts the requests it accepted.
+func newProxy(t *testing.T, user, password string, accepted *int32) *httptest.Server {
+	t.Helper()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &http.Request{Header: http.Header{"Authorization": r.Header["Proxy-Authorization"]}}
+		if u, p, ok := req.BasicAuth(); !ok || u != user || p != password {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+
+			return
+		}
+
+		atomic.AddInt32(accepted, 1)
+
+		if r.Method != http.MethodConnect {
+			r.RequestURI = ""
+			r.Header.Del("Proxy-Authorization")
+
+			res, err := (&http.Transport{}).RoundTrip(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+
+				return
+			}
+
+			defer res.Body.Close()
+
+			for key, values := range res.Header {
+				w.Header()[key] = values
+			}
+
+			w.WriteHeader(res.StatusCode)
+			io.Copy(w, res.Body) //nolint:errcheck
+
+			return
+		}
+
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			defer upstream.Close()
+
+			io.Copy(upstream, conn) //nolint:errcheck
+		}()
+
+		go io.Copy(conn, upstream) //nolint:errcheck
+	}))
+
+	t.Cleanup(proxy.Close)
+
+	return proxy
+}
+
+func TestProxy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&models.Info{Endpoints: models.Endpoints{API: "localhost:80", SSH: "localhost:22"}}) //nolint:errcheck
+	})
+	mux.HandleFunc("/ssh/connection", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		conn.Close()
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cases := []struct {
+		description string
+		user        string
+		password    string
+		expected    func(t *testing.T, accepted int32, info *models.Info, infoErr, websocketErr error)
+	}{
+		{
+			description: "connects through the proxy with its credentials",
+			user:        "user",
+			password:    "password",
+			expected: func(t *testing.T, accepted int32, info *models.Info, infoErr, websocketErr error) {
+				assert.NoError(t, infoErr)
+				assert.NotNil(t, info)
+				assert.NoError(t, websocketErr)
+				assert.Equal(t, int32(2), accepted)
+			},
+		},
+		{
+			description: "fails to connect through the proxy with wrong credentials",
+			user:        "user",
+			password:    "wrong",
+			expected: func(t *testing.T, accepted int32, info *models.Info, infoErr, websocketErr error) {
+				// The proxy's refusal is a response to the request, not an error.
+				assert.NoError(t, infoErr)
+				assert.Nil(t, info)
+				assert.Error(t, websocketErr)
+				assert.Equal(t, int32(0), accepted)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			var accepted int32
+			proxy := newProxy(t, "user", "password", &accepted)
+
+			proxyURL, err := url.Parse(proxy.URL)
+			assert.NoError(t, err)
+			proxyURL.User = url.UserPassword(tc.user, tc.password)
+
+			ag, err := NewAgentWithConfig(&Config{
+				ServerAddress: server.URL,
+				TenantID:      "00000000-0000-4000-0000-000000000000",
+				PrivateKey:    filepath.Join(t.TempDir(), "shellhub.key"),
+				Proxy:         proxyURL.String(),
+			})
+			assert.NoError(t, err)
+
+			cli := client.NewClient(ag.clientOptions(ag.failover.address(), client.WithRetries(0))...)
+
+			info, infoErr := cli.GetInfo(AgentVersion)
+
+			listener, websocketErr := cli.NewReverseListener("token")
+			if listener != nil {
+				listener.Close()
+			}
+
+			tc.expected(t, atomic.LoadInt32(&accepted), info, infoErr, websocketErr)
+		})
+	}
+}
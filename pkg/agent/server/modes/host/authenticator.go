@@ -23,8 +23,8 @@ var _ modes.Authenticator = (*Authenticator)(nil)
 type Authenticator struct {
 	// api is a client to communicate with the ShellHub's API.
 	api client.Client
-	// authData is the authentication data received from the API to authenticate the device.
-	authData *models.DeviceAuthResponse
+	// token returns the device's token received from the API, which the agent renews.
+	token func() string
 	// singleUserPassword is the password of the single user.
 	// When it is empty, it means that the single user is disabled.
 	singleUserPassword string
@@ -37,16 +37,15 @@ type Authenticator struct {
 }
 
 // NewAuthenticator creates a new instance of Authenticator for the host mode.
-// It receives the api client to perform requests to the ShellHub's API, the authentication data received by the agent
-// when started the communication between it and the agent, the singleUserPassword, what indicates is is running at
-// this mode and the deviceName.
+// It receives the api client to perform requests to the ShellHub's API, a function returning the device's token
+// received by the agent, the singleUserPassword, what indicates is is running at this mode and the deviceName.
 //
 // The deviceName is a pointer to a string because when the server is created, we don't know the device name yet, that
 // is set later.
-func NewAuthenticator(api client.Client, authData *models.DeviceAuthResponse, singleUserPassword string, deviceName *string) *Authenticator {
+func NewAuthenticator(api client.Client, token func() string, singleUserPassword string, deviceName *string) *Authenticator {
 	return &Authenticator{
 		api:                api,
-		authData:           authData,
+		token:              token,
 		singleUserPassword: singleUserPassword,
 		deviceName:         deviceName,
 		osauth:             new(osauth.OSAuth),
@@ -101,7 +100,7 @@ func (a *Authenticator) PublicKey(ctx gliderssh.Context, _ string, key gliderssh
 	res, err := a.api.AuthPublicKey(&models.PublicKeyAuthRequest{
 		Fingerprint: gossh.FingerprintLegacyMD5(key),
 		Data:        string(sigBytes),
-	}, a.authData.Token)
+	}, a.token())
 	if err != nil {
 		return false
 	}
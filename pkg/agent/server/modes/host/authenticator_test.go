@@ -58,9 +58,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
@@ -80,9 +78,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
@@ -104,9 +100,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
@@ -128,9 +122,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
@@ -152,9 +144,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
@@ -176,9 +166,7 @@ func TestPublicKey(t *testing.T) {
 				user: "test",
 			},
 			authenticator: &Authenticator{
-				authData: &models.DeviceAuthResponse{
-					Token: "token",
-				},
+				token:              func() string { return "token" },
 				singleUserPassword: "",
 				deviceName:         stringToRef("device"),
 				api:                new(clientMocks.Client),
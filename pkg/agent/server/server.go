@@ -10,7 +10,6 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/agent/server/modes"
 	"github.com/shellhub-io/shellhub/pkg/agent/server/modes/host"
 	"github.com/shellhub-io/shellhub/pkg/api/client"
-	"github.com/shellhub-io/shellhub/pkg/models"
 	log "github.com/sirupsen/logrus"
 	gossh "golang.org/x/crypto/ssh"
 )
@@ -38,13 +37,15 @@ func (c *sshConn) Close() error {
 type Server struct {
 	sshd               *gliderssh.Server
 	api                client.Client
-	authData           *models.DeviceAuthResponse
 	cmds               map[string]*exec.Cmd
-	Sessions           map[string]net.Conn
 	deviceName         string
 	mu                 sync.Mutex
 	keepAliveInterval  int
 	singleUserPassword string
+	// sessions are the connections of the sessions opened through the tunnel, by their IDs. They are written by the
+	// tunnel's handlers and read by the agent's status and ping, so they are guarded by sessionsMu.
+	sessions   map[string]net.Conn
+	sessionsMu sync.Mutex
 	// mode is the mode of the server, identifing where and how the SSH's server is running.
 	//
 	// For example, the [modes.HostMode] means that the SSH's server runs in the host machine, using the host
@@ -86,12 +87,11 @@ const (
 )
 
 // NewServer creates a new server SSH agent server.
-func NewServer(api client.Client, authData *models.DeviceAuthResponse, privateKey string, keepAliveInterval int, singleUserPassword string) *Server {
+func NewServer(api client.Client, token func() string, privateKey string, keepAliveInterval int, singleUserPassword string) *Server {
 	server := &Server{
 		api:                api,
-		authData:           authData,
 		cmds:               make(map[string]*exec.Cmd),
-		Sessions:           make(map[string]net.Conn),
+		sessions:           make(map[string]net.Conn),
 		keepAliveInterval:  keepAliveInterval,
 		singleUserPassword: singleUserPassword,
 		mode:               modes.HostMode,
@@ -99,7 +99,7 @@ func NewServer(api client.Client, authData *models.DeviceAuthResponse, privateKe
 
 	switch server.mode {
 	case modes.HostMode:
-		server.authenticator = host.NewAuthenticator(api, authData, singleUserPassword, &server.deviceName)
+		server.authenticator = host.NewAuthenticator(api, token, singleUserPassword, &server.deviceName)
 		server.sessioner = host.NewSessioner(&server.deviceName, server.cmds)
 	}
 
@@ -146,7 +146,9 @@ func NewServer(api client.Client, authData *models.DeviceAuthResponse, privateKe
 
 // startKeepAlive sends a keep alive message to the server every in keepAliveInterval seconds.
 func (s *Server) startKeepAliveLoop(session gliderssh.Session) {
+	s.mu.Lock()
 	interval := time.Duration(s.keepAliveInterval) * time.Second
+	s.mu.Unlock()
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
@@ -207,10 +209,44 @@ func (s *Server) SetDeviceName(name string) {
 	s.deviceName = name
 }
 
+// SetKeepAliveInterval sets the interval, in seconds, of the keep alive messages sent on the next sessions. It is
+// called by a reload of the agent's configuration while the sessions run, so the interval is guarded by the mutex.
+func (s *Server) SetKeepAliveInterval(interval int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keepAliveInterval = interval
+}
+
+// AddSession registers the connection of the session opened through the tunnel, to be closed by [Server.CloseSession].
+func (s *Server) AddSession(id string, conn net.Conn) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	s.sessions[id] = conn
+}
+
+// SessionIDs returns a snapshot of the IDs of the sessions opened through the tunnel.
+func (s *Server) SessionIDs() []string {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	ids := make([]string, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
 func (s *Server) CloseSession(id string) {
-	if session, ok := s.Sessions[id]; ok {
+	s.sessionsMu.Lock()
+	session, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.sessionsMu.Unlock()
+
+	if ok {
 		session.Close()
-		delete(s.Sessions, id)
 	}
 }
 
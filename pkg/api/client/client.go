@@ -7,8 +7,10 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"time"
 
 	resty "github.com/go-resty/resty/v2"
+	"github.com/gorilla/websocket"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/sirupsen/logrus"
 )
@@ -20,13 +22,22 @@ const (
 var (
 	ErrConnectionFailed = errors.New("connection failed")
 	ErrNotFound         = errors.New("not found")
+	ErrUnauthorized     = errors.New("unauthorized")
 	ErrUnknown          = errors.New("unknown error")
 )
 
+// noRetry is the context's key that marks a request which is not retried, like the ones signing a challenge, that the
+// server accepts only once.
+type noRetry struct{}
+
 func NewClient(opts ...Opt) Client {
 	httpClient := resty.New()
 	httpClient.SetRetryCount(math.MaxInt32)
 	httpClient.AddRetryCondition(func(r *resty.Response, err error) bool {
+		if r != nil && r.Request != nil && r.Request.Context().Value(noRetry{}) != nil {
+			return false
+		}
+
 		if _, ok := err.(net.Error); ok {
 			return true
 		}
@@ -39,6 +50,12 @@ func NewClient(opts ...Opt) Client {
 		port:   apiPort,
 		scheme: apiScheme,
 		http:   httpClient,
+		// The websocket connections honor the same proxy environment variables, HTTPS_PROXY and NO_PROXY, of the
+		// HTTP requests.
+		websocket: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: 45 * time.Second,
+		},
 	}
 
 	for _, opt := range opts {
@@ -60,11 +77,12 @@ type commonAPI interface {
 }
 
 type client struct {
-	scheme string
-	host   string
-	port   int
-	http   *resty.Client
-	logger *logrus.Logger
+	scheme    string
+	host      string
+	port      int
+	http      *resty.Client
+	websocket *websocket.Dialer
+	logger    *logrus.Logger
 }
 
 func (c *client) ListDevices() ([]models.Device, error) {
@@ -7,7 +7,6 @@ import (
 	"regexp"
 	"strings"
 
-	resty "github.com/go-resty/resty/v2"
 	"github.com/gorilla/websocket"
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/pkg/revdial"
@@ -29,7 +28,13 @@ type Client interface {
 type publicAPI interface {
 	GetInfo(agentVersion string) (*models.Info, error)
 	Endpoints() (*models.Endpoints, error)
+	// AuthDeviceChallenge gets the nonce that the device signs to authenticate.
+	AuthDeviceChallenge() (*models.DeviceChallenge, error)
 	AuthDevice(req *models.DeviceAuthRequest) (*models.DeviceAuthResponse, error)
+	// AuthDeviceRotateKey replaces the device's key by a new one, keeping its identity.
+	AuthDeviceRotateKey(req *models.DeviceKeyRotation) error
+	// AuthDeviceRekey replaces the device's key by a new one, authorized by the re-key token issued by an administrator.
+	AuthDeviceRekey(req *models.DeviceRekey) error
 	NewReverseListener(token string) (*revdial.Listener, error)
 	AuthPublicKey(req *models.PublicKeyAuthRequest, token string) (*models.PublicKeyAuthResponse, error)
 }
@@ -47,26 +52,28 @@ func (c *client) GetInfo(agentVersion string) (*models.Info, error) {
 	return info, nil
 }
 
+func (c *client) AuthDeviceChallenge() (*models.DeviceChallenge, error) {
+	var challenge *models.DeviceChallenge
+	resp, err := c.http.R().
+		SetResult(&challenge).
+		Post(buildURL(c, "/api/devices/auth/challenge"))
+	if err != nil {
+		return nil, ErrConnectionFailed
+	}
+
+	if resp.IsError() {
+		return nil, ErrUnknown
+	}
+
+	return challenge, nil
+}
+
+// AuthDevice authenticates the device with a signed challenge. The request is not retried, since the server accepts
+// each challenge once, so the caller retries it with a new challenge.
 func (c *client) AuthDevice(req *models.DeviceAuthRequest) (*models.DeviceAuthResponse, error) {
 	var res *models.DeviceAuthResponse
-	_, err := c.http.R().
-		AddRetryCondition(func(r *resty.Response, err error) bool {
-			identity := func(mac, hostname string) string {
-				if mac != "" {
-					return mac
-				}
-
-				return hostname
-			}
-
-			log.WithFields(log.Fields{
-				"tenant_id":   req.TenantID,
-				"identity":    identity(req.Identity.MAC, req.Hostname),
-				"status_code": r.StatusCode(),
-			}).Debug("failed to authenticate device")
-
-			return r.IsError()
-		}).
+	resp, err := c.http.R().
+		SetContext(context.WithValue(context.Background(), noRetry{}, true)).
 		SetBody(req).
 		SetResult(&res).
 		Post(buildURL(c, "/api/devices/auth"))
@@ -74,9 +81,67 @@ func (c *client) AuthDevice(req *models.DeviceAuthRequest) (*models.DeviceAuthRe
 		return nil, err
 	}
 
+	if resp.IsError() {
+		identity := req.Hostname
+		if req.Identity != nil && req.Identity.MAC != "" {
+			identity = req.Identity.MAC
+		}
+
+		log.WithFields(log.Fields{
+			"tenant_id":   req.TenantID,
+			"identity":    identity,
+			"status_code": resp.StatusCode(),
+		}).Debug("failed to authenticate device")
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusUnauthorized, resp.StatusCode() == http.StatusForbidden:
+		return nil, ErrUnauthorized
+	case resp.IsError() || res == nil:
+		return nil, ErrUnknown
+	}
+
 	return res, nil
 }
 
+func (c *client) AuthDeviceRotateKey(req *models.DeviceKeyRotation) error {
+	resp, err := c.http.R().
+		SetContext(context.WithValue(context.Background(), noRetry{}, true)).
+		SetBody(req).
+		Post(buildURL(c, "/api/devices/auth/rotate"))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusUnauthorized, resp.StatusCode() == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.IsError():
+		return ErrUnknown
+	}
+
+	return nil
+}
+
+func (c *client) AuthDeviceRekey(req *models.DeviceRekey) error {
+	resp, err := c.http.R().
+		SetContext(context.WithValue(context.Background(), noRetry{}, true)).
+		SetBody(req).
+		Post(buildURL(c, "/api/devices/auth/rekey"))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	switch {
+	case resp.StatusCode() == http.StatusUnauthorized, resp.StatusCode() == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.IsError():
+		return ErrUnknown
+	}
+
+	return nil
+}
+
 func (c *client) Endpoints() (*models.Endpoints, error) {
 	var endpoints *models.Endpoints
 	_, err := c.http.R().
@@ -94,14 +159,14 @@ func (c *client) NewReverseListener(token string) (*revdial.Listener, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
 	url := regexp.MustCompile(`^http`).ReplaceAllString(buildURL(c, "/ssh/connection"), "ws")
-	conn, _, err := websocket.DefaultDialer.Dial(url, req.Header)
+	conn, _, err := c.websocket.Dial(url, req.Header)
 	if err != nil {
 		return nil, err
 	}
 
 	listener := revdial.NewListener(wsconnadapter.New(conn),
 		func(ctx context.Context, path string) (*websocket.Conn, *http.Response, error) {
-			return tunnelDial(ctx, strings.Replace(c.scheme, "http", "ws", 1), c.host, c.port, path)
+			return tunnelDial(ctx, c.websocket, strings.Replace(c.scheme, "http", "ws", 1), c.host, c.port, path)
 		},
 	)
 
@@ -122,6 +187,6 @@ func (c *client) AuthPublicKey(req *models.PublicKeyAuthRequest, token string) (
 	return res, nil
 }
 
-func tunnelDial(ctx context.Context, protocol, address string, port int, path string) (*websocket.Conn, *http.Response, error) {
-	return websocket.DefaultDialer.DialContext(ctx, strings.Join([]string{fmt.Sprintf("%s://%s:%d", protocol, address, port), path}, ""), nil)
+func tunnelDial(ctx context.Context, dialer *websocket.Dialer, protocol, address string, port int, path string) (*websocket.Conn, *http.Response, error) {
+	return dialer.DialContext(ctx, strings.Join([]string{fmt.Sprintf("%s://%s:%d", protocol, address, port), path}, ""), nil)
 }
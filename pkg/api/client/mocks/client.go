@@ -37,6 +37,57 @@ func (_m *Client) AuthDevice(req *models.DeviceAuthRequest) (*models.DeviceAuthR
 	return r0, r1
 }
 
+// AuthDeviceChallenge provides a mock function with given fields:
+func (_m *Client) AuthDeviceChallenge() (*models.DeviceChallenge, error) {
+	ret := _m.Called()
+
+	var r0 *models.DeviceChallenge
+	if rf, ok := ret.Get(0).(func() *models.DeviceChallenge); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.DeviceChallenge)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuthDeviceRekey provides a mock function with given fields: req
+func (_m *Client) AuthDeviceRekey(req *models.DeviceRekey) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.DeviceRekey) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AuthDeviceRotateKey provides a mock function with given fields: req
+func (_m *Client) AuthDeviceRotateKey(req *models.DeviceKeyRotation) error {
+	ret := _m.Called(req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.DeviceKeyRotation) error); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // AuthPublicKey provides a mock function with given fields: req, token
 func (_m *Client) AuthPublicKey(req *models.PublicKeyAuthRequest, token string) (*models.PublicKeyAuthResponse, error) {
 	ret := _m.Called(req, token)
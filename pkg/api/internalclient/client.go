@@ -21,6 +21,8 @@ const (
 var (
 	ErrConnectionFailed = errors.New("connection failed")
 	ErrNotFound         = errors.New("not found")
+	ErrForbidden        = errors.New("forbidden")
+	ErrTooManyRequests  = errors.New("too many requests")
 	ErrUnknown          = errors.New("unknown error")
 )
 
@@ -16,6 +16,7 @@ const (
 	apiPort    = 8080
 	apiScheme  = "http"
 	billingURL = "billing-api"
+	sshHost    = "ssh"
 )
 
 type Client interface {
@@ -29,16 +30,23 @@ type internalAPI interface {
 	CreatePrivateKey() (*models.PrivateKey, error)
 	EvaluateKey(fingerprint string, dev *models.Device, username string) (bool, error)
 	DevicesOffline(id string) error
+	DisconnectDevice(uid string) error
 	DevicesHeartbeat(id string) error
 	FirewallEvaluate(lookup map[string]string) error
 	SessionAsAuthenticated(uid string) []error
-	FinishSession(uid string) []error
+	FinishSession(uid, reason string) []error
+	CloseSession(uid, device string) error
 	KeepAliveSession(uid string) []error
 	RecordSession(session *models.SessionRecorded, recordURL string)
 	Lookup(lookup map[string]string) (string, []error)
 	DeviceLookup(lookup map[string]string) (*models.Device, []error)
 	BillingReport(tenant string, action string) (int, error)
 	BillingEvaluate(tenantID string) (*models.BillingEvaluation, int, error)
+	EvaluateAccessRequest(tenantID, deviceUID, userID string) (bool, error)
+	EvaluateMember(tenantID, userID, deviceUID string) (bool, error)
+	ShadowSession(uid, tenantID, userID, role string) (*models.Session, error)
+	GetNamespaceSettings(tenantID string) (*models.NamespaceSettings, error)
+	ValidateMFA(tenantID, userID, username, code string) (string, error)
 }
 
 func (c *client) LookupDevice() {
@@ -103,6 +111,97 @@ func (c *client) EvaluateKey(fingerprint string, dev *models.Device, username st
 	return false, nil
 }
 
+// EvaluateAccessRequest checks if the user has access to the device when it requires an approved access request.
+func (c *client) EvaluateAccessRequest(tenantID, deviceUID, userID string) (bool, error) {
+	var allowed bool
+	resp, err := c.http.R().
+		SetQueryParams(map[string]string{
+			"tenant_id":  tenantID,
+			"device_uid": deviceUID,
+			"user_id":    userID,
+		}).
+		SetResult(&allowed).
+		Get(buildURL(c, "/internal/access-requests/evaluate"))
+	if err != nil {
+		return false, ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return false, ErrUnknown
+	}
+
+	return allowed, nil
+}
+
+// EvaluateMember checks if the namespace's member can connect to the device. An empty userID evaluates a connection that
+// is not bound to any member.
+func (c *client) EvaluateMember(tenantID, userID, deviceUID string) (bool, error) {
+	var allowed bool
+	resp, err := c.http.R().
+		SetQueryParams(map[string]string{
+			"device_uid": deviceUID,
+			"user_id":    userID,
+		}).
+		SetResult(&allowed).
+		Get(buildURL(c, fmt.Sprintf("/internal/namespaces/%s/members/evaluate", tenantID)))
+	if err != nil {
+		return false, ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return false, ErrUnknown
+	}
+
+	return allowed, nil
+}
+
+// ShadowSession checks if the namespace's member, identified by its ID and role, can watch the session.
+func (c *client) ShadowSession(uid, tenantID, userID, role string) (*models.Session, error) {
+	var session *models.Session
+	resp, err := c.http.R().
+		SetHeaders(map[string]string{
+			"X-Tenant-ID": tenantID,
+			"X-ID":        userID,
+			"X-Role":      role,
+		}).
+		SetResult(&session).
+		Get(buildURL(c, fmt.Sprintf("/internal/sessions/%s/shadow", uid)))
+	if err != nil {
+		return nil, ErrConnectionFailed
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		return session, nil
+	case http.StatusForbidden:
+		return nil, ErrForbidden
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, ErrUnknown
+	}
+}
+
+// GetNamespaceSettings gets the settings of the namespace.
+func (c *client) GetNamespaceSettings(tenantID string) (*models.NamespaceSettings, error) {
+	var settings *models.NamespaceSettings
+	resp, err := c.http.R().
+		SetResult(&settings).
+		Get(buildURL(c, fmt.Sprintf("/internal/namespaces/%s/settings", tenantID)))
+	if err != nil {
+		return nil, ErrConnectionFailed
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+		return settings, nil
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, ErrUnknown
+	}
+}
+
 func (c *client) CreatePrivateKey() (*models.PrivateKey, error) {
 	var privKey *models.PrivateKey
 	_, err := c.http.R().
@@ -125,6 +224,39 @@ func (c *client) DevicesOffline(id string) error {
 	return nil
 }
 
+// DisconnectDevice drops the device's tunnel on the SSH server. A device without tunnel is already disconnected.
+func (c *client) DisconnectDevice(uid string) error {
+	// The request is not retried, as the client's one, since the SSH server may be down.
+	resp, err := resty.New().R().
+		Post(fmt.Sprintf("%s://%s:%d/internal/devices/%s/disconnect", apiScheme, sshHost, apiPort, uid))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return ErrUnknown
+	}
+
+	return nil
+}
+
+// CloseSession closes the session on the SSH server, which asks the device's agent to end it.
+func (c *client) CloseSession(uid, device string) error {
+	// The request is not retried, as the client's one, since the SSH server may be down.
+	resp, err := resty.New().R().
+		SetBody(map[string]string{"device": device}).
+		Post(fmt.Sprintf("%s://%s:%d/sessions/%s/close", apiScheme, sshHost, apiPort, uid))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	if resp.StatusCode() != http.StatusOK {
+		return ErrUnknown
+	}
+
+	return nil
+}
+
 func (c *client) DevicesHeartbeat(id string) error {
 	_, err := c.asynq.Enqueue(asynq.NewTask("api:heartbeat", []byte(id)), asynq.Queue("api"), asynq.Group("heartbeats"))
 
@@ -177,9 +309,11 @@ func (c *client) SessionAsAuthenticated(uid string) []error {
 	return errors
 }
 
-func (c *client) FinishSession(uid string) []error {
+// FinishSession closes the session. Reason is empty when the session was closed by the user.
+func (c *client) FinishSession(uid, reason string) []error {
 	var errors []error
 	_, err := c.http.R().
+		SetBody(map[string]string{"reason": reason}).
 		Post(buildURL(c, fmt.Sprintf("/internal/sessions/%s/finish", uid)))
 	if err != nil {
 		errors = append(errors, err)
@@ -239,3 +373,32 @@ func (c *client) DeviceLookup(lookup map[string]string) (*models.Device, []error
 
 	return device, nil
 }
+
+// ValidateMFA checks the TOTP code of a namespace's member, identified by its ID or, when empty, by its username. It
+// returns the member's ID when the code is valid, or an empty string otherwise, and ErrTooManyRequests while the member
+// is blocked by its failed attempts.
+func (c *client) ValidateMFA(tenantID, userID, username, code string) (string, error) {
+	var id string
+	resp, err := c.http.R().
+		SetBody(map[string]string{
+			"tenant_id": tenantID,
+			"user_id":   userID,
+			"username":  username,
+			"code":      code,
+		}).
+		SetResult(&id).
+		Post(buildURL(c, "/internal/mfa/validate"))
+	if err != nil {
+		return "", ErrConnectionFailed
+	}
+
+	switch resp.StatusCode() {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return "", ErrTooManyRequests
+	default:
+		return "", ErrUnknown
+	}
+
+	return id, nil
+}
@@ -69,6 +69,20 @@ func (_m *Client) BillingReport(tenant string, action string) (int, error) {
 	return r0, r1
 }
 
+// CloseSession provides a mock function with given fields: uid, device
+func (_m *Client) CloseSession(uid string, device string) error {
+	ret := _m.Called(uid, device)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(uid, device)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreatePrivateKey provides a mock function with given fields:
 func (_m *Client) CreatePrivateKey() (*models.PrivateKey, error) {
 	ret := _m.Called()
@@ -151,6 +165,44 @@ func (_m *Client) DevicesOffline(id string) error {
 	return r0
 }
 
+// DisconnectDevice provides a mock function with given fields: uid
+func (_m *Client) DisconnectDevice(uid string) error {
+	ret := _m.Called(uid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// EvaluateAccessRequest provides a mock function with given fields: tenantID, deviceUID, userID
+func (_m *Client) EvaluateAccessRequest(tenantID string, deviceUID string, userID string) (bool, error) {
+	ret := _m.Called(tenantID, deviceUID, userID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (bool, error)); ok {
+		return rf(tenantID, deviceUID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(tenantID, deviceUID, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(tenantID, deviceUID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // EvaluateKey provides a mock function with given fields: fingerprint, dev, username
 func (_m *Client) EvaluateKey(fingerprint string, dev *models.Device, username string) (bool, error) {
 	ret := _m.Called(fingerprint, dev, username)
@@ -175,13 +227,37 @@ func (_m *Client) EvaluateKey(fingerprint string, dev *models.Device, username s
 	return r0, r1
 }
 
-// FinishSession provides a mock function with given fields: uid
-func (_m *Client) FinishSession(uid string) []error {
-	ret := _m.Called(uid)
+// EvaluateMember provides a mock function with given fields: tenantID, userID, deviceUID
+func (_m *Client) EvaluateMember(tenantID string, userID string, deviceUID string) (bool, error) {
+	ret := _m.Called(tenantID, userID, deviceUID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string) (bool, error)); ok {
+		return rf(tenantID, userID, deviceUID)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string) bool); ok {
+		r0 = rf(tenantID, userID, deviceUID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string) error); ok {
+		r1 = rf(tenantID, userID, deviceUID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishSession provides a mock function with given fields: uid, reason
+func (_m *Client) FinishSession(uid string, reason string) []error {
+	ret := _m.Called(uid, reason)
 
 	var r0 []error
-	if rf, ok := ret.Get(0).(func(string) []error); ok {
-		r0 = rf(uid)
+	if rf, ok := ret.Get(0).(func(string, string) []error); ok {
+		r0 = rf(uid, reason)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]error)
@@ -257,6 +333,32 @@ func (_m *Client) GetDeviceByPublicURLAddress(address string) (*models.Device, e
 	return r0, r1
 }
 
+// GetNamespaceSettings provides a mock function with given fields: tenantID
+func (_m *Client) GetNamespaceSettings(tenantID string) (*models.NamespaceSettings, error) {
+	ret := _m.Called(tenantID)
+
+	var r0 *models.NamespaceSettings
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.NamespaceSettings, error)); ok {
+		return rf(tenantID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.NamespaceSettings); ok {
+		r0 = rf(tenantID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.NamespaceSettings)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(tenantID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPublicKey provides a mock function with given fields: fingerprint, tenant
 func (_m *Client) GetPublicKey(fingerprint string, tenant string) (*models.PublicKey, error) {
 	ret := _m.Called(fingerprint, tenant)
@@ -377,6 +479,56 @@ func (_m *Client) SessionAsAuthenticated(uid string) []error {
 	return r0
 }
 
+// ShadowSession provides a mock function with given fields: uid, tenantID, userID, role
+func (_m *Client) ShadowSession(uid string, tenantID string, userID string, role string) (*models.Session, error) {
+	ret := _m.Called(uid, tenantID, userID, role)
+
+	var r0 *models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (*models.Session, error)); ok {
+		return rf(uid, tenantID, userID, role)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) *models.Session); ok {
+		r0 = rf(uid, tenantID, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(uid, tenantID, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ValidateMFA provides a mock function with given fields: tenantID, userID, username, code
+func (_m *Client) ValidateMFA(tenantID string, userID string, username string, code string) (string, error) {
+	ret := _m.Called(tenantID, userID, username, code)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, string) (string, error)); ok {
+		return rf(tenantID, userID, username, code)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, string) string); ok {
+		r0 = rf(tenantID, userID, username, code)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, string) error); ok {
+		r1 = rf(tenantID, userID, username, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewClient interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,44 @@
+package requests
+
+import (
+	"time"
+)
+
+// AccessRequestParam is a structure to represent and validate an access request ID as path param.
+type AccessRequestParam struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// AccessRequestList is the structure to represent the request data for list access requests endpoint.
+type AccessRequestList struct {
+	Status string `query:"status" validate:"omitempty,oneof=pending approved denied expired"`
+}
+
+// AccessRequestCreate is the structure to represent the request data for create access request endpoint.
+type AccessRequestCreate struct {
+	DeviceUID     string `json:"device_uid" validate:"required"`
+	Justification string `json:"justification" validate:"required,max=1024"`
+	// StartsAt is when the access window begins. When it is zero, the window begins when the request is created. In
+	// both cases, the access is only granted after the request is approved.
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+}
+
+// AccessRequestReview is the structure to represent the request data for review access request endpoint.
+type AccessRequestReview struct {
+	AccessRequestParam
+	Action string `param:"action" validate:"required,oneof=approve deny"`
+}
+
+// AccessRequestEvaluate is the structure to represent the request data for evaluate access request endpoint.
+type AccessRequestEvaluate struct {
+	TenantID  string `query:"tenant_id" validate:"required"`
+	DeviceUID string `query:"device_uid" validate:"required"`
+	UserID    string `query:"user_id"`
+}
+
+// NamespaceEditAccessApproval is the structure to represent the request data for edit access approval tags endpoint.
+type NamespaceEditAccessApproval struct {
+	TenantParam
+	Tags []string `json:"tags" validate:"omitempty,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
+}
@@ -9,3 +9,45 @@ type AuthTokenGet struct {
 type AuthTokenSwap struct {
 	TenantParam
 }
+
+// AuthMFA is the structure to represent the request data for the endpoint that completes a login with a second factor.
+type AuthMFA struct {
+	// Token is the MFA token returned by the user auth endpoint.
+	Token        string `json:"token" validate:"required"`
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+// MFAValidate is the structure to represent the request data for the internal endpoint that validates a member's TOTP
+// code.
+type MFAValidate struct {
+	TenantID string `json:"tenant_id" validate:"required"`
+	// UserID identifies the member by its ID. When empty, Username is used.
+	UserID string `json:"user_id" validate:"required_without=Username"`
+	// Username identifies the member by its username or e-mail.
+	Username string `json:"username" validate:"required_without=UserID"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// AuthMFAEnroll is the structure to represent the request data for the endpoint that enrolls MFA during a login.
+type AuthMFAEnroll struct {
+	// Token is the MFA token returned by the user auth endpoint.
+	Token string `json:"token" validate:"required"`
+}
+
+// AuthOIDC is the structure to complete a login through OpenID Connect with the values sent by the identity provider to
+// the redirect URL.
+type AuthOIDC struct {
+	Code  string `json:"code" validate:"required"`
+	State string `json:"state" validate:"required"`
+	// BrowserState is the state bound to the browser's cookie when the login started.
+	BrowserState string `json:"-"`
+}
+
+// AuthRefresh is the structure to represent the request data for the endpoint that refreshes the user's token.
+type AuthRefresh struct {
+	// RefreshToken is the refresh token returned by the last login or refresh.
+	RefreshToken string `json:"refresh_token" validate:"required"`
+	// Tenant is the namespace of the new token. When empty, the user's first namespace is used.
+	Tenant string `json:"tenant"`
+}
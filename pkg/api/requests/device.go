@@ -83,6 +83,49 @@ type DeviceAuth struct {
 	Identity  *DeviceIdentity `json:"identity,omitempty" validate:"required_without=Hostname,omitempty"`
 	PublicKey string          `json:"public_key" validate:"required"`
 	TenantID  string          `json:"tenant_id" validate:"required"`
+	// EnrollmentToken is the namespace's enrollment token that accepts the device when it registers.
+	EnrollmentToken string `json:"enrollment_token,omitempty"`
+	// Tags and Labels are declared by the agent's configuration.
+	Tags   []string          `json:"tags,omitempty" validate:"omitempty,max=3,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
+	Labels map[string]string `json:"labels,omitempty" validate:"omitempty,max=32,dive,keys,min=1,max=64,printascii,endkeys,max=255"`
+	// Challenge is the nonce issued by the server and Signature is its base64 encoded signature, with SHA256 and
+	// PKCS #1 v1.5, by the device's private key. They are only optional when the legacy device auth, of the agents
+	// older than the proof of key possession, is enabled.
+	Challenge string `json:"challenge,omitempty" validate:"required_with=Signature"`
+	Signature string `json:"signature,omitempty" validate:"required_with=Challenge"`
+}
+
+// DeviceRotateKey is the structure to represent the request data for device key rotation endpoint.
+type DeviceRotateKey struct {
+	TenantID  string `json:"tenant_id" validate:"required"`
+	PublicKey string `json:"public_key" validate:"required"`
+	Challenge string `json:"challenge" validate:"required"`
+	// Signature is the signature of the challenge followed by NewPublicKey by the current key.
+	Signature    string `json:"signature" validate:"required"`
+	NewPublicKey string `json:"new_public_key" validate:"required"`
+	// NewSignature is the signature of the challenge by the new key.
+	NewSignature string `json:"new_signature" validate:"required"`
+}
+
+// DeviceRekey is the structure to represent the request data for device re-key endpoint.
+type DeviceRekey struct {
+	TenantID string `json:"tenant_id" validate:"required"`
+	// Token is the one-time re-key token issued to the device by an administrator.
+	Token        string `json:"token" validate:"required"`
+	Challenge    string `json:"challenge" validate:"required"`
+	NewPublicKey string `json:"new_public_key" validate:"required"`
+	// NewSignature is the signature of the challenge by the new key.
+	NewSignature string `json:"new_signature" validate:"required"`
+}
+
+// DeviceCreateRekeyToken is the structure to represent the request data for device re-key token creation endpoint.
+type DeviceCreateRekeyToken struct {
+	DeviceParam
+}
+
+// DeviceRevokeKey is the structure to represent the request data for device key revocation endpoint.
+type DeviceRevokeKey struct {
+	DeviceParam
 }
 
 type DeviceGetPublicURL struct {
@@ -0,0 +1,27 @@
+package requests
+
+// EnrollmentTokenList is the structure to represent the request data for list enrollment tokens endpoint.
+type EnrollmentTokenList struct {
+	TenantParam
+}
+
+// EnrollmentTokenCreate is the structure to represent the request data for create enrollment token endpoint.
+type EnrollmentTokenCreate struct {
+	TenantParam
+	Description string `json:"description" validate:"max=255"`
+	// Tags are set to the devices registered with the token.
+	Tags []string `json:"tags" validate:"omitempty,max=3,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
+	// NameTemplate is the name given to the devices registered with the token, accepting the "{hostname}", "{mac}" and
+	// "{uid}" placeholders.
+	NameTemplate string `json:"name_template" validate:"max=63"`
+	// MaxUses is the number of devices the token can register. Zero means no limit.
+	MaxUses int `json:"max_uses" validate:"min=0"`
+	// ExpiresIn is the number of hours the token is valid, up to a year.
+	ExpiresIn int `json:"expires_in" validate:"required,min=1,max=8760"`
+}
+
+// EnrollmentTokenDelete is the structure to represent the request data for delete enrollment token endpoint.
+type EnrollmentTokenDelete struct {
+	TenantParam
+	ID string `param:"id" validate:"required"`
+}
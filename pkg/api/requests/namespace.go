@@ -57,6 +57,74 @@ type NamespaceEditUser struct {
 	RoleBody
 }
 
+// NamespaceEditMemberScope is the structure to represent the request data for edit member's scope endpoint.
+type NamespaceEditMemberScope struct {
+	TenantParam
+	MemberParam
+	Tags    []string `json:"tags" validate:"omitempty,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
+	Devices []string `json:"devices" validate:"omitempty,unique,dive,required"`
+}
+
+// NamespaceEvaluateMember is the structure to represent the request data for the internal endpoint that evaluates if
+// a member can connect to a device.
+type NamespaceEvaluateMember struct {
+	TenantParam
+	DeviceUID string `query:"device_uid" validate:"required"`
+	// UserID is the member's ID. It is empty when the connection is not bound to any member.
+	UserID string `query:"user_id"`
+}
+
+// NamespaceEditSessionPolicy is the structure to represent the request data for edit namespace's session policy endpoint.
+type NamespaceEditSessionPolicy struct {
+	TenantParam
+	// IdleTimeout is the number of minutes without activity after which a session is closed.
+	IdleTimeout int `json:"idle_timeout" validate:"min=0,max=10080"`
+	// MaxSessionDuration is the number of minutes after which a session is closed.
+	MaxSessionDuration int `json:"max_session_duration" validate:"min=0,max=10080"`
+}
+
+// NamespaceEditAgentTags is the structure to represent the request data for edit namespace's agent tags endpoint.
+type NamespaceEditAgentTags struct {
+	TenantParam
+	// AgentTagsOverwrite lets the tags declared by the agents replace the tags set through the API.
+	AgentTagsOverwrite bool `json:"agent_tags_overwrite"`
+}
+
+// NamespaceEditRequireMFA is the structure to represent the request data for edit namespace's MFA requirement endpoint.
+type NamespaceEditRequireMFA struct {
+	TenantParam
+	// RequireMFA requires the members to log in with MFA.
+	RequireMFA bool `json:"require_mfa"`
+	// RequireSSHMFA requires a TOTP code to connect to the namespace's devices through SSH.
+	RequireSSHMFA bool `json:"require_ssh_mfa"`
+}
+
+// NamespaceEditRequireEnrollmentToken is the structure to represent the request data for edit namespace's enrollment
+// token requirement endpoint.
+type NamespaceEditRequireEnrollmentToken struct {
+	TenantParam
+	RequireEnrollmentToken bool `json:"require_enrollment_token"`
+}
+
+// NamespaceTransferOwnership is the structure to represent the request data for the endpoint that starts the transfer
+// of the namespace's ownership to a member.
+type NamespaceTransferOwnership struct {
+	TenantParam
+	MemberParam
+}
+
+// NamespaceTransferAccept is the structure to represent the request data for the endpoint that accepts the transfer of
+// the namespace's ownership.
+type NamespaceTransferAccept struct {
+	TenantParam
+}
+
+// NamespaceTransferCancel is the structure to represent the request data for the endpoint that cancels, or declines,
+// the transfer of the namespace's ownership.
+type NamespaceTransferCancel struct {
+	TenantParam
+}
+
 // SessionEditRecordStatus is the structure to represent the request data for edit session record status endpoint.
 type SessionEditRecordStatus struct {
 	TenantParam
@@ -20,14 +20,33 @@ type PublicKeyFilter struct {
 	Tags []string `json:"tags,omitempty" validate:"required_without=Hostname,excluded_with=Hostname,max=3,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
 }
 
+// PublicKeyRestrictions limits what a session authenticated by the public key can do.
+type PublicKeyRestrictions struct {
+	// SessionTypes are the session's types allowed to the public key.
+	SessionTypes []string `json:"session_types,omitempty" validate:"omitempty,unique,dive,oneof=shell exec sftp scp port-forward"`
+	// ForceCommand is the command executed on the device, instead of the shell or the requested one.
+	ForceCommand string `json:"force_command,omitempty"`
+	// AllowedCommands are regular expressions, where, at least, one must match the command of an exec session.
+	AllowedCommands []string `json:"allowed_commands,omitempty" validate:"omitempty,dive,required,regexp"`
+}
+
 // PublicKeyCreate is the structure to represent the request data for create public key endpoint.
 type PublicKeyCreate struct {
-	Data        []byte          `json:"data" validate:"required"`
-	Filter      PublicKeyFilter `json:"filter" validate:"required"`
-	Name        string          `json:"name" validate:"required"`
-	Username    string          `json:"username" validate:"required,regexp"`
-	TenantID    string          `json:"-"`
-	Fingerprint string          `json:"-"`
+	Data     []byte          `json:"data" validate:"required"`
+	Filter   PublicKeyFilter `json:"filter" validate:"required"`
+	Name     string          `json:"name" validate:"required"`
+	Username string          `json:"username" validate:"required,regexp"`
+	Member   string          `json:"member,omitempty" validate:"required_if=RequireMFA true"`
+	// Restrictions limits what the sessions authenticated by the public key can do.
+	Restrictions *PublicKeyRestrictions `json:"restrictions,omitempty"`
+	// RequireMFA requires the member's TOTP code after the public key is verified.
+	RequireMFA bool `json:"require_mfa"`
+	// Automation skips the TOTP code required by the namespace.
+	Automation  bool   `json:"automation" validate:"excluded_with=RequireMFA"`
+	TenantID    string `json:"-"`
+	Fingerprint string `json:"-"`
+	// UserID is the member who creates the public key.
+	UserID string `json:"-"`
 }
 
 // PublicKeyUpdate is the structure to represent the request data for update public key endpoint.
@@ -39,6 +58,16 @@ type PublicKeyUpdate struct {
 	Username string `json:"username" validate:"required,regexp"`
 	// Filter is the public key's filter.
 	Filter PublicKeyFilter `json:"filter" validate:"required"`
+	// Member is the namespace's member ID who the public key belongs to.
+	Member string `json:"member,omitempty" validate:"required_if=RequireMFA true"`
+	// Restrictions limits what the sessions authenticated by the public key can do.
+	Restrictions *PublicKeyRestrictions `json:"restrictions,omitempty"`
+	// RequireMFA requires the member's TOTP code after the public key is verified.
+	RequireMFA bool `json:"require_mfa"`
+	// Automation skips the TOTP code required by the namespace.
+	Automation bool `json:"automation" validate:"excluded_with=RequireMFA"`
+	// UserID is the member who edits the public key.
+	UserID string `json:"-"`
 }
 
 // PublicKeyDelete is the structure to represent the request data for delete public key endpoint.
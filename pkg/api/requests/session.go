@@ -25,11 +25,15 @@ type SessionCreate struct {
 	IPAddress string `json:"ip_address" validate:"required"`
 	Type      string `json:"type" validate:"required"`
 	Term      string `json:"term" validate:""`
+	// Member is the ID of the namespace's member the session is bound to, if any.
+	Member string `json:"member"`
 }
 
 // SessionFinish is the structure to represent the request data for finish session endpoint.
 type SessionFinish struct {
 	SessionIDParam
+	// Reason is why the session was closed by ShellHub. It is empty when the session was closed by the user.
+	Reason string `json:"reason" validate:"omitempty,oneof=idle_timeout max_duration"`
 }
 
 // SessionFinish is the structure to represent the request data for keep alive session endpoint.
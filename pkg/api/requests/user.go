@@ -25,3 +25,43 @@ type UserAuth struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+// UserMFACode is the structure to represent the request body for the endpoints that enable and disable user's MFA.
+type UserMFACode struct {
+	Code         string `json:"code" validate:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code" validate:"required_without=Code"`
+}
+
+// UserSessionParam is the structure to represent the path parameter of the user's session ID.
+type UserSessionParam struct {
+	ID string `param:"id" validate:"required"`
+}
+
+// UserPasswordRecover is the structure to represent the request body for the endpoint that sends the link to reset a
+// forgotten password.
+type UserPasswordRecover struct {
+	// Username is the user's username or e-mail.
+	Username string `json:"username" validate:"required"`
+}
+
+// UserPasswordReset is the structure to represent the request body for the endpoint that resets a forgotten password
+// with the token sent by e-mail.
+type UserPasswordReset struct {
+	ID       string `json:"id" validate:"required"`
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=5,max=30"`
+}
+
+// UserEmailResend is the structure to represent the request body for the endpoint that sends again the link to confirm
+// the user's e-mail.
+type UserEmailResend struct {
+	// Username is the user's username or e-mail.
+	Username string `json:"username" validate:"required"`
+}
+
+// UserEmailConfirm is the structure to represent the request body for the endpoint that confirms the user's e-mail with
+// the token sent by e-mail.
+type UserEmailConfirm struct {
+	ID    string `json:"id" validate:"required"`
+	Token string `json:"token" validate:"required"`
+}
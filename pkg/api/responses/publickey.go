@@ -9,12 +9,24 @@ type PublicKeyFilter struct {
 	Tags []string `json:"tags,omitempty" validate:"required_without=Hostname,excluded_with=Hostname,max=3,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
 }
 
+// PublicKeyRestrictions limits what a session authenticated by the public key can do.
+type PublicKeyRestrictions struct {
+	SessionTypes    []string `json:"session_types,omitempty"`
+	ForceCommand    string   `json:"force_command,omitempty"`
+	AllowedCommands []string `json:"allowed_commands,omitempty"`
+}
+
 // PublicKeyCreate is the structure to represent the request data for create public key endpoint.
 type PublicKeyCreate struct {
-	Data        []byte          `json:"data"`
-	Filter      PublicKeyFilter `json:"filter"`
-	Name        string          `json:"name"`
-	Username    string          `json:"username"`
-	TenantID    string          `json:"tenant_id"`
-	Fingerprint string          `json:"fingerprint"`
+	Data     []byte          `json:"data"`
+	Filter   PublicKeyFilter `json:"filter"`
+	Name     string          `json:"name"`
+	Username string          `json:"username"`
+	Member   string          `json:"member,omitempty"`
+	// Restrictions limits what the sessions authenticated by the public key can do.
+	Restrictions *PublicKeyRestrictions `json:"restrictions,omitempty"`
+	RequireMFA   bool                   `json:"require_mfa"`
+	Automation   bool                   `json:"automation"`
+	TenantID     string                 `json:"tenant_id"`
+	Fingerprint  string                 `json:"fingerprint"`
 }
@@ -14,6 +14,10 @@ const (
 const (
 	// A new connection was made to the SSH Server.
 	WebhookIncomingConnectionEvent = "incoming_connection"
+	// A member requested access to a device.
+	WebhookAccessRequestCreatedEvent = "access_request_created"
+	// An access request was approved or denied.
+	WebhookAccessRequestReviewedEvent = "access_request_reviewed"
 )
 
 // IncomingConnectionWebhookRequest is the body payload.
@@ -25,6 +25,9 @@ var (
 
 type Webhook interface {
 	Connect(m map[string]string) (*IncomingConnectionWebhookResponse, error)
+	// Notify delivers an event with its payload to the webhook. When the webhook's URL is not configured, nothing is
+	// sent.
+	Notify(event string, payload interface{}) error
 }
 
 type Options struct {
@@ -70,20 +73,17 @@ func (w *webhookClient) Connect(m map[string]string) (*IncomingConnectionWebhook
 		Namespace: m["domain"],
 		SourceIP:  m["ip_address"],
 	}
-	secret := "secret"
-	uuid := uuid.Generate()
-	mac := hmac.New(sha256.New, []byte(secret))
-	if _, err := mac.Write([]byte(fmt.Sprintf("%v", payload))); err != nil {
+	signature, err := sign(payload)
+	if err != nil {
 		return nil, err
 	}
-	signature := mac.Sum(nil)
 
 	var res *IncomingConnectionWebhookResponse
 	resp, err := w.http.R().
 		SetHeaders(map[string]string{
-			WebhookIDHeader:        uuid,
+			WebhookIDHeader:        uuid.Generate(),
 			WebhookEventHeader:     WebhookIncomingConnectionEvent,
-			WebhookSignatureHeader: hex.EncodeToString(signature),
+			WebhookSignatureHeader: signature,
 		}).
 		SetBody(payload).
 		SetResult(&res).
@@ -103,6 +103,46 @@ func (w *webhookClient) Connect(m map[string]string) (*IncomingConnectionWebhook
 	return nil, ErrUnknown
 }
 
+func (w *webhookClient) Notify(event string, payload interface{}) error {
+	if w.host == "" {
+		return nil
+	}
+
+	signature, err := sign(payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := w.http.R().
+		SetHeaders(map[string]string{
+			WebhookIDHeader:        uuid.Generate(),
+			WebhookEventHeader:     event,
+			WebhookSignatureHeader: signature,
+		}).
+		SetBody(payload).
+		Post(buildURL(w, "/"))
+	if err != nil {
+		return ErrConnectionFailed
+	}
+
+	if resp.IsError() {
+		return ErrUnknown
+	}
+
+	return nil
+}
+
+// sign creates the hex encoded signature of a payload.
+func sign(payload interface{}) (string, error) {
+	secret := "secret"
+	mac := hmac.New(sha256.New, []byte(secret))
+	if _, err := mac.Write([]byte(fmt.Sprintf("%v", payload))); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
 func buildURL(w *webhookClient, uri string) string {
 	u, _ := url.Parse(fmt.Sprintf("%s://%s:%d", w.scheme, w.host, w.port))
 	u.Path = path.Join(u.Path, uri)
@@ -9,4 +9,7 @@ type Cache interface {
 	Get(ctx context.Context, key string, value interface{}) error
 	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
 	Delete(ctx context.Context, key string) error
+	// Incr increments the counter at key atomically, returning its new value, and sets its expiration to ttl. A missing
+	// counter starts at zero. The counter is not a value read by Get.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
 }
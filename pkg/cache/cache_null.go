@@ -24,3 +24,7 @@ func (n *nullCache) Set(_ context.Context, _ string, _ interface{}, _ time.Durat
 func (n *nullCache) Delete(_ context.Context, _ string) error {
 	return nil
 }
+
+func (n *nullCache) Incr(_ context.Context, _ string, _ time.Duration) (int64, error) {
+	return 1, nil
+}
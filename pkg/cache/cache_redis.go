@@ -9,7 +9,8 @@ import (
 )
 
 type redisCache struct {
-	cache *rediscache.Cache
+	cache  *rediscache.Cache
+	client *redis.Client
 }
 
 var _ Cache = &redisCache{}
@@ -21,10 +22,13 @@ func NewRedisCache(uri string) (Cache, error) {
 		return nil, err
 	}
 
+	client := redis.NewClient(opt)
+
 	return &redisCache{
 		cache: rediscache.New(&rediscache.Options{
-			Redis: redis.NewClient(opt),
+			Redis: client,
 		}),
+		client: client,
 	}, nil
 }
 
@@ -52,3 +56,15 @@ func (c *redisCache) Delete(ctx context.Context, key string) error {
 
 	return c.cache.Delete(ctx, key)
 }
+
+// incr increments the counter and sets its expiration in a single step, so a counter is never left without it.
+var incr = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], ARGV[1])
+return value
+`)
+
+// Incr increments the counter at key atomically and sets its expiration.
+func (c *redisCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incr.Run(ctx, c.client, []string{key}, ttl.Milliseconds()).Int64()
+}
@@ -48,6 +48,20 @@ func (m *ConnectionManager) Set(key string, conn net.Conn) {
 	}()
 }
 
+// Close closes the connection of the key, removing it from the manager.
+func (m *ConnectionManager) Close(key string) error {
+	m.lock.Lock()
+	dialer, ok := m.dialers[key]
+	delete(m.dialers, key)
+	m.lock.Unlock()
+
+	if !ok {
+		return ErrNoConnection
+	}
+
+	return dialer.Close()
+}
+
 func (m *ConnectionManager) Dial(ctx context.Context, key string) (net.Conn, error) {
 	m.lock.RLock()
 	dialer, ok := m.dialers[key]
@@ -96,6 +96,11 @@ func (t *Tunnel) Dial(ctx context.Context, id string) (net.Conn, error) {
 	return t.connman.Dial(ctx, id)
 }
 
+// Close closes the tunnel of the id, calling the CloseHandler.
+func (t *Tunnel) Close(id string) error {
+	return t.connman.Close(id)
+}
+
 func (t *Tunnel) SendRequest(ctx context.Context, id string, req *http.Request) (*http.Response, error) {
 	conn, err := t.connman.Dial(ctx, id)
 	if err != nil {
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// AccessRequestPending is the status of an access request waiting for review.
+	AccessRequestPending = "pending"
+	// AccessRequestApproved is the status of an access request granted by an administrator.
+	AccessRequestApproved = "approved"
+	// AccessRequestDenied is the status of an access request refused by an administrator.
+	AccessRequestDenied = "denied"
+	// AccessRequestExpired is the status of an access request whose time window has ended.
+	AccessRequestExpired = "expired"
+)
+
+// AccessRequest is a member's request to connect to a device during a time window.
+//
+// Devices with, at least, one of the namespace's AccessApprovalTags only accept connections from members with an
+// approved AccessRequest whose window contains the connection time.
+type AccessRequest struct {
+	ID            string     `json:"id" bson:"_id,omitempty"`
+	TenantID      string     `json:"tenant_id" bson:"tenant_id"`
+	DeviceUID     string     `json:"device_uid" bson:"device_uid"`
+	UserID        string     `json:"user_id" bson:"user_id"`
+	Justification string     `json:"justification" bson:"justification"`
+	StartsAt      time.Time  `json:"starts_at" bson:"starts_at"`
+	EndsAt        time.Time  `json:"ends_at" bson:"ends_at"`
+	Status        string     `json:"status" bson:"status"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at" bson:"created_at"`
+}
+
+// IsGranted checks if the access request allows a connection at the given time.
+func (a *AccessRequest) IsGranted(now time.Time) bool {
+	return a.Status == AccessRequestApproved && !now.Before(a.StartsAt) && now.Before(a.EndsAt)
+}
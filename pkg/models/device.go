@@ -36,6 +36,22 @@ type Device struct {
 	PublicURL        bool            `json:"public_url" bson:"public_url,omitempty"`
 	PublicURLAddress string          `json:"public_url_address" bson:"public_url_address,omitempty"`
 	Acceptable       bool            `json:"acceptable" bson:"acceptable,omitempty"`
+	// Labels are the key/value attributes declared by the agent when it starts.
+	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty"`
+	// RevokedKeys are the public keys that cannot authenticate the device anymore, because they were rotated or
+	// revoked.
+	RevokedKeys []string `json:"-" bson:"revoked_keys,omitempty"`
+}
+
+// IsKeyRevoked checks if the public key was rotated or revoked from the device.
+func (d *Device) IsKeyRevoked(publicKey string) bool {
+	for _, key := range d.RevokedKeys {
+		if key == publicKey {
+			return true
+		}
+	}
+
+	return false
 }
 
 type DeviceAuthClaims struct {
@@ -48,9 +64,52 @@ type DeviceAuthClaims struct {
 type DeviceAuthRequest struct {
 	Info     *DeviceInfo `json:"info"`
 	Sessions []string    `json:"sessions,omitempty"`
+	// EnrollmentToken registers the device already accepted in the namespace. It is kept out of DeviceAuth, since it
+	// does not identify the device.
+	EnrollmentToken string `json:"enrollment_token,omitempty"`
+	// Tags and Labels are declared by the agent's configuration. The tags are set when the device registers, and
+	// later only when the namespace lets the agent overwrite the device's tags.
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	// Challenge is the nonce got from the server and Signature is its signature by the device's private key, proving
+	// that the device holds the key of PublicKey.
+	Challenge string `json:"challenge"`
+	Signature string `json:"signature"`
 	*DeviceAuth
 }
 
+// DeviceKeyRotation replaces the device's key by a new one, keeping its UID. Signature is the signature of the challenge
+// followed by NewPublicKey by the current key, and NewSignature is the signature of the challenge by the new key.
+type DeviceKeyRotation struct {
+	TenantID     string `json:"tenant_id"`
+	PublicKey    string `json:"public_key"`
+	Challenge    string `json:"challenge"`
+	Signature    string `json:"signature"`
+	NewPublicKey string `json:"new_public_key"`
+	NewSignature string `json:"new_signature"`
+}
+
+// DeviceRekey replaces the revoked key of a device by a new one, keeping its UID, authorized by the re-key token issued
+// by an administrator. NewSignature is the signature of the challenge by the new key.
+type DeviceRekey struct {
+	TenantID     string `json:"tenant_id"`
+	Token        string `json:"token"`
+	Challenge    string `json:"challenge"`
+	NewPublicKey string `json:"new_public_key"`
+	NewSignature string `json:"new_signature"`
+}
+
+// DeviceRekeyToken is the one-time token that authorizes a device to replace its key, even when it was revoked.
+type DeviceRekeyToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DeviceChallenge is the nonce that the device signs to authenticate.
+type DeviceChallenge struct {
+	Nonce string `json:"nonce"`
+}
+
 type DeviceAuth struct {
 	Hostname  string          `json:"hostname,omitempty" bson:"hostname,omitempty" validate:"required_without=Identity,omitempty,hostname_rfc1123" hash:"-"`
 	Identity  *DeviceIdentity `json:"identity,omitempty" bson:"identity,omitempty" validate:"required_without=Hostname,omitempty"`
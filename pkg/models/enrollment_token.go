@@ -0,0 +1,59 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// EnrollmentToken is a namespace's token that registers devices already accepted, for provisioning devices without
+// someone accepting each one of them.
+//
+// The devices that present a valid token when they register for the first time receive the token's Tags and, when
+// NameTemplate is set, a name built from it.
+type EnrollmentToken struct {
+	ID       string `json:"id" bson:"_id"`
+	TenantID string `json:"tenant_id" bson:"tenant_id"`
+	// Hash is the SHA256 hash of the token, which is only known when it is created.
+	Hash        string   `json:"-" bson:"hash"`
+	Description string   `json:"description" bson:"description"`
+	Tags        []string `json:"tags" bson:"tags"`
+	// NameTemplate is the name given to the devices, where "{hostname}", "{mac}" and "{uid}" are replaced by the
+	// device's hostname, MAC address and the first characters of its UID. When empty, the hostname is kept.
+	NameTemplate string `json:"name_template,omitempty" bson:"name_template,omitempty"`
+	// MaxUses is the number of devices the token can register. Zero means no limit.
+	MaxUses   int       `json:"max_uses" bson:"max_uses"`
+	Uses      int       `json:"uses" bson:"uses"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
+	CreatedBy string    `json:"created_by" bson:"created_by"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	// Token is the token to configure on the devices, only returned when it is created.
+	Token string `json:"token,omitempty" bson:"-"`
+}
+
+// IsValid checks if the token can register a device at the given time.
+func (t *EnrollmentToken) IsValid(now time.Time) bool {
+	return now.Before(t.ExpiresAt) && (t.MaxUses == 0 || t.Uses < t.MaxUses)
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// DeviceName builds the device's name from the NameTemplate, replacing the characters not allowed in a hostname.
+// It returns an empty string when the token has no NameTemplate.
+func (t *EnrollmentToken) DeviceName(hostname, mac, uid string) string {
+	if t.NameTemplate == "" {
+		return ""
+	}
+
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+
+	name := strings.NewReplacer("{hostname}", hostname, "{mac}", mac, "{uid}", uid).Replace(t.NameTemplate)
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+
+	return strings.Trim(name, "-")
+}
@@ -16,6 +16,16 @@ type Namespace struct {
 	DevicesCount int                `json:"devices_count" bson:"devices_count,omitempty"`
 	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
 	Billing      *Billing           `json:"billing" bson:"billing,omitempty"`
+	// Transfer is the pending transfer of the namespace's ownership, waiting for the new owner's confirmation.
+	Transfer *NamespaceTransfer `json:"transfer,omitempty" bson:"transfer,omitempty"`
+}
+
+// NamespaceTransfer is a transfer of the namespace's ownership started by the owner.
+type NamespaceTransfer struct {
+	// To is the ID of the member that becomes the owner when it accepts the transfer.
+	To        string    `json:"to" bson:"to"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at"`
 }
 
 // HasMaxDevices checks if the namespace has a maximum number of devices.
@@ -33,10 +43,90 @@ func (n *Namespace) HasMaxDevicesReached() bool {
 
 type NamespaceSettings struct {
 	SessionRecord bool `json:"session_record" bson:"session_record,omitempty"`
+	// AccessApprovalTags are the device's tags that require an approved AccessRequest to connect.
+	AccessApprovalTags []string `json:"access_approval_tags,omitempty" bson:"access_approval_tags,omitempty"`
+	// IdleTimeout is the number of minutes without input or output after which a session is closed. Zero disables it.
+	IdleTimeout int `json:"idle_timeout,omitempty" bson:"idle_timeout,omitempty"`
+	// MaxSessionDuration is the number of minutes after which a session is closed, regardless of its activity. Zero
+	// disables it.
+	MaxSessionDuration int `json:"max_session_duration,omitempty" bson:"max_session_duration,omitempty"`
+	// RequireMFA requires every member to log in with multi-factor authentication.
+	RequireMFA bool `json:"require_mfa,omitempty" bson:"require_mfa,omitempty"`
+	// RequireSSHMFA requires a TOTP code, asked as a keyboard-interactive step, to connect to the namespace's devices.
+	RequireSSHMFA bool `json:"require_ssh_mfa,omitempty" bson:"require_ssh_mfa,omitempty"`
+	// RequireEnrollmentToken rejects the registration of new devices without a valid EnrollmentToken.
+	RequireEnrollmentToken bool `json:"require_enrollment_token,omitempty" bson:"require_enrollment_token,omitempty"`
+	// AgentTagsOverwrite lets the tags declared by the agent replace, whenever it authenticates, the device's tags set
+	// through the API. Otherwise, they are only set when the device registers.
+	AgentTagsOverwrite bool `json:"agent_tags_overwrite,omitempty" bson:"agent_tags_overwrite,omitempty"`
+}
+
+// RequiresApproval checks if connections to the device need an approved AccessRequest.
+func (s *NamespaceSettings) RequiresApproval(device *Device) bool {
+	if s == nil || device == nil {
+		return false
+	}
+
+	for _, tag := range s.AccessApprovalTags {
+		for _, t := range device.Tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 type Member struct {
-	ID       string `json:"id,omitempty" bson:"id,omitempty"`
-	Username string `json:"username,omitempty" bson:"username,omitempty" validate:"username"`
-	Role     string `json:"role" bson:"role" validate:"required,oneof=administrator operator observer"`
+	ID       string       `json:"id,omitempty" bson:"id,omitempty"`
+	Username string       `json:"username,omitempty" bson:"username,omitempty" validate:"username"`
+	Role     string       `json:"role" bson:"role" validate:"required,oneof=administrator operator observer"`
+	Scope    *MemberScope `json:"scope,omitempty" bson:"scope,omitempty"`
+}
+
+// MemberScope restricts the devices a member can reach inside a namespace.
+//
+// A device is in the scope when its UID is listed in Devices or when it has, at least, one of the Tags. A nil or empty
+// MemberScope means that the member has access to every device in the namespace.
+//
+// The scope applies to the member's web sessions and to the SSH connections made with the public keys that belong to
+// the member. The SSH connections authenticated by a device's password, or by a public key without member, could be
+// made by any member, so, while a member of the namespace has a scope, the public keys without member are refused and
+// the passwords must be followed by the member's ShellHub username and TOTP code, asked as a keyboard-interactive step.
+type MemberScope struct {
+	Tags    []string `json:"tags,omitempty" bson:"tags,omitempty" validate:"omitempty,unique,dive,min=3,max=255,alphanum,ascii,excludes=/@&:"`
+	Devices []string `json:"devices,omitempty" bson:"devices,omitempty" validate:"omitempty,unique,dive,required"`
+}
+
+// IsEmpty checks if the scope has no restriction.
+func (s *MemberScope) IsEmpty() bool {
+	return s == nil || (len(s.Tags) == 0 && len(s.Devices) == 0)
+}
+
+// Allows checks if the device is reachable inside the scope.
+func (s *MemberScope) Allows(device *Device) bool {
+	if s.IsEmpty() {
+		return true
+	}
+
+	if device == nil {
+		return false
+	}
+
+	for _, uid := range s.Devices {
+		if uid == device.UID {
+			return true
+		}
+	}
+
+	for _, tag := range s.Tags {
+		for _, t := range device.Tags {
+			if t == tag {
+				return true
+			}
+		}
+	}
+
+	return false
 }
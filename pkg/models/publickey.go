@@ -19,6 +19,93 @@ type PublicKeyFields struct {
 	Name     string          `json:"name"`
 	Username string          `json:"username" bson:"username" validate:"regexp"`
 	Filter   PublicKeyFilter `json:"filter" bson:"filter" validate:"required"`
+	// Member is the ID of the namespace's member who the public key belongs to. When set, the connections made with the
+	// public key are subject to the member's role and scope.
+	Member string `json:"member,omitempty" bson:"member"`
+	// Restrictions limits what the sessions authenticated by the public key can do. When nil, there is no limit.
+	Restrictions *PublicKeyRestrictions `json:"restrictions,omitempty" bson:"restrictions"`
+	// RequireMFA requires the member's TOTP code after the public key is verified. The code is asked as a
+	// keyboard-interactive step of the authentication.
+	RequireMFA bool `json:"require_mfa" bson:"require_mfa"`
+	// Automation marks the public key as used by non-interactive clients, skipping the TOTP challenge required by the
+	// namespace.
+	Automation bool `json:"automation" bson:"automation"`
+}
+
+// RequiresMFA checks if the connections authenticated by the public key must answer the TOTP challenge.
+func (f *PublicKeyFields) RequiresMFA(settings *NamespaceSettings) bool {
+	if f.Automation {
+		return false
+	}
+
+	return f.RequireMFA || (settings != nil && settings.RequireSSHMFA)
+}
+
+// Session's types that can be allowed on PublicKeyRestrictions.
+const (
+	PublicKeySessionShell       = "shell"
+	PublicKeySessionExec        = "exec"
+	PublicKeySessionSFTP        = "sftp"
+	PublicKeySessionSCP         = "scp"
+	PublicKeySessionPortForward = "port-forward"
+)
+
+// PublicKeyRestrictions limits what a session authenticated by a public key can do, like the options of the OpenSSH's
+// authorized_keys file.
+type PublicKeyRestrictions struct {
+	// SessionTypes are the session's types allowed to the public key. When empty, all types are allowed.
+	SessionTypes []string `json:"session_types,omitempty" bson:"session_types,omitempty" validate:"omitempty,unique,dive,oneof=shell exec sftp scp port-forward"`
+	// ForceCommand is the command executed on the device, instead of the shell or the requested one. SFTP and SCP
+	// sessions are refused unless listed explicitly on SessionTypes.
+	ForceCommand string `json:"force_command,omitempty" bson:"force_command,omitempty"`
+	// AllowedCommands are regular expressions, where, at least, one must match the whole command of an exec session.
+	// When empty, all commands are allowed.
+	AllowedCommands []string `json:"allowed_commands,omitempty" bson:"allowed_commands,omitempty" validate:"omitempty,dive,required,regexp"`
+}
+
+// AllowsSession checks if the session's type is allowed.
+func (r *PublicKeyRestrictions) AllowsSession(kind string) bool {
+	if r == nil || len(r.SessionTypes) == 0 {
+		return true
+	}
+
+	return r.ListsSession(kind)
+}
+
+// ListsSession checks if the session's type is listed explicitly, what is required to open SFTP and SCP sessions when
+// there is a forced command.
+func (r *PublicKeyRestrictions) ListsSession(kind string) bool {
+	if r == nil {
+		return false
+	}
+
+	for _, t := range r.SessionTypes {
+		if t == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AllowsCommand checks if the command matches, at least, one of the allowed commands.
+func (r *PublicKeyRestrictions) AllowsCommand(command string) bool {
+	if r == nil || len(r.AllowedCommands) == 0 {
+		return true
+	}
+
+	for _, allowed := range r.AllowedCommands {
+		expression, err := regexp.Compile("^(?:" + allowed + ")$")
+		if err != nil {
+			continue
+		}
+
+		if expression.MatchString(command) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (p *PublicKeyFields) Validate() error {
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicKeyRestrictionsAllowsSession(t *testing.T) {
+	cases := []struct {
+		description  string
+		restrictions *PublicKeyRestrictions
+		kind         string
+		expected     bool
+	}{
+		{
+			description:  "allows any session without restrictions",
+			restrictions: nil,
+			kind:         PublicKeySessionSFTP,
+			expected:     true,
+		},
+		{
+			description:  "allows any session when no type is listed",
+			restrictions: &PublicKeyRestrictions{},
+			kind:         PublicKeySessionShell,
+			expected:     true,
+		},
+		{
+			description:  "allows a session of a listed type",
+			restrictions: &PublicKeyRestrictions{SessionTypes: []string{PublicKeySessionShell, PublicKeySessionExec}},
+			kind:         PublicKeySessionExec,
+			expected:     true,
+		},
+		{
+			description:  "refuses a session of a type not listed",
+			restrictions: &PublicKeyRestrictions{SessionTypes: []string{PublicKeySessionShell}},
+			kind:         PublicKeySessionSFTP,
+			expected:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.restrictions.AllowsSession(tc.kind))
+		})
+	}
+}
+
+func TestPublicKeyRestrictionsListsSession(t *testing.T) {
+	assert.False(t, (*PublicKeyRestrictions)(nil).ListsSession(PublicKeySessionSFTP))
+	assert.False(t, (&PublicKeyRestrictions{}).ListsSession(PublicKeySessionSFTP))
+	assert.True(t, (&PublicKeyRestrictions{SessionTypes: []string{PublicKeySessionSFTP}}).ListsSession(PublicKeySessionSFTP))
+}
+
+func TestPublicKeyRestrictionsAllowsCommand(t *testing.T) {
+	cases := []struct {
+		description  string
+		restrictions *PublicKeyRestrictions
+		command      string
+		expected     bool
+	}{
+		{
+			description:  "allows any command without restrictions",
+			restrictions: nil,
+			command:      "rm -rf /",
+			expected:     true,
+		},
+		{
+			description:  "allows a command matching an expression",
+			restrictions: &PublicKeyRestrictions{AllowedCommands: []string{"uptime", "systemctl status [a-z-]+"}},
+			command:      "systemctl status nginx",
+			expected:     true,
+		},
+		{
+			description:  "refuses a command only partially matching an expression",
+			restrictions: &PublicKeyRestrictions{AllowedCommands: []string{"uptime"}},
+			command:      "uptime; rm -rf /",
+			expected:     false,
+		},
+		{
+			description:  "ignores an invalid expression",
+			restrictions: &PublicKeyRestrictions{AllowedCommands: []string{"("}},
+			command:      "(",
+			expected:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.restrictions.AllowsCommand(tc.command))
+		})
+	}
+}
@@ -25,8 +25,22 @@ type Session struct {
 	Type          string          `json:"type" bson:"type"`
 	Term          string          `json:"term" bson:"term"`
 	Position      SessionPosition `json:"position" bson:"position"`
+	// FinishReason is why the session was closed by ShellHub, when it was not closed by the user.
+	FinishReason string `json:"finish_reason,omitempty" bson:"finish_reason,omitempty"`
+	// Member is the ID of the namespace's member the session is bound to, when it was authenticated by the member's
+	// public key or the member identified itself in the second factor challenge.
+	Member string `json:"member,omitempty" bson:"member,omitempty"`
 }
 
+const (
+	// SessionFinishReasonIdle is set when the session was closed for having no activity for longer than the
+	// namespace's idle timeout.
+	SessionFinishReasonIdle = "idle_timeout"
+	// SessionFinishReasonMaxDuration is set when the session was closed for lasting longer than the namespace's
+	// maximum session duration.
+	SessionFinishReasonMaxDuration = "max_duration"
+)
+
 type ActiveSession struct {
 	UID      UID       `json:"uid"`
 	LastSeen time.Time `json:"last_seen" bson:"last_seen"`
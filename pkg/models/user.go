@@ -26,6 +26,43 @@ type User struct {
 	EmailMarketing bool      `json:"email_marketing" bson:"email_marketing"`
 	UserData       `bson:",inline"`
 	UserPassword   `bson:",inline"`
+	MFA            UserMFA `json:"mfa" bson:"mfa,omitempty"`
+	// Identity links the user to an external identity provider's account. It is nil to local users.
+	Identity *UserIdentity `json:"identity,omitempty" bson:"identity,omitempty"`
+}
+
+const (
+	// UserIdentityOIDC is the provider of the users that log in through OpenID Connect.
+	UserIdentityOIDC = "oidc"
+	// UserIdentityLDAP is the provider of the users that log in through a LDAP directory.
+	UserIdentityLDAP = "ldap"
+)
+
+// UserIdentity is the user's account at an external identity provider.
+type UserIdentity struct {
+	// Provider is the kind of identity provider, like UserIdentityOIDC.
+	Provider string `json:"provider" bson:"provider"`
+	// Subject is the unique and stable identifier of the account at the identity provider.
+	Subject string `json:"-" bson:"subject"`
+}
+
+// UserMFA is the user's time-based one-time password configuration.
+type UserMFA struct {
+	// Enabled indicates if a code is required to log in.
+	Enabled bool `json:"enabled" bson:"enabled"`
+	// Secret is the TOTP's secret. It is set on the enrollment, before MFA is enabled.
+	Secret string `json:"-" bson:"secret,omitempty"`
+	// RecoveryCodes are the SHA256 hashes of the codes that can be used, once each, instead of a TOTP code.
+	RecoveryCodes []string `json:"-" bson:"recovery_codes,omitempty"`
+	// LastStep is the time step of the last TOTP code accepted, so a code cannot be used again while it is valid.
+	LastStep uint64 `json:"-" bson:"last_step,omitempty"`
+}
+
+// UserMFAEnrollment is the data used to register the user's TOTP secret on an authenticator app.
+type UserMFAEnrollment struct {
+	Secret        string   `json:"secret"`
+	URI           string   `json:"uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type UserAuthRequest struct {
@@ -41,6 +78,12 @@ type UserAuthResponse struct {
 	Tenant string `json:"tenant"`
 	Role   string `json:"role"`
 	Email  string `json:"email"`
+	// RefreshToken is used to get a new token when it expires. It is rotated on each use.
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// MFAToken, when set, is the token to complete the login with a TOTP or recovery code. In that case, Token is empty.
+	MFAToken string `json:"mfa_token,omitempty"`
+	// MFAEnrollment indicates that the user must enroll MFA before completing the login.
+	MFAEnrollment bool `json:"mfa_enrollment,omitempty"`
 }
 
 type UserAuthClaims struct {
@@ -49,6 +92,34 @@ type UserAuthClaims struct {
 	Tenant   string `json:"tenant"`
 	ID       string `json:"id"`
 	Role     string `json:"role"`
+	// SessionID is the ID of the login that issued the token. The token is rejected when the session is revoked.
+	SessionID string `json:"sid,omitempty"`
+
+	AuthClaims           `mapstruct:",squash"`
+	jwt.RegisteredClaims `mapstruct:",squash"`
+}
+
+// UserSession is a user's login, from where its tokens are refreshed until it expires or is revoked.
+type UserSession struct {
+	ID     string `json:"id" bson:"_id"`
+	UserID string `json:"-" bson:"user_id"`
+	// RefreshToken is the SHA256 hash of the current refresh token.
+	RefreshToken string `json:"-" bson:"refresh_token"`
+	// UserAgent identifies the device used to log in.
+	UserAgent  string    `json:"user_agent" bson:"user_agent"`
+	IP         string    `json:"ip" bson:"ip"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at" bson:"expires_at"`
+	// Current indicates that it is the session of the token used by the request.
+	Current bool `json:"current" bson:"-"`
+}
+
+// UserMFAClaims are the claims of the short-lived token issued when a login waits for its second factor.
+type UserMFAClaims struct {
+	ID string `json:"id"`
+	// Enrollment indicates that the user must enroll MFA before completing the login.
+	Enrollment bool `json:"enrollment"`
 
 	AuthClaims           `mapstruct:",squash"`
 	jwt.RegisteredClaims `mapstruct:",squash"`
@@ -0,0 +1,53 @@
+// Package password hashes and compares the users' passwords.
+//
+// Passwords are hashed with bcrypt, whose encoded format records the algorithm version, the cost and the salt. Hashes
+// created before it, an unsalted SHA256 encoded as hexadecimal, are still accepted by Compare, and NeedsRehash reports
+// them, so they can be replaced on the next successful login.
+package password
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cost is the bcrypt's cost used to hash new passwords.
+const Cost = bcrypt.DefaultCost
+
+// bcryptPrefix is the prefix of every bcrypt's encoded hash.
+const bcryptPrefix = "$2"
+
+// Hash hashes the plain password, returning it encoded with the algorithm and its parameters.
+func Hash(plain string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plain), Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Compare reports whether plain is the password hashed on hash.
+func Compare(plain, hash string) bool {
+	if strings.HasPrefix(hash, bcryptPrefix) {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plain)) == nil
+	}
+
+	digest := sha256.Sum256([]byte(plain))
+
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(digest[:])), []byte(hash)) == 1
+}
+
+// NeedsRehash reports whether hash was not created with the current algorithm and parameters.
+func NeedsRehash(hash string) bool {
+	if !strings.HasPrefix(hash, bcryptPrefix) {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost != Cost
+}
@@ -0,0 +1,89 @@
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHash(t *testing.T) {
+	hash, err := Hash("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "secret", hash)
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	assert.NoError(t, err)
+	assert.Equal(t, Cost, cost)
+
+	other, err := Hash("secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other, "hashes of the same password must be salted")
+}
+
+func TestCompare(t *testing.T) {
+	hash, err := Hash("secret")
+	assert.NoError(t, err)
+
+	legacy := sha256.Sum256([]byte("secret"))
+
+	cases := []struct {
+		description string
+		plain       string
+		hash        string
+		expected    bool
+	}{
+		{
+			description: "matches a bcrypt hash",
+			plain:       "secret",
+			hash:        hash,
+			expected:    true,
+		},
+		{
+			description: "does not match a bcrypt hash of other password",
+			plain:       "wrong",
+			hash:        hash,
+			expected:    false,
+		},
+		{
+			description: "matches a legacy SHA256 hash",
+			plain:       "secret",
+			hash:        hex.EncodeToString(legacy[:]),
+			expected:    true,
+		},
+		{
+			description: "does not match a legacy SHA256 hash of other password",
+			plain:       "wrong",
+			hash:        hex.EncodeToString(legacy[:]),
+			expected:    false,
+		},
+		{
+			description: "does not match an empty hash",
+			plain:       "",
+			hash:        "",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Compare(tc.plain, tc.hash))
+		})
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	hash, err := Hash("secret")
+	assert.NoError(t, err)
+
+	weak, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	assert.NoError(t, err)
+
+	legacy := sha256.Sum256([]byte("secret"))
+
+	assert.False(t, NeedsRehash(hash))
+	assert.True(t, NeedsRehash(string(weak)))
+	assert.True(t, NeedsRehash(hex.EncodeToString(legacy[:])))
+}
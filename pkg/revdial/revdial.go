@@ -55,6 +55,8 @@ type Dialer struct {
 	donec         chan struct{}
 	keepAliveChan chan bool
 	closeOnce     sync.Once
+	// writeMu serializes the control messages, as the pings are answered while serve sends the others.
+	writeMu sync.Mutex
 }
 
 var (
@@ -192,6 +194,10 @@ func (d *Dialer) serve() error {
 				}
 			case "keep-alive":
 				d.keepAliveChan <- true
+			case "ping":
+				if err := d.sendMessage(controlMsg{Command: "pong"}); err != nil {
+					return
+				}
 			default:
 				// Ignore unknown messages
 			}
@@ -223,6 +229,9 @@ func (d *Dialer) serve() error {
 }
 
 func (d *Dialer) sendMessage(m controlMsg) error {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+
 	if err := d.conn.SetWriteDeadline(clock.Now().Add(10 * time.Second)); err != nil {
 		return err
 	}
@@ -249,6 +258,9 @@ func NewListener(serverConn net.Conn, dialServer func(context.Context, string) (
 		dial:  dialServer,
 		connc: make(chan net.Conn, 8), // arbitrary
 		donec: make(chan struct{}),
+		// The write channel is created here, so the messages sent before run starts are not lost.
+		writec: make(chan []byte, 8),
+		pongc:  make(chan struct{}, 1),
 	}
 	go ln.run()
 
@@ -264,7 +276,9 @@ type Listener struct {
 	connc  chan net.Conn
 	donec  chan struct{}
 	dial   func(context.Context, string) (*websocket.Conn, *http.Response, error)
-	writec chan<- []byte
+	writec chan []byte
+	// pongc is notified when the server answers a ping.
+	pongc chan struct{}
 
 	mu      sync.Mutex // guards below, closing connc, and writing to rw
 	readErr error
@@ -272,7 +286,7 @@ type Listener struct {
 }
 
 type controlMsg struct {
-	Command  string `json:"command,omitempty"`  // "keep-alive", "conn-ready", "pickup-failed"
+	Command  string `json:"command,omitempty"`  // "keep-alive", "conn-ready", "pickup-failed", "ping", "pong"
 	ConnPath string `json:"connPath,omitempty"` // conn pick-up URL path for "conn-url", "pickup-failed"
 	Err      string `json:"err,omitempty"`
 }
@@ -283,14 +297,12 @@ func (ln *Listener) run() {
 	defer ln.Close()
 
 	// Write loop
-	writec := make(chan []byte, 8)
-	ln.writec = writec
 	go func() {
 		for {
 			select {
 			case <-ln.donec:
 				return
-			case msg := <-writec:
+			case msg := <-ln.writec:
 				if _, err := ln.sc.Write(msg); err != nil {
 					log.Printf("revdial.Listener: error writing message to server: %v", err)
 					ln.Close()
@@ -319,6 +331,11 @@ func (ln *Listener) run() {
 			case "keep-alive":
 			// Occasional no-op message from server to keep
 			// us alive through NAT timeouts.
+			case "pong":
+				select {
+				case ln.pongc <- struct{}{}:
+				default:
+				}
 			case "conn-ready":
 				go ln.grabConn(msg.ConnPath)
 			default:
@@ -342,6 +359,27 @@ func (ln *Listener) run() {
 	}
 }
 
+// Ping sends a ping message to the server and waits for its answer, checking the round-trip of the control connection.
+// Servers older than the ping message ignore it, so the context's deadline is reached.
+func (ln *Listener) Ping(ctx context.Context) error {
+	// An answer to a previous ping, which was not waited for, is discarded.
+	select {
+	case <-ln.pongc:
+	default:
+	}
+
+	ln.sendMessage(controlMsg{Command: "ping"})
+
+	select {
+	case <-ln.pongc:
+		return nil
+	case <-ln.donec:
+		return errors.New("revdial.Listener closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (ln *Listener) sendMessage(m controlMsg) {
 	j, _ := json.Marshal(m)
 	j = append(j, '\n')
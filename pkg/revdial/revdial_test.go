@@ -0,0 +1,45 @@
+package revdial
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPing(t *testing.T) {
+	server, agent := net.Pipe()
+
+	dialer := NewDialer(server, "/revdial")
+	defer dialer.Close()
+
+	// The dialer blocks until its keep-alive messages are read, as the connection manager does.
+	go func() {
+		for {
+			select {
+			case <-dialer.KeepAlives():
+			case <-dialer.Done():
+				return
+			}
+		}
+	}()
+
+	listener := NewListener(agent, nil)
+	defer listener.Close()
+
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		assert.NoError(t, listener.Ping(ctx))
+		cancel()
+	}
+
+	// The ping fails once the dialer is gone.
+	dialer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.Error(t, listener.Ping(ctx))
+}
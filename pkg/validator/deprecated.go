@@ -1,12 +1,9 @@
 package validator
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"reflect"
 	"regexp"
-	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/shellhub-io/shellhub/pkg/models"
@@ -152,21 +149,6 @@ func ValidateFieldPassword(password string) bool {
 	return ValidateField(s, Field, password)
 }
 
-func HashPassword(password string) string {
-	s := sha256.Sum256([]byte(password))
-
-	return hex.EncodeToString(s[:])
-}
-
-// FormatUser apply some formation rules to a models.User and encrypt the password.
-func FormatUser(user *models.User) {
-	user.Username = strings.ToLower(user.Username)
-	user.Email = strings.ToLower(user.Email)
-	if user.Password != "" {
-		user.Password = HashPassword(user.Password)
-	}
-}
-
 const (
 	// TagRegexp is the tag used to validate a regexp.
 	TagRegexp = "regexp"
@@ -10,6 +10,7 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/labstack/echo/v4"
 	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/connman"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/loglevel"
 	sshTunnel "github.com/shellhub-io/shellhub/ssh/pkg/tunnel"
@@ -89,6 +90,17 @@ func main() {
 		return c.NoContent(http.StatusOK)
 	})
 
+	// The API drops the tunnel of a device whose key was revoked, so it cannot be reached until it authenticates again.
+	router.POST("/internal/devices/:uid/disconnect", func(c echo.Context) error {
+		if err := tunnel.Close(c.Param("uid")); err != nil && !errors.Is(err, connman.ErrNoConnection) {
+			log.WithError(err).WithField("device", c.Param("uid")).Error("failed to disconnect the device")
+
+			return c.JSON(http.StatusInternalServerError, err.Error())
+		}
+
+		return c.NoContent(http.StatusOK)
+	})
+
 	router.Any("/ssh/http", func(c echo.Context) error {
 		replyError := func(err error, msg string, code int) error {
 			log.WithError(err).WithFields(log.Fields{
@@ -140,6 +152,8 @@ func main() {
 		web.HandlerCreateSession(web.CreateSession)(res, req)
 	})))
 
+	router.GET("/ws/ssh/shadow", echo.WrapHandler(handler.ShadowHandler(tunnel.API)))
+
 	router.GET("/healthcheck", func(c echo.Context) error {
 		return c.String(http.StatusOK, "OK")
 	})
@@ -26,6 +26,10 @@ const (
 	agent = "agent"
 	// established is the key to store and restore the established state from the context.
 	established = "established"
+	// publicKey is the key to store and restore the public key used to authenticate from the context.
+	publicKey = "public_key_model"
+	// member is the key to store and restore the ID of the member identified by the keyboard-interactive step.
+	member = "member"
 )
 
 const (
@@ -112,3 +112,24 @@ func RestoreEstablished(ctx gliderssh.Context) bool {
 
 	return value.(bool)
 }
+
+// RestorePublicKey restores the public key used to authenticate from context as metadata.
+func RestorePublicKey(ctx gliderssh.Context) *models.PublicKey {
+	value := restore(ctx, publicKey)
+	if value == nil {
+		return nil
+	}
+
+	return value.(*models.PublicKey)
+}
+
+// RestoreMember restores the ID of the namespace's member who identified itself from context as metadata. It is empty
+// when no member was identified.
+func RestoreMember(ctx gliderssh.Context) string {
+	value := restore(ctx, member)
+	if value == nil {
+		return ""
+	}
+
+	return value.(string)
+}
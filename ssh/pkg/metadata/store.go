@@ -53,6 +53,16 @@ func MaybeStoreFingerprint(ctx gliderssh.Context, value string) string {
 	return maybeStore(ctx, fingerprint, value).(string)
 }
 
+// StorePublicKey stores the public key used to authenticate in the context as metadata.
+func StorePublicKey(ctx gliderssh.Context, value *models.PublicKey) {
+	store(ctx, publicKey, value)
+}
+
+// StoreMember stores the ID of the namespace's member who identified itself in the context as metadata.
+func StoreMember(ctx gliderssh.Context, value string) {
+	store(ctx, member, value)
+}
+
 // MaybeStoreTarget stores the target in the context as metadata if is not set yet.
 func MaybeStoreTarget(ctx gliderssh.Context, sshid string) (*target.Target, error) {
 	value, err := target.NewTarget(sshid)
@@ -0,0 +1,155 @@
+// Package shadow fans out the output of live terminal sessions to watchers.
+//
+// Sessions are kept in memory, so a watcher must reach the same SSH server instance that holds the session.
+package shadow
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrNotFound is returned when the session is not live on this server.
+var ErrNotFound = errors.New("session not found")
+
+// buffer is the number of output chunks kept for each watcher before new chunks are dropped.
+const buffer = 256
+
+var (
+	mu       sync.RWMutex
+	sessions = make(map[string]*Session)
+)
+
+// Session is a live terminal session that can be watched.
+type Session struct {
+	mu       sync.Mutex
+	input    io.Writer
+	watchers map[*Watcher]struct{}
+	onWatch  func(takeover bool)
+}
+
+// Watcher receives the output of a Session.
+type Watcher struct {
+	// Output receives each chunk written to the Session. It is closed when the Session ends or the watcher leaves.
+	Output   chan []byte
+	session  *Session
+	takeover bool
+	once     sync.Once
+}
+
+// Register makes the session identified by uid watchable. Input is where a watcher's keystrokes are written when it
+// takes over the session and onWatch, when not nil, is called each time a watcher joins.
+func Register(uid string, input io.Writer, onWatch func(takeover bool)) *Session {
+	session := &Session{
+		input:    input,
+		watchers: make(map[*Watcher]struct{}),
+		onWatch:  onWatch,
+	}
+
+	mu.Lock()
+	sessions[uid] = session
+	mu.Unlock()
+
+	return session
+}
+
+// Unregister removes the session identified by uid, closing its watchers.
+func Unregister(uid string) {
+	mu.Lock()
+	session, ok := sessions[uid]
+	delete(sessions, uid)
+	mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	for watcher := range session.watchers {
+		watcher.close()
+	}
+
+	session.watchers = nil
+}
+
+// Watch attaches a new watcher to the session identified by uid. When takeover is true, the watcher can also write to
+// the session's input.
+func Watch(uid string, takeover bool) (*Watcher, error) {
+	mu.RLock()
+	session, ok := sessions[uid]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	watcher := &Watcher{
+		Output:   make(chan []byte, buffer),
+		session:  session,
+		takeover: takeover,
+	}
+
+	session.mu.Lock()
+	if session.watchers == nil {
+		session.mu.Unlock()
+
+		return nil, ErrNotFound
+	}
+
+	session.watchers[watcher] = struct{}{}
+	session.mu.Unlock()
+
+	if session.onWatch != nil {
+		session.onWatch(takeover)
+	}
+
+	return watcher, nil
+}
+
+// Write sends a copy of data to every watcher. It never blocks: a slow watcher loses the chunks that do not fit in its
+// buffer.
+func (s *Session) Write(data []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for watcher := range s.watchers {
+		chunk := make([]byte, len(data))
+		copy(chunk, data)
+
+		select {
+		case watcher.Output <- chunk:
+		default:
+		}
+	}
+
+	return len(data), nil
+}
+
+// Write sends data to the session's input. It fails when the watcher has not taken over the session.
+func (w *Watcher) Write(data []byte) (int, error) {
+	if !w.takeover {
+		return 0, errors.New("watcher is read-only")
+	}
+
+	return w.session.input.Write(data)
+}
+
+// Close detaches the watcher from its session.
+func (w *Watcher) Close() {
+	w.session.mu.Lock()
+	defer w.session.mu.Unlock()
+
+	if w.session.watchers != nil {
+		delete(w.session.watchers, w)
+	}
+
+	w.close()
+}
+
+func (w *Watcher) close() {
+	w.once.Do(func() {
+		close(w.Output)
+	})
+}
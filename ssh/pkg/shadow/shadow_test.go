@@ -0,0 +1,111 @@
+package shadow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	_, err := Watch("unknown", false)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	var input bytes.Buffer
+	var watched []bool
+
+	session := Register("uid", &input, func(takeover bool) {
+		watched = append(watched, takeover)
+	})
+	defer Unregister("uid")
+
+	viewer, err := Watch("uid", false)
+	assert.NoError(t, err)
+
+	owner, err := Watch("uid", true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []bool{false, true}, watched)
+
+	// Each watcher receives its own copy of the output.
+	data := []byte("output")
+	n, err := session.Write(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), n)
+
+	data[0] = 'O'
+	assert.Equal(t, []byte("output"), <-viewer.Output)
+	assert.Equal(t, []byte("output"), <-owner.Output)
+
+	// Only the watcher that took over the session writes to its input.
+	_, err = viewer.Write([]byte("ls\n"))
+	assert.Error(t, err)
+
+	_, err = owner.Write([]byte("ls\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ls\n", input.String())
+}
+
+func TestWatcherClose(t *testing.T) {
+	session := Register("uid", &bytes.Buffer{}, nil)
+	defer Unregister("uid")
+
+	watcher, err := Watch("uid", false)
+	assert.NoError(t, err)
+
+	watcher.Close()
+
+	_, ok := <-watcher.Output
+	assert.False(t, ok)
+
+	// The detached watcher does not receive the output anymore, and it can be closed again.
+	_, err = session.Write([]byte("output"))
+	assert.NoError(t, err)
+
+	assert.NotPanics(t, watcher.Close)
+}
+
+func TestSlowWatcher(t *testing.T) {
+	session := Register("uid", &bytes.Buffer{}, nil)
+	defer Unregister("uid")
+
+	slow, err := Watch("uid", false)
+	assert.NoError(t, err)
+
+	// The session is never blocked by a watcher that does not read, which loses the chunks beyond its buffer.
+	for i := 0; i < buffer+10; i++ {
+		_, err := session.Write([]byte{byte(i)})
+		assert.NoError(t, err)
+	}
+
+	assert.Len(t, slow.Output, buffer)
+	for i := 0; i < buffer; i++ {
+		assert.Equal(t, []byte{byte(i)}, <-slow.Output)
+	}
+
+	// Once there is room in the buffer again, the new chunks are received.
+	_, err = session.Write([]byte("next"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("next"), <-slow.Output)
+}
+
+func TestUnregister(t *testing.T) {
+	Register("uid", &bytes.Buffer{}, nil)
+
+	watcher, err := Watch("uid", false)
+	assert.NoError(t, err)
+
+	Unregister("uid")
+
+	// The watchers are closed when the session ends.
+	_, ok := <-watcher.Output
+	assert.False(t, ok)
+
+	assert.NotPanics(t, watcher.Close)
+
+	_, err = Watch("uid", false)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Unregistering a session that is not live is harmless.
+	assert.NotPanics(t, func() { Unregister("uid") })
+}
@@ -52,3 +52,8 @@ func (t *Tunnel) GetRouter() *echo.Echo {
 func (t *Tunnel) Dial(ctx context.Context, id string) (net.Conn, error) {
 	return t.Tunnel.Dial(ctx, id)
 }
+
+// Close drops the device's tunnel, closing its sessions.
+func (t *Tunnel) Close(id string) error {
+	return t.Tunnel.Close(id)
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+
+	gliderssh "github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	ErrNoClientAuth     = errors.New("client authentication is required")
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// ServerConfig creates the SSH configuration of each connection, setting the authentication callbacks instead of the
+// gliderssh.Server's handlers, as those cannot ask for a second authentication method after the public key or the
+// password was accepted.
+func ServerConfig(ctx gliderssh.Context) *gossh.ServerConfig {
+	return &gossh.ServerConfig{ // nolint: exhaustruct
+		// The gliderssh.Server enables the "none" method when it has no authentication handler, so it is refused here.
+		NoClientAuthCallback: func(gossh.ConnMetadata) (*gossh.Permissions, error) {
+			return nil, ErrNoClientAuth
+		},
+		PasswordCallback: func(conn gossh.ConnMetadata, password []byte) (*gossh.Permissions, error) {
+			applyConnMetadata(ctx, conn)
+
+			if !PasswordHandler(ctx, string(password)) {
+				return nil, ErrPermissionDenied
+			}
+
+			return ctx.Permissions().Permissions, SecondFactor(ctx)
+		},
+		PublicKeyCallback: func(conn gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			applyConnMetadata(ctx, conn)
+
+			if !PublicKeyHandler(ctx, key) {
+				return nil, ErrPermissionDenied
+			}
+
+			ctx.SetValue(gliderssh.ContextKeyPublicKey, key)
+
+			return ctx.Permissions().Permissions, SecondFactor(ctx)
+		},
+	}
+}
+
+// applyConnMetadata stores the connection's metadata in the context, as the gliderssh.Server does before calling its
+// authentication handlers.
+func applyConnMetadata(ctx gliderssh.Context, conn gossh.ConnMetadata) {
+	if ctx.Value(gliderssh.ContextKeySessionID) != nil {
+		return
+	}
+
+	ctx.SetValue(gliderssh.ContextKeySessionID, hex.EncodeToString(conn.SessionID()))
+	ctx.SetValue(gliderssh.ContextKeyClientVersion, string(conn.ClientVersion()))
+	ctx.SetValue(gliderssh.ContextKeyServerVersion, string(conn.ServerVersion()))
+	ctx.SetValue(gliderssh.ContextKeyUser, conn.User())
+	ctx.SetValue(gliderssh.ContextKeyLocalAddr, conn.LocalAddr())
+	ctx.SetValue(gliderssh.ContextKeyRemoteAddr, conn.RemoteAddr())
+}
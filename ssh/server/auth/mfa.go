@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"errors"
+
+	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/metadata"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// MFAAttempts is the number of verification codes that the client can send in each keyboard-interactive attempt.
+const MFAAttempts = 3
+
+var (
+	ErrMFAEval    = errors.New("failed to evaluate the verification code")
+	ErrMFAInvalid = errors.New("you cannot connect to this device without a valid verification code")
+	ErrMFABlocked = errors.New("too many invalid verification codes; try again later")
+	ErrMFAMember  = errors.New("this public key must belong to a member with MFA enabled to connect to this device")
+	// ErrMemberEval is returned when the member's access to the device cannot be evaluated.
+	ErrMemberEval = errors.New("failed to evaluate the member's access to this device")
+	// ErrMemberScope is returned when the identified member cannot connect to the device, as it is out of its scope.
+	ErrMemberScope = errors.New("you cannot connect to this device because it is out of your scope in the namespace")
+)
+
+// SecondFactor checks if the connection, already authenticated by a public key or a password, must answer the TOTP
+// challenge of a ShellHub user. When it must, a partial success is returned with the keyboard-interactive step that
+// asks for the code; otherwise, it returns nil and the connection is authenticated.
+//
+// Connections authenticated by a public key are challenged with the code of the key's member, whose scope was already
+// checked with the key. The others are not bound to any member, so, besides the namespace's MFA requirement, they are
+// also challenged when a member of the namespace has a scope: the ShellHub username and its code identify the member,
+// whose scope must allow the device.
+func SecondFactor(ctx gliderssh.Context) error {
+	api := metadata.RestoreAPI(ctx)
+	device := metadata.RestoreDevice(ctx)
+
+	settings, err := api.GetNamespaceSettings(device.TenantID)
+	if err != nil {
+		return &gossh.BannerError{Err: ErrMFAEval, Message: ErrMFAEval.Error() + "\r\n"}
+	}
+
+	// The magic key, used by the web terminal, has no stored public key and is challenged as a password.
+	if key := metadata.RestorePublicKey(ctx); metadata.RestoreAuthenticationMethod(ctx) == metadata.PublicKeyAuthenticationMethod && key != nil {
+		if !key.RequiresMFA(settings) {
+			return nil
+		}
+
+		if key.Member == "" {
+			return &gossh.BannerError{Err: ErrMFAMember, Message: ErrMFAMember.Error() + "\r\n"}
+		}
+
+		return &gossh.PartialSuccessError{
+			Next: gossh.ServerAuthCallbacks{ // nolint: exhaustruct
+				KeyboardInteractiveCallback: challengeMFA(ctx, api, device, key.Member),
+			},
+		}
+	}
+
+	allowed, err := api.EvaluateMember(device.TenantID, "", device.UID)
+	if err != nil {
+		return &gossh.BannerError{Err: ErrMemberEval, Message: ErrMemberEval.Error() + "\r\n"}
+	}
+
+	if allowed && !requiresMFA(settings) {
+		return nil
+	}
+
+	return &gossh.PartialSuccessError{
+		Next: gossh.ServerAuthCallbacks{ // nolint: exhaustruct
+			KeyboardInteractiveCallback: challengeMFA(ctx, api, device, ""),
+		},
+	}
+}
+
+func requiresMFA(settings *models.NamespaceSettings) bool {
+	return settings != nil && settings.RequireSSHMFA
+}
+
+// challengeMFA creates the keyboard-interactive callback that asks for the TOTP code of the member, or, when member is
+// empty, for the ShellHub username and its code. A member identified by its username is stored in the context and
+// must be allowed to connect to the device.
+func challengeMFA(ctx gliderssh.Context, api internalclient.Client, device *models.Device, member string) func(gossh.ConnMetadata, gossh.KeyboardInteractiveChallenge) (*gossh.Permissions, error) {
+	return func(_ gossh.ConnMetadata, challenge gossh.KeyboardInteractiveChallenge) (*gossh.Permissions, error) {
+		var username, instruction string
+
+		for i := 0; i < MFAAttempts; i++ {
+			questions, echos := []string{"Verification code: "}, []bool{false}
+			if member == "" && username == "" {
+				questions, echos = append([]string{"ShellHub username: "}, questions...), append([]bool{true}, echos...)
+			}
+
+			answers, err := challenge("", instruction, questions, echos)
+			if err != nil || len(answers) != len(questions) {
+				return nil, ErrMFAInvalid
+			}
+
+			if len(answers) > 1 {
+				username = answers[0]
+			}
+
+			id, err := api.ValidateMFA(device.TenantID, member, username, answers[len(answers)-1])
+			switch {
+			case errors.Is(err, internalclient.ErrTooManyRequests):
+				return nil, &gossh.BannerError{Err: ErrMFABlocked, Message: ErrMFABlocked.Error() + "\r\n"}
+			case err != nil:
+				return nil, &gossh.BannerError{Err: ErrMFAEval, Message: ErrMFAEval.Error() + "\r\n"}
+			}
+
+			if id == "" {
+				instruction = "Invalid verification code"
+
+				continue
+			}
+
+			if member == "" {
+				allowed, err := api.EvaluateMember(device.TenantID, id, device.UID)
+				if err != nil {
+					return nil, &gossh.BannerError{Err: ErrMemberEval, Message: ErrMemberEval.Error() + "\r\n"}
+				}
+
+				if !allowed {
+					return nil, &gossh.BannerError{Err: ErrMemberScope, Message: ErrMemberScope.Error() + "\r\n"}
+				}
+
+				metadata.StoreMember(ctx, id)
+			}
+
+			return ctx.Permissions().Permissions, nil
+		}
+
+		return nil, &gossh.BannerError{Err: ErrMFAInvalid, Message: ErrMFAInvalid.Error() + "\r\n"}
+	}
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	internalclientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/pkg/metadata"
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// testContext is a gliderssh.Context for the authentication callbacks, which only use its values and permissions.
+type testContext struct {
+	context.Context
+	sync.Mutex
+	permissions *gliderssh.Permissions
+}
+
+func (c *testContext) User() string                        { return "" }
+func (c *testContext) SessionID() string                   { return "" }
+func (c *testContext) ClientVersion() string               { return "" }
+func (c *testContext) ServerVersion() string               { return "" }
+func (c *testContext) RemoteAddr() net.Addr                { return nil }
+func (c *testContext) LocalAddr() net.Addr                 { return nil }
+func (c *testContext) Permissions() *gliderssh.Permissions { return c.permissions }
+
+func (c *testContext) SetValue(key, value interface{}) {
+	c.Context = context.WithValue(c.Context, key, value)
+}
+
+// emptyContext creates the context of a connection without metadata.
+func emptyContext() *testContext {
+	return &testContext{
+		Context:     context.Background(),
+		permissions: &gliderssh.Permissions{Permissions: &gossh.Permissions{}},
+	}
+}
+
+// newContext creates the context of a connection authenticated to the device returned by the API's lookup.
+func newContext(api internalclient.Client, method metadata.AuthenticationMethod, key *models.PublicKey) gliderssh.Context {
+	ctx := emptyContext()
+
+	metadata.MaybeSetAPI(ctx, api)
+	metadata.MaybeStoreDevice(ctx, nil, api) // nolint:errcheck
+	metadata.StoreAuthenticationMethod(ctx, method)
+
+	if key != nil {
+		metadata.StorePublicKey(ctx, key)
+	}
+
+	return ctx
+}
+
+// answer returns a keyboard-interactive challenge that answers with each group of answers in order, recording the
+// questions and instructions it received.
+func answer(questions *[][]string, instructions *[]string, answers ...[]string) gossh.KeyboardInteractiveChallenge {
+	return func(_, instruction string, asked []string, _ []bool) ([]string, error) {
+		*questions = append(*questions, asked)
+		*instructions = append(*instructions, instruction)
+
+		if len(answers) == 0 {
+			return nil, errors.New("no answers")
+		}
+
+		next := answers[0]
+		answers = answers[1:]
+
+		return next, nil
+	}
+}
+
+func TestSecondFactor(t *testing.T) {
+	device := &models.Device{UID: "uid", TenantID: "tenant"}
+	required := &models.NamespaceSettings{RequireSSHMFA: true}
+	allowed, refused := true, false
+
+	cases := []struct {
+		description string
+		method      metadata.AuthenticationMethod
+		key         *models.PublicKey
+		settings    *models.NamespaceSettings
+		// unbound is the evaluation of a connection without member, done for the ones not authenticated by a stored
+		// public key. It is not done when nil.
+		unbound    *bool
+		unboundErr error
+		err        error
+		challenged bool
+	}{
+		{
+			description: "accepts a password when the namespace does not require MFA",
+			method:      metadata.PasswordAuthenticationMethod,
+			settings:    &models.NamespaceSettings{},
+			unbound:     &allowed,
+		},
+		{
+			description: "challenges a password when the namespace requires MFA",
+			method:      metadata.PasswordAuthenticationMethod,
+			settings:    required,
+			unbound:     &allowed,
+			challenged:  true,
+		},
+		{
+			description: "challenges a password when a member of the namespace has a scope",
+			method:      metadata.PasswordAuthenticationMethod,
+			settings:    &models.NamespaceSettings{},
+			unbound:     &refused,
+			challenged:  true,
+		},
+		{
+			description: "refuses a password when the member's access cannot be evaluated",
+			method:      metadata.PasswordAuthenticationMethod,
+			settings:    &models.NamespaceSettings{},
+			unbound:     &refused,
+			unboundErr:  errors.New("error"),
+			err:         ErrMemberEval,
+		},
+		{
+			description: "accepts an automation key when the namespace requires MFA",
+			method:      metadata.PublicKeyAuthenticationMethod,
+			key:         &models.PublicKey{PublicKeyFields: models.PublicKeyFields{Member: "member", Automation: true}},
+			settings:    required,
+		},
+		{
+			description: "challenges a public key that requires MFA",
+			method:      metadata.PublicKeyAuthenticationMethod,
+			key:         &models.PublicKey{PublicKeyFields: models.PublicKeyFields{Member: "member", RequireMFA: true}},
+			settings:    &models.NamespaceSettings{},
+			challenged:  true,
+		},
+		{
+			description: "refuses a public key without member that requires MFA",
+			method:      metadata.PublicKeyAuthenticationMethod,
+			key:         &models.PublicKey{PublicKeyFields: models.PublicKeyFields{RequireMFA: true}},
+			settings:    &models.NamespaceSettings{},
+			err:         ErrMFAMember,
+		},
+		{
+			description: "challenges the magic key when the namespace requires MFA",
+			method:      metadata.PublicKeyAuthenticationMethod,
+			settings:    required,
+			unbound:     &allowed,
+			challenged:  true,
+		},
+		{
+			description: "challenges the magic key when a member of the namespace has a scope",
+			method:      metadata.PublicKeyAuthenticationMethod,
+			settings:    &models.NamespaceSettings{},
+			unbound:     &refused,
+			challenged:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			api := new(internalclientmocks.Client)
+			api.On("DeviceLookup", map[string]string(nil)).Return(device, nil).Once()
+			api.On("GetNamespaceSettings", "tenant").Return(tc.settings, nil).Once()
+			if tc.unbound != nil {
+				api.On("EvaluateMember", "tenant", "", "uid").Return(*tc.unbound, tc.unboundErr).Once()
+			}
+
+			err := SecondFactor(newContext(api, tc.method, tc.key))
+
+			var partial *gossh.PartialSuccessError
+			assert.Equal(t, tc.challenged, errors.As(err, &partial))
+
+			if !tc.challenged {
+				if tc.err != nil {
+					assert.ErrorIs(t, err, tc.err)
+				} else {
+					assert.NoError(t, err)
+				}
+			} else {
+				assert.NotNil(t, partial.Next.KeyboardInteractiveCallback)
+				assert.Nil(t, partial.Next.PasswordCallback)
+				assert.Nil(t, partial.Next.PublicKeyCallback)
+			}
+
+			api.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChallengeMFA(t *testing.T) {
+	device := &models.Device{UID: "uid", TenantID: "tenant"}
+
+	t.Run("asks the member's code until it is valid", func(t *testing.T) {
+		api := new(internalclientmocks.Client)
+		api.On("ValidateMFA", "tenant", "member", "", "000000").Return("", nil).Once()
+		api.On("ValidateMFA", "tenant", "member", "", "123456").Return("member", nil).Once()
+
+		var questions [][]string
+		var instructions []string
+
+		perms, err := challengeMFA(emptyContext(), api, device, "member")(nil, answer(&questions, &instructions, []string{"000000"}, []string{"123456"}))
+		assert.NoError(t, err)
+		assert.NotNil(t, perms)
+
+		assert.Equal(t, [][]string{{"Verification code: "}, {"Verification code: "}}, questions)
+		assert.Equal(t, []string{"", "Invalid verification code"}, instructions)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("asks the username once when the member is unknown", func(t *testing.T) {
+		api := new(internalclientmocks.Client)
+		api.On("ValidateMFA", "tenant", "", "john", "000000").Return("", nil).Once()
+		api.On("ValidateMFA", "tenant", "", "john", "123456").Return("id", nil).Once()
+		api.On("EvaluateMember", "tenant", "id", "uid").Return(true, nil).Once()
+
+		var questions [][]string
+		var instructions []string
+
+		ctx := emptyContext()
+
+		_, err := challengeMFA(ctx, api, device, "")(nil, answer(&questions, &instructions, []string{"john", "000000"}, []string{"123456"}))
+		assert.NoError(t, err)
+
+		assert.Equal(t, [][]string{{"ShellHub username: ", "Verification code: "}, {"Verification code: "}}, questions)
+		assert.Equal(t, "id", metadata.RestoreMember(ctx))
+		api.AssertExpectations(t)
+	})
+
+	t.Run("refuses the identified member when the device is out of its scope", func(t *testing.T) {
+		api := new(internalclientmocks.Client)
+		api.On("ValidateMFA", "tenant", "", "john", "123456").Return("id", nil).Once()
+		api.On("EvaluateMember", "tenant", "id", "uid").Return(false, nil).Once()
+
+		var questions [][]string
+		var instructions []string
+
+		ctx := emptyContext()
+
+		_, err := challengeMFA(ctx, api, device, "")(nil, answer(&questions, &instructions, []string{"john", "123456"}))
+		assert.ErrorIs(t, err, ErrMemberScope)
+		assert.Empty(t, metadata.RestoreMember(ctx))
+		api.AssertExpectations(t)
+	})
+
+	t.Run("refuses after the attempts", func(t *testing.T) {
+		api := new(internalclientmocks.Client)
+		api.On("ValidateMFA", "tenant", "member", "", "000000").Return("", nil).Times(MFAAttempts)
+
+		var questions [][]string
+		var instructions []string
+
+		_, err := challengeMFA(emptyContext(), api, device, "member")(nil, answer(&questions, &instructions, []string{"000000"}, []string{"000000"}, []string{"000000"}, []string{"123456"}))
+		assert.ErrorIs(t, err, ErrMFAInvalid)
+
+		assert.Len(t, questions, MFAAttempts)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("refuses when the member is blocked", func(t *testing.T) {
+		api := new(internalclientmocks.Client)
+		api.On("ValidateMFA", "tenant", "member", "", "123456").Return("", internalclient.ErrTooManyRequests).Once()
+
+		var questions [][]string
+		var instructions []string
+
+		_, err := challengeMFA(emptyContext(), api, device, "member")(nil, answer(&questions, &instructions, []string{"123456"}))
+		assert.ErrorIs(t, err, ErrMFABlocked)
+
+		var banner *gossh.BannerError
+		assert.ErrorAs(t, err, &banner)
+		api.AssertExpectations(t)
+	})
+
+	t.Run("refuses when the client does not answer", func(t *testing.T) {
+		var questions [][]string
+		var instructions []string
+
+		_, err := challengeMFA(emptyContext(), new(internalclientmocks.Client), device, "member")(nil, answer(&questions, &instructions))
+		assert.ErrorIs(t, err, ErrMFAInvalid)
+	})
+}
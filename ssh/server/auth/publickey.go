@@ -47,13 +47,16 @@ func PublicKeyHandler(ctx gliderssh.Context, publicKey gliderssh.PublicKey) bool
 	}
 
 	if gossh.FingerprintLegacyMD5(magic) != fingerprint {
-		if _, err = api.GetPublicKey(fingerprint, device.TenantID); err != nil {
+		key, err := api.GetPublicKey(fingerprint, device.TenantID)
+		if err != nil {
 			return false
 		}
 
 		if ok, err := api.EvaluateKey(fingerprint, device, tag.Username); !ok || err != nil {
 			return false
 		}
+
+		metadata.StorePublicKey(ctx, key)
 	}
 
 	metadata.StoreAuthenticationMethod(ctx, metadata.PublicKeyAuthenticationMethod)
@@ -40,7 +40,7 @@ func SFTPSubsystemHandler(tunnel *httptunnel.Tunnel) gliderssh.SubsystemHandler
 			return
 		}
 
-		defer sess.Finish() // nolint:errcheck
+		defer sess.Finish(api) // nolint:errcheck
 
 		config := &gossh.ClientConfig{ // nolint: exhaustruct
 			User:            sess.Username,
@@ -104,6 +104,11 @@ func connectSFTP(ctx context.Context, client gliderssh.Session, sess *session.Se
 
 	defer agent.Close()
 
+	policy, err := sessionPolicy(api, sess, metadata.RestoreDevice(ctx.(gliderssh.Context)))
+	if err != nil {
+		return err
+	}
+
 	if err = agent.RequestSubsystem(SFTPSubsystem); err != nil {
 		return err
 	}
@@ -114,6 +119,11 @@ func connectSFTP(ctx context.Context, client gliderssh.Session, sess *session.Se
 		return errs[0]
 	}
 
+	// Like the other sessions, the SFTP's one is closed when it exceeds the namespace's idle timeout or maximum
+	// duration.
+	activity := session.NewActivity(client)
+	defer sess.Enforce(policy, activity, agent.Close)()
+
 	flw, err := flow.NewFlow(agent)
 	if err != nil {
 		return err
@@ -121,9 +131,9 @@ func connectSFTP(ctx context.Context, client gliderssh.Session, sess *session.Se
 
 	done := make(chan bool)
 
-	go flw.PipeIn(client, done)
-	go flw.PipeOut(client, done)
-	go flw.PipeErr(client, done)
+	go flw.PipeIn(activity, done)
+	go flw.PipeOut(activity, done)
+	go flw.PipeErr(activity, done)
 
 	<-done
 	<-done
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shellhub-io/shellhub/pkg/api/internalclient"
+	"github.com/shellhub-io/shellhub/ssh/pkg/shadow"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/websocket"
+)
+
+var (
+	ErrShadowForbidden = errors.New("you are not allowed to watch this session")
+	ErrShadowNotFound  = errors.New("session is not active")
+	ErrShadowEvaluate  = errors.New("failed to evaluate the session")
+)
+
+// ShadowHandler handles a websocket connection to watch a live terminal session.
+//
+// The session's UID is received on the "session" query parameter and, when "takeover" is "true", what is sent through
+// the websocket is written to the session's input. The member is identified by the X-Tenant-ID, X-ID and X-Role
+// headers, set on the gateway, and must have the permission to watch sessions.
+func ShadowHandler(api internalclient.Client) websocket.Handler {
+	return func(socket *websocket.Conn) {
+		defer socket.Close()
+
+		request := socket.Request()
+
+		uid := request.URL.Query().Get("session")
+		takeover := request.URL.Query().Get("takeover") == "true"
+
+		fail := func(internal, external error) {
+			log.WithError(internal).WithFields(log.Fields{
+				"session":  uid,
+				"takeover": takeover,
+			}).Error("failed to watch the session")
+
+			socket.Write([]byte(fmt.Sprintf("%s\n", external.Error()))) //nolint: errcheck
+		}
+
+		if _, err := api.ShadowSession(uid, request.Header.Get("X-Tenant-ID"), request.Header.Get("X-ID"), request.Header.Get("X-Role")); err != nil {
+			switch {
+			case errors.Is(err, internalclient.ErrForbidden):
+				fail(err, ErrShadowForbidden)
+			case errors.Is(err, internalclient.ErrNotFound):
+				fail(err, ErrShadowNotFound)
+			default:
+				fail(err, ErrShadowEvaluate)
+			}
+
+			return
+		}
+
+		watcher, err := shadow.Watch(uid, takeover)
+		if err != nil {
+			fail(err, ErrShadowNotFound)
+
+			return
+		}
+
+		defer watcher.Close()
+
+		log.WithFields(log.Fields{
+			"session":  uid,
+			"takeover": takeover,
+			"member":   request.Header.Get("X-ID"),
+		}).Info("started to watch the session")
+
+		go func() {
+			var input io.Writer = io.Discard
+			if takeover {
+				input = watcher
+			}
+
+			// When the websocket is closed by the watcher, it leaves the session.
+			io.Copy(input, socket) //nolint:errcheck
+			watcher.Close()
+		}()
+
+		for chunk := range watcher.Output {
+			if _, err := socket.Write(chunk); err != nil {
+				break
+			}
+		}
+	}
+}
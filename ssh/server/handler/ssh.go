@@ -20,6 +20,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/pkg/flow"
 	"github.com/shellhub-io/shellhub/ssh/pkg/metadata"
+	"github.com/shellhub-io/shellhub/ssh/pkg/shadow"
 	"github.com/shellhub-io/shellhub/ssh/session"
 	log "github.com/sirupsen/logrus"
 	gossh "golang.org/x/crypto/ssh"
@@ -55,6 +56,7 @@ var (
 	ErrShell              = fmt.Errorf("failed to get the shell to agent")
 	ErrTarget             = fmt.Errorf("failed to get client target")
 	ErrAuthentication     = fmt.Errorf("failed to authenticate to device")
+	ErrSessionPolicy      = fmt.Errorf("failed to get the namespace's session policy")
 )
 
 // sendAndInformError sends the external error to client and log the internal one to server.
@@ -66,6 +68,8 @@ func sendAndInformError(client io.Writer, internal, external error) {
 
 type ConfigOptions struct {
 	RecordURL string `envconfig:"record_url"`
+	// ShadowNotify defines if the user is informed when someone starts to watch the session.
+	ShadowNotify bool `envconfig:"shadow_notify" default:"true"`
 }
 
 // SSHHandler handlers a "normal" SSH connection.
@@ -91,7 +95,7 @@ func SSHHandler(tunnel *httptunnel.Tunnel) gliderssh.Handler {
 			return
 		}
 
-		defer sess.Finish() // nolint: errcheck
+		defer sess.Finish(api) // nolint: errcheck
 
 		if wh := webhook.NewClient(); wh != nil {
 			res, err := wh.Connect(sess.Lookup)
@@ -187,21 +191,38 @@ func connectSSH(ctx context.Context, client gliderssh.Session, sess *session.Ses
 
 	metadata.MaybeStoreEstablished(ctx.(gliderssh.Context), true)
 
-	pty, winCh, _ := client.Pty()
+	device := metadata.RestoreDevice(ctx.(gliderssh.Context))
+
+	policy, err := sessionPolicy(api, sess, device)
+	if err != nil {
+		return err
+	}
+
+	// The client is wrapped to know when the session was last used, closing the agent's session when it exceeds the
+	// namespace's idle timeout or maximum duration.
+	activity := session.NewActivity(client)
+	defer sess.Enforce(policy, activity, agent.Close)()
+
+	pty, winCh, _ := activity.Pty()
 
 	switch sess.GetType() {
 	case session.Term, session.Web:
-		err := shell(api, sess, sess.UID, agent, client, pty, winCh, opts)
+		err := shell(api, sess, sess.UID, agent, activity, pty, winCh, opts)
 		if err != nil {
 			return ErrRequestShell
 		}
 	case session.HereDoc:
-		err := heredoc(api, sess.UID, agent, client)
+		err := heredoc(api, sess.UID, sess.ForceCommand(), agent, activity)
 		if err != nil {
 			return ErrRequestHeredoc
 		}
 	case session.Exec, session.SCP:
-		err := exec(api, sess.UID, metadata.RestoreDevice(ctx.(gliderssh.Context)), agent, client)
+		command := activity.RawCommand()
+		if forced := sess.ForceCommand(); forced != "" {
+			command = forced
+		}
+
+		err := exec(api, sess.UID, command, device, agent, activity)
 		if err != nil {
 			return ErrRequestExec
 		}
@@ -214,6 +235,22 @@ func connectSSH(ctx context.Context, client gliderssh.Session, sess *session.Ses
 	return nil
 }
 
+// sessionPolicy gets the namespace's session policy. The session is refused when it cannot be got, as it would run
+// without the namespace's limits.
+func sessionPolicy(api internalclient.Client, sess *session.Session, device *models.Device) (session.Policy, error) {
+	settings, err := api.GetNamespaceSettings(device.TenantID)
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"client": sess.UID,
+			"tenant": device.TenantID,
+		}).Error("failed to get the namespace's session policy")
+
+		return session.Policy{}, ErrSessionPolicy
+	}
+
+	return session.NewPolicy(settings), nil
+}
+
 // exitCodeFromError gets the exit code from the client.
 //
 // If error is nil, the exit code is zero, meaning that there isn't error. If none exit code is returned, it returns 255.
@@ -275,6 +312,21 @@ func shell(api internalclient.Client, sess *session.Session, uid string, agent *
 
 	go flw.PipeIn(client, done)
 
+	var notify func(takeover bool)
+	if opts.ShadowNotify {
+		notify = func(takeover bool) {
+			message := "\r\n[ShellHub] an administrator started to watch this session\r\n"
+			if takeover {
+				message = "\r\n[ShellHub] an administrator started to watch and type in this session\r\n"
+			}
+
+			client.Write([]byte(message)) // nolint:errcheck
+		}
+	}
+
+	shadowed := shadow.Register(uid, flw.Stdin, notify)
+	defer shadow.Unregister(uid)
+
 	go func() {
 		buffer := make([]byte, 1024)
 		for {
@@ -283,6 +335,8 @@ func shell(api internalclient.Client, sess *session.Session, uid string, agent *
 				break
 			}
 
+			shadowed.Write(buffer[:read]) // nolint:errcheck
+
 			if _, err = io.Copy(client, bytes.NewReader(buffer[:read])); err != nil && err != io.EOF {
 				log.WithError(err).WithFields(log.Fields{
 					"client": uid,
@@ -314,7 +368,7 @@ func shell(api internalclient.Client, sess *session.Session, uid string, agent *
 		agent.Close()
 	}()
 
-	if err := agent.Shell(); err != nil {
+	if err := startShell(agent, sess.ForceCommand()); err != nil {
 		log.WithError(err).WithFields(log.Fields{
 			"client": uid,
 		}).Error("failed to start a new shell")
@@ -334,8 +388,17 @@ func shell(api internalclient.Client, sess *session.Session, uid string, agent *
 	return nil
 }
 
-// heredoc handles a heredoc session.
-func heredoc(api internalclient.Client, uid string, agent *gossh.Session, client gliderssh.Session) error {
+// startShell starts the shell on the agent or, when command is not empty, the command instead of it.
+func startShell(agent *gossh.Session, command string) error {
+	if command != "" {
+		return agent.Start(command)
+	}
+
+	return agent.Shell()
+}
+
+// heredoc handles a heredoc session. When command is not empty, it runs instead of the shell.
+func heredoc(api internalclient.Client, uid, command string, agent *gossh.Session, client gliderssh.Session) error {
 	if errs := api.SessionAsAuthenticated(uid); len(errs) > 0 {
 		return errs[0]
 	}
@@ -362,7 +425,7 @@ func heredoc(api internalclient.Client, uid string, agent *gossh.Session, client
 		agent.Close()
 	}()
 
-	if err := agent.Shell(); err != nil {
+	if err := startShell(agent, command); err != nil {
 		log.WithError(err).WithFields(log.Fields{
 			"client": uid,
 		}).Error("failed to start a new shell")
@@ -382,8 +445,8 @@ func heredoc(api internalclient.Client, uid string, agent *gossh.Session, client
 	return nil
 }
 
-// exec handles a non-interactive session.
-func exec(api internalclient.Client, uid string, device *models.Device, agent *gossh.Session, client gliderssh.Session) error {
+// exec handles a non-interactive session, running the command on the agent.
+func exec(api internalclient.Client, uid, command string, device *models.Device, agent *gossh.Session, client gliderssh.Session) error {
 	if errs := api.SessionAsAuthenticated(uid); len(errs) > 0 {
 		return errs[0]
 	}
@@ -413,10 +476,10 @@ func exec(api internalclient.Client, uid string, device *models.Device, agent *g
 	go flw.PipeOut(client, waitPipeOut)
 	go flw.PipeErr(client, nil)
 
-	if err := agent.Start(client.RawCommand()); err != nil {
+	if err := agent.Start(command); err != nil {
 		log.WithError(err).WithFields(log.Fields{
 			"client":  uid,
-			"command": client.RawCommand(),
+			"command": command,
 		}).Error("failed to start a command on agent")
 
 		return err
@@ -454,7 +517,7 @@ func exec(api internalclient.Client, uid string, device *models.Device, agent *g
 	if isUnknownExitError(err) {
 		log.WithError(err).WithFields(log.Fields{
 			"client":  uid,
-			"command": client.RawCommand(),
+			"command": command,
 		}).Warning("command on agent returned an error")
 	}
 
@@ -171,6 +171,9 @@ func WebSession(socket *websocket.Conn, input *web.Session) {
 		return
 	}
 
+	// The TOTP code, when required, is asked in the web terminal as the keyboard-interactive step after the others.
+	auth = append(auth, ssh.KeyboardInteractive(webChallenge(socket)))
+
 	connection, err := ssh.Dial("tcp", "localhost:2222", &ssh.ClientConfig{ //nolint: exhaustruct
 		User:            data.User,
 		Auth:            auth,
@@ -255,6 +258,75 @@ func WebSession(socket *websocket.Conn, input *web.Session) {
 	}
 }
 
+// webChallengeMaxLineSize is the maximum number of characters read for each answer of a keyboard-interactive challenge.
+const webChallengeMaxLineSize = 256
+
+// webChallenge answers the keyboard-interactive challenges of the SSH server with what the user types in the web
+// terminal.
+func webChallenge(socket *websocket.Conn) ssh.KeyboardInteractiveChallenge {
+	return func(_, instruction string, questions []string, echos []bool) ([]string, error) {
+		if instruction != "" {
+			if _, err := socket.Write([]byte(instruction + "\r\n")); err != nil {
+				return nil, err
+			}
+		}
+
+		answers := make([]string, len(questions))
+		for i, question := range questions {
+			if _, err := socket.Write([]byte(question)); err != nil {
+				return nil, err
+			}
+
+			answer, err := readLine(socket, echos[i])
+			if err != nil {
+				return nil, err
+			}
+
+			answers[i] = answer
+		}
+
+		return answers, nil
+	}
+}
+
+// readLine reads a line typed in the web terminal. The terminal does not echo the characters by itself, so they are
+// written back when echo is true, and the erase key is handled here.
+func readLine(socket io.ReadWriter, echo bool) (string, error) {
+	line := make([]byte, 0, webChallengeMaxLineSize)
+	buffer := make([]byte, 1)
+
+	for {
+		if _, err := socket.Read(buffer); err != nil {
+			return "", err
+		}
+
+		switch char := buffer[0]; char {
+		case '\r', '\n':
+			socket.Write([]byte("\r\n")) // nolint:errcheck
+
+			return string(line), nil
+		case 3, 4: // Ctrl+C and Ctrl+D.
+			return "", io.EOF
+		case '\b', 127:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+
+				if echo {
+					socket.Write([]byte("\b \b")) // nolint:errcheck
+				}
+			}
+		default:
+			if len(line) < webChallengeMaxLineSize {
+				line = append(line, char)
+
+				if echo {
+					socket.Write(buffer) // nolint:errcheck
+				}
+			}
+		}
+	}
+}
+
 func redirToWs(rd io.Reader, ws *websocket.Conn) error {
 	var buf [32 * 1024]byte
 	var start, end, buflen int
@@ -8,6 +8,7 @@ import (
 	gliderssh "github.com/gliderlabs/ssh"
 	"github.com/pires/go-proxyproto"
 	"github.com/shellhub-io/shellhub/pkg/httptunnel"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/pkg/metadata"
 	"github.com/shellhub-io/shellhub/ssh/server/auth"
 	"github.com/shellhub-io/shellhub/ssh/server/channels"
@@ -34,9 +35,9 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 	}
 
 	server.sshd = &gliderssh.Server{ // nolint: exhaustruct
-		Addr:             ":2222",
-		PasswordHandler:  auth.PasswordHandler,
-		PublicKeyHandler: auth.PublicKeyHandler,
+		Addr: ":2222",
+		// The authentication is set by the configuration's callbacks, which can require the TOTP code as a second step.
+		ServerConfigCallback: auth.ServerConfig,
 		SessionRequestCallback: func(client gliderssh.Session, request string) bool {
 			metadata.StoreRequest(client.Context(), request)
 
@@ -47,6 +48,10 @@ func NewServer(opts *Options, tunnel *httptunnel.Tunnel) *Server {
 			handler.SFTPSubsystem: handler.SFTPSubsystemHandler(tunnel),
 		},
 		LocalPortForwardingCallback: func(ctx gliderssh.Context, dhost string, dport uint32) bool {
+			if key := metadata.RestorePublicKey(ctx); key != nil {
+				return key.Restrictions.AllowsSession(models.PublicKeySessionPortForward)
+			}
+
 			return true
 		},
 		ReversePortForwardingCallback: func(ctx gliderssh.Context, bindHost string, bindPort uint32) bool {
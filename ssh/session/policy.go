@@ -0,0 +1,210 @@
+package session
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gliderssh "github.com/gliderlabs/ssh"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// warningAdvance is the maximum time, before closing a session, that the user is warned about it.
+const warningAdvance = time.Minute
+
+// enforceInterval is how often the session is checked against its policy.
+var enforceInterval = time.Second
+
+// Policy limits how long a session can stay idle and how long it can last.
+type Policy struct {
+	// IdleTimeout is the time without input or output after which the session is closed.
+	IdleTimeout time.Duration
+	// MaxDuration is the time after which the session is closed, regardless of its activity.
+	MaxDuration time.Duration
+}
+
+// NewPolicy creates a Policy from the namespace's settings.
+func NewPolicy(settings *models.NamespaceSettings) Policy {
+	if settings == nil {
+		return Policy{}
+	}
+
+	return Policy{
+		IdleTimeout: time.Duration(settings.IdleTimeout) * time.Minute,
+		MaxDuration: time.Duration(settings.MaxSessionDuration) * time.Minute,
+	}
+}
+
+// IsEmpty checks if the policy has no limit.
+func (p Policy) IsEmpty() bool {
+	return p.IdleTimeout <= 0 && p.MaxDuration <= 0
+}
+
+// advance returns how long before the limit the user should be warned.
+func advance(limit time.Duration) time.Duration {
+	if limit/2 < warningAdvance {
+		return limit / 2
+	}
+
+	return warningAdvance
+}
+
+// Activity wraps a client's session, recording the last time data was read from or written to it.
+type Activity struct {
+	gliderssh.Session
+	last atomic.Int64
+}
+
+// NewActivity wraps the client's session to record its activity.
+func NewActivity(client gliderssh.Session) *Activity {
+	activity := &Activity{Session: client}
+	activity.touch()
+
+	return activity
+}
+
+func (a *Activity) touch() {
+	a.last.Store(clock.Now().UnixNano())
+}
+
+// Last returns the last time that the session had activity.
+func (a *Activity) Last() time.Time {
+	return time.Unix(0, a.last.Load())
+}
+
+func (a *Activity) Read(data []byte) (int, error) {
+	read, err := a.Session.Read(data)
+	if read > 0 {
+		a.touch()
+	}
+
+	return read, err
+}
+
+func (a *Activity) Write(data []byte) (int, error) {
+	wrote, err := a.Session.Write(data)
+	if wrote > 0 {
+		a.touch()
+	}
+
+	return wrote, err
+}
+
+func (a *Activity) Stderr() io.ReadWriter {
+	return &activityStderr{ReadWriter: a.Session.Stderr(), activity: a}
+}
+
+type activityStderr struct {
+	io.ReadWriter
+	activity *Activity
+}
+
+func (s *activityStderr) Write(data []byte) (int, error) {
+	wrote, err := s.ReadWriter.Write(data)
+	if wrote > 0 {
+		s.activity.touch()
+	}
+
+	return wrote, err
+}
+
+// Enforce watches the session, calling closer when it exceeds one of the policy's limits. Before that, the user is
+// warned on the terminal or, when the session has no pty, on the standard error.
+//
+// The returned function stops the enforcement and must be called when the session ends.
+func (s *Session) Enforce(policy Policy, activity *Activity, closer func() error) (stop func()) {
+	if policy.IsEmpty() {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+
+	warn := func(message string) {
+		if s.Pty {
+			activity.Session.Write([]byte(fmt.Sprintf("\r\n[ShellHub] %s\r\n", message))) // nolint:errcheck
+
+			return
+		}
+
+		activity.Session.Stderr().Write([]byte(fmt.Sprintf("[ShellHub] %s\n", message))) // nolint:errcheck
+	}
+
+	finish := func(reason, message string) {
+		s.setFinishReason(reason)
+
+		warn(message)
+
+		log.WithFields(log.Fields{
+			"session": s.UID,
+			"reason":  reason,
+		}).Info("closing the session due to the namespace's session policy")
+
+		if err := closer(); err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"session": s.UID,
+			}).Warn("failed to close the session")
+		}
+	}
+
+	// The start is read before the goroutine runs, so the maximum duration is not extended by its scheduling.
+	started := clock.Now()
+
+	go func() {
+		ticker := time.NewTicker(enforceInterval)
+		defer ticker.Stop()
+
+		var warnedIdle, warnedMax bool
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			now := clock.Now()
+
+			if policy.MaxDuration > 0 {
+				remaining := started.Add(policy.MaxDuration).Sub(now)
+				switch {
+				case remaining <= 0:
+					finish(models.SessionFinishReasonMaxDuration, "this session was closed because it reached the maximum duration")
+
+					return
+				case remaining <= advance(policy.MaxDuration) && !warnedMax:
+					warn(fmt.Sprintf("this session will be closed in %s because it is reaching the maximum duration", remaining.Round(time.Second)))
+					warnedMax = true
+				}
+			}
+
+			if policy.IdleTimeout > 0 {
+				remaining := activity.Last().Add(policy.IdleTimeout).Sub(now)
+				switch {
+				case remaining <= 0:
+					finish(models.SessionFinishReasonIdle, "this session was closed due to inactivity")
+
+					return
+				case remaining <= advance(policy.IdleTimeout):
+					if !warnedIdle {
+						warn(fmt.Sprintf("this session will be closed in %s due to inactivity", remaining.Round(time.Second)))
+						warnedIdle = true
+					}
+				default:
+					// The session had activity after the warning, so the user must be warned again.
+					warnedIdle = false
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
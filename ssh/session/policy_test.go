@@ -0,0 +1,174 @@
+package session
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	internalclientmocks "github.com/shellhub-io/shellhub/pkg/api/internalclient/mocks"
+	"github.com/shellhub-io/shellhub/pkg/clock"
+	clockmocks "github.com/shellhub-io/shellhub/pkg/clock/mocks"
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// mockClock makes clock.Now return the time stored in the returned value, checking the policy every millisecond, until
+// the test ends.
+func mockClock(t *testing.T, start time.Time) *atomic.Int64 {
+	t.Helper()
+
+	now := new(atomic.Int64)
+	now.Store(start.UnixNano())
+
+	clk := new(clockmocks.Clock)
+	clk.On("Now").Return(func() time.Time {
+		return time.Unix(0, now.Load())
+	})
+
+	backend, interval := clock.DefaultBackend, enforceInterval
+	clock.DefaultBackend, enforceInterval = clk, time.Millisecond
+
+	t.Cleanup(func() {
+		clock.DefaultBackend, enforceInterval = backend, interval
+	})
+
+	return now
+}
+
+// mockClient returns a client's session whose writes are sent to the returned channel.
+func mockClient() (*mocks.Session, chan string) {
+	written := make(chan string, 16)
+
+	client := new(mocks.Session)
+	client.On("Write", mock.Anything).Return(func(data []byte) (int, error) {
+		written <- string(data)
+
+		return len(data), nil
+	})
+
+	return client, written
+}
+
+// waitClosed waits until the closer is called, failing the test when it takes too long.
+func waitClosed(t *testing.T, closed <-chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "the session was not closed")
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	assert.True(t, NewPolicy(nil).IsEmpty())
+	assert.Equal(t, Policy{IdleTimeout: 10 * time.Minute, MaxDuration: time.Hour}, NewPolicy(&models.NamespaceSettings{IdleTimeout: 10, MaxSessionDuration: 60}))
+}
+
+func TestEnforceIdleTimeout(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := mockClock(t, start)
+
+	client, written := mockClient()
+	activity := NewActivity(client)
+
+	closed := make(chan struct{})
+	sess := &Session{UID: "uid", Pty: true}
+
+	stop := sess.Enforce(Policy{IdleTimeout: 10 * time.Minute}, activity, func() error {
+		close(closed)
+
+		return nil
+	})
+	defer stop()
+
+	// The user is warned before the session is closed.
+	now.Store(start.Add(9*time.Minute + 30*time.Second).UnixNano())
+	assert.Contains(t, <-written, "will be closed in 30s due to inactivity")
+
+	// Any activity postpones the timeout.
+	activity.Write([]byte("output")) // nolint:errcheck
+	<-written
+
+	now.Store(start.Add(15 * time.Minute).UnixNano())
+	time.Sleep(10 * enforceInterval)
+
+	select {
+	case <-closed:
+		assert.FailNow(t, "the session was closed while it had activity")
+	default:
+	}
+
+	now.Store(start.Add(20 * time.Minute).UnixNano())
+	waitClosed(t, closed)
+
+	assert.Contains(t, drain(written), "this session was closed due to inactivity")
+
+	api := new(internalclientmocks.Client)
+	api.On("FinishSession", "uid", models.SessionFinishReasonIdle).Return(nil).Once()
+
+	assert.NoError(t, sess.Finish(api))
+	api.AssertExpectations(t)
+}
+
+func TestEnforceMaxDuration(t *testing.T) {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	now := mockClock(t, start)
+
+	client, written := mockClient()
+	activity := NewActivity(client)
+
+	closed := make(chan struct{})
+	sess := &Session{UID: "uid", Pty: true}
+
+	stop := sess.Enforce(Policy{IdleTimeout: 10 * time.Minute, MaxDuration: 30 * time.Minute}, activity, func() error {
+		close(closed)
+
+		return nil
+	})
+	defer stop()
+
+	// The session is closed at the maximum duration, even though it never stays idle.
+	for elapsed := 5 * time.Minute; elapsed < 30*time.Minute; elapsed += 5 * time.Minute {
+		now.Store(start.Add(elapsed).UnixNano())
+		activity.Write([]byte("output")) // nolint:errcheck
+	}
+
+	now.Store(start.Add(30 * time.Minute).UnixNano())
+	waitClosed(t, closed)
+
+	assert.Contains(t, drain(written), "this session was closed because it reached the maximum duration")
+
+	api := new(internalclientmocks.Client)
+	api.On("FinishSession", "uid", models.SessionFinishReasonMaxDuration).Return(nil).Once()
+
+	assert.NoError(t, sess.Finish(api))
+	api.AssertExpectations(t)
+}
+
+func TestFinishClosedByUser(t *testing.T) {
+	sess := &Session{UID: "uid"}
+
+	// The reason is empty when the session was not closed by the policy.
+	api := new(internalclientmocks.Client)
+	api.On("FinishSession", "uid", "").Return(nil).Once()
+
+	assert.NoError(t, sess.Finish(api))
+	api.AssertExpectations(t)
+}
+
+// drain returns everything written to the client so far.
+func drain(written chan string) string {
+	var output strings.Builder
+	for {
+		select {
+		case data := <-written:
+			output.WriteString(data)
+		default:
+			return output.String()
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	gliderssh "github.com/gliderlabs/ssh"
@@ -15,6 +16,7 @@ import (
 	"github.com/shellhub-io/shellhub/pkg/clock"
 	"github.com/shellhub-io/shellhub/pkg/envs"
 	"github.com/shellhub-io/shellhub/pkg/httptunnel"
+	"github.com/shellhub-io/shellhub/pkg/models"
 	"github.com/shellhub-io/shellhub/ssh/pkg/host"
 	"github.com/shellhub-io/shellhub/ssh/pkg/metadata"
 	log "github.com/sirupsen/logrus"
@@ -30,6 +32,11 @@ var (
 	ErrHost               = fmt.Errorf("failed to get the device address")
 	ErrFindDevice         = fmt.Errorf("failed to find the device")
 	ErrDial               = fmt.Errorf("failed to connect to device agent, please check the device connection")
+	ErrAccessRequest      = fmt.Errorf("you cannot connect to this device without an approved access request for the current time")
+	ErrAccessRequestEval  = fmt.Errorf("failed to evaluate the access request")
+	ErrAccessRequestBind  = fmt.Errorf("this device requires an approved access request, which is only checked when you connect with a public key of yours or answer the second factor challenge")
+	ErrRestrictedSession  = fmt.Errorf("this public key is not allowed to open this type of session")
+	ErrRestrictedCommand  = fmt.Errorf("this public key is not allowed to run this command")
 )
 
 type Session struct {
@@ -46,6 +53,17 @@ type Session struct {
 	Lookup        map[string]string
 	Pty           bool
 	Dialed        net.Conn
+
+	// Member is the ID of the namespace's member the session is bound to, if any. The API closes the member's sessions
+	// to a device that requires approval when its access request expires.
+	Member string `json:"member"`
+
+	// restrictions are the limits of the public key used to authenticate, if any.
+	restrictions *models.PublicKeyRestrictions
+
+	mu sync.Mutex
+	// reason is why the session was closed by ShellHub, when it was not closed by the user.
+	reason string
 }
 
 const (
@@ -144,6 +162,30 @@ func NewSession(client gliderssh.Session, tunnel *httptunnel.Tunnel) (*Session,
 		}
 	}
 
+	// Devices that require approval only accept connections bound to a member with a granted access request: the ones
+	// authenticated by a public key that belongs to the member or the ones where the member identified itself in the
+	// keyboard-interactive step. A password, like the web terminal's, authenticates the device's user, not a member,
+	// so it is refused on those devices unless the namespace's second factor identifies the member.
+	member := metadata.RestoreMember(client.Context())
+	var restrictions *models.PublicKeyRestrictions
+	if key := metadata.RestorePublicKey(client.Context()); key != nil {
+		member = key.Member
+		restrictions = key.Restrictions
+	}
+
+	allowed, err := api.EvaluateAccessRequest(device.TenantID, device.UID, member)
+	if err != nil {
+		return nil, ErrAccessRequestEval
+	}
+
+	if !allowed {
+		if member == "" {
+			return nil, ErrAccessRequestBind
+		}
+
+		return nil, ErrAccessRequest
+	}
+
 	if envs.IsCloud() && envs.HasBilling() {
 		device, err := api.GetDevice(device.UID)
 		if err != nil {
@@ -155,29 +197,37 @@ func NewSession(client gliderssh.Session, tunnel *httptunnel.Tunnel) (*Session,
 		}
 	}
 
+	uid := client.Context().Value(gliderssh.ContextKeySessionID).(string) //nolint:forcetypeassert
+
+	session := &Session{ //nolint:exhaustruct
+		Client:       client,
+		UID:          uid,
+		Username:     tag.Username,
+		IPAddress:    hos.Host,
+		Device:       device.UID,
+		Member:       member,
+		Lookup:       lookup,
+		restrictions: restrictions,
+	}
+
+	handlePty(session)
+
+	// The public key's restrictions are checked before dialing the device, so a refused session never reaches it.
+	if err := session.checkRestrictions(); err != nil {
+		return nil, err
+	}
+
 	dialed, err := tunnel.Dial(client.Context(), device.UID)
 	if err != nil {
 		return nil, ErrDial
 	}
 
-	uid := client.Context().Value(gliderssh.ContextKeySessionID).(string) //nolint:forcetypeassert
-
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/ssh/%s", uid), nil)
 	if err = req.Write(dialed); err != nil {
 		return nil, err
 	}
 
-	session := &Session{ //nolint:exhaustruct
-		Client:    client,
-		UID:       uid,
-		Username:  tag.Username,
-		IPAddress: hos.Host,
-		Device:    device.UID,
-		Lookup:    lookup,
-		Dialed:    dialed,
-	}
-
-	handlePty(session)
+	session.Dialed = dialed
 
 	session.Register(client) // nolint:errcheck
 
@@ -188,6 +238,52 @@ func (s *Session) GetType() string {
 	return s.Type
 }
 
+// checkRestrictions checks if the session's type and command are allowed by the public key's restrictions.
+func (s *Session) checkRestrictions() error {
+	var kind string
+	switch s.Type {
+	case Term, Web, HereDoc:
+		kind = models.PublicKeySessionShell
+	case Exec:
+		kind = models.PublicKeySessionExec
+	case SCP:
+		kind = models.PublicKeySessionSCP
+	case SFTP:
+		kind = models.PublicKeySessionSFTP
+	}
+
+	if !s.restrictions.AllowsSession(kind) {
+		return ErrRestrictedSession
+	}
+
+	// A forced command replaces the requested one, so there is nothing else to check. Like the OpenSSH's command
+	// option, it also replaces the subsystems, so SFTP and SCP sessions, which would reach any file, are refused unless
+	// listed explicitly.
+	if s.ForceCommand() != "" {
+		if (s.Type == SFTP || s.Type == SCP) && !s.restrictions.ListsSession(kind) {
+			return ErrRestrictedSession
+		}
+
+		return nil
+	}
+
+	if (s.Type == Exec || s.Type == SCP) && !s.restrictions.AllowsCommand(s.Client.RawCommand()) {
+		return ErrRestrictedCommand
+	}
+
+	return nil
+}
+
+// ForceCommand returns the command that the public key's restrictions force to run instead of the shell or the
+// requested one. It is empty when there is no forced command.
+func (s *Session) ForceCommand() string {
+	if s.restrictions == nil {
+		return ""
+	}
+
+	return s.restrictions.ForceCommand
+}
+
 // NewClientConnWithDeadline creates a new connection to the agent.
 func (s *Session) NewClientConnWithDeadline(config *gossh.ClientConfig) (*gossh.Client, <-chan *gossh.Request, error) {
 	const Addr = "tcp"
@@ -218,7 +314,7 @@ func (s *Session) NewClientConnWithDeadline(config *gossh.ClientConfig) (*gossh.
 // Register registers a new Client at the api.
 func (s *Session) Register(_ gliderssh.Session) error {
 	if _, err := resty.New().R().
-		SetBody(*s).
+		SetBody(s).
 		Post("http://api:8080/internal/sessions"); err != nil {
 		return err
 	}
@@ -226,7 +322,15 @@ func (s *Session) Register(_ gliderssh.Session) error {
 	return nil
 }
 
-func (s *Session) Finish() error {
+func (s *Session) setFinishReason(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reason = reason
+}
+
+// Finish closes the session on the agent and informs the API why it was closed.
+func (s *Session) Finish(api internalclient.Client) error {
 	if s.Dialed != nil {
 		request, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("/ssh/close/%s", s.UID), nil)
 
@@ -237,7 +341,11 @@ func (s *Session) Finish() error {
 		}
 	}
 
-	if errs := internalclient.NewClient().FinishSession(s.UID); len(errs) > 0 {
+	s.mu.Lock()
+	reason := s.reason
+	s.mu.Unlock()
+
+	if errs := api.FinishSession(s.UID, reason); len(errs) > 0 {
 		return errs[0]
 	}
 
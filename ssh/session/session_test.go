@@ -0,0 +1,84 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/shellhub-io/shellhub/pkg/models"
+	"github.com/shellhub-io/shellhub/ssh/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRestrictions(t *testing.T) {
+	cases := []struct {
+		description  string
+		kind         string
+		command      string
+		restrictions *models.PublicKeyRestrictions
+		expected     error
+	}{
+		{
+			description:  "allows any session without restrictions",
+			kind:         SFTP,
+			restrictions: nil,
+			expected:     nil,
+		},
+		{
+			description:  "refuses a session of a type not allowed",
+			kind:         Term,
+			restrictions: &models.PublicKeyRestrictions{SessionTypes: []string{models.PublicKeySessionExec}},
+			expected:     ErrRestrictedSession,
+		},
+		{
+			description:  "refuses a command not allowed",
+			kind:         Exec,
+			command:      "rm -rf /",
+			restrictions: &models.PublicKeyRestrictions{AllowedCommands: []string{"uptime"}},
+			expected:     ErrRestrictedCommand,
+		},
+		{
+			description:  "allows a command allowed",
+			kind:         Exec,
+			command:      "uptime",
+			restrictions: &models.PublicKeyRestrictions{AllowedCommands: []string{"uptime"}},
+			expected:     nil,
+		},
+		{
+			description:  "allows any command replaced by the forced one",
+			kind:         Exec,
+			restrictions: &models.PublicKeyRestrictions{ForceCommand: "uptime", AllowedCommands: []string{"ls"}},
+			expected:     nil,
+		},
+		{
+			description:  "refuses a SFTP session when there is a forced command",
+			kind:         SFTP,
+			restrictions: &models.PublicKeyRestrictions{ForceCommand: "uptime"},
+			expected:     ErrRestrictedSession,
+		},
+		{
+			description:  "refuses a SCP session when there is a forced command",
+			kind:         SCP,
+			restrictions: &models.PublicKeyRestrictions{ForceCommand: "uptime"},
+			expected:     ErrRestrictedSession,
+		},
+		{
+			description: "allows a SFTP session listed explicitly when there is a forced command",
+			kind:        SFTP,
+			restrictions: &models.PublicKeyRestrictions{
+				ForceCommand: "uptime",
+				SessionTypes: []string{models.PublicKeySessionShell, models.PublicKeySessionSFTP},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			client := new(mocks.Session)
+			client.On("RawCommand").Return(tc.command).Maybe()
+
+			s := &Session{Client: client, Type: tc.kind, restrictions: tc.restrictions}
+
+			assert.ErrorIs(t, s.checkRestrictions(), tc.expected)
+		})
+	}
+}